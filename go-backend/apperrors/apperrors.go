@@ -109,6 +109,42 @@ func ErrDomainNotRegistered(email string) (err error) {
 // ErrFailedToCreate - Failed to create record in database
 var ErrFailedToCreate = errors.New("Failed to create database record")
 
+// ErrHi5AlreadyGiven - the user has already given a Hi5 on the recognition
+var ErrHi5AlreadyGiven = errors.New("Hi5 already given for this recognition")
+
+// ErrAlreadyReported - the user already has a report on the recognition that isn't resolved yet
+var ErrAlreadyReported = errors.New("Recognition already reported")
+
+// ErrNothingToAppeal - the latest moderation decision on the recognition didn't hide it
+var ErrNothingToAppeal = errors.New("Recognition has no moderation decision to appeal")
+
+// ErrAlreadyAppealed - the moderation decision already has an appeal
+var ErrAlreadyAppealed = errors.New("Moderation decision already appealed")
+
+// ErrAppealAlreadyDecided - the appeal was upheld or overturned already
+var ErrAppealAlreadyDecided = errors.New("Appeal already decided")
+
+// ErrReportReasonExists - the organization already has an active report reason with the name
+var ErrReportReasonExists = errors.New("Report reason already exists")
+
+// ErrInsufficientHi5Quota - the user has no Hi5 quota balance left in the current period
+var ErrInsufficientHi5Quota = errors.New("Insufficient Hi5 quota balance")
+
+// ErrHi5BalanceCapExceeded - a grant would take the user's balance above the organization's cap
+var ErrHi5BalanceCapExceeded = errors.New("Hi5 quota balance cap exceeded")
+
+// ErrBadgeAlreadyAwarded - the user already holds the badge, whether it was granted or earned
+var ErrBadgeAlreadyAwarded = errors.New("Badge already awarded")
+
+// ErrCoreValueOrderMismatch - a reorder doesn't list every active core value under the parent exactly once
+var ErrCoreValueOrderMismatch = errors.New("Core value order doesn't match the core values")
+
+// ErrUnsupportedImage - an uploaded image can't be decoded or isn't in the format it was uploaded as
+var ErrUnsupportedImage = errors.New("Unsupported image")
+
+// ErrImageTooLarge - an uploaded image is wider or taller than we are willing to decode
+var ErrImageTooLarge = errors.New("Image dimensions too large")
+
 // -----
 // Let's make the more "generic" errors dead last in our file
 // -----
@@ -6,4 +6,10 @@ import "context"
 type AWSStorer interface {
 	// AWS S3 service
 	GetAWSS3SignedURL(context.Context, string, string) (S3SignedURL, error)
+	GetAWSS3UploadURL(context.Context, string, string, string) (S3SignedURL, error)
+	GetAWSS3DownloadURL(context.Context, string, string) (S3SignedURL, error)
+	GetAWSS3ObjectInfo(context.Context, string, string) (S3ObjectInfo, error)
+	DeleteAWSS3Object(context.Context, string, string) error
+	GetAWSS3Object(context.Context, string, string) ([]byte, error)
+	PutAWSS3Object(context.Context, string, string, string, []byte) error
 }
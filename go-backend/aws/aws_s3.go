@@ -1,7 +1,9 @@
 package aws
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -9,21 +11,135 @@ import (
 	logger "github.com/sirupsen/logrus"
 )
 
+const (
+	uploadURLExpiry   = 15 * time.Minute
+	downloadURLExpiry = 5 * time.Minute
+)
+
 type S3SignedURL struct {
 	S3SignedURL string `json:"s3_signed_url"`
 }
 
+// S3ObjectInfo - metadata of an uploaded object, used to verify uploads before accepting them
+type S3ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
 func (s *awsSession) GetAWSS3SignedURL(ctx context.Context, bucketName, fileName string) (signedURL S3SignedURL, err error) {
+	return s.GetAWSS3UploadURL(ctx, bucketName, fileName, "image/jpeg")
+}
+
+// GetAWSS3UploadURL - presigns a PUT for the given key, restricted to the given content type
+func (s *awsSession) GetAWSS3UploadURL(ctx context.Context, bucketName, fileName, contentType string) (signedURL S3SignedURL, err error) {
 	serviceClient := s3.New(s.awsConnection)
 	req, _ := serviceClient.PutObjectRequest(&s3.PutObjectInput{
 		Bucket:      aws.String(bucketName),
 		Key:         aws.String(fileName),
-		ContentType: aws.String("image/jpeg"),
+		ContentType: aws.String(contentType),
 	})
-	signedURL.S3SignedURL, err = req.Presign(15 * time.Minute)
+	signedURL.S3SignedURL, err = req.Presign(uploadURLExpiry)
 	if err != nil {
 		logger.WithField("err", err.Error()).Error("Failed to sign request")
 		return
 	}
 	return
 }
+
+// GetAWSS3DownloadURL - presigns a short-lived GET for the given key
+func (s *awsSession) GetAWSS3DownloadURL(ctx context.Context, bucketName, fileName string) (signedURL S3SignedURL, err error) {
+	serviceClient := s3.New(s.awsConnection)
+	req, _ := serviceClient.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	signedURL.S3SignedURL, err = req.Presign(downloadURLExpiry)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Failed to sign request")
+		return
+	}
+	return
+}
+
+// GetAWSS3ObjectInfo - returns the size and content type of an object, failing if it doesn't exist
+func (s *awsSession) GetAWSS3ObjectInfo(ctx context.Context, bucketName, fileName string) (info S3ObjectInfo, err error) {
+	serviceClient := s3.New(s.awsConnection)
+	output, err := serviceClient.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err": err.Error(),
+			"key": fileName,
+		}).Error("Failed to fetch object info")
+		return
+	}
+
+	info.Size = aws.Int64Value(output.ContentLength)
+	info.ContentType = aws.StringValue(output.ContentType)
+	return
+}
+
+// DeleteAWSS3Object - removes an object from the bucket
+func (s *awsSession) DeleteAWSS3Object(ctx context.Context, bucketName, fileName string) (err error) {
+	serviceClient := s3.New(s.awsConnection)
+	_, err = serviceClient.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err": err.Error(),
+			"key": fileName,
+		}).Error("Failed to delete object")
+		return
+	}
+	return
+}
+
+// GetAWSS3Object - reads a whole object, only meant for objects whose size was already checked
+func (s *awsSession) GetAWSS3Object(ctx context.Context, bucketName, fileName string) (body []byte, err error) {
+	serviceClient := s3.New(s.awsConnection)
+	output, err := serviceClient.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(fileName),
+	})
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err": err.Error(),
+			"key": fileName,
+		}).Error("Failed to fetch object")
+		return
+	}
+	defer output.Body.Close()
+
+	body, err = ioutil.ReadAll(output.Body)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err": err.Error(),
+			"key": fileName,
+		}).Error("Failed to read object")
+		return
+	}
+	return
+}
+
+// PutAWSS3Object - stores an object generated by the server, e.g. a thumbnail
+func (s *awsSession) PutAWSS3Object(ctx context.Context, bucketName, fileName, contentType string, body []byte) (err error) {
+	serviceClient := s3.New(s.awsConnection)
+	_, err = serviceClient.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucketName),
+		Key:         aws.String(fileName),
+		ContentType: aws.String(contentType),
+		Body:        bytes.NewReader(body),
+	})
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err": err.Error(),
+			"key": fileName,
+		}).Error("Failed to store object")
+		return
+	}
+	return
+}
@@ -0,0 +1,54 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// AWSMockStore - test mock struct
+type AWSMockStore struct {
+	mock.Mock
+}
+
+// GetAWSS3SignedURL - test mock
+func (m *AWSMockStore) GetAWSS3SignedURL(ctx context.Context, bucketName, fileName string) (signedURL S3SignedURL, err error) {
+	args := m.Called(ctx, bucketName, fileName)
+	return args.Get(0).(S3SignedURL), args.Error(1)
+}
+
+// GetAWSS3UploadURL - test mock
+func (m *AWSMockStore) GetAWSS3UploadURL(ctx context.Context, bucketName, fileName, contentType string) (signedURL S3SignedURL, err error) {
+	args := m.Called(ctx, bucketName, fileName, contentType)
+	return args.Get(0).(S3SignedURL), args.Error(1)
+}
+
+// GetAWSS3DownloadURL - test mock
+func (m *AWSMockStore) GetAWSS3DownloadURL(ctx context.Context, bucketName, fileName string) (signedURL S3SignedURL, err error) {
+	args := m.Called(ctx, bucketName, fileName)
+	return args.Get(0).(S3SignedURL), args.Error(1)
+}
+
+// GetAWSS3ObjectInfo - test mock
+func (m *AWSMockStore) GetAWSS3ObjectInfo(ctx context.Context, bucketName, fileName string) (info S3ObjectInfo, err error) {
+	args := m.Called(ctx, bucketName, fileName)
+	return args.Get(0).(S3ObjectInfo), args.Error(1)
+}
+
+// DeleteAWSS3Object - test mock
+func (m *AWSMockStore) DeleteAWSS3Object(ctx context.Context, bucketName, fileName string) (err error) {
+	args := m.Called(ctx, bucketName, fileName)
+	return args.Error(0)
+}
+
+// GetAWSS3Object - test mock
+func (m *AWSMockStore) GetAWSS3Object(ctx context.Context, bucketName, fileName string) (body []byte, err error) {
+	args := m.Called(ctx, bucketName, fileName)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+// PutAWSS3Object - test mock
+func (m *AWSMockStore) PutAWSS3Object(ctx context.Context, bucketName, fileName, contentType string, body []byte) (err error) {
+	args := m.Called(ctx, bucketName, fileName, contentType, body)
+	return args.Error(0)
+}
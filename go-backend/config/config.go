@@ -59,6 +59,16 @@ func JWTExpiryDurationHours() int {
 	return int(ReadEnvInt("JWT_EXPIRY_DURATION_HOURS"))
 }
 
+// AttachmentsBucket - returns the S3 bucket that recognition attachments are uploaded to
+func AttachmentsBucket() string {
+	return ReadEnvString("AWS_ATTACHMENTS_BUCKET")
+}
+
+// ImagesBucket - returns the S3 bucket that badge artwork and its thumbnails are stored in
+func ImagesBucket() string {
+	return ReadEnvString("AWS_IMAGES_BUCKET")
+}
+
 // ReadEnvInt - reads an environment variable as an integer
 func ReadEnvInt(key string) int {
 	checkIfSet(key)
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 
 	logger "github.com/sirupsen/logrus"
 )
@@ -11,28 +12,35 @@ const (
 		name,
 		org_id,
 		hi5_count_required,
-		hi5_frequency)
-		VALUES ($1, $2, $3, $4) RETURNING id`
+		hi5_frequency,
+		criteria)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id`
 
 	getBadgeQuery = `SELECT id,
 		name,
 		org_id,
 		hi5_count_required,
-		hi5_frequency FROM badges WHERE id = $1 and org_id = $2`
+		hi5_frequency,
+		criteria,
+		image_key FROM badges WHERE id = $1 and org_id = $2`
 
 	listBadgesQuery = `SELECT id,
 		name,
 		org_id,
 		hi5_count_required,
-		hi5_frequency FROM badges where org_id = $1 ORDER BY name ASC`
+		hi5_frequency,
+		criteria,
+		image_key FROM badges where org_id = $1 ORDER BY name ASC`
 
 	updateBadgesQuery = `UPDATE badges SET (
 		name,
 		hi5_count_required,
-		hi5_frequency) =
-		($1, $2, $3) where (id = $4 and org_id = $5) AND id NOT IN(SELECT badge_id from user_badges)`
+		hi5_frequency,
+		criteria) =
+		($1, $2, $3, $4) where (id = $5 and org_id = $6) AND id NOT IN(SELECT badge_id from user_badges)`
 
-	deleteBadgeQuery = `DELETE FROM badges WHERE (id = $1 and org_id = $2) AND id NOT IN(SELECT badge_id from user_badges)`
+	deleteBadgeQuery = `DELETE FROM badges WHERE (id = $1 and org_id = $2) AND id NOT IN(SELECT badge_id from user_badges)
+		RETURNING image_key`
 )
 
 type Badge struct {
@@ -41,6 +49,12 @@ type Badge struct {
 	OrganizationID   int    `db:"org_id" json:"org_id"`
 	Hi5CountRequired int    `db:"hi5_count_required" json:"hi5_count_required"`
 	Hi5Frequency     string `db:"hi5_frequency" json:"hi5_frequency"`
+	// Criteria - when set, replaces hi5_count_required and hi5_frequency
+	Criteria *BadgeCriteria `db:"criteria" json:"criteria,omitempty"`
+	// ImageKey - object key of the artwork, its thumbnails are stored next to it
+	ImageKey string `db:"image_key" json:"-"`
+	// Image - signed URLs of the artwork, filled in by the handlers; null when there is none
+	Image *BadgeImage `db:"-" json:"image"`
 }
 
 func (badge *Badge) Validate() (errorResponse map[string]ErrorResponse, valid bool) {
@@ -49,6 +63,21 @@ func (badge *Badge) Validate() (errorResponse map[string]ErrorResponse, valid bo
 		fieldErrors["name"] = "Can't be blank"
 	}
 
+	if badge.Criteria != nil {
+		for field, message := range badge.Criteria.Validate() {
+			fieldErrors[field] = message
+		}
+	} else {
+		if badge.Hi5CountRequired <= 0 {
+			fieldErrors["hi5_count_required"] = "Must be greater than 0"
+		}
+
+		_, err := badge.Hi5WindowDays()
+		if err != nil {
+			fieldErrors["hi5_frequency"] = err.Error()
+		}
+	}
+
 	if len(fieldErrors) == 0 {
 		valid = true
 		return
@@ -72,6 +101,7 @@ func (s *pgStore) CreateBadge(ctx context.Context, badge Badge) (createdBadge Ba
 		badge.OrganizationID,
 		badge.Hi5CountRequired,
 		badge.Hi5Frequency,
+		badge.Criteria,
 	).Scan(&lastInsertId)
 
 	if err != nil {
@@ -116,6 +146,7 @@ func (s *pgStore) UpdateBadge(ctx context.Context, badge Badge) (updatedBadge Ba
 		badge.Name,
 		badge.Hi5CountRequired,
 		badge.Hi5Frequency,
+		badge.Criteria,
 		badge.ID,
 		badge.OrganizationID)
 	if err != nil {
@@ -142,16 +173,42 @@ func (s *pgStore) ShowBadge(ctx context.Context, badge Badge) (dbBadge Badge, er
 	return
 }
 
-func (s *pgStore) DeleteBadge(ctx context.Context, organizationID, id int) (err error) {
-	_, err = s.db.Exec(
-		deleteBadgeQuery,
-		organizationID,
-		id,
-	)
+// DeleteBadge - deletes the badge unless it has been awarded. Returns the keys of the objects stored
+// for it, its artwork, thumbnails and unconfirmed uploads, so they can be removed from storage.
+func (s *pgStore) DeleteBadge(ctx context.Context, organizationID, id int) (objectKeys []string, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	var uploadKeys []string
+	err = tx.SelectContext(ctx, &uploadKeys, listBadgeImageUploadKeysQuery, id, organizationID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while listing badge image uploads")
+		return
+	}
+
+	var imageKey string
+	err = tx.GetContext(ctx, &imageKey, deleteBadgeQuery, id, organizationID)
+	if err == sql.ErrNoRows {
+		// nothing was deleted, so the objects are still in use
+		err = nil
+		return
+	}
 	if err != nil {
 		logger.WithField("err", err.Error()).Error("Error while deleting badge")
 		return
 	}
 
+	objectKeys = append(BadgeImageObjectKeys(imageKey), uploadKeys...)
 	return
 }
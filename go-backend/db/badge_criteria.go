@@ -0,0 +1,171 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// Metrics a badge can be earned on
+	Hi5sReceivedMetric         = "hi5s_received"
+	RecognitionsReceivedMetric = "recognitions_received"
+	RecognitionsGivenMetric    = "recognitions_given"
+	DistinctGiversMetric       = "distinct_givers"
+	// WeeklyStreakMetric - consecutive weeks, up to the current or the previous one, with a recognition received
+	WeeklyStreakMetric = "weekly_streak"
+
+	BronzeTier = "bronze"
+	SilverTier = "silver"
+	GoldTier   = "gold"
+)
+
+var badgeMetrics = map[string]bool{
+	Hi5sReceivedMetric:         true,
+	RecognitionsReceivedMetric: true,
+	RecognitionsGivenMetric:    true,
+	DistinctGiversMetric:       true,
+	WeeklyStreakMetric:         true,
+}
+
+// BadgeCriteria - what a user has to achieve to earn a badge. Either a single threshold or
+// bronze, silver and gold tiers are set. Recognitions can be restricted to some core values.
+type BadgeCriteria struct {
+	Metric       string  `json:"metric"`
+	CoreValueIDs []int64 `json:"core_value_ids,omitempty"`
+	// WindowDays - only count the last this many days; 0 counts everything. Not used by streaks.
+	WindowDays int         `json:"window_days,omitempty"`
+	Threshold  int         `json:"threshold,omitempty"`
+	Tiers      *BadgeTiers `json:"tiers,omitempty"`
+}
+
+// BadgeTiers - thresholds of a tiered badge, each higher than the one before
+type BadgeTiers struct {
+	Bronze int `json:"bronze"`
+	Silver int `json:"silver"`
+	Gold   int `json:"gold"`
+}
+
+// BadgeLevel - a threshold a badge is awarded at. Untiered badges have a single level with no tier.
+type BadgeLevel struct {
+	Tier      string `json:"tier"`
+	Threshold int    `json:"threshold"`
+}
+
+// Levels - the badge's levels, lowest first
+func (criteria BadgeCriteria) Levels() []BadgeLevel {
+	if criteria.Tiers == nil {
+		return []BadgeLevel{{Threshold: criteria.Threshold}}
+	}
+
+	return []BadgeLevel{
+		{Tier: BronzeTier, Threshold: criteria.Tiers.Bronze},
+		{Tier: SilverTier, Threshold: criteria.Tiers.Silver},
+		{Tier: GoldTier, Threshold: criteria.Tiers.Gold},
+	}
+}
+
+// Validate - returns the errors keyed by field, prefixed with "criteria."
+func (criteria BadgeCriteria) Validate() (errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if !badgeMetrics[criteria.Metric] {
+		errFields["criteria.metric"] = fmt.Sprintf("Must be one of %s, %s, %s, %s or %s", Hi5sReceivedMetric,
+			RecognitionsReceivedMetric, RecognitionsGivenMetric, DistinctGiversMetric, WeeklyStreakMetric)
+	}
+
+	seen := make(map[int64]bool)
+	for _, coreValueID := range criteria.CoreValueIDs {
+		if coreValueID <= 0 || seen[coreValueID] {
+			errFields["criteria.core_value_ids"] = "Must be distinct core value ids"
+			break
+		}
+		seen[coreValueID] = true
+	}
+
+	if criteria.WindowDays < 0 {
+		errFields["criteria.window_days"] = "Can't be negative"
+	} else if criteria.WindowDays > 0 && criteria.Metric == WeeklyStreakMetric {
+		errFields["criteria.window_days"] = "Can't be set for streaks"
+	}
+
+	switch {
+	case criteria.Tiers != nil && criteria.Threshold != 0:
+		errFields["criteria.threshold"] = "Can't be set together with tiers"
+	case criteria.Tiers != nil:
+		tiers := criteria.Tiers
+		if tiers.Bronze <= 0 {
+			errFields["criteria.tiers.bronze"] = "Must be greater than 0"
+		}
+		if tiers.Silver <= tiers.Bronze {
+			errFields["criteria.tiers.silver"] = "Must be greater than bronze"
+		}
+		if tiers.Gold <= tiers.Silver {
+			errFields["criteria.tiers.gold"] = "Must be greater than silver"
+		}
+	case criteria.Threshold <= 0:
+		errFields["criteria.threshold"] = "Must be greater than 0"
+	}
+	return
+}
+
+// Value - stores the criteria as JSON
+func (criteria BadgeCriteria) Value() (driver.Value, error) {
+	return json.Marshal(criteria)
+}
+
+// Scan - reads criteria stored as JSON
+func (criteria *BadgeCriteria) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, criteria)
+	case string:
+		return json.Unmarshal([]byte(data), criteria)
+	}
+	return fmt.Errorf("Unsupported badge criteria type %T", src)
+}
+
+// EffectiveCriteria - badges created before criteria existed count Hi5s received within hi5_frequency
+func (badge Badge) EffectiveCriteria() (criteria BadgeCriteria, err error) {
+	if badge.Criteria != nil {
+		criteria = *badge.Criteria
+		return
+	}
+
+	days, err := badge.Hi5WindowDays()
+	if err != nil {
+		return
+	}
+
+	criteria = BadgeCriteria{
+		Metric:     Hi5sReceivedMetric,
+		WindowDays: days,
+		Threshold:  badge.Hi5CountRequired,
+	}
+	return
+}
+
+// weeklyStreak - consecutive weeks ending in currentWeek or the week before. weeks must be
+// distinct and sorted newest first; a streak still counts until a whole week has been missed.
+func weeklyStreak(weeks []int64, currentWeek int64) (streak int) {
+	expected := currentWeek
+	for i, week := range weeks {
+		if i == 0 && week == currentWeek-1 {
+			expected = week
+		}
+		if week > expected {
+			continue
+		}
+		if week != expected {
+			break
+		}
+		streak++
+		expected--
+	}
+	return
+}
+
+// unixWeek - weeks since the first Monday after the epoch, in UTC
+func unixWeek(unix int64) int64 {
+	return (unix - firstUnixMonday) / secondsInWeek
+}
@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/jmoiron/sqlx"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// AutomaticBadgeAward - earned by meeting the badge's criteria
+	AutomaticBadgeAward = "automatic"
+	// ManualBadgeAward - granted by an admin, the badge engine never touches these
+	ManualBadgeAward = "manual"
+
+	BadgeGrantAction  = "grant"
+	BadgeRevokeAction = "revoke"
+
+	// MaxBadgeAwardAuditEntries - most audit entries returned in one listing
+	MaxBadgeAwardAuditEntries = 100
+
+	grantBadgeQuery = `INSERT INTO user_badges (user_id, badge_id, tier, achieved_count, awarded_at, source, granted_by, note)
+		VALUES ($1, $2, $3, 0, $4, 'manual', $5, $6)
+		ON CONFLICT (user_id, badge_id, tier) DO NOTHING
+		RETURNING id, user_id, badge_id, tier, achieved_count, awarded_at, source, granted_by, note`
+
+	// automatic awards are left to the engine, only manual grants can be revoked
+	revokeBadgeQuery = `DELETE FROM user_badges
+		WHERE user_id = $1 AND badge_id = $2 AND tier = $3 AND source = 'manual'`
+
+	insertBadgeAwardAuditQuery = `INSERT INTO badge_award_audit (org_id, user_id, badge_id, tier, action, actor_id, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, org_id, user_id, badge_id, tier, action, actor_id, note, created_at`
+
+	listBadgeAwardAuditQuery = `SELECT id, org_id, user_id, badge_id, tier, action, actor_id, note, created_at
+		FROM badge_award_audit WHERE org_id = $1 ORDER BY created_at DESC, id DESC LIMIT $2`
+)
+
+// BadgeGrant - an admin granting a badge to, or revoking it from, a user
+type BadgeGrant struct {
+	OrgID   int    `json:"-"`
+	BadgeID int    `json:"-"`
+	ActorID int    `json:"-"`
+	UserID  int    `json:"user_id"`
+	Tier    string `json:"tier"`
+	Note    string `json:"note"`
+}
+
+// Validate - a grant needs a user, a note saying why and, for tiered badges, one of the badge's tiers
+func (grant BadgeGrant) Validate(badge Badge) (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if grant.UserID <= 0 {
+		errFields["user_id"] = "Can't be blank"
+	}
+	if strings.TrimSpace(grant.Note) == "" {
+		errFields["note"] = "Can't be blank"
+	}
+
+	criteria, err := badge.EffectiveCriteria()
+	if err == nil {
+		tierFound := false
+		for _, level := range criteria.Levels() {
+			if level.Tier == grant.Tier {
+				tierFound = true
+			}
+		}
+		if !tierFound && criteria.Tiers != nil {
+			errFields["tier"] = "Must be " + BronzeTier + ", " + SilverTier + " or " + GoldTier
+		} else if !tierFound {
+			errFields["tier"] = "Must be blank, the badge has no tiers"
+		}
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// BadgeAwardAuditEntry - a manual grant or revocation
+type BadgeAwardAuditEntry struct {
+	ID        int64  `db:"id" json:"id"`
+	OrgID     int    `db:"org_id" json:"org_id"`
+	UserID    int    `db:"user_id" json:"user_id"`
+	BadgeID   int    `db:"badge_id" json:"badge_id"`
+	Tier      string `db:"tier" json:"tier"`
+	Action    string `db:"action" json:"action"`
+	ActorID   int    `db:"actor_id" json:"actor_id"`
+	Note      string `db:"note" json:"note"`
+	CreatedAt int64  `db:"created_at" json:"created_at"`
+}
+
+// GrantBadge - awards the badge manually and records it in the audit trail.
+// Fails with ErrBadgeAlreadyAwarded if the user already holds that tier of the badge.
+func (s *pgStore) GrantBadge(ctx context.Context, grant BadgeGrant) (userBadge UserBadge, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	now := time.Now().Unix()
+	err = tx.GetContext(
+		ctx,
+		&userBadge,
+		grantBadgeQuery,
+		grant.UserID,
+		grant.BadgeID,
+		grant.Tier,
+		now,
+		grant.ActorID,
+		strings.TrimSpace(grant.Note),
+	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrBadgeAlreadyAwarded
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":          err.Error(),
+			"grant_params": grant,
+		}).Error("Error while granting badge")
+		return
+	}
+
+	_, err = insertBadgeAwardAudit(ctx, tx, grant, BadgeGrantAction, now)
+	return
+}
+
+// RevokeBadge - removes a manual grant and records it in the audit trail.
+// Fails with ErrRecordNotFound if the user holds no manual grant of that tier of the badge.
+func (s *pgStore) RevokeBadge(ctx context.Context, grant BadgeGrant) (entry BadgeAwardAuditEntry, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, revokeBadgeQuery, grant.UserID, grant.BadgeID, grant.Tier)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":          err.Error(),
+			"grant_params": grant,
+		}).Error("Error while revoking badge")
+		return
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if revoked == 0 {
+		err = ae.ErrRecordNotFound
+		return
+	}
+
+	entry, err = insertBadgeAwardAudit(ctx, tx, grant, BadgeRevokeAction, time.Now().Unix())
+	return
+}
+
+func insertBadgeAwardAudit(ctx context.Context, tx *sqlx.Tx, grant BadgeGrant, action string, now int64) (entry BadgeAwardAuditEntry, err error) {
+	err = tx.GetContext(
+		ctx,
+		&entry,
+		insertBadgeAwardAuditQuery,
+		grant.OrgID,
+		grant.UserID,
+		grant.BadgeID,
+		grant.Tier,
+		action,
+		grant.ActorID,
+		strings.TrimSpace(grant.Note),
+		now,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":          err.Error(),
+			"grant_params": grant,
+		}).Error("Error while recording badge award audit")
+		return
+	}
+
+	return
+}
+
+// ListBadgeAwardAudit - the organization's most recent manual grants and revocations, newest first
+func (s *pgStore) ListBadgeAwardAudit(ctx context.Context, orgID int) (entries []BadgeAwardAuditEntry, err error) {
+	entries = make([]BadgeAwardAuditEntry, 0)
+	err = s.db.SelectContext(ctx, &entries, listBadgeAwardAuditQuery, orgID, MaxBadgeAwardAuditEntries)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing badge award audit")
+		return
+	}
+
+	return
+}
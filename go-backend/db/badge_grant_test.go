@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BadgeGrantTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *BadgeGrantTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *BadgeGrantTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+var testBadgeGrant = BadgeGrant{OrgID: 1, BadgeID: 2, ActorID: 1, UserID: 7, Note: "Won the July hackathon"}
+
+func (suite *BadgeGrantTestSuite) TestValidate() {
+	tiered := Badge{Criteria: &BadgeCriteria{Metric: RecognitionsGivenMetric, Tiers: &BadgeTiers{Bronze: 1, Silver: 2, Gold: 3}}}
+
+	valid, errFields := BadgeGrant{Note: " "}.Validate(tiered)
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"user_id": "Can't be blank",
+		"note":    "Can't be blank",
+		"tier":    "Must be bronze, silver or gold",
+	}, errFields)
+
+	valid, errFields = BadgeGrant{UserID: 7, Tier: GoldTier, Note: "Hackathon"}.Validate(Badge{Hi5CountRequired: 5})
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{"tier": "Must be blank, the badge has no tiers"}, errFields)
+
+	valid, _ = BadgeGrant{UserID: 7, Tier: GoldTier, Note: "Hackathon"}.Validate(tiered)
+	assert.True(suite.T(), valid)
+}
+
+func (suite *BadgeGrantTestSuite) TestGrantBadge() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO user_badges").
+		WithArgs(7, 2, "", sqlmock.AnyArg(), 1, "Won the July hackathon").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "badge_id", "tier", "achieved_count", "awarded_at", "source", "granted_by", "note"}).
+			AddRow(3, 7, 2, "", 0, 1594512000, ManualBadgeAward, 1, "Won the July hackathon"))
+	suite.sqlmock.ExpectQuery("INSERT INTO badge_award_audit").
+		WithArgs(1, 7, 2, "", BadgeGrantAction, 1, "Won the July hackathon", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.sqlmock.ExpectCommit()
+
+	userBadge, err := suite.dbStore.GrantBadge(context.Background(), testBadgeGrant)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), ManualBadgeAward, userBadge.Source)
+	assert.Equal(suite.T(), 1, *userBadge.GrantedBy)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *BadgeGrantTestSuite) TestGrantBadgeAlreadyAwarded() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO user_badges").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.GrantBadge(context.Background(), testBadgeGrant)
+
+	assert.Equal(suite.T(), ae.ErrBadgeAlreadyAwarded, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *BadgeGrantTestSuite) TestRevokeBadgeOnlyRevokesManualGrants() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("DELETE FROM user_badges (.+) source = 'manual'").
+		WithArgs(7, 2, "").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.RevokeBadge(context.Background(), testBadgeGrant)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *BadgeGrantTestSuite) TestRevokeBadge() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("DELETE FROM user_badges").
+		WithArgs(7, 2, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO badge_award_audit").
+		WithArgs(1, 7, 2, "", BadgeRevokeAction, 1, "Won the July hackathon", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "action"}).AddRow(2, BadgeRevokeAction))
+	suite.sqlmock.ExpectCommit()
+
+	entry, err := suite.dbStore.RevokeBadge(context.Background(), testBadgeGrant)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), BadgeRevokeAction, entry.Action)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
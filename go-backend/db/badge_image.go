@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxBadgeImageSizeBytes - largest file accepted as badge artwork (2 MB)
+	MaxBadgeImageSizeBytes = 2 * 1024 * 1024
+	// MaxBadgeImageDimension - widest or tallest artwork that gets decoded to generate thumbnails
+	MaxBadgeImageDimension = 2048
+	// BadgeThumbnailContentType - thumbnails are generated as PNG whatever the artwork was uploaded as
+	BadgeThumbnailContentType = "image/png"
+
+	createBadgeImageUploadQuery = `INSERT INTO badge_image_uploads (badge_id, uploaded_by, object_key, content_type, size_bytes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, badge_id, uploaded_by, object_key, content_type, size_bytes, created_at`
+
+	getBadgeImageUploadQuery = `SELECT id, badge_id, uploaded_by, object_key, content_type, size_bytes, created_at
+		FROM badge_image_uploads WHERE badge_id = $1 AND id = $2`
+
+	deleteBadgeImageUploadQuery = `DELETE FROM badge_image_uploads WHERE badge_id = $1 AND id = $2`
+
+	setBadgeImageQuery = `UPDATE badges SET image_key = $1 WHERE id = $2 AND org_id = $3`
+
+	listBadgeImageUploadKeysQuery = `SELECT u.object_key FROM badge_image_uploads u
+		JOIN badges b ON b.id = u.badge_id WHERE b.id = $1 AND b.org_id = $2`
+)
+
+// BadgeThumbnailSizes - the square, in pixels, each badge thumbnail is scaled to fit in
+var BadgeThumbnailSizes = []int{64, 128, 256}
+
+// BadgeImage - short-lived URLs of a badge's artwork and its thumbnails
+type BadgeImage struct {
+	URL        string           `json:"url"`
+	Thumbnails []BadgeThumbnail `json:"thumbnails"`
+}
+
+// BadgeThumbnail - the artwork scaled to fit in a Size x Size square
+type BadgeThumbnail struct {
+	Size int    `json:"size"`
+	URL  string `json:"url"`
+}
+
+// BadgeImageUpload - an upload slot handed out for new badge artwork, until the upload is confirmed
+type BadgeImageUpload struct {
+	ID          int64     `db:"id" json:"id"`
+	BadgeID     int       `db:"badge_id" json:"badge_id"`
+	UploadedBy  int       `db:"uploaded_by" json:"uploaded_by"`
+	ObjectKey   string    `db:"object_key" json:"-"`
+	ContentType string    `db:"content_type" json:"content_type"`
+	SizeBytes   int64     `db:"size_bytes" json:"size_bytes"`
+	CreatedAt   time.Time `db:"created_at" json:"-"`
+}
+
+// Validate - checks the upload slot request before any object key is handed out
+func (upload BadgeImageUpload) Validate() (valid bool, errFields map[string]string) {
+	errFields = validateImageUpload(upload.ContentType, upload.SizeBytes, MaxBadgeImageSizeBytes)
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// VerifyUpload - compares the object that actually landed in storage with what the client asked to upload
+func (upload BadgeImageUpload) VerifyUpload(size int64, contentType string) (valid bool, errFields map[string]string) {
+	errFields = verifyImageUpload(upload.SizeBytes, upload.ContentType, size, contentType, MaxBadgeImageSizeBytes)
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// Format - the image format the upload has to decode as
+func (upload BadgeImageUpload) Format() string {
+	return imageFormats[upload.ContentType]
+}
+
+func newBadgeImageObjectKey(badgeID int, contentType string) string {
+	return fmt.Sprintf("badges/%d/%s.%s", badgeID, uuid.New().String(), imageFormats[contentType])
+}
+
+// BadgeThumbnailKey - where the thumbnail of the given size is stored, next to the artwork
+func BadgeThumbnailKey(imageKey string, size int) string {
+	return resizedImageKey(imageKey, size)
+}
+
+// BadgeImageObjectKeys - the artwork and all of its thumbnails, nothing when there is no artwork
+func BadgeImageObjectKeys(imageKey string) (objectKeys []string) {
+	if imageKey == "" {
+		return
+	}
+
+	objectKeys = append(objectKeys, imageKey)
+	for _, size := range BadgeThumbnailSizes {
+		objectKeys = append(objectKeys, BadgeThumbnailKey(imageKey, size))
+	}
+	return
+}
+
+func (s *pgStore) CreateBadgeImageUpload(ctx context.Context, upload BadgeImageUpload) (resp BadgeImageUpload, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&resp,
+		createBadgeImageUploadQuery,
+		upload.BadgeID,
+		upload.UploadedBy,
+		newBadgeImageObjectKey(upload.BadgeID, upload.ContentType),
+		upload.ContentType,
+		upload.SizeBytes,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"upload_params": upload,
+		}).Error("Error while creating badge image upload")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) GetBadgeImageUpload(ctx context.Context, badgeID int, uploadID int64) (upload BadgeImageUpload, err error) {
+	err = s.db.GetContext(ctx, &upload, getBadgeImageUploadQuery, badgeID, uploadID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"badge_id":  badgeID,
+			"upload_id": uploadID,
+		}).Error("Error while getting badge image upload")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) DeleteBadgeImageUpload(ctx context.Context, badgeID int, uploadID int64) (err error) {
+	_, err = s.db.ExecContext(ctx, deleteBadgeImageUploadQuery, badgeID, uploadID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"badge_id":  badgeID,
+			"upload_id": uploadID,
+		}).Error("Error while deleting badge image upload")
+		return
+	}
+
+	return
+}
+
+// ConfirmBadgeImageUpload - makes the verified upload the badge's artwork. Fails with
+// ErrRecordNotFound if the upload was already confirmed, so it can only be applied once.
+func (s *pgStore) ConfirmBadgeImageUpload(ctx context.Context, orgID int, upload BadgeImageUpload) (badge Badge, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, deleteBadgeImageUploadQuery, upload.BadgeID, upload.ID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"upload_id": upload.ID,
+		}).Error("Error while confirming badge image upload")
+		return
+	}
+
+	confirmed, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if confirmed == 0 {
+		err = ae.ErrRecordNotFound
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, setBadgeImageQuery, upload.ObjectKey, upload.BadgeID, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":      err.Error(),
+			"badge_id": upload.BadgeID,
+		}).Error("Error while setting badge image")
+		return
+	}
+
+	err = tx.GetContext(ctx, &badge, getBadgeQuery, upload.BadgeID, orgID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while getting badge")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"regexp"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BadgeImageTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *BadgeImageTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *BadgeImageTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+var testBadgeImageUpload = BadgeImageUpload{ID: 4, BadgeID: 2, UploadedBy: 1, ObjectKey: "badges/2/new.png", ContentType: "image/png", SizeBytes: 1024}
+
+func (suite *BadgeImageTestSuite) TestValidate() {
+	valid, errFields := BadgeImageUpload{ContentType: "image/svg+xml", SizeBytes: MaxBadgeImageSizeBytes + 1}.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"content_type": "Must be image/png, image/jpeg or image/gif",
+		"size_bytes":   "Must be between 1 and 2097152 bytes",
+	}, errFields)
+
+	valid, _ = BadgeImageUpload{ContentType: "image/jpeg", SizeBytes: 1024}.Validate()
+	assert.True(suite.T(), valid)
+}
+
+func (suite *BadgeImageTestSuite) TestVerifyUpload() {
+	valid, errFields := testBadgeImageUpload.VerifyUpload(2048, "image/gif")
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"size_bytes":   "Uploaded file size doesn't match the requested size",
+		"content_type": "Uploaded file type doesn't match the requested type",
+	}, errFields)
+
+	valid, _ = testBadgeImageUpload.VerifyUpload(1024, "image/png")
+	assert.True(suite.T(), valid)
+}
+
+func (suite *BadgeImageTestSuite) TestBadgeImageObjectKeys() {
+	assert.Equal(suite.T(), "badges/2/art_64.png", BadgeThumbnailKey("badges/2/art.jpeg", 64))
+	assert.Equal(suite.T(), []string{
+		"badges/2/art.gif",
+		"badges/2/art_64.png",
+		"badges/2/art_128.png",
+		"badges/2/art_256.png",
+	}, BadgeImageObjectKeys("badges/2/art.gif"))
+	assert.Empty(suite.T(), BadgeImageObjectKeys(""))
+}
+
+func (suite *BadgeImageTestSuite) TestCreateBadgeImageUpload() {
+	suite.sqlmock.ExpectQuery("INSERT INTO badge_image_uploads").
+		WithArgs(2, 1, sqlmock.AnyArg(), "image/png", 1024, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "badge_id", "uploaded_by", "object_key", "content_type", "size_bytes"}).
+			AddRow(4, 2, 1, "badges/2/new.png", "image/png", 1024))
+
+	upload, err := suite.dbStore.CreateBadgeImageUpload(context.Background(), BadgeImageUpload{
+		BadgeID: 2, UploadedBy: 1, ContentType: "image/png", SizeBytes: 1024,
+	})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), testBadgeImageUpload, upload)
+	assert.Regexp(suite.T(), `^badges/2/[0-9a-f-]{36}\.png$`, newBadgeImageObjectKey(2, "image/png"))
+}
+
+func (suite *BadgeImageTestSuite) TestConfirmBadgeImageUpload() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("DELETE FROM badge_image_uploads").
+		WithArgs(2, int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec(regexp.QuoteMeta("UPDATE badges SET image_key")).
+		WithArgs("badges/2/new.png", 2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT id").
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "org_id", "image_key"}).AddRow(2, "Hackathon winner", 1, "badges/2/new.png"))
+	suite.sqlmock.ExpectCommit()
+
+	badge, err := suite.dbStore.ConfirmBadgeImageUpload(context.Background(), 1, testBadgeImageUpload)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Badge{ID: 2, Name: "Hackathon winner", OrganizationID: 1, ImageKey: "badges/2/new.png"}, badge)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *BadgeImageTestSuite) TestConfirmBadgeImageUploadTwice() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("DELETE FROM badge_image_uploads").
+		WithArgs(2, int64(4)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.ConfirmBadgeImageUpload(context.Background(), 1, testBadgeImageUpload)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// BadgeProgress - how close a user is to the next level of a badge. Target is the threshold of
+// the next level not held yet, or of the highest level once every level is held.
+type BadgeProgress struct {
+	BadgeID      int          `json:"badge_id"`
+	Name         string       `json:"name"`
+	Metric       string       `json:"metric"`
+	CoreValueIDs []int64      `json:"core_value_ids"`
+	Current      int          `json:"current"`
+	Target       int          `json:"target"`
+	NextTier     string       `json:"next_tier"`
+	Levels       []BadgeLevel `json:"levels"`
+	EarnedTiers  []string     `json:"earned_tiers"`
+	Completed    bool         `json:"completed"`
+	// WindowDays - 0 when everything is counted
+	WindowDays int `json:"window_days"`
+	// ExpiresAt - when the current value is next expected to drop, either because the oldest counted
+	// activity leaves the window or because a streak week is missed. Empty when it can't drop.
+	ExpiresAt *int64 `json:"expires_at"`
+	ImageKey  string `json:"-"`
+	// Image - the badge's artwork, filled in by the handlers
+	Image *BadgeImage `json:"image"`
+}
+
+// ListBadgeProgress - the user's progress on every badge of the organization, measured with
+// the same queries the badge engine awards with
+func (s *pgStore) ListBadgeProgress(ctx context.Context, orgID, userID int) (progress []BadgeProgress, err error) {
+	progress = make([]BadgeProgress, 0)
+
+	badges, err := s.ListBadges(ctx, orgID)
+	if err != nil {
+		return
+	}
+
+	userBadges, err := s.ListUserBadges(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	earned := make(map[int][]string)
+	for _, userBadge := range userBadges {
+		earned[userBadge.BadgeID] = append(earned[userBadge.BadgeID], userBadge.Tier)
+	}
+
+	now := time.Now().Unix()
+	for _, badge := range badges {
+		criteria, criteriaErr := badge.EffectiveCriteria()
+		if criteriaErr != nil || len(criteria.Validate()) > 0 {
+			logger.WithField("badge_id", badge.ID).Error("Skipping badge with invalid criteria")
+			continue
+		}
+
+		var measured []badgeProgress
+		measured, err = s.measureBadgeCriteria(ctx, orgID, criteria, userID, now)
+		if err != nil {
+			return
+		}
+
+		progress = append(progress, newBadgeProgress(badge, criteria, measured, earned[badge.ID]))
+	}
+	return
+}
+
+func newBadgeProgress(badge Badge, criteria BadgeCriteria, measured []badgeProgress, earnedTiers []string) (progress BadgeProgress) {
+	progress = BadgeProgress{
+		BadgeID:      badge.ID,
+		Name:         badge.Name,
+		Metric:       criteria.Metric,
+		CoreValueIDs: criteria.CoreValueIDs,
+		Levels:       criteria.Levels(),
+		EarnedTiers:  earnedTiers,
+		WindowDays:   criteria.WindowDays,
+		ImageKey:     badge.ImageKey,
+	}
+	if progress.CoreValueIDs == nil {
+		progress.CoreValueIDs = []int64{}
+	}
+	if progress.EarnedTiers == nil {
+		progress.EarnedTiers = []string{}
+	}
+
+	if len(measured) > 0 {
+		progress.Current = measured[0].Achieved
+		if measured[0].ExpiresAt > 0 {
+			progress.ExpiresAt = &measured[0].ExpiresAt
+		}
+	}
+
+	held := make(map[string]bool)
+	for _, tier := range earnedTiers {
+		held[tier] = true
+	}
+
+	progress.Completed = true
+	for _, level := range progress.Levels {
+		progress.Target = level.Threshold
+		progress.NextTier = level.Tier
+		if !held[level.Tier] {
+			progress.Completed = false
+			break
+		}
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BadgeProgressTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *BadgeProgressTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *BadgeProgressTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *BadgeProgressTestSuite) TestListBadgeProgress() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM badges").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "name", "org_id", "hi5_count_required", "hi5_frequency", "criteria"}).
+			AddRow(1, "Star", 1, 5, "ALL_TIME", nil).
+			AddRow(2, "Team player", 1, 0, "", []byte(`{"metric":"distinct_givers","window_days":7,"tiers":{"bronze":2,"silver":4,"gold":8}}`)))
+
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM user_badges").
+		WithArgs(7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "badge_id", "tier"}).
+			AddRow(10, 7, 2, BronzeTier).
+			AddRow(11, 7, 2, SilverTier))
+
+	// the evaluator only measures the requesting user
+	suite.sqlmock.ExpectQuery("FROM recognition_hi5").
+		WithArgs(1, 0, "{}", 7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "achieved", "oldest"}).AddRow(7, 3, 1594000000))
+
+	suite.sqlmock.ExpectQuery("COUNT\\(DISTINCT r.given_by\\)").
+		WithArgs(1, sqlmock.AnyArg(), "{}", 7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "achieved", "oldest"}).AddRow(7, 5, 1594339200))
+
+	progress, err := suite.dbStore.ListBadgeProgress(context.Background(), 1, 7)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(progress))
+
+	assert.Equal(suite.T(), 3, progress[0].Current)
+	assert.Equal(suite.T(), 5, progress[0].Target)
+	assert.Nil(suite.T(), progress[0].ExpiresAt)
+	assert.False(suite.T(), progress[0].Completed)
+
+	assert.Equal(suite.T(), 5, progress[1].Current)
+	assert.Equal(suite.T(), 8, progress[1].Target)
+	assert.Equal(suite.T(), GoldTier, progress[1].NextTier)
+	assert.Equal(suite.T(), []string{BronzeTier, SilverTier}, progress[1].EarnedTiers)
+	assert.Equal(suite.T(), int64(1594339200+7*24*60*60), *progress[1].ExpiresAt)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *BadgeProgressTestSuite) TestNewBadgeProgressWhenEveryLevelIsHeld() {
+	criteria := BadgeCriteria{Metric: WeeklyStreakMetric, Threshold: 4}
+
+	progress := newBadgeProgress(Badge{ID: 3, Name: "Regular"}, criteria, []badgeProgress{}, []string{""})
+
+	assert.True(suite.T(), progress.Completed)
+	assert.Equal(suite.T(), 0, progress.Current)
+	assert.Equal(suite.T(), 4, progress.Target)
+	assert.Equal(suite.T(), []int64{}, progress.CoreValueIDs)
+}
@@ -118,10 +118,37 @@ func (suite *BadgeTestSuite) TestUpdateBadgeFailure() {
 }
 
 func (suite *OrganizationTestSuite) TestDeleteBadgeSuccess() {
-	suite.sqlmock.ExpectExec("DELETE").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT u.object_key FROM badge_image_uploads").
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"object_key"}).AddRow("badges/2/pending.gif"))
+	suite.sqlmock.ExpectQuery("DELETE FROM badges").
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"image_key"}).AddRow("badges/2/art.png"))
+	suite.sqlmock.ExpectCommit()
+
+	objectKeys, err := suite.dbStore.DeleteBadge(context.Background(), 1, 2)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{
+		"badges/2/art.png",
+		"badges/2/art_64.png",
+		"badges/2/art_128.png",
+		"badges/2/art_256.png",
+		"badges/2/pending.gif",
+	}, objectKeys)
+}
+
+func (suite *OrganizationTestSuite) TestDeleteAwardedBadgeKeepsObjects() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT u.object_key FROM badge_image_uploads").
+		WillReturnRows(sqlmock.NewRows([]string{"object_key"}).AddRow("badges/2/pending.gif"))
+	suite.sqlmock.ExpectQuery("DELETE FROM badges").
+		WillReturnRows(sqlmock.NewRows([]string{"image_key"}))
+	suite.sqlmock.ExpectCommit()
 
-	err := suite.dbStore.DeleteBadge(context.Background(), 1, 1)
+	objectKeys, err := suite.dbStore.DeleteBadge(context.Background(), 1, 2)
 
 	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), objectKeys)
 }
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"database/sql"
+	ae "joshsoftware/peerly/apperrors"
 	"regexp"
 )
 
@@ -18,3 +20,16 @@ const (
 
 var emailRegex = regexp.MustCompile(emailRegexString)
 var domainRegex = regexp.MustCompile(domainRegexString)
+
+// checkRowsAffected - returns ErrRecordNotFound when an UPDATE or DELETE matched no rows
+func checkRowsAffected(result sql.Result) (err error) {
+	count, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+
+	if count == 0 {
+		err = ae.ErrRecordNotFound
+	}
+	return
+}
@@ -33,6 +33,27 @@ func InitMockDB() (s Storer, sqlConn *sqlx.DB, sqlmockInstance sqlmock.Sqlmock)
 func TestExampleTestSuite(t *testing.T) {
 	suite.Run(t, new(OrganizationTestSuite))
 	suite.Run(t, new(RecognitionHi5TestSuite))
+	suite.Run(t, new(RecognitionTestSuite))
+	suite.Run(t, new(RecognitionAttachmentTestSuite))
+	suite.Run(t, new(RecognitionRuleTestSuite))
+	suite.Run(t, new(RecognitionFeedTestSuite))
 	suite.Run(t, new(ReportedRecognitionTestSuite))
 	suite.Run(t, new(RecognitionModerationTestSuite))
+	suite.Run(t, new(Hi5QuotaLedgerTestSuite))
+	suite.Run(t, new(Hi5QuotaScheduleTestSuite))
+	suite.Run(t, new(UserHi5QuotaBalanceTestSuite))
+	suite.Run(t, new(Hi5QuotaPolicyTestSuite))
+	suite.Run(t, new(UserBadgeTestSuite))
+	suite.Run(t, new(BadgeGrantTestSuite))
+	suite.Run(t, new(BadgeProgressTestSuite))
+	suite.Run(t, new(BadgeImageTestSuite))
+	suite.Run(t, new(CoreValueTreeTestSuite))
+	suite.Run(t, new(CoreValueThumbnailTestSuite))
+	suite.Run(t, new(TranslationTestSuite))
+	suite.Run(t, new(ModerationQueueTestSuite))
+	suite.Run(t, new(RecognitionQuarantineTestSuite))
+	suite.Run(t, new(ContentFilterTestSuite))
+	suite.Run(t, new(ReportReasonTestSuite))
+	suite.Run(t, new(RecognitionAppealTestSuite))
+	suite.Run(t, new(ModerationMetricsTestSuite))
 }
@@ -0,0 +1,427 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// ContentActionOff - the checker doesn't run
+	ContentActionOff = "off"
+	// ContentActionReject - text the checker finds something in can't be saved
+	ContentActionReject = "reject"
+	// ContentActionFlag - the text is saved and its recognition is put in the moderation queue
+	ContentActionFlag = "flag"
+	// ContentActionMask - the offending part of the text is masked before it is saved
+	ContentActionMask = "mask"
+
+	// ContentFilterReportType - type of the reports filed by the content filter instead of a user
+	ContentFilterReportType = "content_filter"
+
+	// MaxBlocklistTermLength - longest term an organization can block
+	MaxBlocklistTermLength = 100
+
+	getContentFilterSettingsQuery = `SELECT org_id, blocklist_action, pii_action, max_text_length, max_length_action
+		FROM content_filter_settings WHERE org_id = $1`
+
+	upsertContentFilterSettingsQuery = `INSERT INTO content_filter_settings (org_id, blocklist_action, pii_action,
+		max_text_length, max_length_action, updated_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (org_id) DO UPDATE SET (blocklist_action, pii_action, max_text_length, max_length_action, updated_at) =
+		(EXCLUDED.blocklist_action, EXCLUDED.pii_action, EXCLUDED.max_text_length, EXCLUDED.max_length_action, EXCLUDED.updated_at)
+		RETURNING org_id, blocklist_action, pii_action, max_text_length, max_length_action`
+
+	listBlocklistTermsQuery = `SELECT term FROM content_blocklist_terms WHERE org_id = $1 ORDER BY term`
+
+	listContentBlocklistTermsQuery = `SELECT id, org_id, term, created_by, created_at
+		FROM content_blocklist_terms WHERE org_id = $1 ORDER BY term`
+
+	// adding a term that is already blocked returns the existing term
+	createContentBlocklistTermQuery = `INSERT INTO content_blocklist_terms (org_id, term, created_by, created_at)
+		VALUES ($1, $2, $3, $4) ON CONFLICT (org_id, term) DO UPDATE SET term = EXCLUDED.term
+		RETURNING id, org_id, term, created_by, created_at`
+
+	deleteContentBlocklistTermQuery = `DELETE FROM content_blocklist_terms WHERE id = $1 AND org_id = $2`
+
+	// reports filed by the content filter have no reporter, so they never count towards a quarantine
+	flagRecognitionContentQuery = `INSERT INTO reported_recognitions (recognition_id, type_of_reporting,
+		reason_for_reporting, reported_by, reported_at, created_at, updated_at) VALUES ($1, $2, $3, NULL, $4, $5, $5)`
+)
+
+// CONTENT_FILTER_ACTIONS - what a checker can do with text it finds something in
+var CONTENT_FILTER_ACTIONS = []string{ContentActionOff, ContentActionReject, ContentActionFlag, ContentActionMask}
+
+// ContentFilterSettings - per organization configuration of the content filter pipeline.
+// A max_text_length of 0 doesn't limit the length of text.
+type ContentFilterSettings struct {
+	OrgID           int      `db:"org_id" json:"org_id"`
+	BlocklistAction string   `db:"blocklist_action" json:"blocklist_action"`
+	PIIAction       string   `db:"pii_action" json:"pii_action"`
+	MaxTextLength   int      `db:"max_text_length" json:"max_text_length"`
+	MaxLengthAction string   `db:"max_length_action" json:"max_length_action"`
+	Blocklist       []string `db:"-" json:"-"`
+}
+
+// DefaultContentFilterSettings - settings used by organizations that haven't configured their own
+func DefaultContentFilterSettings(orgID int) ContentFilterSettings {
+	return ContentFilterSettings{
+		OrgID:           orgID,
+		BlocklistAction: ContentActionReject,
+		PIIAction:       ContentActionMask,
+		MaxTextLength:   1000,
+		MaxLengthAction: ContentActionReject,
+	}
+}
+
+// Validate - ensures every checker has a known action and the length limit isn't negative
+func (settings ContentFilterSettings) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	actions := map[string]string{
+		"blocklist_action":  settings.BlocklistAction,
+		"pii_action":        settings.PIIAction,
+		"max_length_action": settings.MaxLengthAction,
+	}
+	for field, action := range actions {
+		if !include(CONTENT_FILTER_ACTIONS, action) {
+			errFields[field] = "Must be one of off, reject, flag or mask"
+		}
+	}
+
+	if settings.MaxTextLength < 0 {
+		errFields["max_text_length"] = "Can't be negative"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// ContentBlocklistTerm - a word the organization doesn't want in recognitions or Hi5 comments
+type ContentBlocklistTerm struct {
+	ID        int64  `db:"id" json:"id"`
+	OrgID     int    `db:"org_id" json:"org_id"`
+	Term      string `db:"term" json:"term"`
+	CreatedBy *int64 `db:"created_by" json:"created_by"`
+	CreatedAt int64  `db:"created_at" json:"created_at"`
+}
+
+// Validate - terms are matched case insensitively, so they are saved in lower case
+func (term *ContentBlocklistTerm) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	term.Term = strings.ToLower(strings.TrimSpace(term.Term))
+	if term.Term == "" {
+		errFields["term"] = "Can't be blank"
+	} else if len([]rune(term.Term)) > MaxBlocklistTermLength {
+		errFields["term"] = fmt.Sprintf("Can't be longer than %d characters", MaxBlocklistTermLength)
+	} else if strings.Trim(term.Term, "*") == "" {
+		errFields["term"] = "Must contain more than wildcards"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// ContentFilterResult - the text to save, masked where a checker asked for it, along with what
+// the checkers that reject or flag the text found in it
+type ContentFilterResult struct {
+	Text     string
+	Rejected string
+	Flags    []string
+}
+
+// ContentChecker - a check of the content filter pipeline. Check describes what it found in the
+// text, or returns an empty finding, along with the text masked as the checker would mask it.
+type ContentChecker interface {
+	Action(ContentFilterSettings) string
+	Check(ContentFilterSettings, string) (finding string, masked string)
+}
+
+// contentCheckers are run in order, each on the text masked by the ones before; add new checkers here.
+// The length is checked last since masking keeps the length of text.
+var contentCheckers = []ContentChecker{
+	blocklistChecker{},
+	piiChecker{},
+	maxLengthChecker{},
+}
+
+// FilterContent - runs the content filter pipeline on text written by a user. Everything runs
+// locally, nothing is sent to an outside service.
+func FilterContent(settings ContentFilterSettings, text string) (result ContentFilterResult) {
+	result.Text = text
+	for _, checker := range contentCheckers {
+		action := checker.Action(settings)
+		if action == ContentActionOff || action == "" {
+			continue
+		}
+
+		finding, masked := checker.Check(settings, result.Text)
+		if finding == "" {
+			continue
+		}
+
+		switch action {
+		case ContentActionReject:
+			if result.Rejected == "" {
+				result.Rejected = finding
+			}
+		case ContentActionFlag:
+			result.Flags = append(result.Flags, finding)
+		case ContentActionMask:
+			result.Text = masked
+		}
+	}
+	return
+}
+
+// maskSpans - replaces every character of the given byte ranges of text with an asterisk
+func maskSpans(text string, spans [][]int) string {
+	if len(spans) == 0 {
+		return text
+	}
+
+	var masked strings.Builder
+	last := 0
+	for _, span := range spans {
+		if span[0] < last {
+			continue
+		}
+		masked.WriteString(text[last:span[0]])
+		masked.WriteString(strings.Repeat("*", len([]rune(text[span[0]:span[1]]))))
+		last = span[1]
+	}
+	masked.WriteString(text[last:])
+	return masked.String()
+}
+
+type blocklistChecker struct{}
+
+func (blocklistChecker) Action(settings ContentFilterSettings) string {
+	return settings.BlocklistAction
+}
+
+// Check - matches whole words case insensitively, a * in a term matches any run of letters or digits
+func (blocklistChecker) Check(settings ContentFilterSettings, text string) (finding string, masked string) {
+	if len(settings.Blocklist) == 0 {
+		return
+	}
+
+	patterns := make([]string, 0, len(settings.Blocklist))
+	for _, term := range settings.Blocklist {
+		parts := strings.Split(term, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		patterns = append(patterns, strings.Join(parts, `\w*`))
+	}
+
+	blocklist, err := regexp.Compile(`(?i)\b(?:` + strings.Join(patterns, "|") + `)\b`)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while compiling content blocklist")
+		return
+	}
+
+	spans := blocklist.FindAllStringIndex(text, -1)
+	if len(spans) > 0 {
+		finding = "blocked terms"
+		masked = maskSpans(text, spans)
+	}
+	return
+}
+
+// piiPatterns are looked for in order, ID numbers before phone numbers since both are runs of digits
+var piiPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"email", regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+	{"ID number", regexp.MustCompile(`\b(?:\d{3}-\d{2}-\d{4}|\d{4}[ -]?\d{4}[ -]?\d{4}|[A-Za-z]{5}\d{4}[A-Za-z])\b`)},
+	{"phone number", regexp.MustCompile(`\+?\(?\d(?:[ .()-]{0,2}\d){9,14}`)},
+}
+
+type piiChecker struct{}
+
+func (piiChecker) Action(settings ContentFilterSettings) string {
+	return settings.PIIAction
+}
+
+// Check - looks for emails, ID numbers (SSN, Aadhaar and PAN) and phone numbers
+func (piiChecker) Check(settings ContentFilterSettings, text string) (finding string, masked string) {
+	kinds := []string{}
+	masked = text
+	for _, pii := range piiPatterns {
+		spans := pii.pattern.FindAllStringIndex(masked, -1)
+		if len(spans) == 0 {
+			continue
+		}
+
+		kinds = append(kinds, pii.kind)
+		masked = maskSpans(masked, spans)
+	}
+
+	if len(kinds) > 0 {
+		finding = fmt.Sprintf("personal information (%s)", strings.Join(kinds, ", "))
+	}
+	return
+}
+
+type maxLengthChecker struct{}
+
+func (maxLengthChecker) Action(settings ContentFilterSettings) string {
+	return settings.MaxLengthAction
+}
+
+// Check - masking text that is too long truncates it
+func (maxLengthChecker) Check(settings ContentFilterSettings, text string) (finding string, masked string) {
+	runes := []rune(text)
+	if settings.MaxTextLength == 0 || len(runes) <= settings.MaxTextLength {
+		return
+	}
+
+	finding = fmt.Sprintf("more than %d characters", settings.MaxTextLength)
+	masked = string(runes[:settings.MaxTextLength])
+	return
+}
+
+// GetContentFilterSettings - returns the organization's content filter settings, or the defaults if none
+// are saved, along with its blocklist
+func (s *pgStore) GetContentFilterSettings(ctx context.Context, orgID int) (settings ContentFilterSettings, err error) {
+	err = s.db.GetContext(ctx, &settings, getContentFilterSettingsQuery, orgID)
+	if err == sql.ErrNoRows {
+		settings = DefaultContentFilterSettings(orgID)
+		err = nil
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while getting content filter settings")
+		return
+	}
+
+	settings.Blocklist = make([]string, 0)
+	err = s.db.SelectContext(ctx, &settings.Blocklist, listBlocklistTermsQuery, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while getting content blocklist")
+		return
+	}
+
+	return
+}
+
+// UpdateContentFilterSettings - saves the organization's content filter settings
+func (s *pgStore) UpdateContentFilterSettings(ctx context.Context, settings ContentFilterSettings) (updatedSettings ContentFilterSettings, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&updatedSettings,
+		upsertContentFilterSettingsQuery,
+		settings.OrgID,
+		settings.BlocklistAction,
+		settings.PIIAction,
+		settings.MaxTextLength,
+		settings.MaxLengthAction,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"settings_params": settings,
+		}).Error("Error while updating content filter settings")
+		return
+	}
+
+	return
+}
+
+// ListContentBlocklistTerms - the organization's blocked terms in alphabetical order
+func (s *pgStore) ListContentBlocklistTerms(ctx context.Context, orgID int) (terms []ContentBlocklistTerm, err error) {
+	terms = make([]ContentBlocklistTerm, 0)
+	err = s.db.SelectContext(ctx, &terms, listContentBlocklistTermsQuery, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing content blocklist terms")
+		return
+	}
+
+	return
+}
+
+// CreateContentBlocklistTerm - adds a term to the organization's blocklist
+func (s *pgStore) CreateContentBlocklistTerm(ctx context.Context, term ContentBlocklistTerm) (createdTerm ContentBlocklistTerm, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&createdTerm,
+		createContentBlocklistTermQuery,
+		term.OrgID,
+		term.Term,
+		term.CreatedBy,
+		time.Now().Unix(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":         err.Error(),
+			"term_params": term,
+		}).Error("Error while creating content blocklist term")
+		return
+	}
+
+	return
+}
+
+// DeleteContentBlocklistTerm - removes a term from the organization's blocklist.
+// Fails with ErrRecordNotFound if the organization has no such term.
+func (s *pgStore) DeleteContentBlocklistTerm(ctx context.Context, orgID int, termID int64) (err error) {
+	result, err := s.db.ExecContext(ctx, deleteContentBlocklistTermQuery, termID, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"term_id": termID,
+		}).Error("Error while deleting content blocklist term")
+		return
+	}
+
+	err = checkRowsAffected(result)
+	if err != nil && err != ae.ErrRecordNotFound {
+		logger.WithField("err", err.Error()).Error("Error while checking deleted content blocklist term")
+	}
+	return
+}
+
+// FlagRecognitionContent - puts the recognition in the moderation queue with what the content filter found
+func (s *pgStore) FlagRecognitionContent(ctx context.Context, recognitionID int64, findings []string) (err error) {
+	now := time.Now()
+	_, err = s.db.ExecContext(
+		ctx,
+		flagRecognitionContentQuery,
+		recognitionID,
+		ContentFilterReportType,
+		strings.Join(findings, "; "),
+		now.Unix(),
+		now,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while flagging recognition content")
+		return
+	}
+
+	return
+}
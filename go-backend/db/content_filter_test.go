@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContentFilterTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *ContentFilterTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *ContentFilterTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *ContentFilterTestSuite) TestFilterContentRejectsBlockedTerms() {
+	settings := DefaultContentFilterSettings(1)
+	settings.Blocklist = []string{"dumb*"}
+
+	result := FilterContent(settings, "Not a DUMBO idea at all")
+
+	assert.Equal(suite.T(), "blocked terms", result.Rejected)
+	assert.Empty(suite.T(), result.Flags)
+}
+
+func (suite *ContentFilterTestSuite) TestFilterContentMatchesWholeWords() {
+	settings := DefaultContentFilterSettings(1)
+	settings.Blocklist = []string{"ass"}
+
+	result := FilterContent(settings, "Thanks for the assist with the class")
+
+	assert.Empty(suite.T(), result.Rejected)
+	assert.Equal(suite.T(), "Thanks for the assist with the class", result.Text)
+}
+
+func (suite *ContentFilterTestSuite) TestFilterContentMasksPII() {
+	result := FilterContent(DefaultContentFilterSettings(1), "Call me on +91 98220 12345 or mail jo@example.com")
+
+	assert.Empty(suite.T(), result.Rejected)
+	assert.Equal(suite.T(), "Call me on *************** or mail **************", result.Text)
+}
+
+func (suite *ContentFilterTestSuite) TestFilterContentFlagsAndMasks() {
+	settings := DefaultContentFilterSettings(1)
+	settings.Blocklist = []string{"slacker"}
+	settings.BlocklistAction = ContentActionMask
+	settings.PIIAction = ContentActionFlag
+	settings.MaxTextLength = 30
+	settings.MaxLengthAction = ContentActionMask
+
+	result := FilterContent(settings, "No slacker here, my PAN is ABCDE1234F and SSN 123-45-6789")
+
+	assert.Empty(suite.T(), result.Rejected)
+	assert.Equal(suite.T(), []string{"personal information (ID number)"}, result.Flags)
+	assert.Equal(suite.T(), "No ******* here, my PAN is ABC", result.Text)
+}
+
+func (suite *ContentFilterTestSuite) TestFilterContentRejectsLongText() {
+	settings := DefaultContentFilterSettings(1)
+	settings.MaxTextLength = 5
+
+	result := FilterContent(settings, "Great work")
+
+	assert.Equal(suite.T(), "more than 5 characters", result.Rejected)
+}
+
+func (suite *ContentFilterTestSuite) TestContentFilterSettingsValidate() {
+	ok, errFields := ContentFilterSettings{BlocklistAction: "block", PIIAction: ContentActionOff, MaxLengthAction: ContentActionReject, MaxTextLength: -1}.Validate()
+
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), map[string]string{
+		"blocklist_action": "Must be one of off, reject, flag or mask",
+		"max_text_length":  "Can't be negative",
+	}, errFields)
+}
+
+func (suite *ContentFilterTestSuite) TestContentBlocklistTermValidate() {
+	term := ContentBlocklistTerm{Term: "  Dumb* "}
+	ok, _ := term.Validate()
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "dumb*", term.Term)
+
+	ok, errFields := (&ContentBlocklistTerm{Term: "**"}).Validate()
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), "Must contain more than wildcards", errFields["term"])
+}
+
+func (suite *ContentFilterTestSuite) TestGetContentFilterSettingsDefaults() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM content_filter_settings").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id"}))
+	suite.sqlmock.ExpectQuery("SELECT term FROM content_blocklist_terms").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"term"}).AddRow("dumb*").AddRow("slacker"))
+
+	settings, err := suite.dbStore.GetContentFilterSettings(context.Background(), 1)
+
+	expected := DefaultContentFilterSettings(1)
+	expected.Blocklist = []string{"dumb*", "slacker"}
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), expected, settings)
+}
+
+func (suite *ContentFilterTestSuite) TestFlagRecognitionContent() {
+	suite.sqlmock.ExpectExec("INSERT INTO reported_recognitions").
+		WithArgs(int64(8), ContentFilterReportType, "text contains blocked terms; comment contains more than 5 characters", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := suite.dbStore.FlagRecognitionContent(context.Background(), 8, []string{"text contains blocked terms", "comment contains more than 5 characters"})
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -8,26 +8,44 @@ import (
 )
 
 const (
-	listCoreValuesQuery  = `SELECT id, org_id, text, description, parent_id  FROM core_values WHERE org_id = $1`
-	getCoreValueQuery    = `SELECT id, org_id, text, description, parent_id FROM core_values WHERE org_id = $1 and id = $2`
+	coreValueColumns = `id, org_id, text, description, parent_id, thumbnail_key, position, archived_at`
+
+	// $2 - whether archived core values are listed too
+	listCoreValuesQuery = `SELECT ` + coreValueColumns + ` FROM core_values
+		WHERE org_id = $1 AND ($2 OR archived_at IS NULL) ORDER BY position, id`
+	getCoreValueQuery    = `SELECT ` + coreValueColumns + ` FROM core_values WHERE org_id = $1 and id = $2`
 	createCoreValueQuery = `INSERT INTO core_values (org_id, text,
-		description, parent_id, thumbnail_url, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, org_id, text, description, parent_id, thumbnail_url`
-	deleteSubCoreValueQuery = `DELETE FROM core_values WHERE org_id = $1 and parent_id = $2`
-	deleteCoreValueQuery    = `DELETE FROM core_values WHERE org_id = $1 and id = $2`
-	updateCoreValueQuery    = `UPDATE core_values SET (text, description, updated_at) =
-		($1, $2, $3) where id = $4 and org_id = $5 RETURNING id, org_id, text, description, parent_id`
+		description, parent_id, created_at, updated_at, position) VALUES ($1, $2, $3, $4, $5, $6,
+		(SELECT COALESCE(MAX(position) + 1, 0) FROM core_values WHERE org_id = $1 AND parent_id IS NOT DISTINCT FROM $4))
+		RETURNING ` + coreValueColumns
+	// sub core values are archived along with their parent
+	archiveCoreValueQuery = `UPDATE core_values SET (archived_at, updated_at) = ($3, $3)
+		WHERE org_id = $1 AND (id = $2 OR parent_id = $2) AND archived_at IS NULL`
+	updateCoreValueQuery = `UPDATE core_values SET (text, description, updated_at) =
+		($1, $2, $3) where id = $4 and org_id = $5 RETURNING ` + coreValueColumns
 )
 
 // CoreValue - struct representing a core value object
 type CoreValue struct {
-	ID           int64     `db:"id" json:"id"`
-	OrgID        int64     `db:"org_id" json:"org_id"`
-	Text         string    `db:"text" json:"text"`
-	Description  string    `db:"description" json:"description"`
-	ParentID     *int64    `db:"parent_id" json:"parent_id"`
-	ThumbnailURL *string   `db:"thumbnail_url" json:"thumbnail_url"`
-	CreatedAt    time.Time `db:"created_at" json:"-"`
-	UpdatedAt    time.Time `db:"updated_at" json:"-"`
+	ID           int64  `db:"id" json:"id"`
+	OrgID        int64  `db:"org_id" json:"org_id"`
+	Text         string `db:"text" json:"text"`
+	Description  string `db:"description" json:"description"`
+	ParentID     *int64 `db:"parent_id" json:"parent_id"`
+	ThumbnailKey string `db:"thumbnail_key" json:"-"`
+	// ThumbnailURL - short-lived URL of the resized thumbnail, filled in by the handlers
+	ThumbnailURL *string `db:"-" json:"thumbnail_url"`
+	// Position - order among the core values sharing the same parent
+	Position int `db:"position" json:"position"`
+	// ArchivedAt - archived core values can't be used for new recognitions but old ones still refer to them
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"-"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"-"`
+}
+
+// IsArchived - whether the core value has been archived
+func (coreValue CoreValue) IsArchived() bool {
+	return coreValue.ArchivedAt != nil
 }
 
 func validateParentCoreValue(ctx context.Context, storer Storer, organisationID, coreValueID int64) (ok bool) {
@@ -42,6 +60,11 @@ func validateParentCoreValue(ctx context.Context, storer Storer, organisationID,
 		return
 	}
 
+	if coreValue.IsArchived() {
+		logger.Error("Parent core value is archived")
+		return
+	}
+
 	return true
 }
 
@@ -67,13 +90,15 @@ func (coreValue CoreValue) Validate(ctx context.Context, storer Storer, organisa
 	return
 }
 
-func (s *pgStore) ListCoreValues(ctx context.Context, organisationID int64) (coreValues []CoreValue, err error) {
+// ListCoreValues - the organization's core values in the order they are shown, archived ones only when asked for
+func (s *pgStore) ListCoreValues(ctx context.Context, organisationID int64, includeArchived bool) (coreValues []CoreValue, err error) {
 	coreValues = make([]CoreValue, 0)
 	err = s.db.SelectContext(
 		ctx,
 		&coreValues,
 		listCoreValuesQuery,
 		organisationID,
+		includeArchived,
 	)
 
 	if err != nil {
@@ -117,7 +142,6 @@ func (s *pgStore) CreateCoreValue(ctx context.Context, organisationID int64, cor
 		coreValue.Text,
 		coreValue.Description,
 		coreValue.ParentID,
-		coreValue.ThumbnailURL,
 		now,
 		now,
 	)
@@ -133,34 +157,22 @@ func (s *pgStore) CreateCoreValue(ctx context.Context, organisationID int64, cor
 	return
 }
 
-func (s *pgStore) DeleteCoreValue(ctx context.Context, organisationID, coreValueID int64) (err error) {
-	_, err = s.db.ExecContext(
-		ctx,
-		deleteSubCoreValueQuery,
-		organisationID,
-		coreValueID,
-	)
-	if err != nil {
-		logger.WithFields(logger.Fields{
-			"err":           err.Error(),
-			"org_id":        organisationID,
-			"core_value_id": coreValueID,
-		}).Error("Error while deleting sub core value")
-		return
-	}
-
+// ArchiveCoreValue - archives the core value and its sub core values. Nothing is deleted so
+// recognitions given for them keep resolving.
+func (s *pgStore) ArchiveCoreValue(ctx context.Context, organisationID, coreValueID int64) (err error) {
 	_, err = s.db.ExecContext(
 		ctx,
-		deleteCoreValueQuery,
+		archiveCoreValueQuery,
 		organisationID,
 		coreValueID,
+		time.Now(),
 	)
 	if err != nil {
 		logger.WithFields(logger.Fields{
 			"err":           err.Error(),
 			"org_id":        organisationID,
 			"core_value_id": coreValueID,
-		}).Error("Error while deleting core value")
+		}).Error("Error while archiving core value")
 		return
 	}
 
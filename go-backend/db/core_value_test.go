@@ -103,11 +103,11 @@ func (suite *CoreValueTestSuite) TestGetCoreValueSuccess() {
 	assert.Equal(suite.T(), coreValue, resp)
 }
 
-func (suite *CoreValueTestSuite) TestDeleteCoreValueSuccess() {
-	suite.sqlmock.ExpectExec("DELETE").
+func (suite *CoreValueTestSuite) TestArchiveCoreValueSuccess() {
+	suite.sqlmock.ExpectExec("UPDATE core_values SET").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := suite.dbStore.DeleteCoreValue(context.Background(), coreValue.OrgID, coreValue.ID)
+	err := suite.dbStore.ArchiveCoreValue(context.Background(), coreValue.OrgID, coreValue.ID)
 
 	assert.Nil(suite.T(), err)
 }
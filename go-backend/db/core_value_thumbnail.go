@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/google/uuid"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxCoreValueThumbnailSizeBytes - largest file accepted as a core value thumbnail (2 MB)
+	MaxCoreValueThumbnailSizeBytes = 2 * 1024 * 1024
+	// MaxCoreValueThumbnailDimension - widest or tallest upload that gets decoded to be resized
+	MaxCoreValueThumbnailDimension = 2048
+	// CoreValueThumbnailSize - the square, in pixels, uploads are scaled to fit in
+	CoreValueThumbnailSize = 128
+	// CoreValueThumbnailContentType - thumbnails are stored as PNG whatever they were uploaded as
+	CoreValueThumbnailContentType = "image/png"
+
+	createCoreValueThumbnailUploadQuery = `INSERT INTO core_value_thumbnail_uploads (core_value_id, uploaded_by, object_key, content_type, size_bytes, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, core_value_id, uploaded_by, object_key, content_type, size_bytes, created_at`
+
+	getCoreValueThumbnailUploadQuery = `SELECT id, core_value_id, uploaded_by, object_key, content_type, size_bytes, created_at
+		FROM core_value_thumbnail_uploads WHERE core_value_id = $1 AND id = $2`
+
+	deleteCoreValueThumbnailUploadQuery = `DELETE FROM core_value_thumbnail_uploads WHERE core_value_id = $1 AND id = $2`
+
+	// the legacy link is cleared once a thumbnail is uploaded in its place
+	setCoreValueThumbnailQuery = `UPDATE core_values SET (thumbnail_key, legacy_thumbnail_url, updated_at) = ($1, NULL, $2)
+		WHERE id = $3 AND org_id = $4 RETURNING ` + coreValueColumns
+)
+
+// CoreValueThumbnailUpload - an upload slot handed out for a new core value thumbnail, until the upload is confirmed
+type CoreValueThumbnailUpload struct {
+	ID          int64     `db:"id" json:"id"`
+	CoreValueID int64     `db:"core_value_id" json:"core_value_id"`
+	UploadedBy  int       `db:"uploaded_by" json:"uploaded_by"`
+	ObjectKey   string    `db:"object_key" json:"-"`
+	ContentType string    `db:"content_type" json:"content_type"`
+	SizeBytes   int64     `db:"size_bytes" json:"size_bytes"`
+	CreatedAt   time.Time `db:"created_at" json:"-"`
+}
+
+// Validate - checks the upload slot request before any object key is handed out
+func (upload CoreValueThumbnailUpload) Validate() (valid bool, errFields map[string]string) {
+	errFields = validateImageUpload(upload.ContentType, upload.SizeBytes, MaxCoreValueThumbnailSizeBytes)
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// VerifyUpload - compares the object that actually landed in storage with what the client asked to upload
+func (upload CoreValueThumbnailUpload) VerifyUpload(size int64, contentType string) (valid bool, errFields map[string]string) {
+	errFields = verifyImageUpload(upload.SizeBytes, upload.ContentType, size, contentType, MaxCoreValueThumbnailSizeBytes)
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// Format - the image format the upload has to decode as
+func (upload CoreValueThumbnailUpload) Format() string {
+	return imageFormats[upload.ContentType]
+}
+
+// ThumbnailKey - where the resized thumbnail is stored. The uploaded original is removed once it is resized.
+func (upload CoreValueThumbnailUpload) ThumbnailKey() string {
+	return resizedImageKey(upload.ObjectKey, CoreValueThumbnailSize)
+}
+
+func newCoreValueThumbnailObjectKey(coreValueID int64, contentType string) string {
+	return fmt.Sprintf("core_values/%d/%s.%s", coreValueID, uuid.New().String(), imageFormats[contentType])
+}
+
+func (s *pgStore) CreateCoreValueThumbnailUpload(ctx context.Context, upload CoreValueThumbnailUpload) (resp CoreValueThumbnailUpload, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&resp,
+		createCoreValueThumbnailUploadQuery,
+		upload.CoreValueID,
+		upload.UploadedBy,
+		newCoreValueThumbnailObjectKey(upload.CoreValueID, upload.ContentType),
+		upload.ContentType,
+		upload.SizeBytes,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"upload_params": upload,
+		}).Error("Error while creating core value thumbnail upload")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) GetCoreValueThumbnailUpload(ctx context.Context, coreValueID, uploadID int64) (upload CoreValueThumbnailUpload, err error) {
+	err = s.db.GetContext(ctx, &upload, getCoreValueThumbnailUploadQuery, coreValueID, uploadID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"core_value_id": coreValueID,
+			"upload_id":     uploadID,
+		}).Error("Error while getting core value thumbnail upload")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) DeleteCoreValueThumbnailUpload(ctx context.Context, coreValueID, uploadID int64) (err error) {
+	_, err = s.db.ExecContext(ctx, deleteCoreValueThumbnailUploadQuery, coreValueID, uploadID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"core_value_id": coreValueID,
+			"upload_id":     uploadID,
+		}).Error("Error while deleting core value thumbnail upload")
+		return
+	}
+
+	return
+}
+
+// ConfirmCoreValueThumbnailUpload - makes the resized upload the core value's thumbnail. Fails with
+// ErrRecordNotFound if the upload was already confirmed, so it can only be applied once.
+func (s *pgStore) ConfirmCoreValueThumbnailUpload(ctx context.Context, organisationID int64, upload CoreValueThumbnailUpload) (coreValue CoreValue, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	result, err := tx.ExecContext(ctx, deleteCoreValueThumbnailUploadQuery, upload.CoreValueID, upload.ID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"upload_id": upload.ID,
+		}).Error("Error while confirming core value thumbnail upload")
+		return
+	}
+
+	confirmed, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if confirmed == 0 {
+		err = ae.ErrRecordNotFound
+		return
+	}
+
+	err = tx.GetContext(ctx, &coreValue, setCoreValueThumbnailQuery, upload.ThumbnailKey(), time.Now(), upload.CoreValueID, organisationID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"core_value_id": upload.CoreValueID,
+		}).Error("Error while setting core value thumbnail")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoreValueThumbnailTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *CoreValueThumbnailTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *CoreValueThumbnailTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+var testCoreValueThumbnailUpload = CoreValueThumbnailUpload{ID: 4, CoreValueID: 3, UploadedBy: 1, ObjectKey: "core_values/3/new.jpeg", ContentType: "image/jpeg", SizeBytes: 1024}
+
+func (suite *CoreValueThumbnailTestSuite) TestValidate() {
+	valid, errFields := CoreValueThumbnailUpload{ContentType: "image/webp"}.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"content_type": "Must be image/png, image/jpeg or image/gif",
+		"size_bytes":   "Must be between 1 and 2097152 bytes",
+	}, errFields)
+
+	valid, _ = CoreValueThumbnailUpload{ContentType: "image/gif", SizeBytes: 1024}.Validate()
+	assert.True(suite.T(), valid)
+}
+
+func (suite *CoreValueThumbnailTestSuite) TestThumbnailKey() {
+	assert.Equal(suite.T(), "core_values/3/new_128.png", testCoreValueThumbnailUpload.ThumbnailKey())
+	assert.Equal(suite.T(), "jpeg", testCoreValueThumbnailUpload.Format())
+	assert.Regexp(suite.T(), `^core_values/3/[0-9a-f-]{36}\.gif$`, newCoreValueThumbnailObjectKey(3, "image/gif"))
+}
+
+func (suite *CoreValueThumbnailTestSuite) TestCreateCoreValueThumbnailUpload() {
+	suite.sqlmock.ExpectQuery("INSERT INTO core_value_thumbnail_uploads").
+		WithArgs(3, 1, sqlmock.AnyArg(), "image/jpeg", 1024, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "core_value_id", "uploaded_by", "object_key", "content_type", "size_bytes"}).
+			AddRow(4, 3, 1, "core_values/3/new.jpeg", "image/jpeg", 1024))
+
+	upload, err := suite.dbStore.CreateCoreValueThumbnailUpload(context.Background(), CoreValueThumbnailUpload{
+		CoreValueID: 3, UploadedBy: 1, ContentType: "image/jpeg", SizeBytes: 1024,
+	})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), testCoreValueThumbnailUpload, upload)
+}
+
+func (suite *CoreValueThumbnailTestSuite) TestConfirmCoreValueThumbnailUpload() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("DELETE FROM core_value_thumbnail_uploads").
+		WithArgs(3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("UPDATE core_values SET \\(thumbnail_key, legacy_thumbnail_url, updated_at\\) = \\(\\$1, NULL, \\$2\\)").
+		WithArgs("core_values/3/new_128.png", sqlmock.AnyArg(), 3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "org_id", "text", "description", "parent_id", "thumbnail_key", "position", "archived_at"}).
+			AddRow(3, 1, "Teamwork", "Working together", nil, "core_values/3/new_128.png", 0, nil))
+	suite.sqlmock.ExpectCommit()
+
+	coreValue, err := suite.dbStore.ConfirmCoreValueThumbnailUpload(context.Background(), 1, testCoreValueThumbnailUpload)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), CoreValue{ID: 3, OrgID: 1, Text: "Teamwork", Description: "Working together", ThumbnailKey: "core_values/3/new_128.png"}, coreValue)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *CoreValueThumbnailTestSuite) TestConfirmCoreValueThumbnailUploadTwice() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("DELETE FROM core_value_thumbnail_uploads").
+		WithArgs(3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.ConfirmCoreValueThumbnailUpload(context.Background(), 1, testCoreValueThumbnailUpload)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/lib/pq"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	listSiblingCoreValueIDsQuery = `SELECT id FROM core_values
+		WHERE org_id = $1 AND parent_id IS NOT DISTINCT FROM $2 AND archived_at IS NULL FOR UPDATE`
+
+	// positions follow the order of the ids, starting at 0
+	reorderCoreValuesQuery = `UPDATE core_values cv SET (position, updated_at) = (o.position - 1, $3)
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, position)
+		WHERE cv.org_id = $1 AND cv.id = o.id`
+
+	listSiblingCoreValuesQuery = `SELECT ` + coreValueColumns + ` FROM core_values
+		WHERE org_id = $1 AND parent_id IS NOT DISTINCT FROM $2 AND archived_at IS NULL ORDER BY position, id`
+)
+
+// CoreValueNode - a top level core value with its sub core values
+type CoreValueNode struct {
+	CoreValue
+	Children []CoreValue `json:"children"`
+}
+
+// CoreValueTree - nests sub core values under their parents, keeping the order they were listed in.
+// Sub core values whose parent isn't listed are left out.
+func CoreValueTree(coreValues []CoreValue) (tree []CoreValueNode) {
+	tree = make([]CoreValueNode, 0)
+	index := make(map[int64]int)
+	for _, coreValue := range coreValues {
+		if coreValue.ParentID == nil {
+			index[coreValue.ID] = len(tree)
+			tree = append(tree, CoreValueNode{CoreValue: coreValue, Children: []CoreValue{}})
+		}
+	}
+
+	for _, coreValue := range coreValues {
+		if coreValue.ParentID == nil {
+			continue
+		}
+		if i, ok := index[*coreValue.ParentID]; ok {
+			tree[i].Children = append(tree[i].Children, coreValue)
+		}
+	}
+	return
+}
+
+// CoreValueOrder - the new order of the active core values sharing a parent, the top level ones
+// when ParentID is nil. CoreValueIDs has to list every one of them exactly once.
+type CoreValueOrder struct {
+	ParentID     *int64  `json:"parent_id"`
+	CoreValueIDs []int64 `json:"core_value_ids"`
+}
+
+// Validate - checks the order is well formed, whether it matches the core values is up to the store
+func (order CoreValueOrder) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	seen := make(map[int64]bool)
+	for _, coreValueID := range order.CoreValueIDs {
+		if seen[coreValueID] {
+			errFields["core_value_ids"] = "Can't contain duplicates"
+			break
+		}
+		seen[coreValueID] = true
+	}
+	if len(order.CoreValueIDs) == 0 {
+		errFields["core_value_ids"] = "Can't be blank"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// ReorderCoreValues - stores the new positions and returns the reordered core values. Fails with
+// ErrCoreValueOrderMismatch unless the order lists exactly the active core values under the parent.
+func (s *pgStore) ReorderCoreValues(ctx context.Context, organisationID int64, order CoreValueOrder) (coreValues []CoreValue, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	var siblingIDs []int64
+	err = tx.SelectContext(ctx, &siblingIDs, listSiblingCoreValueIDsQuery, organisationID, order.ParentID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": organisationID,
+		}).Error("Error while listing core values to reorder")
+		return
+	}
+
+	siblings := make(map[int64]bool)
+	for _, id := range siblingIDs {
+		siblings[id] = true
+	}
+	for _, id := range order.CoreValueIDs {
+		if !siblings[id] {
+			err = ae.ErrCoreValueOrderMismatch
+			return
+		}
+	}
+	if len(order.CoreValueIDs) != len(siblingIDs) {
+		err = ae.ErrCoreValueOrderMismatch
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, reorderCoreValuesQuery, organisationID, pq.Array(order.CoreValueIDs), time.Now())
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":          err.Error(),
+			"org_id":       organisationID,
+			"order_params": order,
+		}).Error("Error while reordering core values")
+		return
+	}
+
+	coreValues = make([]CoreValue, 0)
+	err = tx.SelectContext(ctx, &coreValues, listSiblingCoreValuesQuery, organisationID, order.ParentID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": organisationID,
+		}).Error("Error while listing reordered core values")
+		return
+	}
+
+	return
+}
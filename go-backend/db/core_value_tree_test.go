@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type CoreValueTreeTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *CoreValueTreeTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *CoreValueTreeTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *CoreValueTreeTestSuite) TestCoreValueTree() {
+	teamwork, quality := int64(1), int64(3)
+	tree := CoreValueTree([]CoreValue{
+		{ID: 1, Text: "Teamwork"},
+		{ID: 2, Text: "Mentoring", ParentID: &teamwork},
+		{ID: 3, Text: "Quality"},
+		{ID: 4, Text: "Testing", ParentID: &quality},
+		{ID: 5, Text: "Pairing", ParentID: &teamwork},
+		{ID: 6, Text: "Orphan", ParentID: new(int64)},
+	})
+
+	assert.Equal(suite.T(), []CoreValueNode{
+		{
+			CoreValue: CoreValue{ID: 1, Text: "Teamwork"},
+			Children:  []CoreValue{{ID: 2, Text: "Mentoring", ParentID: &teamwork}, {ID: 5, Text: "Pairing", ParentID: &teamwork}},
+		},
+		{
+			CoreValue: CoreValue{ID: 3, Text: "Quality"},
+			Children:  []CoreValue{{ID: 4, Text: "Testing", ParentID: &quality}},
+		},
+	}, tree)
+}
+
+func (suite *CoreValueTreeTestSuite) TestCoreValueOrderValidate() {
+	valid, errFields := CoreValueOrder{}.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{"core_value_ids": "Can't be blank"}, errFields)
+
+	valid, errFields = CoreValueOrder{CoreValueIDs: []int64{1, 2, 1}}.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{"core_value_ids": "Can't contain duplicates"}, errFields)
+
+	valid, _ = CoreValueOrder{CoreValueIDs: []int64{2, 1}}.Validate()
+	assert.True(suite.T(), valid)
+}
+
+func (suite *CoreValueTreeTestSuite) TestReorderCoreValues() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT id FROM core_values").
+		WithArgs(1, nil).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(3))
+	suite.sqlmock.ExpectExec("UPDATE core_values cv SET").
+		WithArgs(1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM core_values").
+		WithArgs(1, nil).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "org_id", "text", "description", "parent_id", "position", "archived_at"}).
+			AddRow(3, 1, "Quality", "Doing it right", nil, 0, nil).
+			AddRow(1, 1, "Teamwork", "Working together", nil, 1, nil))
+	suite.sqlmock.ExpectCommit()
+
+	coreValues, err := suite.dbStore.ReorderCoreValues(context.Background(), 1, CoreValueOrder{CoreValueIDs: []int64{3, 1}})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(coreValues))
+	assert.Equal(suite.T(), int64(3), coreValues[0].ID)
+	assert.Equal(suite.T(), 1, coreValues[1].Position)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *CoreValueTreeTestSuite) TestReorderCoreValuesWhenOrderDoesNotMatch() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT id FROM core_values").
+		WithArgs(1, nil).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(3))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.ReorderCoreValues(context.Background(), 1, CoreValueOrder{CoreValueIDs: []int64{3}})
+
+	assert.Equal(suite.T(), ae.ErrCoreValueOrderMismatch, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -32,33 +32,129 @@ type Storer interface {
 	ShowRecognition(context.Context, int) (Recognition, error)
 	ListRecognitions(context.Context) ([]Recognition, error)
 	ListRecognitionsWithFilter(context.Context, map[string]int) ([]Recognition, error)
+	ListRecognitionDrafts(context.Context, int) ([]Recognition, error)
+	UpdateRecognitionDraft(context.Context, Recognition) (Recognition, error)
+	DeleteRecognitionDraft(context.Context, int, int) error
+	ListRecognitionFeed(context.Context, FeedQuery) (FeedPage, error)
+
+	// Recognition anti-gaming rules
+	GetRecognitionRuleSettings(context.Context, int) (RecognitionRuleSettings, error)
+	UpdateRecognitionRuleSettings(context.Context, RecognitionRuleSettings) (RecognitionRuleSettings, error)
+	CountRecognitionsGiven(context.Context, int, int, int64) (int, error)
+
+	// Recognition attachments
+	CreateRecognitionAttachment(context.Context, RecognitionAttachment) (RecognitionAttachment, error)
+	GetRecognitionAttachment(context.Context, int64, int64) (RecognitionAttachment, error)
+	ConfirmRecognitionAttachment(context.Context, int64, int64) (RecognitionAttachment, error)
+	DeleteRecognitionAttachment(context.Context, int64, int64) error
+	ListRecognitionAttachments(context.Context, []int64) ([]RecognitionAttachment, error)
+
+	// cron job to publish scheduled recognitions
+	PublishScheduledRecognitionsJob() error
+
+	// Hi5 quota ledger
+	ListHi5LedgerEntries(context.Context, int) ([]Hi5LedgerEntry, error)
+	ReconcileHi5Ledger(context.Context, bool) ([]Hi5LedgerDiscrepancy, error)
+
+	// Hi5 quota policies
+	GetHi5QuotaPolicy(context.Context, int) (Hi5QuotaPolicy, error)
+	UpdateHi5QuotaPolicy(context.Context, Hi5QuotaPolicy) (Hi5QuotaPolicy, error)
+	ListHi5LimitOverrides(context.Context, int) ([]Hi5LimitOverride, error)
+	SetHi5LimitOverride(context.Context, Hi5LimitOverride) (Hi5LimitOverride, error)
+	DeleteHi5LimitOverride(context.Context, Hi5LimitOverride) error
+	GetEffectiveHi5Limit(context.Context, int) (int, error)
+	GrantBonusHi5s(context.Context, int, Hi5BonusGrant, int) (Hi5LedgerEntry, error)
 
 	// cron job to reset user's Hi5 data
 	ResetHi5QuotaBalanceJob() error
-	UpdateHi5QuotaRenewalFrequencyOfUsers(Organization) error
+	UpdateHi5QuotaRenewalFrequencyOfUsers(Organization, int64) error
 
 	// Roles
 	GetRoleByID(context.Context, int) (Role, error)
 	GetRoleByName(context.Context, string) (Role, error)
 
 	// Core values
-	ListCoreValues(context.Context, int64) ([]CoreValue, error)
+	ListCoreValues(context.Context, int64, bool) ([]CoreValue, error)
 	GetCoreValue(context.Context, int64, int64) (CoreValue, error)
 	CreateCoreValue(context.Context, int64, CoreValue) (CoreValue, error)
-	DeleteCoreValue(context.Context, int64, int64) error
+	ArchiveCoreValue(context.Context, int64, int64) error
 	UpdateCoreValue(context.Context, int64, int64, CoreValue) (CoreValue, error)
+	ReorderCoreValues(context.Context, int64, CoreValueOrder) ([]CoreValue, error)
 
 	//RecognitionHi5
 	CreateRecognitionHi5(context.Context, RecognitionHi5, int) error
+	DeleteRecognitionHi5(context.Context, int, int, int64) (bool, error)
+	ListRecognitionHi5s(context.Context, int) ([]RecognitionHi5, error)
 
 	//Reported Recognition
 	CreateReportedRecognition(context.Context, int64, ReportedRecognition) (ReportedRecognition, error)
+	ListReviewedReportTypes(context.Context, int64) ([]string, error)
+	CountReportsBy(context.Context, int64, int64) (int, error)
 
 	//Recognition Moderation
-	CreateRecognitionModeration(context.Context, int64, RecognitionModeration) (RecognitionModeration, error)
+	CreateRecognitionModeration(context.Context, int64, RecognitionModeration, int64) (RecognitionModeration, error)
+	ListModerationQueue(context.Context, ModerationQueueQuery) (ModerationQueuePage, error)
+	AssignModeration(context.Context, int, ModerationAssignment) (ModerationAssignment, error)
+	GetModerationSettings(context.Context, int) (ModerationSettings, error)
+	UpdateModerationSettings(context.Context, ModerationSettings) (ModerationSettings, error)
+	QuarantineRecognition(context.Context, int, int64, ModerationSettings) (*RecognitionQuarantine, error)
+	ListModerationNotifications(context.Context, int) ([]ModerationNotification, error)
+	ReadModerationNotification(context.Context, int, int64) (ModerationNotification, error)
+	GetModerationMetrics(context.Context, ModerationMetricsQuery) (ModerationMetrics, error)
+	CreateRecognitionAppeal(context.Context, int, RecognitionAppeal) (RecognitionAppeal, error)
+	GetRecognitionAppeal(context.Context, int, int64) (RecognitionAppeal, error)
+	ListRecognitionAppeals(context.Context, int, string, int64) ([]RecognitionAppeal, error)
+	AssignRecognitionAppeal(context.Context, int64, int64) (RecognitionAppeal, error)
+	DecideRecognitionAppeal(context.Context, AppealDecision) (RecognitionAppeal, error)
+	ListRecognitionModerationHistory(context.Context, int64) (ModerationHistory, error)
+	ListReportReasons(context.Context, int, bool) ([]ReportReason, error)
+	CreateReportReason(context.Context, ReportReason) (ReportReason, error)
+	UpdateReportReason(context.Context, ReportReason) (ReportReason, error)
+	ArchiveReportReason(context.Context, int, int64) error
+
+	//Content filter
+	GetContentFilterSettings(context.Context, int) (ContentFilterSettings, error)
+	UpdateContentFilterSettings(context.Context, ContentFilterSettings) (ContentFilterSettings, error)
+	ListContentBlocklistTerms(context.Context, int) ([]ContentBlocklistTerm, error)
+	CreateContentBlocklistTerm(context.Context, ContentBlocklistTerm) (ContentBlocklistTerm, error)
+	DeleteContentBlocklistTerm(context.Context, int, int64) error
+	FlagRecognitionContent(context.Context, int64, []string) error
+
 	CreateBadge(context.Context, Badge) (Badge, error)
 	ListBadges(context.Context, int) ([]Badge, error)
 	UpdateBadge(context.Context, Badge) (Badge, error)
 	ShowBadge(context.Context, Badge) (Badge, error)
-	DeleteBadge(context.Context, int, int) error
+	DeleteBadge(context.Context, int, int) ([]string, error)
+
+	// Badge awards
+	AwardBadges(context.Context, int, int) ([]UserBadge, error)
+	AwardBadgesJob() error
+	ListUserBadges(context.Context, int) ([]UserBadge, error)
+	ListBadgeHolders(context.Context, int, int) ([]BadgeHolder, error)
+	ListBadgeProgress(context.Context, int, int) ([]BadgeProgress, error)
+	GrantBadge(context.Context, BadgeGrant) (UserBadge, error)
+	RevokeBadge(context.Context, BadgeGrant) (BadgeAwardAuditEntry, error)
+	ListBadgeAwardAudit(context.Context, int) ([]BadgeAwardAuditEntry, error)
+
+	// Badge images
+	CreateBadgeImageUpload(context.Context, BadgeImageUpload) (BadgeImageUpload, error)
+	GetBadgeImageUpload(context.Context, int, int64) (BadgeImageUpload, error)
+	DeleteBadgeImageUpload(context.Context, int, int64) error
+	ConfirmBadgeImageUpload(context.Context, int, BadgeImageUpload) (Badge, error)
+
+	// Core value thumbnails
+	CreateCoreValueThumbnailUpload(context.Context, CoreValueThumbnailUpload) (CoreValueThumbnailUpload, error)
+	GetCoreValueThumbnailUpload(context.Context, int64, int64) (CoreValueThumbnailUpload, error)
+	DeleteCoreValueThumbnailUpload(context.Context, int64, int64) error
+	ConfirmCoreValueThumbnailUpload(context.Context, int64, CoreValueThumbnailUpload) (CoreValue, error)
+
+	// Translations
+	ListCoreValueTranslations(context.Context, int64) ([]CoreValueTranslation, error)
+	SaveCoreValueTranslation(context.Context, CoreValueTranslation) (CoreValueTranslation, error)
+	DeleteCoreValueTranslation(context.Context, int64, string) error
+	PreferredCoreValueTranslations(context.Context, int64, []string) (map[int64]CoreValueTranslation, error)
+	ListBadgeTranslations(context.Context, int) ([]BadgeTranslation, error)
+	SaveBadgeTranslation(context.Context, BadgeTranslation) (BadgeTranslation, error)
+	DeleteBadgeTranslation(context.Context, int, string) error
+	PreferredBadgeTranslations(context.Context, int, []string) (map[int]BadgeTranslation, error)
 }
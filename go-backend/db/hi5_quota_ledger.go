@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/jmoiron/sqlx"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// Hi5LedgerGrant - quota given to a user, e.g. when they join
+	Hi5LedgerGrant = "grant"
+	// Hi5LedgerSpend - quota used by giving a Hi5
+	Hi5LedgerSpend = "spend"
+	// Hi5LedgerRefund - quota returned when a Hi5 is taken back in the same period
+	Hi5LedgerRefund = "refund"
+	// Hi5LedgerReset - balance set back to the organization's limit at the start of a quota period
+	Hi5LedgerReset = "reset"
+	// Hi5LedgerAdjustment - manual correction, including the opening balance recorded by the migration
+	Hi5LedgerAdjustment = "adjustment"
+
+	// the user row is locked so concurrent entries for the same user are applied one at a time
+	lockHi5QuotaBalanceQuery = `SELECT COALESCE(hi5_quota_balance, 0) FROM users WHERE id = $1 FOR UPDATE`
+
+	setHi5QuotaBalanceQuery = `UPDATE users SET hi5_quota_balance = $1 WHERE id = $2`
+
+	insertHi5LedgerEntryQuery = `INSERT INTO hi5_quota_ledger (user_id, entry_type, amount, balance_after,
+		recognition_id, reason, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, user_id, entry_type, amount, balance_after, recognition_id, reason, created_at`
+
+	listHi5LedgerEntriesQuery = `SELECT id, user_id, entry_type, amount, balance_after, recognition_id, reason, created_at
+		FROM hi5_quota_ledger WHERE user_id = $1 ORDER BY id DESC LIMIT $2`
+
+	listHi5LedgerDiscrepanciesQuery = `SELECT u.id AS user_id, COALESCE(u.hi5_quota_balance, 0) AS cached_balance,
+		COALESCE(SUM(l.amount), 0) AS ledger_balance
+		FROM users u LEFT JOIN hi5_quota_ledger l ON l.user_id = u.id
+		GROUP BY u.id HAVING COALESCE(u.hi5_quota_balance, 0) <> COALESCE(SUM(l.amount), 0)
+		ORDER BY u.id`
+
+	// new users start with whatever balance they were created with, granted through the ledger
+	grantNewUserHi5QuotaQuery = `INSERT INTO hi5_quota_ledger (user_id, entry_type, amount, balance_after, reason, created_at)
+		SELECT id, 'grant', hi5_quota_balance, hi5_quota_balance, 'Initial quota', NOW() FROM users
+		WHERE email = $1 AND hi5_quota_balance <> 0`
+
+	sumHi5LedgerQuery = `SELECT COALESCE(SUM(amount), 0) FROM hi5_quota_ledger WHERE user_id = $1`
+
+	// MaxHi5LedgerEntries - most ledger entries returned in one listing
+	MaxHi5LedgerEntries = 100
+)
+
+// Hi5LedgerEntry - one append-only change to a user's Hi5 quota. The sum of a user's
+// amounts is their balance; users.hi5_quota_balance only caches it.
+type Hi5LedgerEntry struct {
+	ID            int64     `db:"id" json:"id"`
+	UserID        int       `db:"user_id" json:"user_id"`
+	EntryType     string    `db:"entry_type" json:"entry_type"`
+	Amount        int       `db:"amount" json:"amount"`
+	BalanceAfter  int       `db:"balance_after" json:"balance_after"`
+	RecognitionID *int      `db:"recognition_id" json:"recognition_id"`
+	Reason        string    `db:"reason" json:"reason"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// Hi5LedgerDiscrepancy - a user whose cached balance doesn't match their ledger
+type Hi5LedgerDiscrepancy struct {
+	UserID        int `db:"user_id" json:"user_id"`
+	CachedBalance int `db:"cached_balance" json:"cached_balance"`
+	LedgerBalance int `db:"ledger_balance" json:"ledger_balance"`
+}
+
+// appendHi5LedgerEntry - records the entry and updates the cached balance inside the caller's transaction.
+// Spends that would take the balance below zero fail with ErrInsufficientHi5Quota, and grants that
+// would take it above balanceCap fail with ErrHi5BalanceCapExceeded. A balanceCap of 0 means no cap.
+func appendHi5LedgerEntry(ctx context.Context, tx *sqlx.Tx, entry Hi5LedgerEntry, balanceCap int) (recorded Hi5LedgerEntry, err error) {
+	var balance int
+	err = tx.GetContext(ctx, &balance, lockHi5QuotaBalanceQuery, entry.UserID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": entry.UserID,
+		}).Error("Error while locking user's Hi5 quota balance")
+		return
+	}
+
+	if entry.EntryType == Hi5LedgerSpend && balance+entry.Amount < 0 {
+		err = ae.ErrInsufficientHi5Quota
+		return
+	}
+
+	if entry.EntryType == Hi5LedgerGrant && balanceCap > 0 && balance+entry.Amount > balanceCap {
+		err = ae.ErrHi5BalanceCapExceeded
+		return
+	}
+
+	balance += entry.Amount
+	_, err = tx.ExecContext(ctx, setHi5QuotaBalanceQuery, balance, entry.UserID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": entry.UserID,
+		}).Error("Error while updating user's Hi5 quota balance")
+		return
+	}
+
+	err = tx.GetContext(
+		ctx,
+		&recorded,
+		insertHi5LedgerEntryQuery,
+		entry.UserID,
+		entry.EntryType,
+		entry.Amount,
+		balance,
+		entry.RecognitionID,
+		entry.Reason,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":          err.Error(),
+			"entry_params": entry,
+		}).Error("Error while recording Hi5 ledger entry")
+		return
+	}
+
+	return
+}
+
+// ListHi5LedgerEntries - the user's most recent ledger entries, newest first
+func (s *pgStore) ListHi5LedgerEntries(ctx context.Context, userID int) (entries []Hi5LedgerEntry, err error) {
+	entries = make([]Hi5LedgerEntry, 0)
+	err = s.db.SelectContext(ctx, &entries, listHi5LedgerEntriesQuery, userID, MaxHi5LedgerEntries)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while listing Hi5 ledger entries")
+		return
+	}
+
+	return
+}
+
+// ReconcileHi5Ledger - finds users whose cached balance has drifted from their ledger.
+// With fix set, the cached balance is overwritten with the ledger balance.
+func (s *pgStore) ReconcileHi5Ledger(ctx context.Context, fix bool) (discrepancies []Hi5LedgerDiscrepancy, err error) {
+	discrepancies = make([]Hi5LedgerDiscrepancy, 0)
+	err = s.db.SelectContext(ctx, &discrepancies, listHi5LedgerDiscrepanciesQuery)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while listing Hi5 ledger discrepancies")
+		return
+	}
+
+	if !fix {
+		return
+	}
+
+	for _, discrepancy := range discrepancies {
+		err = s.resetHi5QuotaBalanceFromLedger(ctx, discrepancy.UserID)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// resetHi5QuotaBalanceFromLedger - the ledger is summed only after the user row is locked,
+// so entries committed by concurrent Hi5s are never missed
+func (s *pgStore) resetHi5QuotaBalanceFromLedger(ctx context.Context, userID int) (err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	var balance int
+	err = tx.GetContext(ctx, &balance, lockHi5QuotaBalanceQuery, userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while locking user's Hi5 quota balance")
+		return
+	}
+
+	err = tx.GetContext(ctx, &balance, sumHi5LedgerQuery, userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while summing Hi5 ledger")
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, setHi5QuotaBalanceQuery, balance, userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while fixing user's Hi5 quota balance")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Hi5QuotaLedgerTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *Hi5QuotaLedgerTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *Hi5QuotaLedgerTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *Hi5QuotaLedgerTestSuite) TestListHi5LedgerEntriesSuccess() {
+	createdAt := time.Now()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_ledger WHERE user_id = (.+) ORDER BY id DESC").
+		WithArgs(1, MaxHi5LedgerEntries).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "entry_type", "amount", "balance_after", "recognition_id", "reason", "created_at"}).
+			AddRow(2, 1, Hi5LedgerSpend, -1, 4, 7, "Hi5 given", createdAt).
+			AddRow(1, 1, Hi5LedgerGrant, 5, 5, nil, "Initial quota", createdAt))
+
+	entries, err := suite.dbStore.ListHi5LedgerEntries(context.Background(), 1)
+
+	recognitionID := 7
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []Hi5LedgerEntry{
+		{ID: 2, UserID: 1, EntryType: Hi5LedgerSpend, Amount: -1, BalanceAfter: 4, RecognitionID: &recognitionID, Reason: "Hi5 given", CreatedAt: createdAt},
+		{ID: 1, UserID: 1, EntryType: Hi5LedgerGrant, Amount: 5, BalanceAfter: 5, Reason: "Initial quota", CreatedAt: createdAt},
+	}, entries)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *Hi5QuotaLedgerTestSuite) TestListHi5LedgerEntriesFailure() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_ledger").
+		WithArgs(1, MaxHi5LedgerEntries).
+		WillReturnError(errors.New("connection lost"))
+
+	_, err := suite.dbStore.ListHi5LedgerEntries(context.Background(), 1)
+
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *Hi5QuotaLedgerTestSuite) TestReconcileHi5LedgerWithoutFix() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users u LEFT JOIN hi5_quota_ledger").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "cached_balance", "ledger_balance"}).AddRow(3, 5, 4))
+
+	discrepancies, err := suite.dbStore.ReconcileHi5Ledger(context.Background(), false)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []Hi5LedgerDiscrepancy{{UserID: 3, CachedBalance: 5, LedgerBalance: 4}}, discrepancies)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *Hi5QuotaLedgerTestSuite) TestReconcileHi5LedgerWithFix() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users u LEFT JOIN hi5_quota_ledger").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "cached_balance", "ledger_balance"}).AddRow(3, 5, 4))
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(3).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(5))
+	suite.sqlmock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\) FROM hi5_quota_ledger").
+		WithArgs(3).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"sum"}).AddRow(4))
+	suite.sqlmock.ExpectExec("UPDATE users SET hi5_quota_balance").
+		WithArgs(4, 3).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectCommit()
+
+	discrepancies, err := suite.dbStore.ReconcileHi5Ledger(context.Background(), true)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(discrepancies))
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
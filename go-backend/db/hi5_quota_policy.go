@@ -0,0 +1,299 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// AdminRoleName - users with this role manage their organization's quota policies
+	AdminRoleName = "Admin"
+
+	getHi5QuotaPolicyQuery = `SELECT org_id, rollover_percent, max_carry_over, balance_cap
+		FROM hi5_quota_policies WHERE org_id = $1`
+
+	upsertHi5QuotaPolicyQuery = `INSERT INTO hi5_quota_policies (org_id, rollover_percent, max_carry_over,
+		balance_cap, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (org_id) DO UPDATE SET (rollover_percent, max_carry_over, balance_cap, updated_at) =
+		(EXCLUDED.rollover_percent, EXCLUDED.max_carry_over, EXCLUDED.balance_cap, EXCLUDED.updated_at)
+		RETURNING org_id, rollover_percent, max_carry_over, balance_cap`
+
+	listHi5LimitOverridesQuery = `SELECT id, org_id, role_id, user_id, hi5_limit FROM hi5_limit_overrides
+		WHERE org_id = $1 ORDER BY role_id NULLS LAST, user_id`
+
+	upsertRoleHi5LimitOverrideQuery = `INSERT INTO hi5_limit_overrides (org_id, role_id, hi5_limit, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (org_id, role_id) WHERE role_id IS NOT NULL
+		DO UPDATE SET (hi5_limit, updated_at) = (EXCLUDED.hi5_limit, EXCLUDED.updated_at)
+		RETURNING id, org_id, role_id, user_id, hi5_limit`
+
+	// only users of the organization can be given an override
+	upsertUserHi5LimitOverrideQuery = `INSERT INTO hi5_limit_overrides (org_id, user_id, hi5_limit, updated_at)
+		SELECT $1, id, $3, $4 FROM users WHERE id = $2 AND org_id = $1
+		ON CONFLICT (user_id) WHERE user_id IS NOT NULL
+		DO UPDATE SET (hi5_limit, updated_at) = (EXCLUDED.hi5_limit, EXCLUDED.updated_at)
+		RETURNING id, org_id, role_id, user_id, hi5_limit`
+
+	deleteRoleHi5LimitOverrideQuery = `DELETE FROM hi5_limit_overrides WHERE org_id = $1 AND role_id = $2`
+
+	deleteUserHi5LimitOverrideQuery = `DELETE FROM hi5_limit_overrides WHERE org_id = $1 AND user_id = $2`
+
+	// a user's own override wins over their role's, which wins over the organization's limit
+	getEffectiveHi5LimitQuery = `SELECT COALESCE(
+		(SELECT hi5_limit FROM hi5_limit_overrides WHERE user_id = u.id),
+		(SELECT hi5_limit FROM hi5_limit_overrides WHERE org_id = u.org_id AND role_id = u.role_id),
+		o.hi5_limit, 0)
+		FROM users u JOIN organizations o ON o.id = u.org_id WHERE u.id = $1`
+)
+
+// Hi5QuotaPolicy - how an organization's balances carry over from one period to the next.
+// A value of 0 switches that part of the policy off.
+type Hi5QuotaPolicy struct {
+	OrgID int `db:"org_id" json:"org_id"`
+	// RolloverPercent - share of the unused balance carried into the next period
+	RolloverPercent int `db:"rollover_percent" json:"rollover_percent"`
+	// MaxCarryOver - most Hi5s that can be carried into the next period
+	MaxCarryOver int `db:"max_carry_over" json:"max_carry_over"`
+	// BalanceCap - no balance can go above this, whether through resets or bonus grants
+	BalanceCap int `db:"balance_cap" json:"balance_cap"`
+}
+
+// DefaultHi5QuotaPolicy - no rollover and no cap, every user gets exactly their limit at reset
+func DefaultHi5QuotaPolicy(orgID int) Hi5QuotaPolicy {
+	return Hi5QuotaPolicy{OrgID: orgID}
+}
+
+// Validate - ensures none of the values are negative and the rollover is a percentage
+func (policy Hi5QuotaPolicy) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if policy.RolloverPercent < 0 || policy.RolloverPercent > 100 {
+		errFields["rollover_percent"] = "Must be between 0 and 100"
+	}
+	if policy.MaxCarryOver < 0 {
+		errFields["max_carry_over"] = "Can't be negative"
+	}
+	if policy.BalanceCap < 0 {
+		errFields["balance_cap"] = "Can't be negative"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// RenewedBalance - the balance a user starts the next period with. The renewal job and the quota preview both use it.
+func (policy Hi5QuotaPolicy) RenewedBalance(balance, hi5Limit int) (renewed int) {
+	carryOver := 0
+	if balance > 0 {
+		carryOver = balance * policy.RolloverPercent / 100
+	}
+	if policy.MaxCarryOver > 0 && carryOver > policy.MaxCarryOver {
+		carryOver = policy.MaxCarryOver
+	}
+
+	renewed = hi5Limit + carryOver
+	if policy.BalanceCap > 0 && renewed > policy.BalanceCap {
+		renewed = policy.BalanceCap
+	}
+	return
+}
+
+// Hi5LimitOverride - a Hi5 limit used instead of the organization's for a role or a single user
+type Hi5LimitOverride struct {
+	ID       int  `db:"id" json:"id"`
+	OrgID    int  `db:"org_id" json:"org_id"`
+	RoleID   *int `db:"role_id" json:"role_id"`
+	UserID   *int `db:"user_id" json:"user_id"`
+	Hi5Limit int  `db:"hi5_limit" json:"hi5_limit"`
+}
+
+// Validate - the limit can't be negative
+func (override Hi5LimitOverride) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if override.Hi5Limit < 0 {
+		errFields["hi5_limit"] = "Can't be negative"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// Hi5BonusGrant - one-off Hi5s given to a user on top of their quota
+type Hi5BonusGrant struct {
+	Amount int    `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// Validate - a bonus must add Hi5s and say why
+func (grant Hi5BonusGrant) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if grant.Amount <= 0 {
+		errFields["amount"] = "Must be greater than 0"
+	}
+	if strings.TrimSpace(grant.Reason) == "" {
+		errFields["reason"] = "Can't be blank"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// GetHi5QuotaPolicy - returns the organization's policy, or the default if none is saved
+func (s *pgStore) GetHi5QuotaPolicy(ctx context.Context, orgID int) (policy Hi5QuotaPolicy, err error) {
+	err = s.db.GetContext(ctx, &policy, getHi5QuotaPolicyQuery, orgID)
+	if err == sql.ErrNoRows {
+		policy = DefaultHi5QuotaPolicy(orgID)
+		err = nil
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while getting Hi5 quota policy")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) UpdateHi5QuotaPolicy(ctx context.Context, policy Hi5QuotaPolicy) (updatedPolicy Hi5QuotaPolicy, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&updatedPolicy,
+		upsertHi5QuotaPolicyQuery,
+		policy.OrgID,
+		policy.RolloverPercent,
+		policy.MaxCarryOver,
+		policy.BalanceCap,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"policy_params": policy,
+		}).Error("Error while updating Hi5 quota policy")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) ListHi5LimitOverrides(ctx context.Context, orgID int) (overrides []Hi5LimitOverride, err error) {
+	overrides = make([]Hi5LimitOverride, 0)
+	err = s.db.SelectContext(ctx, &overrides, listHi5LimitOverridesQuery, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing Hi5 limit overrides")
+		return
+	}
+
+	return
+}
+
+// SetHi5LimitOverride - creates or replaces the override of the role or user set on the override.
+// Returns ErrRecordNotFound for a user outside the organization.
+func (s *pgStore) SetHi5LimitOverride(ctx context.Context, override Hi5LimitOverride) (savedOverride Hi5LimitOverride, err error) {
+	if override.RoleID != nil {
+		err = s.db.GetContext(ctx, &savedOverride, upsertRoleHi5LimitOverrideQuery,
+			override.OrgID, *override.RoleID, override.Hi5Limit, time.Now())
+	} else {
+		err = s.db.GetContext(ctx, &savedOverride, upsertUserHi5LimitOverrideQuery,
+			override.OrgID, *override.UserID, override.Hi5Limit, time.Now())
+	}
+	if err == sql.ErrNoRows {
+		err = ae.ErrRecordNotFound
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"override_params": override,
+		}).Error("Error while saving Hi5 limit override")
+		return
+	}
+
+	return
+}
+
+// DeleteHi5LimitOverride - removes the override of the role or user set on the override
+func (s *pgStore) DeleteHi5LimitOverride(ctx context.Context, override Hi5LimitOverride) (err error) {
+	var result sql.Result
+	if override.RoleID != nil {
+		result, err = s.db.ExecContext(ctx, deleteRoleHi5LimitOverrideQuery, override.OrgID, *override.RoleID)
+	} else {
+		result, err = s.db.ExecContext(ctx, deleteUserHi5LimitOverrideQuery, override.OrgID, *override.UserID)
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"override_params": override,
+		}).Error("Error while deleting Hi5 limit override")
+		return
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if deleted == 0 {
+		err = ae.ErrRecordNotFound
+	}
+	return
+}
+
+// GetEffectiveHi5Limit - the limit the user's balance is renewed to at each reset
+func (s *pgStore) GetEffectiveHi5Limit(ctx context.Context, userID int) (hi5Limit int, err error) {
+	err = s.db.GetContext(ctx, &hi5Limit, getEffectiveHi5LimitQuery, userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while getting effective Hi5 limit")
+		return
+	}
+
+	return
+}
+
+// GrantBonusHi5s - adds one-off Hi5s to the user's balance. The grant fails with
+// ErrHi5BalanceCapExceeded if it would take the balance above the cap.
+func (s *pgStore) GrantBonusHi5s(ctx context.Context, userID int, grant Hi5BonusGrant, balanceCap int) (entry Hi5LedgerEntry, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	entry, err = appendHi5LedgerEntry(ctx, tx, Hi5LedgerEntry{
+		UserID:    userID,
+		EntryType: Hi5LedgerGrant,
+		Amount:    grant.Amount,
+		Reason:    strings.TrimSpace(grant.Reason),
+	}, balanceCap)
+	return
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Hi5QuotaPolicyTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestRenewedBalance() {
+	assert.Equal(suite.T(), 5, DefaultHi5QuotaPolicy(1).RenewedBalance(4, 5))
+
+	policy := Hi5QuotaPolicy{RolloverPercent: 50}
+	assert.Equal(suite.T(), 7, policy.RenewedBalance(5, 5))
+	assert.Equal(suite.T(), 5, policy.RenewedBalance(-2, 5))
+
+	policy.MaxCarryOver = 1
+	assert.Equal(suite.T(), 6, policy.RenewedBalance(5, 5))
+
+	policy = Hi5QuotaPolicy{RolloverPercent: 100, BalanceCap: 8}
+	assert.Equal(suite.T(), 8, policy.RenewedBalance(5, 5))
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestValidate() {
+	valid, errFields := Hi5QuotaPolicy{RolloverPercent: 120, MaxCarryOver: -1, BalanceCap: -1}.Validate()
+
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"rollover_percent": "Must be between 0 and 100",
+		"max_carry_over":   "Can't be negative",
+		"balance_cap":      "Can't be negative",
+	}, errFields)
+
+	valid, errFields = Hi5BonusGrant{Amount: 0, Reason: " "}.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{"amount": "Must be greater than 0", "reason": "Can't be blank"}, errFields)
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestGetHi5QuotaPolicyWhenNotConfigured() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_policies").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id", "rollover_percent", "max_carry_over", "balance_cap"}))
+
+	policy, err := suite.dbStore.GetHi5QuotaPolicy(context.Background(), 1)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), DefaultHi5QuotaPolicy(1), policy)
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestSetHi5LimitOverrideForUserOutsideOrganization() {
+	userID := 9
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_limit_overrides").
+		WithArgs(1, 9, 20, sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "org_id", "role_id", "user_id", "hi5_limit"}))
+
+	_, err := suite.dbStore.SetHi5LimitOverride(context.Background(), Hi5LimitOverride{OrgID: 1, UserID: &userID, Hi5Limit: 20})
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestSetHi5LimitOverrideForRole() {
+	roleID := 2
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_limit_overrides \\(org_id, role_id").
+		WithArgs(1, 2, 20, sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "org_id", "role_id", "user_id", "hi5_limit"}).AddRow(1, 1, 2, nil, 20))
+
+	override, err := suite.dbStore.SetHi5LimitOverride(context.Background(), Hi5LimitOverride{OrgID: 1, RoleID: &roleID, Hi5Limit: 20})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), Hi5LimitOverride{ID: 1, OrgID: 1, RoleID: &roleID, Hi5Limit: 20}, override)
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestDeleteHi5LimitOverrideWhenMissing() {
+	roleID := 2
+	suite.sqlmock.ExpectExec("DELETE FROM hi5_limit_overrides").
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.dbStore.DeleteHi5LimitOverride(context.Background(), Hi5LimitOverride{OrgID: 1, RoleID: &roleID})
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestGrantBonusHi5sSuccess() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(3).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(4))
+	suite.sqlmock.ExpectExec("UPDATE users SET hi5_quota_balance").
+		WithArgs(6, 3).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_quota_ledger").
+		WithArgs(3, Hi5LedgerGrant, 2, 6, nil, "Hackathon winner", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "entry_type", "amount", "balance_after", "reason"}).
+			AddRow(7, 3, Hi5LedgerGrant, 2, 6, "Hackathon winner"))
+	suite.sqlmock.ExpectCommit()
+
+	entry, err := suite.dbStore.GrantBonusHi5s(context.Background(), 3, Hi5BonusGrant{Amount: 2, Reason: "Hackathon winner "}, 10)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 6, entry.BalanceAfter)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *Hi5QuotaPolicyTestSuite) TestGrantBonusHi5sAboveCap() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(3).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(9))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.GrantBonusHi5s(context.Background(), 3, Hi5BonusGrant{Amount: 2, Reason: "Hackathon winner"}, 10)
+
+	assert.Equal(suite.T(), ae.ErrHi5BalanceCapExceeded, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -0,0 +1,402 @@
+package db
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Hi5 quota renewal frequencies an organization can choose from. Anything else is
+	// treated as a five field cron expression (minute hour day-of-month month day-of-week).
+	DailyRenewalFrequency     = "DAILY"
+	WeeklyRenewalFrequency    = "WEEKLY"
+	BiweeklyRenewalFrequency  = "BIWEEKLY"
+	MonthlyRenewalFrequency   = "MONTHLY"
+	QuarterlyRenewalFrequency = "QUARTERLY"
+
+	defaultRenewalWeekday = "MONDAY"
+	defaultTimezone       = "UTC"
+
+	// how far cron expressions are searched; leap days can be eight years apart, e.g. around 2100
+	maxCronSearchYears = 8
+)
+
+var (
+	// ErrInvalidCronExpression - the renewal frequency is neither a known frequency nor a valid cron expression
+	ErrInvalidCronExpression = errors.New("Must be DAILY, WEEKLY, BIWEEKLY, MONTHLY, QUARTERLY or a cron expression like \"0 0 * * 1\"")
+	// ErrCronNeverFires - the cron expression is valid but names a date that doesn't exist
+	ErrCronNeverFires = errors.New("Must be a cron expression that occurs, e.g. not on the 30th of February")
+	// ErrInvalidRenewalWeekday - weekly schedules need a day of the week
+	ErrInvalidRenewalWeekday = errors.New("Must be a day of the week, e.g. MONDAY")
+
+	// biweekly periods are counted in whole weeks from this Monday
+	biweeklyAnchor = time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	weekdays = map[string]time.Weekday{
+		"SUNDAY":    time.Sunday,
+		"MONDAY":    time.Monday,
+		"TUESDAY":   time.Tuesday,
+		"WEDNESDAY": time.Wednesday,
+		"THURSDAY":  time.Thursday,
+		"FRIDAY":    time.Friday,
+		"SATURDAY":  time.Saturday,
+	}
+)
+
+// Hi5QuotaSchedule - when an organization's Hi5 quotas are renewed, evaluated in its timezone
+type Hi5QuotaSchedule struct {
+	Frequency string
+	Weekday   time.Weekday
+	Location  *time.Location
+	cron      *cronExpression
+}
+
+// Hi5QuotaSchedule - parses the organization's renewal settings
+func (org Organization) Hi5QuotaSchedule() (schedule Hi5QuotaSchedule, err error) {
+	schedule.Frequency = strings.ToUpper(strings.TrimSpace(org.Hi5QuotaRenewalFrequency))
+	switch schedule.Frequency {
+	case DailyRenewalFrequency, MonthlyRenewalFrequency, QuarterlyRenewalFrequency:
+	case WeeklyRenewalFrequency, BiweeklyRenewalFrequency:
+		weekday := strings.ToUpper(strings.TrimSpace(org.Hi5QuotaRenewalWeekday))
+		if weekday == "" {
+			weekday = defaultRenewalWeekday
+		}
+		var ok bool
+		schedule.Weekday, ok = weekdays[weekday]
+		if !ok {
+			err = ErrInvalidRenewalWeekday
+			return
+		}
+	default:
+		schedule.Frequency = strings.TrimSpace(org.Hi5QuotaRenewalFrequency)
+		schedule.cron, err = parseCronExpression(schedule.Frequency)
+		if err != nil {
+			return
+		}
+		// the search covers the longest gap between leap days, so finding nothing means it never fires
+		if schedule.cron.next(time.Now().UTC()).IsZero() {
+			err = ErrCronNeverFires
+			return
+		}
+	}
+
+	timezone := org.Timezone
+	if timezone == "" {
+		timezone = defaultTimezone
+	}
+	schedule.Location, err = time.LoadLocation(timezone)
+	return
+}
+
+// PreviousReset - the latest renewal at or before now
+func (schedule Hi5QuotaSchedule) PreviousReset(now time.Time) time.Time {
+	now = now.In(schedule.Location)
+	year, month, day := now.Date()
+	midnight := time.Date(year, month, day, 0, 0, 0, 0, schedule.Location)
+
+	switch schedule.Frequency {
+	case DailyRenewalFrequency:
+		return midnight
+	case WeeklyRenewalFrequency:
+		daysSince := (int(now.Weekday()) - int(schedule.Weekday) + 7) % 7
+		return time.Date(year, month, day-daysSince, 0, 0, 0, 0, schedule.Location)
+	case BiweeklyRenewalFrequency:
+		daysSince := (int(now.Weekday()) - int(schedule.Weekday) + 7) % 7
+		if schedule.weeksFromAnchor(year, month, day-daysSince)%2 != 0 {
+			daysSince += 7
+		}
+		return time.Date(year, month, day-daysSince, 0, 0, 0, 0, schedule.Location)
+	case MonthlyRenewalFrequency:
+		return time.Date(year, month, 1, 0, 0, 0, 0, schedule.Location)
+	case QuarterlyRenewalFrequency:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, schedule.Location)
+	}
+	return schedule.cron.previous(now)
+}
+
+// NextReset - the first renewal after now
+func (schedule Hi5QuotaSchedule) NextReset(now time.Time) time.Time {
+	previous := schedule.PreviousReset(now)
+	year, month, day := previous.Date()
+
+	switch schedule.Frequency {
+	case DailyRenewalFrequency:
+		return time.Date(year, month, day+1, 0, 0, 0, 0, schedule.Location)
+	case WeeklyRenewalFrequency:
+		return time.Date(year, month, day+7, 0, 0, 0, 0, schedule.Location)
+	case BiweeklyRenewalFrequency:
+		return time.Date(year, month, day+14, 0, 0, 0, 0, schedule.Location)
+	case MonthlyRenewalFrequency:
+		return time.Date(year, month+1, 1, 0, 0, 0, 0, schedule.Location)
+	case QuarterlyRenewalFrequency:
+		return time.Date(year, month+3, 1, 0, 0, 0, 0, schedule.Location)
+	}
+	return schedule.cron.next(now.In(schedule.Location))
+}
+
+// weeksFromAnchor - whole weeks between the biweekly anchor and the given local date
+func (schedule Hi5QuotaSchedule) weeksFromAnchor(year int, month time.Month, day int) int {
+	// compare calendar dates in UTC so DST shifts don't change the day count
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	anchor := biweeklyAnchor.AddDate(0, 0, (int(schedule.Weekday)-int(time.Monday)+7)%7)
+	days := int(date.Sub(anchor).Hours() / 24)
+	if days < 0 {
+		days -= 6
+	}
+	return days / 7
+}
+
+// cronExpression - a parsed five field cron expression. Each field is the set of allowed values.
+type cronExpression struct {
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	// as in cron, when both day fields are restricted a day matching either of them is used
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+func parseCronExpression(expression string) (cron *cronExpression, err error) {
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		err = ErrInvalidCronExpression
+		return
+	}
+
+	cron = &cronExpression{
+		anyDayOfMonth: fields[2] == "*",
+		anyDayOfWeek:  fields[4] == "*",
+	}
+	bounds := []struct {
+		field    *map[int]bool
+		min, max int
+	}{
+		{&cron.minutes, 0, 59},
+		{&cron.hours, 0, 23},
+		{&cron.daysOfMonth, 1, 31},
+		{&cron.months, 1, 12},
+		{&cron.daysOfWeek, 0, 7},
+	}
+	for i, bound := range bounds {
+		*bound.field, err = parseCronField(fields[i], bound.min, bound.max)
+		if err != nil {
+			cron = nil
+			return
+		}
+	}
+
+	// 7 is another way of writing Sunday
+	if cron.daysOfWeek[7] {
+		cron.daysOfWeek[0] = true
+	}
+	return
+}
+
+// parseCronField - supports *, single values, ranges, lists and steps, e.g. "1-5", "0,30", "*/15"
+func parseCronField(field string, min, max int) (values map[int]bool, err error) {
+	values = make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				err = ErrInvalidCronExpression
+				return
+			}
+			part = part[:i]
+		}
+
+		start, end := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				err = ErrInvalidCronExpression
+				return
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					err = ErrInvalidCronExpression
+					return
+				}
+			} else if step > 1 {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			err = ErrInvalidCronExpression
+			return
+		}
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+	return
+}
+
+func (cron *cronExpression) matchesDay(date time.Time) bool {
+	if !cron.months[int(date.Month())] {
+		return false
+	}
+
+	dayOfMonth := cron.daysOfMonth[date.Day()]
+	dayOfWeek := cron.daysOfWeek[int(date.Weekday())]
+	switch {
+	case cron.anyDayOfMonth && cron.anyDayOfWeek:
+		return true
+	case cron.anyDayOfMonth:
+		return dayOfWeek
+	case cron.anyDayOfWeek:
+		return dayOfMonth
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+// previous - latest matching minute at or before now. The search runs on wall clock time; a minute
+// that a DST change skips or repeats resolves as time.Date does and is passed over if that is after now.
+func (cron *cronExpression) previous(now time.Time) time.Time {
+	for wallClock := wallClockMinute(now); ; wallClock = wallClock.Add(-time.Minute) {
+		wallClock = cron.previousWallClock(wallClock)
+		if wallClock.IsZero() {
+			return wallClock
+		}
+		if at := inLocation(wallClock, now.Location()); !at.After(now) {
+			return at
+		}
+	}
+}
+
+// next - first matching minute after now, see previous for how DST changes are handled
+func (cron *cronExpression) next(now time.Time) time.Time {
+	for wallClock := wallClockMinute(now).Add(time.Minute); ; wallClock = wallClock.Add(time.Minute) {
+		wallClock = cron.nextWallClock(wallClock)
+		if wallClock.IsZero() {
+			return wallClock
+		}
+		if at := inLocation(wallClock, now.Location()); at.After(now) {
+			return at
+		}
+	}
+}
+
+// previousWallClock - latest matching wall clock minute at or before from. Each field that doesn't
+// match moves from to the end of its previous allowed value, or of the next larger unit when none is left.
+func (cron *cronExpression) previousWallClock(from time.Time) time.Time {
+	minYear := from.Year() - maxCronSearchYears
+	for from.Year() >= minYear {
+		year, month, day := from.Date()
+
+		allowedMonth, ok := previousCronValue(cron.months, int(month), 1)
+		if !ok {
+			from = time.Date(year, time.January, 0, 23, 59, 0, 0, time.UTC)
+			continue
+		}
+		if time.Month(allowedMonth) != month {
+			// day 0 of the following month is the last day of the allowed one
+			from = time.Date(year, time.Month(allowedMonth)+1, 0, 23, 59, 0, 0, time.UTC)
+			continue
+		}
+
+		if !cron.matchesDay(from) {
+			from = time.Date(year, month, day, -1, 59, 0, 0, time.UTC)
+			continue
+		}
+
+		hour, ok := previousCronValue(cron.hours, from.Hour(), 0)
+		if !ok {
+			from = time.Date(year, month, day, -1, 59, 0, 0, time.UTC)
+			continue
+		}
+		if hour != from.Hour() {
+			from = time.Date(year, month, day, hour, 59, 0, 0, time.UTC)
+		}
+
+		minute, ok := previousCronValue(cron.minutes, from.Minute(), 0)
+		if !ok {
+			from = time.Date(year, month, day, hour, -1, 0, 0, time.UTC)
+			continue
+		}
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	return time.Time{}
+}
+
+// nextWallClock - first matching wall clock minute at or after from. Each field that doesn't match
+// moves from to the start of its next allowed value, or of the next larger unit when none is left.
+func (cron *cronExpression) nextWallClock(from time.Time) time.Time {
+	maxYear := from.Year() + maxCronSearchYears
+	for from.Year() <= maxYear {
+		year, month, day := from.Date()
+
+		allowedMonth, ok := nextCronValue(cron.months, int(month), 12)
+		if !ok {
+			from = time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if time.Month(allowedMonth) != month {
+			from = time.Date(year, time.Month(allowedMonth), 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		if !cron.matchesDay(from) {
+			from = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+
+		hour, ok := nextCronValue(cron.hours, from.Hour(), 23)
+		if !ok {
+			from = time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if hour != from.Hour() {
+			from = time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+		}
+
+		minute, ok := nextCronValue(cron.minutes, from.Minute(), 59)
+		if !ok {
+			from = time.Date(year, month, day, hour+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	return time.Time{}
+}
+
+// previousCronValue - largest allowed value at or below from
+func previousCronValue(values map[int]bool, from, min int) (value int, ok bool) {
+	for value = from; value >= min; value-- {
+		if values[value] {
+			return value, true
+		}
+	}
+	return
+}
+
+// nextCronValue - smallest allowed value at or above from
+func nextCronValue(values map[int]bool, from, max int) (value int, ok bool) {
+	for value = from; value <= max; value++ {
+		if values[value] {
+			return value, true
+		}
+	}
+	return
+}
+
+// wallClockMinute - the minute t shows on the clock in its own location, as a UTC time free of DST changes
+func wallClockMinute(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
+
+// inLocation - the moment the given wall clock minute is shown in loc
+func inLocation(wallClock time.Time, loc *time.Location) time.Time {
+	year, month, day := wallClock.Date()
+	return time.Date(year, month, day, wallClock.Hour(), wallClock.Minute(), 0, 0, loc)
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type Hi5QuotaScheduleTestSuite struct {
+	suite.Suite
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) schedule(frequency, weekday, timezone string) Hi5QuotaSchedule {
+	schedule, err := Organization{
+		Hi5QuotaRenewalFrequency: frequency,
+		Hi5QuotaRenewalWeekday:   weekday,
+		Timezone:                 timezone,
+	}.Hi5QuotaSchedule()
+	suite.Require().Nil(err)
+	return schedule
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestWeeklyScheduleUsesOrganizationTimezone() {
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	schedule := suite.schedule("weekly", "monday", "Asia/Kolkata")
+	// Sunday evening in UTC is already Monday in India
+	now := time.Date(2020, time.July, 5, 20, 0, 0, 0, time.UTC)
+
+	assert.Equal(suite.T(), time.Date(2020, time.July, 6, 0, 0, 0, 0, kolkata).Unix(), schedule.PreviousReset(now).Unix())
+	assert.Equal(suite.T(), time.Date(2020, time.July, 13, 0, 0, 0, 0, kolkata).Unix(), schedule.NextReset(now).Unix())
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestWeeklyScheduleOnChosenWeekday() {
+	schedule := suite.schedule("WEEKLY", "FRIDAY", "UTC")
+	now := time.Date(2020, time.July, 8, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(suite.T(), time.Date(2020, time.July, 3, 0, 0, 0, 0, time.UTC), schedule.PreviousReset(now))
+	assert.Equal(suite.T(), time.Date(2020, time.July, 10, 0, 0, 0, 0, time.UTC), schedule.NextReset(now))
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestBiweeklySchedule() {
+	schedule := suite.schedule("BIWEEKLY", "MONDAY", "UTC")
+
+	// 6th July 2020 is an odd number of weeks after the anchor, so the period began on 29th June
+	now := time.Date(2020, time.July, 7, 12, 0, 0, 0, time.UTC)
+	assert.Equal(suite.T(), time.Date(2020, time.June, 29, 0, 0, 0, 0, time.UTC), schedule.PreviousReset(now))
+	assert.Equal(suite.T(), time.Date(2020, time.July, 13, 0, 0, 0, 0, time.UTC), schedule.NextReset(now))
+
+	now = time.Date(2020, time.July, 13, 0, 0, 0, 0, time.UTC)
+	assert.Equal(suite.T(), time.Date(2020, time.July, 13, 0, 0, 0, 0, time.UTC), schedule.PreviousReset(now))
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestDailyMonthlyAndQuarterlySchedules() {
+	newYork, _ := time.LoadLocation("America/New_York")
+	now := time.Date(2020, time.August, 15, 2, 0, 0, 0, time.UTC)
+
+	daily := suite.schedule("DAILY", "", "America/New_York")
+	assert.Equal(suite.T(), time.Date(2020, time.August, 14, 0, 0, 0, 0, newYork).Unix(), daily.PreviousReset(now).Unix())
+	assert.Equal(suite.T(), time.Date(2020, time.August, 15, 0, 0, 0, 0, newYork).Unix(), daily.NextReset(now).Unix())
+
+	monthly := suite.schedule("MONTHLY", "", "UTC")
+	assert.Equal(suite.T(), time.Date(2020, time.August, 1, 0, 0, 0, 0, time.UTC), monthly.PreviousReset(now))
+	assert.Equal(suite.T(), time.Date(2020, time.September, 1, 0, 0, 0, 0, time.UTC), monthly.NextReset(now))
+
+	quarterly := suite.schedule("QUARTERLY", "", "UTC")
+	assert.Equal(suite.T(), time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC), quarterly.PreviousReset(now))
+	assert.Equal(suite.T(), time.Date(2020, time.October, 1, 0, 0, 0, 0, time.UTC), quarterly.NextReset(now))
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestCronSchedule() {
+	schedule := suite.schedule("30 9 * * 1-5", "", "UTC")
+	saturday := time.Date(2020, time.July, 11, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(suite.T(), time.Date(2020, time.July, 10, 9, 30, 0, 0, time.UTC), schedule.PreviousReset(saturday))
+	assert.Equal(suite.T(), time.Date(2020, time.July, 13, 9, 30, 0, 0, time.UTC), schedule.NextReset(saturday))
+
+	schedule = suite.schedule("0 0 1,15 */2 *", "", "UTC")
+	assert.Equal(suite.T(), time.Date(2020, time.July, 1, 0, 0, 0, 0, time.UTC), schedule.PreviousReset(saturday))
+	assert.Equal(suite.T(), time.Date(2020, time.September, 1, 0, 0, 0, 0, time.UTC), schedule.NextReset(saturday.AddDate(0, 0, 7)))
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestCronScheduleAcrossYearsAndDSTChanges() {
+	leapDay := suite.schedule("0 0 29 2 *", "", "UTC")
+	assert.Equal(suite.T(), time.Date(2104, time.February, 29, 0, 0, 0, 0, time.UTC), leapDay.NextReset(time.Date(2097, time.March, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(suite.T(), time.Date(2096, time.February, 29, 0, 0, 0, 0, time.UTC), leapDay.PreviousReset(time.Date(2104, time.February, 28, 0, 0, 0, 0, time.UTC)))
+
+	// either day field matches when both are restricted
+	schedule := suite.schedule("0 6 13 * 5", "", "UTC")
+	assert.Equal(suite.T(), time.Date(2020, time.July, 13, 6, 0, 0, 0, time.UTC), schedule.NextReset(time.Date(2020, time.July, 11, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(suite.T(), time.Date(2020, time.July, 10, 6, 0, 0, 0, time.UTC), schedule.PreviousReset(time.Date(2020, time.July, 11, 12, 0, 0, 0, time.UTC)))
+
+	// New York moves its clocks forward on the 8th of March 2020, the reset stays at 9 on the clock
+	newYork, _ := time.LoadLocation("America/New_York")
+	schedule = suite.schedule("0 9 * * *", "", "America/New_York")
+	assert.Equal(suite.T(), time.Date(2020, time.March, 8, 9, 0, 0, 0, newYork).Unix(), schedule.NextReset(time.Date(2020, time.March, 7, 12, 0, 0, 0, newYork)).Unix())
+	assert.Equal(suite.T(), time.Date(2020, time.March, 8, 9, 0, 0, 0, newYork).Unix(), schedule.PreviousReset(time.Date(2020, time.March, 9, 8, 0, 0, 0, newYork)).Unix())
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestInvalidSchedules() {
+	_, err := Organization{Hi5QuotaRenewalFrequency: "1 Week"}.Hi5QuotaSchedule()
+	assert.Equal(suite.T(), ErrInvalidCronExpression, err)
+
+	_, err = Organization{Hi5QuotaRenewalFrequency: "60 0 * * *"}.Hi5QuotaSchedule()
+	assert.Equal(suite.T(), ErrInvalidCronExpression, err)
+
+	_, err = Organization{Hi5QuotaRenewalFrequency: "0 0 30 2 *"}.Hi5QuotaSchedule()
+	assert.Equal(suite.T(), ErrCronNeverFires, err)
+
+	_, err = Organization{Hi5QuotaRenewalFrequency: "WEEKLY", Hi5QuotaRenewalWeekday: "FUNDAY"}.Hi5QuotaSchedule()
+	assert.Equal(suite.T(), ErrInvalidRenewalWeekday, err)
+
+	_, err = Organization{Hi5QuotaRenewalFrequency: "WEEKLY", Timezone: "IST"}.Hi5QuotaSchedule()
+	assert.NotNil(suite.T(), err)
+}
+
+func (suite *Hi5QuotaScheduleTestSuite) TestOrganizationValidateRejectsInvalidSchedule() {
+	org := Organization{
+		Name:                     "test organization",
+		ContactEmail:             "test@gmail.com",
+		DomainName:               "www.testdomain.com",
+		Hi5QuotaRenewalFrequency: "every week",
+		Timezone:                 "IST",
+	}
+
+	errorResponse, valid := org.Validate()
+
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), ErrInvalidCronExpression.Error(), errorResponse["error"].Fields["hi5_quota_renewal_frequency"])
+	assert.Equal(suite.T(), "Please enter a valid IANA timezone, e.g. Asia/Kolkata", errorResponse["error"].Fields["timezone"])
+}
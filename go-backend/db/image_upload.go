@@ -0,0 +1,49 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// imageFormats - content types accepted for uploaded images, mapped to the format they must decode as
+var imageFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+}
+
+// validateImageUpload - checks an image upload request before any object key is handed out
+func validateImageUpload(contentType string, sizeBytes, maxSizeBytes int64) (errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if _, ok := imageFormats[contentType]; !ok {
+		errFields["content_type"] = "Must be image/png, image/jpeg or image/gif"
+	}
+
+	if sizeBytes <= 0 || sizeBytes > maxSizeBytes {
+		errFields["size_bytes"] = fmt.Sprintf("Must be between 1 and %d bytes", maxSizeBytes)
+	}
+	return
+}
+
+// verifyImageUpload - compares the object that actually landed in storage with what the client asked to upload
+func verifyImageUpload(requestedSize int64, requestedContentType string, size int64, contentType string, maxSizeBytes int64) (errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if size != requestedSize || size > maxSizeBytes {
+		errFields["size_bytes"] = "Uploaded file size doesn't match the requested size"
+	}
+
+	if contentType != requestedContentType {
+		errFields["content_type"] = "Uploaded file type doesn't match the requested type"
+	}
+	return
+}
+
+// resizedImageKey - where a resized copy of the image is stored, next to the original
+func resizedImageKey(imageKey string, size int) string {
+	if dot := strings.LastIndex(imageKey, "."); dot > strings.LastIndex(imageKey, "/") {
+		imageKey = imageKey[:dot]
+	}
+	return fmt.Sprintf("%s_%d.png", imageKey, size)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale - locale of organizations that haven't picked one
+const DefaultLocale = "en"
+
+// CanonicalLocale - the BCP 47 form of the locale, e.g. de-DE for de_de. Not ok when it isn't a valid locale.
+func CanonicalLocale(locale string) (canonical string, ok bool) {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return
+	}
+
+	return tag.String(), true
+}
+
+// preferredLocales - the locales worth looking translations up for, most preferred first. Anything
+// after the organization's default locale is dropped since the untranslated names are in that locale.
+func preferredLocales(locales []string, defaultLocale string) (preferred []string) {
+	for _, locale := range locales {
+		if locale == defaultLocale {
+			break
+		}
+		preferred = append(preferred, locale)
+	}
+	return
+}
+
+// localeRank - position of the locale in the preferences, len(locales) when it isn't there
+func localeRank(locales []string, locale string) int {
+	for i, preferred := range locales {
+		if preferred == locale {
+			return i
+		}
+	}
+	return len(locales)
+}
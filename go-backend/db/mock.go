@@ -15,13 +15,13 @@ type DBMockStore struct {
 
 // GetRoleByID - test mock
 func (m *DBMockStore) GetRoleByID(ctx context.Context, id int) (role Role, err error) {
-	args := m.Called(ctx)
+	args := m.Called(ctx, id)
 	return args.Get(0).(Role), args.Error(1)
 }
 
 // GetRoleByName - test mock
 func (m *DBMockStore) GetRoleByName(ctx context.Context, name string) (role Role, err error) {
-	args := m.Called(ctx)
+	args := m.Called(ctx, name)
 	return args.Get(0).(Role), args.Error(1)
 }
 
@@ -78,8 +78,8 @@ func (m *DBMockStore) ListOrganizations(ctx context.Context) (organizations []Or
 }
 
 // ListCoreValues - returns a list of core value objects from the database
-func (m *DBMockStore) ListCoreValues(ctx context.Context, organisationID int64) (coreValues []CoreValue, err error) {
-	args := m.Called(ctx, organisationID)
+func (m *DBMockStore) ListCoreValues(ctx context.Context, organisationID int64, includeArchived bool) (coreValues []CoreValue, err error) {
+	args := m.Called(ctx, organisationID, includeArchived)
 	return args.Get(0).([]CoreValue), args.Error(1)
 }
 
@@ -95,8 +95,8 @@ func (m *DBMockStore) CreateCoreValue(ctx context.Context, organisationID int64,
 	return args.Get(0).(CoreValue), args.Error(1)
 }
 
-// DeleteCoreValue - Deletes the core value of the organization
-func (m *DBMockStore) DeleteCoreValue(ctx context.Context, organisationID, coreValueID int64) (err error) {
+// ArchiveCoreValue - Archives the core value of the organization
+func (m *DBMockStore) ArchiveCoreValue(ctx context.Context, organisationID, coreValueID int64) (err error) {
 	args := m.Called(ctx, organisationID, coreValueID)
 	return args.Error(0)
 }
@@ -107,6 +107,12 @@ func (m *DBMockStore) UpdateCoreValue(ctx context.Context, organisationID, coreV
 	return args.Get(0).(CoreValue), args.Error(1)
 }
 
+// ReorderCoreValues - reorders the core values sharing a parent
+func (m *DBMockStore) ReorderCoreValues(ctx context.Context, organisationID int64, order CoreValueOrder) (coreValues []CoreValue, err error) {
+	args := m.Called(ctx, organisationID, order)
+	return args.Get(0).([]CoreValue), args.Error(1)
+}
+
 // CreateOrganization - creates an organization
 func (m *DBMockStore) CreateOrganization(ctx context.Context, org Organization) (updatedOrg Organization, err error) {
 	args := m.Called(ctx, org)
@@ -156,9 +162,9 @@ func (m *DBMockStore) UpdateBadge(ctx context.Context, badge Badge) (badges Badg
 	return args.Get(0).(Badge), args.Error(1)
 }
 
-func (m *DBMockStore) DeleteBadge(ctx context.Context, org_id int, id int) (err error) {
+func (m *DBMockStore) DeleteBadge(ctx context.Context, org_id int, id int) (objectKeys []string, err error) {
 	args := m.Called(ctx, org_id, id)
-	return args.Error(1)
+	return args.Get(0).([]string), args.Error(1)
 }
 
 func (m *DBMockStore) CreateRecognitionHi5(ctx context.Context, recognitionHi5 RecognitionHi5, recognitionID int) (err error) {
@@ -166,6 +172,16 @@ func (m *DBMockStore) CreateRecognitionHi5(ctx context.Context, recognitionHi5 R
 	return args.Error(0)
 }
 
+func (m *DBMockStore) DeleteRecognitionHi5(ctx context.Context, recognitionID, givenBy int, refundSince int64) (refunded bool, err error) {
+	args := m.Called(ctx, recognitionID, givenBy, refundSince)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *DBMockStore) ListRecognitionHi5s(ctx context.Context, recognitionID int) (hi5s []RecognitionHi5, err error) {
+	args := m.Called(ctx, recognitionID)
+	return args.Get(0).([]RecognitionHi5), args.Error(1)
+}
+
 func (m *DBMockStore) GetUser(ctx context.Context, id int) (user User, err error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(User), args.Error(1)
@@ -179,6 +195,51 @@ func (m *DBMockStore) GetUserByOrganization(ctx context.Context, id, orgID int)
 	return args.Get(0).(User), args.Error(1)
 }
 
+func (m *DBMockStore) ListHi5LedgerEntries(ctx context.Context, userID int) (entries []Hi5LedgerEntry, err error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]Hi5LedgerEntry), args.Error(1)
+}
+
+func (m *DBMockStore) ReconcileHi5Ledger(ctx context.Context, fix bool) (discrepancies []Hi5LedgerDiscrepancy, err error) {
+	args := m.Called(ctx, fix)
+	return args.Get(0).([]Hi5LedgerDiscrepancy), args.Error(1)
+}
+
+func (m *DBMockStore) GetHi5QuotaPolicy(ctx context.Context, orgID int) (policy Hi5QuotaPolicy, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).(Hi5QuotaPolicy), args.Error(1)
+}
+
+func (m *DBMockStore) UpdateHi5QuotaPolicy(ctx context.Context, policy Hi5QuotaPolicy) (updatedPolicy Hi5QuotaPolicy, err error) {
+	args := m.Called(ctx, policy)
+	return args.Get(0).(Hi5QuotaPolicy), args.Error(1)
+}
+
+func (m *DBMockStore) ListHi5LimitOverrides(ctx context.Context, orgID int) (overrides []Hi5LimitOverride, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).([]Hi5LimitOverride), args.Error(1)
+}
+
+func (m *DBMockStore) SetHi5LimitOverride(ctx context.Context, override Hi5LimitOverride) (savedOverride Hi5LimitOverride, err error) {
+	args := m.Called(ctx, override)
+	return args.Get(0).(Hi5LimitOverride), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteHi5LimitOverride(ctx context.Context, override Hi5LimitOverride) (err error) {
+	args := m.Called(ctx, override)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) GetEffectiveHi5Limit(ctx context.Context, userID int) (hi5Limit int, err error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *DBMockStore) GrantBonusHi5s(ctx context.Context, userID int, grant Hi5BonusGrant, balanceCap int) (entry Hi5LedgerEntry, err error) {
+	args := m.Called(ctx, userID, grant, balanceCap)
+	return args.Get(0).(Hi5LedgerEntry), args.Error(1)
+}
+
 // ResetHi5QuotaBalanceJob - test mock
 func (m *DBMockStore) ResetHi5QuotaBalanceJob() (err error) {
 	return
@@ -199,12 +260,332 @@ func (m *DBMockStore) ListRecognitionsWithFilter(ctx context.Context, filters ma
 	return args.Get(0).([]Recognition), args.Error(1)
 }
 
+func (m *DBMockStore) ListRecognitionDrafts(ctx context.Context, givenBy int) (recognitions []Recognition, err error) {
+	args := m.Called(ctx, givenBy)
+	return args.Get(0).([]Recognition), args.Error(1)
+}
+
+func (m *DBMockStore) UpdateRecognitionDraft(ctx context.Context, recognition Recognition) (updatedRecognition Recognition, err error) {
+	args := m.Called(ctx, recognition)
+	return args.Get(0).(Recognition), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteRecognitionDraft(ctx context.Context, recognitionID, givenBy int) (err error) {
+	args := m.Called(ctx, recognitionID, givenBy)
+	return args.Error(0)
+}
+
+// PublishScheduledRecognitionsJob - test mock
+func (m *DBMockStore) PublishScheduledRecognitionsJob() (err error) {
+	return
+}
+
 func (m *DBMockStore) CreateReportedRecognition(ctx context.Context, recognitionID int64, reportedRecognition ReportedRecognition) (resp ReportedRecognition, err error) {
 	args := m.Called(ctx, recognitionID, reportedRecognition)
 	return args.Get(0).(ReportedRecognition), args.Error(1)
 }
 
-func (m *DBMockStore) CreateRecognitionModeration(ctx context.Context, recognitionID int64, recognitionModeration RecognitionModeration) (resp RecognitionModeration, err error) {
-	args := m.Called(ctx, recognitionID, recognitionModeration)
+func (m *DBMockStore) ListReviewedReportTypes(ctx context.Context, recognitionID int64) (types []string, err error) {
+	args := m.Called(ctx, recognitionID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *DBMockStore) CountReportsBy(ctx context.Context, reportedBy int64, since int64) (count int, err error) {
+	args := m.Called(ctx, reportedBy, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *DBMockStore) CreateRecognitionModeration(ctx context.Context, recognitionID int64, recognitionModeration RecognitionModeration, refundSince int64) (resp RecognitionModeration, err error) {
+	args := m.Called(ctx, recognitionID, recognitionModeration, refundSince)
 	return args.Get(0).(RecognitionModeration), args.Error(1)
 }
+
+func (m *DBMockStore) ListModerationQueue(ctx context.Context, query ModerationQueueQuery) (page ModerationQueuePage, err error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(ModerationQueuePage), args.Error(1)
+}
+
+func (m *DBMockStore) AssignModeration(ctx context.Context, orgID int, assignment ModerationAssignment) (resp ModerationAssignment, err error) {
+	args := m.Called(ctx, orgID, assignment)
+	return args.Get(0).(ModerationAssignment), args.Error(1)
+}
+
+func (m *DBMockStore) GetModerationSettings(ctx context.Context, orgID int) (settings ModerationSettings, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).(ModerationSettings), args.Error(1)
+}
+
+func (m *DBMockStore) UpdateModerationSettings(ctx context.Context, settings ModerationSettings) (updatedSettings ModerationSettings, err error) {
+	args := m.Called(ctx, settings)
+	return args.Get(0).(ModerationSettings), args.Error(1)
+}
+
+func (m *DBMockStore) QuarantineRecognition(ctx context.Context, orgID int, recognitionID int64, settings ModerationSettings) (quarantine *RecognitionQuarantine, err error) {
+	args := m.Called(ctx, orgID, recognitionID, settings)
+	return args.Get(0).(*RecognitionQuarantine), args.Error(1)
+}
+
+func (m *DBMockStore) ListModerationNotifications(ctx context.Context, userID int) (notifications []ModerationNotification, err error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]ModerationNotification), args.Error(1)
+}
+
+func (m *DBMockStore) ReadModerationNotification(ctx context.Context, userID int, notificationID int64) (notification ModerationNotification, err error) {
+	args := m.Called(ctx, userID, notificationID)
+	return args.Get(0).(ModerationNotification), args.Error(1)
+}
+
+func (m *DBMockStore) GetContentFilterSettings(ctx context.Context, orgID int) (settings ContentFilterSettings, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).(ContentFilterSettings), args.Error(1)
+}
+
+func (m *DBMockStore) UpdateContentFilterSettings(ctx context.Context, settings ContentFilterSettings) (updatedSettings ContentFilterSettings, err error) {
+	args := m.Called(ctx, settings)
+	return args.Get(0).(ContentFilterSettings), args.Error(1)
+}
+
+func (m *DBMockStore) ListContentBlocklistTerms(ctx context.Context, orgID int) (terms []ContentBlocklistTerm, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).([]ContentBlocklistTerm), args.Error(1)
+}
+
+func (m *DBMockStore) CreateContentBlocklistTerm(ctx context.Context, term ContentBlocklistTerm) (createdTerm ContentBlocklistTerm, err error) {
+	args := m.Called(ctx, term)
+	return args.Get(0).(ContentBlocklistTerm), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteContentBlocklistTerm(ctx context.Context, orgID int, termID int64) (err error) {
+	args := m.Called(ctx, orgID, termID)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) FlagRecognitionContent(ctx context.Context, recognitionID int64, findings []string) (err error) {
+	args := m.Called(ctx, recognitionID, findings)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) GetModerationMetrics(ctx context.Context, query ModerationMetricsQuery) (metrics ModerationMetrics, err error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(ModerationMetrics), args.Error(1)
+}
+
+func (m *DBMockStore) CreateRecognitionAppeal(ctx context.Context, orgID int, appeal RecognitionAppeal) (resp RecognitionAppeal, err error) {
+	args := m.Called(ctx, orgID, appeal)
+	return args.Get(0).(RecognitionAppeal), args.Error(1)
+}
+
+func (m *DBMockStore) GetRecognitionAppeal(ctx context.Context, orgID int, appealID int64) (appeal RecognitionAppeal, err error) {
+	args := m.Called(ctx, orgID, appealID)
+	return args.Get(0).(RecognitionAppeal), args.Error(1)
+}
+
+func (m *DBMockStore) ListRecognitionAppeals(ctx context.Context, orgID int, status string, assignedTo int64) (appeals []RecognitionAppeal, err error) {
+	args := m.Called(ctx, orgID, status, assignedTo)
+	return args.Get(0).([]RecognitionAppeal), args.Error(1)
+}
+
+func (m *DBMockStore) AssignRecognitionAppeal(ctx context.Context, appealID int64, assignedTo int64) (appeal RecognitionAppeal, err error) {
+	args := m.Called(ctx, appealID, assignedTo)
+	return args.Get(0).(RecognitionAppeal), args.Error(1)
+}
+
+func (m *DBMockStore) DecideRecognitionAppeal(ctx context.Context, decision AppealDecision) (appeal RecognitionAppeal, err error) {
+	args := m.Called(ctx, decision)
+	return args.Get(0).(RecognitionAppeal), args.Error(1)
+}
+
+func (m *DBMockStore) ListRecognitionModerationHistory(ctx context.Context, recognitionID int64) (history ModerationHistory, err error) {
+	args := m.Called(ctx, recognitionID)
+	return args.Get(0).(ModerationHistory), args.Error(1)
+}
+
+func (m *DBMockStore) ListReportReasons(ctx context.Context, orgID int, includeArchived bool) (reasons []ReportReason, err error) {
+	args := m.Called(ctx, orgID, includeArchived)
+	return args.Get(0).([]ReportReason), args.Error(1)
+}
+
+func (m *DBMockStore) CreateReportReason(ctx context.Context, reason ReportReason) (createdReason ReportReason, err error) {
+	args := m.Called(ctx, reason)
+	return args.Get(0).(ReportReason), args.Error(1)
+}
+
+func (m *DBMockStore) UpdateReportReason(ctx context.Context, reason ReportReason) (updatedReason ReportReason, err error) {
+	args := m.Called(ctx, reason)
+	return args.Get(0).(ReportReason), args.Error(1)
+}
+
+func (m *DBMockStore) ArchiveReportReason(ctx context.Context, orgID int, reasonID int64) (err error) {
+	args := m.Called(ctx, orgID, reasonID)
+	return args.Error(0)
+}
+
+// UpdateHi5QuotaRenewalFrequencyOfUsers - test mock
+func (m *DBMockStore) UpdateHi5QuotaRenewalFrequencyOfUsers(organization Organization, resetAt int64) (err error) {
+	return
+}
+
+func (m *DBMockStore) CreateRecognitionAttachment(ctx context.Context, attachment RecognitionAttachment) (resp RecognitionAttachment, err error) {
+	args := m.Called(ctx, attachment)
+	return args.Get(0).(RecognitionAttachment), args.Error(1)
+}
+
+func (m *DBMockStore) GetRecognitionAttachment(ctx context.Context, recognitionID, attachmentID int64) (attachment RecognitionAttachment, err error) {
+	args := m.Called(ctx, recognitionID, attachmentID)
+	return args.Get(0).(RecognitionAttachment), args.Error(1)
+}
+
+func (m *DBMockStore) ConfirmRecognitionAttachment(ctx context.Context, attachmentID, sizeBytes int64) (attachment RecognitionAttachment, err error) {
+	args := m.Called(ctx, attachmentID, sizeBytes)
+	return args.Get(0).(RecognitionAttachment), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteRecognitionAttachment(ctx context.Context, recognitionID, attachmentID int64) (err error) {
+	args := m.Called(ctx, recognitionID, attachmentID)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) ListRecognitionFeed(ctx context.Context, query FeedQuery) (page FeedPage, err error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).(FeedPage), args.Error(1)
+}
+
+func (m *DBMockStore) ListRecognitionAttachments(ctx context.Context, recognitionIDs []int64) (attachments []RecognitionAttachment, err error) {
+	args := m.Called(ctx, recognitionIDs)
+	return args.Get(0).([]RecognitionAttachment), args.Error(1)
+}
+
+func (m *DBMockStore) GetRecognitionRuleSettings(ctx context.Context, orgID int) (settings RecognitionRuleSettings, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).(RecognitionRuleSettings), args.Error(1)
+}
+
+func (m *DBMockStore) UpdateRecognitionRuleSettings(ctx context.Context, settings RecognitionRuleSettings) (updatedSettings RecognitionRuleSettings, err error) {
+	args := m.Called(ctx, settings)
+	return args.Get(0).(RecognitionRuleSettings), args.Error(1)
+}
+
+func (m *DBMockStore) CountRecognitionsGiven(ctx context.Context, givenBy, givenFor int, since int64) (count int, err error) {
+	args := m.Called(ctx, givenBy, givenFor, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *DBMockStore) AwardBadges(ctx context.Context, orgID, userID int) (awarded []UserBadge, err error) {
+	args := m.Called(ctx, orgID, userID)
+	return args.Get(0).([]UserBadge), args.Error(1)
+}
+
+// AwardBadgesJob - test mock
+func (m *DBMockStore) AwardBadgesJob() (err error) {
+	return
+}
+
+func (m *DBMockStore) ListUserBadges(ctx context.Context, userID int) (badges []UserBadge, err error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]UserBadge), args.Error(1)
+}
+
+func (m *DBMockStore) ListBadgeHolders(ctx context.Context, orgID, badgeID int) (holders []BadgeHolder, err error) {
+	args := m.Called(ctx, orgID, badgeID)
+	return args.Get(0).([]BadgeHolder), args.Error(1)
+}
+
+func (m *DBMockStore) ListBadgeProgress(ctx context.Context, orgID, userID int) (progress []BadgeProgress, err error) {
+	args := m.Called(ctx, orgID, userID)
+	return args.Get(0).([]BadgeProgress), args.Error(1)
+}
+
+func (m *DBMockStore) GrantBadge(ctx context.Context, grant BadgeGrant) (userBadge UserBadge, err error) {
+	args := m.Called(ctx, grant)
+	return args.Get(0).(UserBadge), args.Error(1)
+}
+
+func (m *DBMockStore) RevokeBadge(ctx context.Context, grant BadgeGrant) (entry BadgeAwardAuditEntry, err error) {
+	args := m.Called(ctx, grant)
+	return args.Get(0).(BadgeAwardAuditEntry), args.Error(1)
+}
+
+func (m *DBMockStore) ListBadgeAwardAudit(ctx context.Context, orgID int) (entries []BadgeAwardAuditEntry, err error) {
+	args := m.Called(ctx, orgID)
+	return args.Get(0).([]BadgeAwardAuditEntry), args.Error(1)
+}
+
+func (m *DBMockStore) CreateBadgeImageUpload(ctx context.Context, upload BadgeImageUpload) (resp BadgeImageUpload, err error) {
+	args := m.Called(ctx, upload)
+	return args.Get(0).(BadgeImageUpload), args.Error(1)
+}
+
+func (m *DBMockStore) GetBadgeImageUpload(ctx context.Context, badgeID int, uploadID int64) (upload BadgeImageUpload, err error) {
+	args := m.Called(ctx, badgeID, uploadID)
+	return args.Get(0).(BadgeImageUpload), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteBadgeImageUpload(ctx context.Context, badgeID int, uploadID int64) (err error) {
+	args := m.Called(ctx, badgeID, uploadID)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) ConfirmBadgeImageUpload(ctx context.Context, orgID int, upload BadgeImageUpload) (badge Badge, err error) {
+	args := m.Called(ctx, orgID, upload)
+	return args.Get(0).(Badge), args.Error(1)
+}
+
+func (m *DBMockStore) CreateCoreValueThumbnailUpload(ctx context.Context, upload CoreValueThumbnailUpload) (resp CoreValueThumbnailUpload, err error) {
+	args := m.Called(ctx, upload)
+	return args.Get(0).(CoreValueThumbnailUpload), args.Error(1)
+}
+
+func (m *DBMockStore) GetCoreValueThumbnailUpload(ctx context.Context, coreValueID, uploadID int64) (upload CoreValueThumbnailUpload, err error) {
+	args := m.Called(ctx, coreValueID, uploadID)
+	return args.Get(0).(CoreValueThumbnailUpload), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteCoreValueThumbnailUpload(ctx context.Context, coreValueID, uploadID int64) (err error) {
+	args := m.Called(ctx, coreValueID, uploadID)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) ConfirmCoreValueThumbnailUpload(ctx context.Context, organisationID int64, upload CoreValueThumbnailUpload) (coreValue CoreValue, err error) {
+	args := m.Called(ctx, organisationID, upload)
+	return args.Get(0).(CoreValue), args.Error(1)
+}
+
+func (m *DBMockStore) ListCoreValueTranslations(ctx context.Context, coreValueID int64) (translations []CoreValueTranslation, err error) {
+	args := m.Called(ctx, coreValueID)
+	return args.Get(0).([]CoreValueTranslation), args.Error(1)
+}
+
+func (m *DBMockStore) SaveCoreValueTranslation(ctx context.Context, translation CoreValueTranslation) (resp CoreValueTranslation, err error) {
+	args := m.Called(ctx, translation)
+	return args.Get(0).(CoreValueTranslation), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteCoreValueTranslation(ctx context.Context, coreValueID int64, locale string) (err error) {
+	args := m.Called(ctx, coreValueID, locale)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) PreferredCoreValueTranslations(ctx context.Context, organisationID int64, locales []string) (translations map[int64]CoreValueTranslation, err error) {
+	args := m.Called(ctx, organisationID, locales)
+	return args.Get(0).(map[int64]CoreValueTranslation), args.Error(1)
+}
+
+func (m *DBMockStore) ListBadgeTranslations(ctx context.Context, badgeID int) (translations []BadgeTranslation, err error) {
+	args := m.Called(ctx, badgeID)
+	return args.Get(0).([]BadgeTranslation), args.Error(1)
+}
+
+func (m *DBMockStore) SaveBadgeTranslation(ctx context.Context, translation BadgeTranslation) (resp BadgeTranslation, err error) {
+	args := m.Called(ctx, translation)
+	return args.Get(0).(BadgeTranslation), args.Error(1)
+}
+
+func (m *DBMockStore) DeleteBadgeTranslation(ctx context.Context, badgeID int, locale string) (err error) {
+	args := m.Called(ctx, badgeID, locale)
+	return args.Error(0)
+}
+
+func (m *DBMockStore) PreferredBadgeTranslations(ctx context.Context, orgID int, locales []string) (translations map[int]BadgeTranslation, err error) {
+	args := m.Called(ctx, orgID, locales)
+	return args.Get(0).(map[int]BadgeTranslation), args.Error(1)
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultModerationMetricsDays - range covered when the caller doesn't give a start
+	DefaultModerationMetricsDays = 30
+
+	reportsOfOrganization = ` FROM reported_recognitions rr
+		JOIN recognitions r ON r.id = rr.recognition_id
+		JOIN users giver ON giver.id = r.given_by`
+
+	// reports filed within [$2, $3)
+	reportedInRange = ` WHERE giver.org_id = $1 AND rr.reported_at >= $2 AND rr.reported_at < $3`
+
+	durationStatsColumns = `SELECT COUNT(seconds) AS count, ROUND(AVG(seconds))::bigint AS average_seconds,
+		ROUND(percentile_cont(0.5) WITHIN GROUP (ORDER BY seconds))::bigint AS median_seconds,
+		ROUND(percentile_cont(0.9) WITHIN GROUP (ORDER BY seconds))::bigint AS p90_seconds`
+
+	timeToFirstReviewQuery = durationStatsColumns + ` FROM (SELECT rr.reviewed_at - rr.reported_at AS seconds` +
+		reportsOfOrganization + reportedInRange + ` AND rr.reviewed_at IS NOT NULL) durations`
+
+	timeToResolutionQuery = durationStatsColumns + ` FROM (SELECT rr.resolved_at - rr.reported_at AS seconds` +
+		reportsOfOrganization + reportedInRange + ` AND rr.resolved_at IS NOT NULL) durations`
+
+	// reasons that were archived or removed are still counted under their name
+	reportsPerReasonQuery = `SELECT rr.type_of_reporting AS reason, COALESCE(MAX(rs.label), rr.type_of_reporting) AS label,
+		COUNT(*) AS count` + reportsOfOrganization + `
+		LEFT JOIN report_reasons rs ON rs.org_id = giver.org_id AND rs.name = rr.type_of_reporting` +
+		reportedInRange + `
+		GROUP BY rr.type_of_reporting ORDER BY count DESC, reason`
+
+	// decisions taken within [$2, $3), with how many of them were overturned on appeal since. The decisions
+	// recorded when an appeal is overturned belong to the appeal, not to the moderation queue, so they are left out.
+	moderatorOutcomesQuery = `SELECT m.moderated_by, COUNT(*) AS decisions,
+		COUNT(*) FILTER (WHERE m.is_inappropriate) AS hidden,
+		COUNT(*) FILTER (WHERE NOT m.is_inappropriate) AS restored,
+		COUNT(a.id) FILTER (WHERE a.status = 'overturned') AS overturned_on_appeal
+		FROM recognition_moderation m
+		JOIN recognitions r ON r.id = m.recognition_id
+		JOIN users giver ON giver.id = r.given_by
+		LEFT JOIN recognition_appeals a ON a.moderation_id = m.id
+		WHERE giver.org_id = $1 AND m.moderated_at >= $2 AND m.moderated_at < $3
+		AND NOT EXISTS (SELECT 1 FROM recognition_appeals o WHERE o.overturn_moderation_id = m.id)
+		GROUP BY m.moderated_by ORDER BY decisions DESC, m.moderated_by`
+
+	// every report of the organization still waiting for a decision, whenever it was filed, aged as of $2
+	backlogAgeQuery = `SELECT COUNT(*) AS count,
+		COUNT(*) FILTER (WHERE rr.status = 'open') AS open,
+		COUNT(*) FILTER (WHERE rr.status = 'in_review') AS in_review,
+		ROUND(AVG($2 - rr.reported_at))::bigint AS average_age_seconds,
+		MAX($2 - rr.reported_at) AS oldest_age_seconds` +
+		reportsOfOrganization + ` WHERE giver.org_id = $1 AND rr.status <> 'resolved'`
+)
+
+// ModerationMetricsQuery - whose reports to measure, filed within [From, To) in unix seconds
+type ModerationMetricsQuery struct {
+	OrgID int
+	From  int64
+	To    int64
+}
+
+// DurationStats - how long a step took for the reports that completed it. The durations are
+// in seconds and null when no report completed the step.
+type DurationStats struct {
+	Count          int    `db:"count" json:"count"`
+	AverageSeconds *int64 `db:"average_seconds" json:"average_seconds"`
+	MedianSeconds  *int64 `db:"median_seconds" json:"median_seconds"`
+	P90Seconds     *int64 `db:"p90_seconds" json:"p90_seconds"`
+}
+
+// ReasonReportCount - how many reports were filed for a reason
+type ReasonReportCount struct {
+	Reason string `db:"reason" json:"reason"`
+	Label  string `db:"label" json:"label"`
+	Count  int    `db:"count" json:"count"`
+}
+
+// ModeratorOutcome - what a moderator decided and how many of their decisions were overturned on appeal
+type ModeratorOutcome struct {
+	ModeratedBy        int64 `db:"moderated_by" json:"moderated_by"`
+	Decisions          int   `db:"decisions" json:"decisions"`
+	Hidden             int   `db:"hidden" json:"hidden"`
+	Restored           int   `db:"restored" json:"restored"`
+	OverturnedOnAppeal int   `db:"overturned_on_appeal" json:"overturned_on_appeal"`
+}
+
+// BacklogAge - reports still waiting for a decision and how long they have been waiting so far, in seconds
+type BacklogAge struct {
+	Count             int    `db:"count" json:"count"`
+	Open              int    `db:"open" json:"open"`
+	InReview          int    `db:"in_review" json:"in_review"`
+	AverageAgeSeconds *int64 `db:"average_age_seconds" json:"average_age_seconds"`
+	OldestAgeSeconds  *int64 `db:"oldest_age_seconds" json:"oldest_age_seconds"`
+}
+
+// ModerationMetrics - how quickly an organization's reports were handled over a date range
+type ModerationMetrics struct {
+	From              int64               `json:"from"`
+	To                int64               `json:"to"`
+	TimeToFirstReview DurationStats       `json:"time_to_first_review"`
+	TimeToResolution  DurationStats       `json:"time_to_resolution"`
+	ReportsPerReason  []ReasonReportCount `json:"reports_per_reason"`
+	ModeratorOutcomes []ModeratorOutcome  `json:"moderator_outcomes"`
+	Backlog           BacklogAge          `json:"backlog"`
+}
+
+// GetModerationMetrics - computes the metrics from the report and moderation decision timestamps.
+// Report metrics cover the reports filed in the range, moderator outcomes the decisions taken in it
+// and the backlog every report still open now, so reports waiting longer than the range aren't missed.
+func (s *pgStore) GetModerationMetrics(ctx context.Context, query ModerationMetricsQuery) (metrics ModerationMetrics, err error) {
+	metrics.From = query.From
+	metrics.To = query.To
+
+	logError := func(err error, message string) {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": query.OrgID,
+		}).Error(message)
+	}
+
+	err = s.db.GetContext(ctx, &metrics.TimeToFirstReview, timeToFirstReviewQuery, query.OrgID, query.From, query.To)
+	if err != nil {
+		logError(err, "Error while computing time to first review")
+		return
+	}
+
+	err = s.db.GetContext(ctx, &metrics.TimeToResolution, timeToResolutionQuery, query.OrgID, query.From, query.To)
+	if err != nil {
+		logError(err, "Error while computing time to resolution")
+		return
+	}
+
+	metrics.ReportsPerReason = make([]ReasonReportCount, 0)
+	err = s.db.SelectContext(ctx, &metrics.ReportsPerReason, reportsPerReasonQuery, query.OrgID, query.From, query.To)
+	if err != nil {
+		logError(err, "Error while counting reports per reason")
+		return
+	}
+
+	metrics.ModeratorOutcomes = make([]ModeratorOutcome, 0)
+	err = s.db.SelectContext(ctx, &metrics.ModeratorOutcomes, moderatorOutcomesQuery, query.OrgID, query.From, query.To)
+	if err != nil {
+		logError(err, "Error while counting moderator outcomes")
+		return
+	}
+
+	err = s.db.GetContext(ctx, &metrics.Backlog, backlogAgeQuery, query.OrgID, time.Now().Unix())
+	if err != nil {
+		logError(err, "Error while computing backlog age")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ModerationMetricsTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *ModerationMetricsTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *ModerationMetricsTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+var durationStatsTestColumns = []string{"count", "average_seconds", "median_seconds", "p90_seconds"}
+
+func (suite *ModerationMetricsTestSuite) TestGetModerationMetrics() {
+	query := ModerationMetricsQuery{OrgID: 1, From: 1595000000, To: 1595600000}
+
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(seconds\\) (.+) rr.reviewed_at - rr.reported_at").
+		WithArgs(1, query.From, query.To).
+		WillReturnRows(suite.sqlmock.NewRows(durationStatsTestColumns).AddRow(3, 600, 300, 1500))
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(seconds\\) (.+) rr.resolved_at - rr.reported_at").
+		WithArgs(1, query.From, query.To).
+		WillReturnRows(suite.sqlmock.NewRows(durationStatsTestColumns).AddRow(0, nil, nil, nil))
+	suite.sqlmock.ExpectQuery("SELECT rr.type_of_reporting AS reason(.+) GROUP BY rr.type_of_reporting").
+		WithArgs(1, query.From, query.To).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"reason", "label", "count"}).
+			AddRow("fraud", "Fraud", 2).
+			AddRow("spam", "spam", 1))
+	suite.sqlmock.ExpectQuery("SELECT m.moderated_by(.+) FROM recognition_moderation m(.+) NOT EXISTS \\(SELECT 1 FROM recognition_appeals o WHERE o.overturn_moderation_id = m.id\\)").
+		WithArgs(1, query.From, query.To).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"moderated_by", "decisions", "hidden", "restored", "overturned_on_appeal"}).
+			AddRow(1, 2, 1, 1, 1))
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(\\*\\) AS count(.+) WHERE giver.org_id = \\$1 AND rr.status <> 'resolved'").
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"count", "open", "in_review", "average_age_seconds", "oldest_age_seconds"}).
+			AddRow(1, 0, 1, 7200, 7200))
+
+	metrics, err := suite.dbStore.GetModerationMetrics(context.Background(), query)
+
+	average, median, p90, age := int64(600), int64(300), int64(1500), int64(7200)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), ModerationMetrics{
+		From:              query.From,
+		To:                query.To,
+		TimeToFirstReview: DurationStats{Count: 3, AverageSeconds: &average, MedianSeconds: &median, P90Seconds: &p90},
+		TimeToResolution:  DurationStats{},
+		ReportsPerReason: []ReasonReportCount{
+			{Reason: "fraud", Label: "Fraud", Count: 2},
+			{Reason: "spam", Label: "spam", Count: 1},
+		},
+		ModeratorOutcomes: []ModeratorOutcome{{ModeratedBy: 1, Decisions: 2, Hidden: 1, Restored: 1, OverturnedOnAppeal: 1}},
+		Backlog:           BacklogAge{Count: 1, InReview: 1, AverageAgeSeconds: &age, OldestAgeSeconds: &age},
+	}, metrics)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *ModerationMetricsTestSuite) TestGetModerationMetricsFailure() {
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(seconds\\)").
+		WillReturnError(errors.New("connection lost"))
+
+	_, err := suite.dbStore.GetModerationMetrics(context.Background(), ModerationMetricsQuery{OrgID: 1, From: 1, To: 2})
+
+	assert.NotNil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
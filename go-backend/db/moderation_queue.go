@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/lib/pq"
+	logger "github.com/sirupsen/logrus"
+
+	ae "joshsoftware/peerly/apperrors"
+)
+
+const (
+	// DefaultModerationQueueLimit - page size used when the moderator doesn't ask for one
+	DefaultModerationQueueLimit = 20
+	// MaxModerationQueueLimit - largest page the moderation queue will return
+	MaxModerationQueueLimit = 100
+
+	// listModerationQueueQuery groups the reports by recognition. A recognition is as far along
+	// as its least progressed report, so a new report reopens a resolved recognition.
+	// Pages are keyed on (first_reported_at, recognition_id), oldest first.
+	listModerationQueueQuery = `SELECT * FROM (
+		SELECT r.id AS recognition_id, r.text, r.given_by, r.given_for, r.given_at,
+		COUNT(rr.id) AS report_count,
+		ARRAY_AGG(DISTINCT rr.type_of_reporting) AS types,
+		ARRAY_AGG(rr.reason_for_reporting ORDER BY rr.reported_at, rr.id) AS reasons,
+		MIN(rr.reported_at) AS first_reported_at, MAX(rr.reported_at) AS last_reported_at,
+		CASE WHEN BOOL_OR(rr.status = 'open') THEN 'open'
+			WHEN BOOL_OR(rr.status = 'in_review') THEN 'in_review'
+			ELSE 'resolved' END AS status,
+		EXISTS (SELECT 1 FROM recognition_quarantines qr WHERE qr.recognition_id = r.id AND qr.reviewed_at IS NULL) AS quarantined,
+		a.assigned_to, a.assigned_at
+		FROM reported_recognitions rr
+		JOIN recognitions r ON r.id = rr.recognition_id
+		JOIN users giver ON giver.id = r.given_by
+		LEFT JOIN recognition_moderation_assignments a ON a.recognition_id = r.id
+		WHERE giver.org_id = $1
+		GROUP BY r.id, a.recognition_id
+	) q
+	WHERE (q.status = $2 OR ($2 = '' AND q.status <> 'resolved'))
+	AND ($3 = 0 OR q.assigned_to = $3)
+	AND ($4 = '' OR $4 = ANY(q.types))
+	AND (q.first_reported_at, q.recognition_id) > ($5, $6)
+	ORDER BY q.first_reported_at, q.recognition_id
+	LIMIT $7`
+
+	// only recognitions of the organization with reports still to be resolved can be assigned
+	assignModerationQuery = `INSERT INTO recognition_moderation_assignments (recognition_id, assigned_to, assigned_by, assigned_at)
+		SELECT r.id, $3, $4, $5 FROM recognitions r
+		JOIN users giver ON giver.id = r.given_by
+		WHERE r.id = $2 AND giver.org_id = $1
+		AND EXISTS (SELECT 1 FROM reported_recognitions WHERE recognition_id = r.id AND status <> 'resolved')
+		ON CONFLICT (recognition_id) DO UPDATE SET assigned_to = EXCLUDED.assigned_to,
+		assigned_by = EXCLUDED.assigned_by, assigned_at = EXCLUDED.assigned_at
+		RETURNING recognition_id, assigned_to, assigned_by, assigned_at`
+
+	// reviewed_at marks when a moderator first picked the report up, for the moderation metrics
+	startReportReviewQuery = `UPDATE reported_recognitions SET status = 'in_review', reviewed_at = $3, updated_at = $2
+		WHERE recognition_id = $1 AND status = 'open'`
+
+	// reports decided on without an assignment were reviewed when they were resolved
+	resolveReportsQuery = `UPDATE reported_recognitions SET status = 'resolved', resolved_at = $2,
+		reviewed_at = COALESCE(reviewed_at, $2), moderation_id = $3, updated_at = $4
+		WHERE recognition_id = $1 AND status <> 'resolved'`
+)
+
+// ErrInvalidModerationQueueCursor - the cursor wasn't produced by the moderation queue
+var ErrInvalidModerationQueueCursor = errors.New("Invalid moderation queue cursor")
+
+// ModerationQueueItem - a reported recognition along with a summary of its reports
+type ModerationQueueItem struct {
+	RecognitionID   int64          `db:"recognition_id" json:"recognition_id"`
+	Text            string         `db:"text" json:"text"`
+	GivenBy         int64          `db:"given_by" json:"given_by"`
+	GivenFor        int64          `db:"given_for" json:"given_for"`
+	GivenAt         int64          `db:"given_at" json:"given_at"`
+	ReportCount     int            `db:"report_count" json:"report_count"`
+	Types           pq.StringArray `db:"types" json:"mark_as"`
+	Reasons         pq.StringArray `db:"reasons" json:"reasons"`
+	FirstReportedAt int64          `db:"first_reported_at" json:"first_reported_at"`
+	LastReportedAt  int64          `db:"last_reported_at" json:"last_reported_at"`
+	Status          string         `db:"status" json:"status"`
+	Quarantined     bool           `db:"quarantined" json:"quarantined"`
+	AssignedTo      *int64         `db:"assigned_to" json:"assigned_to"`
+	AssignedAt      *int64         `db:"assigned_at" json:"assigned_at"`
+}
+
+// ModerationAssignment - the moderator reviewing a reported recognition
+type ModerationAssignment struct {
+	RecognitionID int64 `db:"recognition_id" json:"recognition_id"`
+	AssignedTo    int64 `db:"assigned_to" json:"assigned_to"`
+	AssignedBy    int64 `db:"assigned_by" json:"assigned_by"`
+	AssignedAt    int64 `db:"assigned_at" json:"assigned_at"`
+}
+
+// ModerationQueueCursor - position of the last recognition on a page
+type ModerationQueueCursor struct {
+	FirstReportedAt int64
+	RecognitionID   int64
+}
+
+// Encode - opaque form of the cursor handed to clients
+func (cursor ModerationQueueCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.FirstReportedAt, cursor.RecognitionID)))
+}
+
+// DecodeModerationQueueCursor - parses a cursor returned by a previous page
+func DecodeModerationQueueCursor(encoded string) (cursor ModerationQueueCursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		err = ErrInvalidModerationQueueCursor
+		return
+	}
+
+	_, err = fmt.Sscanf(string(raw), "%d:%d", &cursor.FirstReportedAt, &cursor.RecognitionID)
+	if err != nil {
+		err = ErrInvalidModerationQueueCursor
+	}
+	return
+}
+
+// ModerationQueueQuery - which page of which organization's queue to load. An empty Status
+// lists everything not yet resolved, a zero AssignedTo doesn't filter on the moderator.
+type ModerationQueueQuery struct {
+	OrgID      int
+	Status     string
+	AssignedTo int64
+	Type       string
+	Cursor     *ModerationQueueCursor
+	Limit      int
+}
+
+// ModerationQueuePage - one page of the queue. NextCursor is empty on the last page.
+type ModerationQueuePage struct {
+	Items      []ModerationQueueItem `json:"items"`
+	NextCursor string                `json:"next_cursor"`
+}
+
+// ListModerationQueue - loads a page of the organization's reported recognitions
+func (s *pgStore) ListModerationQueue(ctx context.Context, query ModerationQueueQuery) (page ModerationQueuePage, err error) {
+	cursor := ModerationQueueCursor{FirstReportedAt: math.MinInt64, RecognitionID: 0}
+	if query.Cursor != nil {
+		cursor = *query.Cursor
+	}
+
+	// one extra row tells us whether there is a next page
+	items := make([]ModerationQueueItem, 0)
+	err = s.db.SelectContext(
+		ctx,
+		&items,
+		listModerationQueueQuery,
+		query.OrgID,
+		query.Status,
+		query.AssignedTo,
+		query.Type,
+		cursor.FirstReportedAt,
+		cursor.RecognitionID,
+		query.Limit+1,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": query.OrgID,
+		}).Error("Error while listing moderation queue")
+		return
+	}
+
+	if len(items) > query.Limit {
+		items = items[:query.Limit]
+		last := items[len(items)-1]
+		page.NextCursor = ModerationQueueCursor{FirstReportedAt: last.FirstReportedAt, RecognitionID: last.RecognitionID}.Encode()
+	}
+
+	page.Items = items
+	return
+}
+
+// AssignModeration - assigns a moderator to a reported recognition of the organization and
+// moves its open reports in review. Fails with ErrRecordNotFound if the recognition has no
+// reports left to resolve.
+func (s *pgStore) AssignModeration(ctx context.Context, orgID int, assignment ModerationAssignment) (resp ModerationAssignment, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	now := time.Now()
+	err = tx.GetContext(
+		ctx,
+		&resp,
+		assignModerationQuery,
+		orgID,
+		assignment.RecognitionID,
+		assignment.AssignedTo,
+		assignment.AssignedBy,
+		now.Unix(),
+	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrRecordNotFound
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":               err.Error(),
+			"assignment_params": assignment,
+		}).Error("Error while assigning moderation")
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, startReportReviewQuery, assignment.RecognitionID, now, now.Unix())
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": assignment.RecognitionID,
+		}).Error("Error while moving reports in review")
+	}
+	return
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ModerationQueueTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *ModerationQueueTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *ModerationQueueTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *ModerationQueueTestSuite) TestModerationQueueCursorRoundTrip() {
+	cursor := ModerationQueueCursor{FirstReportedAt: 1594944000, RecognitionID: 42}
+
+	decoded, err := DecodeModerationQueueCursor(cursor.Encode())
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), cursor, decoded)
+
+	_, err = DecodeModerationQueueCursor("not a cursor")
+	assert.Equal(suite.T(), ErrInvalidModerationQueueCursor, err)
+}
+
+func (suite *ModerationQueueTestSuite) TestListModerationQueueNextPage() {
+	suite.sqlmock.ExpectQuery("SELECT \\* FROM").
+		WithArgs(1, "in_review", int64(3), "fraud", int64(100), int64(7), 3).
+		WillReturnRows(sqlmock.NewRows([]string{"recognition_id", "text", "given_by", "given_for", "given_at",
+			"report_count", "types", "reasons", "first_reported_at", "last_reported_at", "status", "assigned_to", "assigned_at"}).
+			AddRow(8, "Great demo", 1, 2, 90, 2, "{fraud,incorrect}", "{Not true,Wrong person}", 101, 120, "in_review", 3, 130).
+			AddRow(9, "Thanks!", 2, 1, 95, 1, "{fraud}", "{Copied}", 102, 102, "in_review", 3, 131).
+			AddRow(10, "Well done", 2, 4, 99, 1, "{fraud}", "{Spam}", 103, 103, "in_review", 3, 132))
+
+	page, err := suite.dbStore.ListModerationQueue(context.Background(), ModerationQueueQuery{
+		OrgID:      1,
+		Status:     ReportInReviewStatus,
+		AssignedTo: 3,
+		Type:       "fraud",
+		Cursor:     &ModerationQueueCursor{FirstReportedAt: 100, RecognitionID: 7},
+		Limit:      2,
+	})
+
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), page.Items, 2)
+	assert.Equal(suite.T(), 2, page.Items[0].ReportCount)
+	assert.Equal(suite.T(), []string{"fraud", "incorrect"}, []string(page.Items[0].Types))
+	assert.Equal(suite.T(), []string{"Not true", "Wrong person"}, []string(page.Items[0].Reasons))
+	assert.Equal(suite.T(), int64(3), *page.Items[0].AssignedTo)
+	assert.Equal(suite.T(), ModerationQueueCursor{FirstReportedAt: 102, RecognitionID: 9}.Encode(), page.NextCursor)
+}
+
+func (suite *ModerationQueueTestSuite) TestAssignModerationSuccess() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation_assignments").
+		WithArgs(1, int64(8), int64(3), int64(1), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"recognition_id", "assigned_to", "assigned_by", "assigned_at"}).
+			AddRow(8, 3, 1, 130))
+	suite.sqlmock.ExpectExec("UPDATE reported_recognitions SET status = 'in_review'").
+		WithArgs(int64(8), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlmock.ExpectCommit()
+
+	assignment, err := suite.dbStore.AssignModeration(context.Background(), 1, ModerationAssignment{RecognitionID: 8, AssignedTo: 3, AssignedBy: 1})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), ModerationAssignment{RecognitionID: 8, AssignedTo: 3, AssignedBy: 1, AssignedAt: 130}, assignment)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *ModerationQueueTestSuite) TestAssignModerationWithoutOpenReports() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation_assignments").
+		WithArgs(1, int64(8), int64(3), int64(1), sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.AssignModeration(context.Background(), 1, ModerationAssignment{RecognitionID: 8, AssignedTo: 3, AssignedBy: 1})
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	getModerationSettingsQuery = `SELECT org_id, quarantine_reporter_threshold, quarantine_window_hours,
+		report_rate_limit, report_rate_window_hours FROM moderation_settings WHERE org_id = $1`
+
+	upsertModerationSettingsQuery = `INSERT INTO moderation_settings (org_id, quarantine_reporter_threshold,
+		quarantine_window_hours, report_rate_limit, report_rate_window_hours, updated_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (org_id) DO UPDATE SET (quarantine_reporter_threshold, quarantine_window_hours,
+		report_rate_limit, report_rate_window_hours, updated_at) =
+		(EXCLUDED.quarantine_reporter_threshold, EXCLUDED.quarantine_window_hours,
+		EXCLUDED.report_rate_limit, EXCLUDED.report_rate_window_hours, EXCLUDED.updated_at)
+		RETURNING org_id, quarantine_reporter_threshold, quarantine_window_hours, report_rate_limit, report_rate_window_hours`
+)
+
+// ModerationSettings - per organization configuration of moderation. A quarantine
+// threshold of 0 switches automatic quarantine off, a report rate limit of 0 lifts the limit.
+type ModerationSettings struct {
+	OrgID                       int `db:"org_id" json:"org_id"`
+	QuarantineReporterThreshold int `db:"quarantine_reporter_threshold" json:"quarantine_reporter_threshold"`
+	QuarantineWindowHours       int `db:"quarantine_window_hours" json:"quarantine_window_hours"`
+	ReportRateLimit             int `db:"report_rate_limit" json:"report_rate_limit"`
+	ReportRateWindowHours       int `db:"report_rate_window_hours" json:"report_rate_window_hours"`
+}
+
+// DefaultModerationSettings - settings used by organizations that haven't configured their own
+func DefaultModerationSettings(orgID int) ModerationSettings {
+	return ModerationSettings{
+		OrgID:                       orgID,
+		QuarantineReporterThreshold: 3,
+		QuarantineWindowHours:       24,
+		ReportRateLimit:             10,
+		ReportRateWindowHours:       24,
+	}
+}
+
+// Validate - ensures the limits aren't negative and their windows are usable
+func (settings ModerationSettings) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if settings.QuarantineReporterThreshold < 0 {
+		errFields["quarantine_reporter_threshold"] = "Can't be negative"
+	}
+	if settings.QuarantineReporterThreshold > 0 && settings.QuarantineWindowHours <= 0 {
+		errFields["quarantine_window_hours"] = "Must be greater than 0 when quarantine_reporter_threshold is set"
+	}
+	if settings.ReportRateLimit < 0 {
+		errFields["report_rate_limit"] = "Can't be negative"
+	}
+	if settings.ReportRateLimit > 0 && settings.ReportRateWindowHours <= 0 {
+		errFields["report_rate_window_hours"] = "Must be greater than 0 when report_rate_limit is set"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// GetModerationSettings - returns the organization's moderation settings, or the defaults if none are saved
+func (s *pgStore) GetModerationSettings(ctx context.Context, orgID int) (settings ModerationSettings, err error) {
+	err = s.db.GetContext(ctx, &settings, getModerationSettingsQuery, orgID)
+	if err == sql.ErrNoRows {
+		settings = DefaultModerationSettings(orgID)
+		err = nil
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while getting moderation settings")
+		return
+	}
+
+	return
+}
+
+// UpdateModerationSettings - saves the organization's moderation settings
+func (s *pgStore) UpdateModerationSettings(ctx context.Context, settings ModerationSettings) (updatedSettings ModerationSettings, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&updatedSettings,
+		upsertModerationSettingsQuery,
+		settings.OrgID,
+		settings.QuarantineReporterThreshold,
+		settings.QuarantineWindowHours,
+		settings.ReportRateLimit,
+		settings.ReportRateWindowHours,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"settings_params": settings,
+		}).Error("Error while updating moderation settings")
+		return
+	}
+
+	return
+}
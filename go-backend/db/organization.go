@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/util/log"
+	"strconv"
+	"strings"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
@@ -19,9 +21,11 @@ const (
 		subscription_valid_upto,
 		hi5_limit,
 		hi5_quota_renewal_frequency,
+		hi5_quota_renewal_weekday,
 		timezone,
+		default_locale,
 		created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`
 
 	updateOrganizationQuery = `UPDATE organizations SET (
 		name,
@@ -31,8 +35,10 @@ const (
 		subscription_valid_upto,
 		hi5_limit,
 		hi5_quota_renewal_frequency,
-		timezone) =
-		($1, $2, $3, $4, $5, $6, $7, $8) where id = $9`
+		hi5_quota_renewal_weekday,
+		timezone,
+		default_locale) =
+		($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) where id = $11`
 
 	deleteOrganizationQuery = `DELETE FROM organizations WHERE id = $1`
 
@@ -44,7 +50,10 @@ const (
 		subscription_valid_upto,
 		hi5_limit,
 		hi5_quota_renewal_frequency,
+		hi5_quota_renewal_weekday,
 		timezone,
+		default_locale,
+		hi5_quota_last_reset_at,
 		created_at FROM organizations WHERE id=$1`
 
 	listOrganizationsQuery = `SELECT id,
@@ -55,7 +64,10 @@ const (
 		subscription_valid_upto,
 		hi5_limit,
 		hi5_quota_renewal_frequency,
+		hi5_quota_renewal_weekday,
 		timezone,
+		default_locale,
+		hi5_quota_last_reset_at,
 		created_at FROM organizations ORDER BY name ASC`
 
 	getOrganizationByDomainNameQuery = `SELECT * FROM organizations WHERE domain_name=$1 LIMIT 1`
@@ -72,7 +84,10 @@ type Organization struct {
 	SubscriptionValidUpto    int       `db:"subscription_valid_upto" json:"subscription_valid_upto"`
 	Hi5Limit                 int       `db:"hi5_limit" json:"hi5_limit"`
 	Hi5QuotaRenewalFrequency string    `db:"hi5_quota_renewal_frequency" json:"hi5_quota_renewal_frequency"`
+	Hi5QuotaRenewalWeekday   string    `db:"hi5_quota_renewal_weekday" json:"hi5_quota_renewal_weekday"`
 	Timezone                 string    `db:"timezone" json:"timezone"`
+	DefaultLocale            string    `db:"default_locale" json:"default_locale"`
+	Hi5QuotaLastResetAt      int64     `db:"hi5_quota_last_reset_at" json:"-"`
 	CreatedAt                time.Time `db:"created_at" json:"created_at"`
 }
 
@@ -92,6 +107,36 @@ func (org *Organization) Validate() (errorResponse map[string]ErrorResponse, val
 		fieldErrors["domain_name"] = "Please enter valid domain"
 	}
 
+	if org.Timezone == "" {
+		org.Timezone = defaultTimezone
+	}
+	if _, err := time.LoadLocation(org.Timezone); err != nil {
+		fieldErrors["timezone"] = "Please enter a valid IANA timezone, e.g. Asia/Kolkata"
+	}
+
+	if org.DefaultLocale == "" {
+		org.DefaultLocale = DefaultLocale
+	}
+	if locale, ok := CanonicalLocale(org.DefaultLocale); ok {
+		org.DefaultLocale = locale
+	} else {
+		fieldErrors["default_locale"] = "Please enter a valid locale, e.g. en or de-DE"
+	}
+
+	org.Hi5QuotaRenewalWeekday = strings.ToUpper(strings.TrimSpace(org.Hi5QuotaRenewalWeekday))
+	if org.Hi5QuotaRenewalWeekday == "" {
+		org.Hi5QuotaRenewalWeekday = defaultRenewalWeekday
+	}
+	schedule, err := org.Hi5QuotaSchedule()
+	switch err {
+	case ErrInvalidRenewalWeekday:
+		fieldErrors["hi5_quota_renewal_weekday"] = err.Error()
+	case ErrInvalidCronExpression, ErrCronNeverFires:
+		fieldErrors["hi5_quota_renewal_frequency"] = err.Error()
+	case nil:
+		org.Hi5QuotaRenewalFrequency = schedule.Frequency
+	}
+
 	if len(fieldErrors) == 0 {
 		valid = true
 		return
@@ -134,7 +179,9 @@ func (s *pgStore) CreateOrganization(ctx context.Context, org Organization) (cre
 		org.SubscriptionValidUpto,
 		org.Hi5Limit,
 		org.Hi5QuotaRenewalFrequency,
+		org.Hi5QuotaRenewalWeekday,
 		org.Timezone,
+		org.DefaultLocale,
 		org.CreatedAt,
 	).Scan(&lastInsertID)
 	if err != nil {
@@ -165,7 +212,9 @@ func (s *pgStore) UpdateOrganization(ctx context.Context, reqOrganization Organi
 		reqOrganization.SubscriptionValidUpto,
 		reqOrganization.Hi5Limit,
 		reqOrganization.Hi5QuotaRenewalFrequency,
+		reqOrganization.Hi5QuotaRenewalWeekday,
 		reqOrganization.Timezone,
+		reqOrganization.DefaultLocale,
 		organizationID,
 	)
 	if err != nil {
@@ -175,7 +224,7 @@ func (s *pgStore) UpdateOrganization(ctx context.Context, reqOrganization Organi
 
 	err = s.db.Get(&updatedOrganization, getOrganizationQuery, organizationID)
 	if err != nil {
-		log.Error(ae.ErrRecordNotFound, "Cannot find organization id "+string(organizationID), err)
+		log.Error(ae.ErrRecordNotFound, "Cannot find organization id "+strconv.Itoa(organizationID), err)
 	}
 
 	return
@@ -25,8 +25,10 @@ var expectedOrg = Organization{
 	SubscriptionStatus:       1,
 	SubscriptionValidUpto:    1588073442241,
 	Hi5Limit:                 5,
-	Hi5QuotaRenewalFrequency: "2",
-	Timezone:                 "IST",
+	Hi5QuotaRenewalFrequency: "WEEKLY",
+	Hi5QuotaRenewalWeekday:   "MONDAY",
+	Timezone:                 "Asia/Kolkata",
+	DefaultLocale:            "en",
 }
 
 func (suite *OrganizationTestSuite) SetupTest() {
@@ -42,8 +44,8 @@ func (suite *OrganizationTestSuite) TearDownTest() {
 }
 
 func (suite *OrganizationTestSuite) getMockedRows() (mockedRows *sqlmock.Rows) {
-	mockedRows = suite.sqlmock.NewRows([]string{"id", "name", "contact_email", "domain_name", "subscription_status", "subscription_valid_upto", "hi5_limit", "hi5_quota_renewal_frequency", "timezone"}).
-		AddRow(1, "test organization", "test@gmail.com", "www.testdomain.com", 1, 1588073442241, 5, "2", "IST")
+	mockedRows = suite.sqlmock.NewRows([]string{"id", "name", "contact_email", "domain_name", "subscription_status", "subscription_valid_upto", "hi5_limit", "hi5_quota_renewal_frequency", "hi5_quota_renewal_weekday", "timezone", "default_locale"}).
+		AddRow(1, "test organization", "test@gmail.com", "www.testdomain.com", 1, 1588073442241, 5, "WEEKLY", "MONDAY", "Asia/Kolkata", "en")
 	return
 }
 
@@ -81,7 +83,7 @@ func (suite *OrganizationTestSuite) TestListOrganizationsSuccess() {
 
 func (suite *OrganizationTestSuite) TestUpdateOrganizationSuccess() {
 	suite.sqlmock.ExpectExec("UPDATE organizations").
-		WithArgs("test organization", "test@gmail.com", "www.testdomain.com", 1, 1588073442241, 5, "2", "IST", 1).
+		WithArgs("test organization", "test@gmail.com", "www.testdomain.com", 1, 1588073442241, 5, "WEEKLY", "MONDAY", "Asia/Kolkata", "en", 1).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	suite.sqlmock.ExpectQuery("SELECT").
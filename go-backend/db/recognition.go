@@ -2,26 +2,66 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	logger "github.com/sirupsen/logrus"
 )
 
 const (
+	// RecognitionStatusDraft - recognition saved by the giver but not yet scheduled
+	RecognitionStatusDraft = "draft"
+	// RecognitionStatusScheduled - recognition waiting to be published at publish_at
+	RecognitionStatusScheduled = "scheduled"
+	// RecognitionStatusPublished - recognition visible to the organization
+	RecognitionStatusPublished = "published"
+	// RecognitionStatusHidden - published recognition a moderator judged inappropriate
+	RecognitionStatusHidden = "hidden"
+
 	createRecognitionQuery = `
 		INSERT INTO recognitions (
 			core_value_id,
 			text,
 			given_for,
 			given_by,
-			given_at
+			given_at,
+			status,
+			publish_at
 		)
-		VALUES ($1, $2, $3, $4, $5) returning id
+		VALUES ($1, $2, $3, $4, $5, $6, $7) returning id
 	`
 	showRecognitionQuery = "SELECT * FROM recognitions WHERE id=$1"
-	listRecognitionQuery = "SELECT * FROM recognitions ORDER BY given_at ASC"
+	listRecognitionQuery = "SELECT * FROM recognitions WHERE status = 'published' ORDER BY given_at ASC"
+
+	listRecognitionDraftsQuery = `SELECT * FROM recognitions WHERE given_by = $1
+		AND status IN ('draft', 'scheduled') ORDER BY id DESC`
+
+	updateRecognitionDraftQuery = `UPDATE recognitions SET (
+		core_value_id,
+		text,
+		given_for,
+		given_at,
+		status,
+		publish_at
+		) = ($1, $2, $3, $4, $5, $6)
+		WHERE id = $7 AND given_by = $8 AND status IN ('draft', 'scheduled')`
+
+	deleteRecognitionDraftQuery = `DELETE FROM recognitions WHERE id = $1 AND given_by = $2
+		AND status IN ('draft', 'scheduled')`
+
+	listDueScheduledRecognitionsQuery = `SELECT recognitions.*, giver.org_id FROM recognitions
+		JOIN users giver ON giver.id = recognitions.given_by
+		WHERE recognitions.status = 'scheduled' AND recognitions.publish_at <= $1
+		ORDER BY recognitions.publish_at ASC, recognitions.id ASC`
+
+	publishScheduledRecognitionQuery = `UPDATE recognitions SET status = 'published', given_at = publish_at, text = $2
+		WHERE id = $1 AND status = 'scheduled'`
+
+	unscheduleRecognitionQuery = `UPDATE recognitions SET status = 'draft', publish_at = NULL
+		WHERE id = $1 AND status = 'scheduled'`
 )
 
 // func listRecognitionsFilterByOneFilterQuery(column_name string) string {
@@ -43,6 +83,31 @@ type Recognition struct {
 	GivenFor    int    `db:"given_for" json:"given_for"`
 	GivenBy     int    `db:"given_by" json:"given_by"`
 	GivenAt     int64  `db:"given_at" json:"given_at"`
+	Status      string `db:"status" json:"status"`
+	PublishAt   *int64 `db:"publish_at" json:"publish_at"`
+}
+
+// IsPublished - only published recognitions are visible and can receive Hi5s
+func (recognition Recognition) IsPublished() bool {
+	return recognition.Status == RecognitionStatusPublished
+}
+
+// IsHidden - hidden recognitions are left out of every listing until a moderator restores them
+func (recognition Recognition) IsHidden() bool {
+	return recognition.Status == RecognitionStatusHidden
+}
+
+// prepareForSave - defaults the status and stamps given_at when the recognition goes out right away
+func (recognition *Recognition) prepareForSave() {
+	if recognition.Status == "" {
+		recognition.Status = RecognitionStatusPublished
+	}
+
+	recognition.GivenAt = 0
+	if recognition.IsPublished() {
+		recognition.GivenAt = time.Now().Unix()
+		recognition.PublishAt = nil
+	}
 }
 
 func (recognition Recognition) ValidateRecognition() (valid bool, errFields map[string]string) {
@@ -63,6 +128,19 @@ func (recognition Recognition) ValidateRecognition() (valid bool, errFields map[
 	if recognition.GivenBy == 0 {
 		errFields["given_by"] = "given_by must be present in request"
 	}
+
+	switch recognition.Status {
+	case "", RecognitionStatusDraft, RecognitionStatusPublished:
+	case RecognitionStatusScheduled:
+		if recognition.PublishAt == nil {
+			errFields["publish_at"] = "publish_at must be present for a scheduled recognition"
+		} else if *recognition.PublishAt <= time.Now().Unix() {
+			errFields["publish_at"] = "publish_at must be in the future"
+		}
+	default:
+		errFields["status"] = "status must be one of draft, scheduled or published"
+	}
+
 	if len(errFields) == 0 {
 		valid = true
 	}
@@ -84,19 +162,24 @@ func (s *pgStore) CreateRecognition(ctx context.Context, recognition Recognition
 		}
 		tx.Commit()
 	}()
-	_, err = tx.ExecContext(ctx,
+
+	recognition.prepareForSave()
+	err = tx.QueryRowContext(ctx,
 		createRecognitionQuery,
 		recognition.CoreValueID,
 		recognition.Text,
 		recognition.GivenFor,
 		recognition.GivenBy,
 		recognition.GivenAt,
-	)
+		recognition.Status,
+		recognition.PublishAt,
+	).Scan(&recognition.ID)
 	if err != nil {
 		logger.WithField("err", err.Error()).Error("Error creating Recognition")
 		return
 	}
 
+	createdRecognition = recognition
 	return
 }
 
@@ -129,6 +212,7 @@ func (s *pgStore) ListRecognitionsWithFilter(ctx context.Context, filters map[st
 		coloumnVals = append(coloumnVals, v)
 		filteredCols = append(filteredCols, fmt.Sprintf(`"%s" = %s`, k, "$"+strconv.Itoa(len(filterValues))))
 	}
+	filteredCols = append(filteredCols, "status = 'published'")
 	err = s.db.Select(&recognitions, "SELECT * FROM recognitions WHERE "+strings.Join(filteredCols, " AND "), coloumnVals...)
 	if err != nil {
 		logger.WithField("err:", err.Error()).Error("Error listing recognitions")
@@ -136,3 +220,199 @@ func (s *pgStore) ListRecognitionsWithFilter(ctx context.Context, filters map[st
 	}
 	return
 }
+
+// ListRecognitionDrafts - lists the draft and scheduled recognitions written by the given user
+func (s *pgStore) ListRecognitionDrafts(ctx context.Context, givenBy int) (recognitions []Recognition, err error) {
+	recognitions = make([]Recognition, 0)
+	err = s.db.SelectContext(ctx, &recognitions, listRecognitionDraftsQuery, givenBy)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":      err.Error(),
+			"given_by": givenBy,
+		}).Error("Error listing recognition drafts")
+		return
+	}
+	return
+}
+
+// UpdateRecognitionDraft - edits a draft or scheduled recognition, publishing it if its status says so
+func (s *pgStore) UpdateRecognitionDraft(ctx context.Context, recognition Recognition) (updatedRecognition Recognition, err error) {
+	recognition.prepareForSave()
+	result, err := s.db.ExecContext(
+		ctx,
+		updateRecognitionDraftQuery,
+		recognition.CoreValueID,
+		recognition.Text,
+		recognition.GivenFor,
+		recognition.GivenAt,
+		recognition.Status,
+		recognition.PublishAt,
+		recognition.ID,
+		recognition.GivenBy,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":                err.Error(),
+			"recognition_params": recognition,
+		}).Error("Error updating recognition draft")
+		return
+	}
+
+	err = checkRowsAffected(result)
+	if err != nil {
+		logger.WithField("recognition_id", recognition.ID).Error("Recognition draft not found")
+		return
+	}
+
+	updatedRecognition, err = s.ShowRecognition(ctx, recognition.ID)
+	return
+}
+
+// DeleteRecognitionDraft - cancels a draft or scheduled recognition; published ones are left untouched
+func (s *pgStore) DeleteRecognitionDraft(ctx context.Context, recognitionID, givenBy int) (err error) {
+	result, err := s.db.ExecContext(ctx, deleteRecognitionDraftQuery, recognitionID, givenBy)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error deleting recognition draft")
+		return
+	}
+
+	err = checkRowsAffected(result)
+	if err != nil {
+		logger.WithField("recognition_id", recognitionID).Error("Recognition draft not found")
+		return
+	}
+	return
+}
+
+// dueRecognition - a scheduled recognition whose publish_at has passed, with its giver's organization
+type dueRecognition struct {
+	Recognition
+	OrgID int `db:"org_id"`
+}
+
+// PublishScheduledRecognitionsJob - called by the scheduler to publish recognitions whose publish_at has passed.
+// The organization's rules and content filter may have changed, or other recognitions been given, since the
+// recognition was scheduled, so both are run again as when a recognition is created. One that fails them is
+// moved back to the giver's drafts to be edited, one they flag is published and put in the moderation queue.
+// Badges are evaluated for the giver and recipient as they are when a published recognition is created.
+func (s *pgStore) PublishScheduledRecognitionsJob() (err error) {
+	var due []dueRecognition
+	err = s.db.Select(&due, listDueScheduledRecognitionsQuery, time.Now().Unix())
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error listing scheduled recognitions")
+		return
+	}
+
+	// recognitions are published one at a time, in order, so the rules count the ones published before them
+	ctx := context.Background()
+	var publishedIDs, unscheduledIDs []int
+	for _, recognition := range due {
+		published, publishErr := s.publishScheduledRecognition(ctx, recognition)
+		if publishErr != nil {
+			// left as it is; if it is still scheduled the next run tries again
+			continue
+		}
+		if !published {
+			unscheduledIDs = append(unscheduledIDs, recognition.ID)
+			continue
+		}
+		publishedIDs = append(publishedIDs, recognition.ID)
+
+		// awarding is best effort like at request time, the nightly job catches anything missed here
+		for _, userID := range []int{recognition.GivenFor, recognition.GivenBy} {
+			_, awardErr := s.AwardBadges(ctx, recognition.OrgID, userID)
+			if awardErr != nil {
+				logger.WithFields(logger.Fields{
+					"err":     awardErr.Error(),
+					"user_id": userID,
+				}).Error("Error while awarding badges")
+			}
+		}
+	}
+
+	if len(publishedIDs) > 0 {
+		logger.WithField("recognition_ids", publishedIDs).Info("Published scheduled recognitions")
+	}
+	if len(unscheduledIDs) > 0 {
+		logger.WithField("recognition_ids", unscheduledIDs).Info("Moved scheduled recognitions breaking organization rules back to drafts")
+	}
+	return
+}
+
+// publishScheduledRecognition - publishes a due recognition that still passes the organization's rules and
+// content filter, otherwise moves it back to drafts. A recognition the giver edited or cancelled in the
+// meantime is left alone and ErrRecordNotFound returned.
+func (s *pgStore) publishScheduledRecognition(ctx context.Context, recognition dueRecognition) (published bool, err error) {
+	ruleSettings, err := s.GetRecognitionRuleSettings(ctx, recognition.OrgID)
+	if err != nil {
+		return
+	}
+
+	valid, errFields, err := EvaluateRecognitionRules(ctx, s, ruleSettings, recognition.Recognition)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognition.ID,
+		}).Error("Error while evaluating recognition rules")
+		return
+	}
+
+	filterSettings, err := s.GetContentFilterSettings(ctx, recognition.OrgID)
+	if err != nil {
+		return
+	}
+	filtered := FilterContent(filterSettings, recognition.Text)
+
+	if !valid || filtered.Rejected != "" {
+		logger.WithFields(logger.Fields{
+			"recognition_id": recognition.ID,
+			"err_fields":     errFields,
+			"rejected":       filtered.Rejected,
+		}).Info("Scheduled recognition breaks organization rules")
+
+		var result sql.Result
+		result, err = s.db.ExecContext(ctx, unscheduleRecognitionQuery, recognition.ID)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":            err.Error(),
+				"recognition_id": recognition.ID,
+			}).Error("Error moving scheduled recognition back to drafts")
+			return
+		}
+
+		err = checkRowsAffected(result)
+		if err != nil {
+			logger.WithField("recognition_id", recognition.ID).Error("Scheduled recognition no longer scheduled")
+		}
+		return
+	}
+
+	result, err := s.db.ExecContext(ctx, publishScheduledRecognitionQuery, recognition.ID, filtered.Text)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognition.ID,
+		}).Error("Error publishing scheduled recognition")
+		return
+	}
+
+	err = checkRowsAffected(result)
+	if err != nil {
+		logger.WithField("recognition_id", recognition.ID).Error("Scheduled recognition no longer scheduled")
+		return
+	}
+	published = true
+
+	if len(filtered.Flags) > 0 {
+		findings := make([]string, 0, len(filtered.Flags))
+		for _, flag := range filtered.Flags {
+			findings = append(findings, fmt.Sprintf("text contains %s", flag))
+		}
+		// flagging is best effort like at request time, the recognition is already published
+		_ = s.FlagRecognitionContent(ctx, int64(recognition.ID), findings)
+	}
+	return
+}
@@ -0,0 +1,445 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/jmoiron/sqlx"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// AppealOpenStatus - the appeal waits for its moderator's decision
+	AppealOpenStatus = "open"
+	// AppealUpheldStatus - the moderator agreed with the appealed decision, the recognition stays hidden
+	AppealUpheldStatus = "upheld"
+	// AppealOverturnedStatus - the moderator reversed the appealed decision and restored the recognition
+	AppealOverturnedStatus = "overturned"
+
+	// AppealNotification - tells a moderator an appeal was assigned to them
+	AppealNotification = "appeal"
+	// AppealDecidedNotification - tells the user who appealed that their appeal was decided
+	AppealDecidedNotification = "appeal_decided"
+
+	// MaxRecognitionAppeals - most appeals returned in one listing
+	MaxRecognitionAppeals = 100
+
+	// MaxAppealStatementLength - longest statement a user can appeal with
+	MaxAppealStatementLength = 2000
+
+	// the appeal comes with the moderator who took the appealed decision
+	recognitionAppealColumns = `a.id, a.recognition_id, a.moderation_id, m.moderated_by, a.appealed_by, a.statement,
+		a.status, a.assigned_to, a.assigned_at, a.decided_by, a.decided_at, a.decision_comment,
+		a.overturn_moderation_id, a.appealed_at`
+
+	recognitionAppealFrom = ` FROM appeal a JOIN recognition_moderation m ON m.id = a.moderation_id`
+
+	recognitionAppealOfOrganizationFrom = ` FROM recognition_appeals a
+		JOIN recognition_moderation m ON m.id = a.moderation_id
+		JOIN recognitions r ON r.id = a.recognition_id
+		JOIN users giver ON giver.id = r.given_by`
+
+	// the latest decision is locked so it can't be superseded while it is being appealed
+	latestRecognitionModerationQuery = `SELECT id, recognition_id, is_inappropriate, moderated_by FROM recognition_moderation
+		WHERE recognition_id = $1 ORDER BY id DESC LIMIT 1 FOR UPDATE`
+
+	createRecognitionAppealQuery = `WITH appeal AS (
+		INSERT INTO recognition_appeals (recognition_id, moderation_id, appealed_by, statement, appealed_at)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (moderation_id) DO NOTHING RETURNING *
+	) SELECT ` + recognitionAppealColumns + recognitionAppealFrom
+
+	// appeals go to the active admin with the fewest open appeals who neither took the decision
+	// nor gave or received the recognition
+	pickAppealModeratorQuery = `SELECT u.id FROM users u JOIN roles ON roles.id = u.role_id
+		LEFT JOIN recognition_appeals pending ON pending.assigned_to = u.id AND pending.status = 'open'
+		WHERE u.org_id = $1 AND roles.name = 'Admin' AND u.soft_delete = FALSE AND u.id <> $2
+		AND NOT EXISTS (SELECT 1 FROM recognitions r WHERE r.id = $3 AND u.id IN (r.given_by, r.given_for))
+		GROUP BY u.id ORDER BY COUNT(pending.id), u.id LIMIT 1`
+
+	assignRecognitionAppealQuery = `WITH appeal AS (
+		UPDATE recognition_appeals SET assigned_to = $2, assigned_at = $3 WHERE id = $1 AND status = 'open' RETURNING *
+	) SELECT ` + recognitionAppealColumns + recognitionAppealFrom
+
+	// only the moderator the appeal is assigned to can decide it
+	decideRecognitionAppealQuery = `WITH appeal AS (
+		UPDATE recognition_appeals SET status = $2, decided_by = $3, decided_at = $4, decision_comment = $5
+		WHERE id = $1 AND status = 'open' AND assigned_to = $3 RETURNING *
+	) SELECT ` + recognitionAppealColumns + recognitionAppealFrom
+
+	setOverturnModerationQuery = `UPDATE recognition_appeals SET overturn_moderation_id = $2 WHERE id = $1`
+
+	notifyUserQuery = `INSERT INTO moderation_notifications (user_id, recognition_id, kind, created_at) VALUES ($1, $2, $3, $4)`
+
+	getRecognitionAppealQuery = `SELECT ` + recognitionAppealColumns + recognitionAppealOfOrganizationFrom + `
+		WHERE a.id = $1 AND giver.org_id = $2`
+
+	// $2 - status, '' for any; $3 - moderator, 0 for any
+	listRecognitionAppealsQuery = `SELECT ` + recognitionAppealColumns + recognitionAppealOfOrganizationFrom + `
+		WHERE giver.org_id = $1 AND ($2 = '' OR a.status = $2) AND ($3 = 0 OR a.assigned_to = $3)
+		ORDER BY a.appealed_at, a.id LIMIT $4`
+
+	listRecognitionModerationsQuery = `SELECT id, recognition_id, is_inappropriate, COALESCE(moderator_comment, '') AS moderator_comment,
+		moderated_by, moderated_at, refund_hi5s, created_at, updated_at
+		FROM recognition_moderation WHERE recognition_id = $1 ORDER BY id`
+
+	listAppealsOfRecognitionQuery = `SELECT ` + recognitionAppealColumns + ` FROM recognition_appeals a
+		JOIN recognition_moderation m ON m.id = a.moderation_id
+		WHERE a.recognition_id = $1 ORDER BY a.id`
+)
+
+// APPEAL_STATUSES - in the order an appeal moves through them
+var APPEAL_STATUSES = []string{AppealOpenStatus, AppealUpheldStatus, AppealOverturnedStatus}
+
+// APPEAL_DECISIONS - what a moderator can decide on an appeal
+var APPEAL_DECISIONS = []string{AppealUpheldStatus, AppealOverturnedStatus}
+
+// RecognitionAppeal - the giver or recipient contesting the moderation decision that hid their recognition
+type RecognitionAppeal struct {
+	ID            int64 `db:"id" json:"id"`
+	RecognitionID int64 `db:"recognition_id" json:"recognition_id"`
+	// ModerationID - the appealed decision, taken by ModeratedBy
+	ModerationID    int64  `db:"moderation_id" json:"moderation_id"`
+	ModeratedBy     int64  `db:"moderated_by" json:"moderated_by"`
+	AppealedBy      int64  `db:"appealed_by" json:"appealed_by"`
+	Statement       string `db:"statement" json:"statement"`
+	Status          string `db:"status" json:"status"`
+	AssignedTo      *int64 `db:"assigned_to" json:"assigned_to"`
+	AssignedAt      *int64 `db:"assigned_at" json:"assigned_at"`
+	DecidedBy       *int64 `db:"decided_by" json:"decided_by"`
+	DecidedAt       *int64 `db:"decided_at" json:"decided_at"`
+	DecisionComment string `db:"decision_comment" json:"decision_comment"`
+	// OverturnModerationID - the decision that restored the recognition when the appeal was overturned
+	OverturnModerationID *int64 `db:"overturn_moderation_id" json:"overturn_moderation_id"`
+	AppealedAt           int64  `db:"appealed_at" json:"appealed_at"`
+}
+
+// AppealDecision - what the assigned moderator decided on an appeal
+type AppealDecision struct {
+	AppealID  int64  `json:"-"`
+	Decision  string `json:"decision"`
+	Comment   string `json:"comment"`
+	DecidedBy int64  `json:"decided_by"`
+}
+
+// ModerationHistory - every decision taken on a recognition and every appeal against them, oldest first
+type ModerationHistory struct {
+	Moderations []RecognitionModeration `json:"moderations"`
+	Appeals     []RecognitionAppeal     `json:"appeals"`
+}
+
+func (appeal RecognitionAppeal) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if appeal.Statement == "" {
+		errFields["statement"] = "Can't be blank"
+	} else if len([]rune(appeal.Statement)) > MaxAppealStatementLength {
+		errFields["statement"] = "Can't be longer than 2000 characters"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+func (decision AppealDecision) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if !include(APPEAL_DECISIONS, decision.Decision) {
+		errFields["decision"] = "Must be one of upheld or overturned"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// CreateRecognitionAppeal - appeals the latest moderation decision on the recognition and assigns the appeal
+// to a moderator other than the one who took the decision, notifying them. The appeal stays unassigned when
+// the organization has no such moderator. Fails with ErrNothingToAppeal unless the latest decision hid the
+// recognition, and with ErrAlreadyAppealed when that decision already has an appeal.
+func (s *pgStore) CreateRecognitionAppeal(ctx context.Context, orgID int, appeal RecognitionAppeal) (resp RecognitionAppeal, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	var moderation RecognitionModeration
+	err = tx.GetContext(ctx, &moderation, latestRecognitionModerationQuery, appeal.RecognitionID)
+	if err == sql.ErrNoRows {
+		err = ae.ErrNothingToAppeal
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": appeal.RecognitionID,
+		}).Error("Error while fetching latest recognition moderation")
+		return
+	}
+
+	if moderation.IsInappropriate == nil || !*moderation.IsInappropriate {
+		err = ae.ErrNothingToAppeal
+		return
+	}
+
+	now := time.Now().Unix()
+	err = tx.GetContext(
+		ctx,
+		&resp,
+		createRecognitionAppealQuery,
+		appeal.RecognitionID,
+		moderation.ID,
+		appeal.AppealedBy,
+		appeal.Statement,
+		now,
+	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrAlreadyAppealed
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"appeal_params": appeal,
+		}).Error("Error while creating recognition appeal")
+		return
+	}
+
+	var moderatorID int64
+	err = tx.GetContext(ctx, &moderatorID, pickAppealModeratorQuery, orgID, moderation.ModeratedBy, appeal.RecognitionID)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"appeal_id": resp.ID,
+		}).Error("Error while picking moderator of appeal")
+		return
+	}
+
+	resp, err = assignRecognitionAppeal(ctx, tx, resp.ID, moderatorID)
+	return
+}
+
+// GetRecognitionAppeal - an appeal against a decision on a recognition of the organization.
+// Fails with ErrRecordNotFound if the organization has no such appeal.
+func (s *pgStore) GetRecognitionAppeal(ctx context.Context, orgID int, appealID int64) (appeal RecognitionAppeal, err error) {
+	err = s.db.GetContext(ctx, &appeal, getRecognitionAppealQuery, appealID, orgID)
+	if err == sql.ErrNoRows {
+		err = ae.ErrRecordNotFound
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"appeal_id": appealID,
+		}).Error("Error while fetching recognition appeal")
+		return
+	}
+
+	return
+}
+
+// ListRecognitionAppeals - the organization's appeals, oldest first. An empty status lists
+// appeals in any status, a zero assignedTo doesn't filter on the moderator.
+func (s *pgStore) ListRecognitionAppeals(ctx context.Context, orgID int, status string, assignedTo int64) (appeals []RecognitionAppeal, err error) {
+	appeals = make([]RecognitionAppeal, 0)
+	err = s.db.SelectContext(ctx, &appeals, listRecognitionAppealsQuery, orgID, status, assignedTo, MaxRecognitionAppeals)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing recognition appeals")
+		return
+	}
+
+	return
+}
+
+// AssignRecognitionAppeal - hands an open appeal to another moderator and notifies them.
+// Fails with ErrAppealAlreadyDecided if the appeal isn't open anymore.
+func (s *pgStore) AssignRecognitionAppeal(ctx context.Context, appealID int64, assignedTo int64) (appeal RecognitionAppeal, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	appeal, err = assignRecognitionAppeal(ctx, tx, appealID, assignedTo)
+	return
+}
+
+func assignRecognitionAppeal(ctx context.Context, tx *sqlx.Tx, appealID int64, assignedTo int64) (appeal RecognitionAppeal, err error) {
+	now := time.Now().Unix()
+	err = tx.GetContext(ctx, &appeal, assignRecognitionAppealQuery, appealID, assignedTo, now)
+	if err == sql.ErrNoRows {
+		err = ae.ErrAppealAlreadyDecided
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"appeal_id": appealID,
+		}).Error("Error while assigning recognition appeal")
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, notifyUserQuery, assignedTo, appeal.RecognitionID, AppealNotification, now)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"appeal_id": appealID,
+		}).Error("Error while notifying moderator of appeal")
+	}
+	return
+}
+
+// DecideRecognitionAppeal - records the assigned moderator's decision and notifies the user who appealed.
+// Overturning records a new decision restoring the recognition along with the Hi5s the appealed decision
+// took back, leaving the appealed decision in place as history. Fails with ErrAppealAlreadyDecided if the
+// appeal isn't open anymore or isn't assigned to the deciding moderator.
+func (s *pgStore) DecideRecognitionAppeal(ctx context.Context, decision AppealDecision) (appeal RecognitionAppeal, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	now := time.Now()
+	err = tx.GetContext(
+		ctx,
+		&appeal,
+		decideRecognitionAppealQuery,
+		decision.AppealID,
+		decision.Decision,
+		decision.DecidedBy,
+		now.Unix(),
+		decision.Comment,
+	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrAppealAlreadyDecided
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"decision_params": decision,
+		}).Error("Error while deciding recognition appeal")
+		return
+	}
+
+	if decision.Decision == AppealOverturnedStatus {
+		isInappropriate := false
+		var moderation RecognitionModeration
+		err = tx.GetContext(
+			ctx,
+			&moderation,
+			createRecognitionModerationQuery,
+			appeal.RecognitionID,
+			&isInappropriate,
+			decision.Comment,
+			decision.DecidedBy,
+			now.Unix(),
+			false,
+			now,
+			now,
+		)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":       err.Error(),
+				"appeal_id": appeal.ID,
+			}).Error("Error while recording overturning moderation")
+			return
+		}
+
+		_, err = tx.ExecContext(ctx, setOverturnModerationQuery, appeal.ID, moderation.ID)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":       err.Error(),
+				"appeal_id": appeal.ID,
+			}).Error("Error while linking overturning moderation to appeal")
+			return
+		}
+		appeal.OverturnModerationID = &moderation.ID
+
+		_, err = tx.ExecContext(ctx, restoreRecognitionQuery, appeal.RecognitionID)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":            err.Error(),
+				"recognition_id": appeal.RecognitionID,
+			}).Error("Error while restoring recognition")
+			return
+		}
+
+		err = restoreRecognitionHi5s(ctx, tx, appeal.ModerationID)
+		if err != nil {
+			return
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, notifyUserQuery, appeal.AppealedBy, appeal.RecognitionID, AppealDecidedNotification, now.Unix())
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"appeal_id": appeal.ID,
+		}).Error("Error while notifying user of appeal decision")
+	}
+	return
+}
+
+// ListRecognitionModerationHistory - every decision taken on the recognition and every appeal against them
+func (s *pgStore) ListRecognitionModerationHistory(ctx context.Context, recognitionID int64) (history ModerationHistory, err error) {
+	history.Moderations = make([]RecognitionModeration, 0)
+	err = s.db.SelectContext(ctx, &history.Moderations, listRecognitionModerationsQuery, recognitionID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while listing recognition moderations")
+		return
+	}
+
+	history.Appeals = make([]RecognitionAppeal, 0)
+	err = s.db.SelectContext(ctx, &history.Appeals, listAppealsOfRecognitionQuery, recognitionID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while listing recognition appeals")
+		return
+	}
+
+	return
+}
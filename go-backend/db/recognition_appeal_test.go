@@ -0,0 +1,264 @@
+package db
+
+import (
+	"context"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionAppealTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *RecognitionAppealTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *RecognitionAppealTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+var recognitionAppealTestColumns = []string{"id", "recognition_id", "moderation_id", "moderated_by", "appealed_by", "statement", "status", "assigned_to", "assigned_at"}
+
+func (suite *RecognitionAppealTestSuite) TestRecognitionAppealValidate() {
+	ok, errFields := RecognitionAppeal{}.Validate()
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), map[string]string{"statement": "Can't be blank"}, errFields)
+
+	ok, errFields = AppealDecision{Decision: "rejected"}.Validate()
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), map[string]string{"decision": "Must be one of upheld or overturned"}, errFields)
+}
+
+func (suite *RecognitionAppealTestSuite) TestCreateRecognitionAppealAssignsAnotherModerator() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_moderation").
+		WithArgs(int64(8)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(3, 8, true, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_appeals").
+		WithArgs(int64(8), int64(3), int64(4), "It was a real demo", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealOpenStatus, nil, nil))
+	// the moderator who took the decision is left out
+	suite.sqlmock.ExpectQuery("SELECT u.id FROM users u").
+		WithArgs(1, int64(1), int64(8)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}).AddRow(5))
+	suite.sqlmock.ExpectQuery("UPDATE recognition_appeals SET assigned_to").
+		WithArgs(int64(1), int64(5), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealOpenStatus, 5, 100))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WithArgs(int64(5), int64(8), AppealNotification, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectCommit()
+
+	appeal, err := suite.dbStore.CreateRecognitionAppeal(context.Background(), 1, RecognitionAppeal{RecognitionID: 8, AppealedBy: 4, Statement: "It was a real demo"})
+
+	assignedTo := int64(5)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &assignedTo, appeal.AssignedTo)
+	assert.Equal(suite.T(), int64(1), appeal.ModeratedBy)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestCreateRecognitionAppealWithoutOtherModerator() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_moderation").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(3, 8, true, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_appeals").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealOpenStatus, nil, nil))
+	suite.sqlmock.ExpectQuery("SELECT u.id FROM users u").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}))
+	suite.sqlmock.ExpectCommit()
+
+	appeal, err := suite.dbStore.CreateRecognitionAppeal(context.Background(), 1, RecognitionAppeal{RecognitionID: 8, AppealedBy: 4, Statement: "It was a real demo"})
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), appeal.AssignedTo)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestCreateRecognitionAppealWhenRecognitionWasRestored() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_moderation").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(3, 8, false, 1))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.CreateRecognitionAppeal(context.Background(), 1, RecognitionAppeal{RecognitionID: 8, AppealedBy: 4, Statement: "It was a real demo"})
+
+	assert.Equal(suite.T(), ae.ErrNothingToAppeal, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestCreateRecognitionAppealWhenAlreadyAppealed() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_moderation").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(3, 8, true, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_appeals").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.CreateRecognitionAppeal(context.Background(), 1, RecognitionAppeal{RecognitionID: 8, AppealedBy: 3, Statement: "Please check again"})
+
+	assert.Equal(suite.T(), ae.ErrAlreadyAppealed, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestDecideRecognitionAppealOverturned() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("UPDATE recognition_appeals SET status").
+		WithArgs(int64(1), AppealOverturnedStatus, int64(5), sqlmock.AnyArg(), "Demo happened").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealOverturnedStatus, 5, 100))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WithArgs(int64(8), false, "Demo happened", int64(5), sqlmock.AnyArg(), false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(6, 8, false, 5))
+	suite.sqlmock.ExpectExec("UPDATE recognition_appeals SET overturn_moderation_id").
+		WithArgs(int64(1), int64(6)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'published'").
+		WithArgs(int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the Hi5s taken by the appealed decision come back, charging the giver who was refunded again
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM removed_recognition_hi5s WHERE moderation_id = (.+) FOR UPDATE").
+		WithArgs(int64(3)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "comment", "given_by", "given_at", "refunded"}).
+			AddRow(1, 8, "Nice", 6, 900, false).
+			AddRow(2, 8, nil, 7, 1100, true))
+	suite.sqlmock.ExpectExec("UPDATE removed_recognition_hi5s SET restored_at").
+		WithArgs(int64(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(2))
+	suite.sqlmock.ExpectExec("UPDATE removed_recognition_hi5s SET restored_at").
+		WithArgs(int64(2), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(2))
+	suite.sqlmock.ExpectExec("UPDATE users SET hi5_quota_balance").
+		WithArgs(1, 7).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_quota_ledger").
+		WithArgs(7, Hi5LedgerSpend, -1, 1, 8, "Hi5 restored by moderation", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "entry_type", "amount", "balance_after"}).
+			AddRow(2, 7, Hi5LedgerSpend, -1, 1))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WithArgs(int64(4), int64(8), AppealDecidedNotification, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectCommit()
+
+	appeal, err := suite.dbStore.DecideRecognitionAppeal(context.Background(), AppealDecision{AppealID: 1, Decision: AppealOverturnedStatus, Comment: "Demo happened", DecidedBy: 5})
+
+	overturnModerationID := int64(6)
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &overturnModerationID, appeal.OverturnModerationID)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestDecideRecognitionAppealOverturnedWhenGiverHasNoQuota() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("UPDATE recognition_appeals SET status").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealOverturnedStatus, 5, 100))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(6, 8, false, 5))
+	suite.sqlmock.ExpectExec("UPDATE recognition_appeals SET overturn_moderation_id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'published'").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM removed_recognition_hi5s").
+		WithArgs(int64(3)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "comment", "given_by", "given_at", "refunded"}).
+			AddRow(2, 8, nil, 7, 1100, true))
+	// the giver spent the refunded quota, so the Hi5 stays removed
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(0))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectCommit()
+
+	_, err := suite.dbStore.DecideRecognitionAppeal(context.Background(), AppealDecision{AppealID: 1, Decision: AppealOverturnedStatus, Comment: "Demo happened", DecidedBy: 5})
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestDecideRecognitionAppealOverturnedWhenGiverHi5dAgain() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("UPDATE recognition_appeals SET status").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealOverturnedStatus, 5, 100))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderated_by"}).AddRow(6, 8, false, 5))
+	suite.sqlmock.ExpectExec("UPDATE recognition_appeals SET overturn_moderation_id").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'published'").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM removed_recognition_hi5s").
+		WithArgs(int64(3)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "comment", "given_by", "given_at", "refunded"}).
+			AddRow(2, 8, nil, 7, 1100, true))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(2))
+	// the giver has Hi5'd the recognition again since, so nothing is restored and they aren't charged
+	suite.sqlmock.ExpectExec("UPDATE removed_recognition_hi5s SET restored_at").
+		WithArgs(int64(2), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectCommit()
+
+	_, err := suite.dbStore.DecideRecognitionAppeal(context.Background(), AppealDecision{AppealID: 1, Decision: AppealOverturnedStatus, Comment: "Demo happened", DecidedBy: 5})
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestDecideRecognitionAppealUpheld() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("UPDATE recognition_appeals SET status").
+		WithArgs(int64(1), AppealUpheldStatus, int64(5), sqlmock.AnyArg(), "").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns).AddRow(1, 8, 3, 1, 4, "It was a real demo", AppealUpheldStatus, 5, 100))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WithArgs(int64(4), int64(8), AppealDecidedNotification, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectCommit()
+
+	appeal, err := suite.dbStore.DecideRecognitionAppeal(context.Background(), AppealDecision{AppealID: 1, Decision: AppealUpheldStatus, DecidedBy: 5})
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), appeal.OverturnModerationID)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestDecideRecognitionAppealAlreadyDecided() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("UPDATE recognition_appeals SET status").
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.DecideRecognitionAppeal(context.Background(), AppealDecision{AppealID: 1, Decision: AppealUpheldStatus, DecidedBy: 5})
+
+	assert.Equal(suite.T(), ae.ErrAppealAlreadyDecided, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAppealTestSuite) TestGetRecognitionAppealOfAnotherOrganization() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_appeals").
+		WithArgs(int64(1), 2).
+		WillReturnRows(suite.sqlmock.NewRows(recognitionAppealTestColumns))
+
+	_, err := suite.dbStore.GetRecognitionAppeal(context.Background(), 2, 1)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+}
@@ -0,0 +1,205 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// AttachmentStatusPending - an upload slot was handed out but the upload hasn't been confirmed
+	AttachmentStatusPending = "pending"
+	// AttachmentStatusUploaded - the upload was verified and the attachment is linked to its recognition
+	AttachmentStatusUploaded = "uploaded"
+
+	// MaxAttachmentSizeBytes - largest file accepted as a recognition attachment (5 MB)
+	MaxAttachmentSizeBytes = 5 * 1024 * 1024
+
+	createRecognitionAttachmentQuery = `INSERT INTO recognition_attachments (recognition_id, uploaded_by,
+		object_key, file_name, content_type, size_bytes, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, recognition_id, uploaded_by, object_key, file_name, content_type, size_bytes, status, created_at, updated_at`
+
+	getRecognitionAttachmentQuery = `SELECT id, recognition_id, uploaded_by, object_key, file_name, content_type,
+		size_bytes, status, created_at, updated_at FROM recognition_attachments WHERE recognition_id = $1 AND id = $2`
+
+	confirmRecognitionAttachmentQuery = `UPDATE recognition_attachments SET (size_bytes, status, updated_at) =
+		($1, $2, $3) WHERE id = $4 AND status = $5
+		RETURNING id, recognition_id, uploaded_by, object_key, file_name, content_type, size_bytes, status, created_at, updated_at`
+
+	deleteRecognitionAttachmentQuery = `DELETE FROM recognition_attachments WHERE recognition_id = $1 AND id = $2`
+
+	listRecognitionAttachmentsQuery = `SELECT id, recognition_id, uploaded_by, object_key, file_name, content_type,
+		size_bytes, status, created_at, updated_at FROM recognition_attachments
+		WHERE recognition_id = ANY($1) AND status = $2 ORDER BY id ASC`
+)
+
+// attachmentContentTypes - content types accepted for attachments, mapped to the extension used in the object key
+var attachmentContentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"application/pdf": ".pdf",
+}
+
+// RecognitionAttachment - struct representing a file uploaded to object storage and linked to a recognition
+type RecognitionAttachment struct {
+	ID            int64     `db:"id" json:"id"`
+	RecognitionID int64     `db:"recognition_id" json:"recognition_id"`
+	UploadedBy    int64     `db:"uploaded_by" json:"uploaded_by"`
+	ObjectKey     string    `db:"object_key" json:"-"`
+	FileName      string    `db:"file_name" json:"file_name"`
+	ContentType   string    `db:"content_type" json:"content_type"`
+	SizeBytes     int64     `db:"size_bytes" json:"size_bytes"`
+	Status        string    `db:"status" json:"status"`
+	URL           string    `db:"-" json:"url,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"-"`
+	UpdatedAt     time.Time `db:"updated_at" json:"-"`
+}
+
+// Validate - checks the upload slot request before any object key is handed out
+func (attachment *RecognitionAttachment) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if attachment.FileName == "" {
+		errFields["file_name"] = "Can't be blank"
+	}
+
+	if _, ok := attachmentContentTypes[attachment.ContentType]; !ok {
+		errFields["content_type"] = "Unsupported content type"
+	}
+
+	if attachment.SizeBytes <= 0 || attachment.SizeBytes > MaxAttachmentSizeBytes {
+		errFields["size_bytes"] = fmt.Sprintf("Must be between 1 and %d bytes", MaxAttachmentSizeBytes)
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// VerifyUpload - compares the object that actually landed in storage with what the client asked to upload
+func (attachment *RecognitionAttachment) VerifyUpload(size int64, contentType string) (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if size != attachment.SizeBytes || size > MaxAttachmentSizeBytes {
+		errFields["size_bytes"] = "Uploaded file size doesn't match the requested size"
+	}
+
+	if contentType != attachment.ContentType {
+		errFields["content_type"] = "Uploaded file type doesn't match the requested type"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+func newAttachmentObjectKey(recognitionID int64, contentType string) string {
+	return fmt.Sprintf("recognitions/%d/%s%s", recognitionID, uuid.New().String(), attachmentContentTypes[contentType])
+}
+
+func (s *pgStore) CreateRecognitionAttachment(ctx context.Context, attachment RecognitionAttachment) (resp RecognitionAttachment, err error) {
+	now := time.Now()
+	err = s.db.GetContext(
+		ctx,
+		&resp,
+		createRecognitionAttachmentQuery,
+		attachment.RecognitionID,
+		attachment.UploadedBy,
+		newAttachmentObjectKey(attachment.RecognitionID, attachment.ContentType),
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.SizeBytes,
+		AttachmentStatusPending,
+		now,
+		now,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":               err.Error(),
+			"attachment_params": attachment,
+		}).Error("Error while creating recognition attachment")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) GetRecognitionAttachment(ctx context.Context, recognitionID, attachmentID int64) (attachment RecognitionAttachment, err error) {
+	err = s.db.GetContext(ctx, &attachment, getRecognitionAttachmentQuery, recognitionID, attachmentID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+			"attachment_id":  attachmentID,
+		}).Error("Error while getting recognition attachment")
+		return
+	}
+
+	return
+}
+
+// ConfirmRecognitionAttachment - links a pending attachment to its recognition once the upload is verified
+func (s *pgStore) ConfirmRecognitionAttachment(ctx context.Context, attachmentID, sizeBytes int64) (attachment RecognitionAttachment, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&attachment,
+		confirmRecognitionAttachmentQuery,
+		sizeBytes,
+		AttachmentStatusUploaded,
+		time.Now(),
+		attachmentID,
+		AttachmentStatusPending,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"attachment_id": attachmentID,
+		}).Error("Error while confirming recognition attachment")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) DeleteRecognitionAttachment(ctx context.Context, recognitionID, attachmentID int64) (err error) {
+	_, err = s.db.ExecContext(ctx, deleteRecognitionAttachmentQuery, recognitionID, attachmentID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+			"attachment_id":  attachmentID,
+		}).Error("Error while deleting recognition attachment")
+		return
+	}
+
+	return
+}
+
+// ListRecognitionAttachments - lists the confirmed attachments of all the given recognitions in one query
+func (s *pgStore) ListRecognitionAttachments(ctx context.Context, recognitionIDs []int64) (attachments []RecognitionAttachment, err error) {
+	attachments = make([]RecognitionAttachment, 0)
+	err = s.db.SelectContext(
+		ctx,
+		&attachments,
+		listRecognitionAttachmentsQuery,
+		pq.Array(recognitionIDs),
+		AttachmentStatusUploaded,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"recognition_ids": recognitionIDs,
+		}).Error("Error while listing recognition attachments")
+		return
+	}
+
+	return
+}
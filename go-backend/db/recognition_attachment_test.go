@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionAttachmentTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *RecognitionAttachmentTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+	now = time.Now()
+}
+
+func (suite *RecognitionAttachmentTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *RecognitionAttachmentTestSuite) getMockedRows(status string) (mockedRows *sqlmock.Rows) {
+	mockedRows = suite.sqlmock.NewRows([]string{"id", "recognition_id", "uploaded_by", "object_key", "file_name", "content_type", "size_bytes", "status", "created_at", "updated_at"}).
+		AddRow(1, 1, 2, "recognitions/1/abc.png", "team.png", "image/png", 1024, status, now, now)
+	return
+}
+
+func (suite *RecognitionAttachmentTestSuite) TestCreateRecognitionAttachmentSuccess() {
+	attachment := RecognitionAttachment{
+		RecognitionID: 1,
+		UploadedBy:    2,
+		FileName:      "team.png",
+		ContentType:   "image/png",
+		SizeBytes:     1024,
+	}
+
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_attachments").
+		WithArgs(1, 2, sqlmock.AnyArg(), "team.png", "image/png", 1024, AttachmentStatusPending, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.getMockedRows(AttachmentStatusPending))
+
+	resp, err := suite.dbStore.CreateRecognitionAttachment(context.Background(), attachment)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), resp.ID)
+	assert.Equal(suite.T(), AttachmentStatusPending, resp.Status)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAttachmentTestSuite) TestConfirmRecognitionAttachmentSuccess() {
+	suite.sqlmock.ExpectQuery("UPDATE recognition_attachments").
+		WithArgs(1024, AttachmentStatusUploaded, sqlmock.AnyArg(), 1, AttachmentStatusPending).
+		WillReturnRows(suite.getMockedRows(AttachmentStatusUploaded))
+
+	resp, err := suite.dbStore.ConfirmRecognitionAttachment(context.Background(), 1, 1024)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), AttachmentStatusUploaded, resp.Status)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionAttachmentTestSuite) TestListRecognitionAttachmentsSuccess() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_attachments").
+		WithArgs(sqlmock.AnyArg(), AttachmentStatusUploaded).
+		WillReturnRows(suite.getMockedRows(AttachmentStatusUploaded))
+
+	resp, err := suite.dbStore.ListRecognitionAttachments(context.Background(), []int64{1})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(resp))
+	assert.Equal(suite.T(), "recognitions/1/abc.png", resp[0].ObjectKey)
+}
+
+func (suite *RecognitionAttachmentTestSuite) TestValidateRecognitionAttachment() {
+	attachment := RecognitionAttachment{
+		FileName:    "notes.exe",
+		ContentType: "application/octet-stream",
+		SizeBytes:   MaxAttachmentSizeBytes + 1,
+	}
+
+	valid, errFields := attachment.Validate()
+
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), "Unsupported content type", errFields["content_type"])
+	assert.Contains(suite.T(), errFields, "size_bytes")
+}
+
+func (suite *RecognitionAttachmentTestSuite) TestVerifyUploadMismatch() {
+	attachment := RecognitionAttachment{
+		FileName:    "team.png",
+		ContentType: "image/png",
+		SizeBytes:   1024,
+	}
+
+	valid, errFields := attachment.VerifyUpload(2048, "image/jpeg")
+
+	assert.False(suite.T(), valid)
+	assert.Contains(suite.T(), errFields, "size_bytes")
+	assert.Contains(suite.T(), errFields, "content_type")
+}
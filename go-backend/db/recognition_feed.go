@@ -0,0 +1,305 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultFeedLimit - page size used when the client doesn't ask for one
+	DefaultFeedLimit = 20
+	// MaxFeedLimit - largest page the feed will return
+	MaxFeedLimit = 100
+
+	// listRecognitionFeedQuery hydrates a whole page in one round trip: users and core values are
+	// joined in and the Hi5 aggregates are computed per recognition by the lateral subquery.
+	// Pages are keyed on (given_at, id) so new recognitions never shift the pages after them.
+	listRecognitionFeedQuery = `SELECT r.id, r.text, r.given_at,
+		giver.id AS giver_id, giver.name AS giver_name, giver.display_name AS giver_display_name,
+		giver.profile_image_url AS giver_profile_image_url,
+		receiver.id AS receiver_id, receiver.name AS receiver_name, receiver.display_name AS receiver_display_name,
+		receiver.profile_image_url AS receiver_profile_image_url,
+		cv.id AS core_value_id, cv.text AS core_value_text,
+		parent.id AS parent_core_value_id, parent.text AS parent_core_value_text,
+		COALESCE(h.hi5_count, 0) AS hi5_count, COALESCE(h.comment_count, 0) AS comment_count,
+		COALESCE(h.has_hi5d, FALSE) AS has_hi5d
+		FROM recognitions r
+		JOIN users giver ON giver.id = r.given_by
+		JOIN users receiver ON receiver.id = r.given_for
+		LEFT JOIN core_values cv ON cv.id = r.core_value_id
+		LEFT JOIN core_values parent ON parent.id = cv.parent_id
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) AS hi5_count, COUNT(NULLIF(comment, '')) AS comment_count,
+			BOOL_OR(given_by = $2) AS has_hi5d
+			FROM recognition_hi5 WHERE recognition_id = r.id
+		) h ON TRUE
+		WHERE giver.org_id = $1 AND r.status = 'published' AND (r.given_at, r.id) < ($3, $4)
+		ORDER BY r.given_at DESC, r.id DESC
+		LIMIT $5`
+
+	// manual badge grants are shown in the feed between the recognitions given around the same time
+	listFeedBadgeGrantsQuery = `SELECT ub.id, ub.badge_id, b.name AS badge_name, ub.tier, ub.note, ub.awarded_at,
+		u.id AS user_id, u.name AS user_name, u.display_name AS user_display_name,
+		u.profile_image_url AS user_profile_image_url
+		FROM user_badges ub
+		JOIN badges b ON b.id = ub.badge_id
+		JOIN users u ON u.id = ub.user_id
+		WHERE ub.source = 'manual' AND b.org_id = $1 AND ub.awarded_at > $2 AND ub.awarded_at <= $3
+		ORDER BY ub.awarded_at DESC, ub.id DESC`
+)
+
+// ErrInvalidFeedCursor - the cursor wasn't produced by the feed
+var ErrInvalidFeedCursor = errors.New("Invalid feed cursor")
+
+// FeedUser - the parts of a user the feed shows next to a recognition
+type FeedUser struct {
+	ID              int    `json:"id"`
+	Name            string `json:"full_name"`
+	DisplayName     string `json:"display_name"`
+	ProfileImageURL string `json:"profile_image_url"`
+}
+
+// FeedCoreValue - core value of a recognition along with its parent, if it has one
+type FeedCoreValue struct {
+	ID     int64          `json:"id"`
+	Text   string         `json:"text"`
+	Parent *FeedCoreValue `json:"parent"`
+}
+
+// FeedRecognition - a published recognition with everything needed to render it in the feed
+type FeedRecognition struct {
+	ID           int                     `json:"id"`
+	Text         string                  `json:"text"`
+	GivenAt      int64                   `json:"given_at"`
+	GivenBy      FeedUser                `json:"given_by"`
+	GivenFor     FeedUser                `json:"given_for"`
+	CoreValue    *FeedCoreValue          `json:"core_value"`
+	Hi5Count     int                     `json:"hi5_count"`
+	CommentCount int                     `json:"comment_count"`
+	HasHi5d      bool                    `json:"has_hi5d"`
+	Attachments  []RecognitionAttachment `json:"attachments"`
+}
+
+// FeedBadgeGrant - a badge an admin granted to a user
+type FeedBadgeGrant struct {
+	ID        int64    `json:"id"`
+	BadgeID   int      `json:"badge_id"`
+	BadgeName string   `json:"badge_name"`
+	Tier      string   `json:"tier"`
+	Note      string   `json:"note"`
+	AwardedAt int64    `json:"awarded_at"`
+	User      FeedUser `json:"user"`
+}
+
+// FeedCursor - position of the last recognition on a page
+type FeedCursor struct {
+	GivenAt       int64
+	RecognitionID int
+}
+
+// Encode - opaque form of the cursor handed to clients
+func (cursor FeedCursor) Encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", cursor.GivenAt, cursor.RecognitionID)))
+}
+
+// DecodeFeedCursor - parses a cursor returned by a previous page
+func DecodeFeedCursor(encoded string) (cursor FeedCursor, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		err = ErrInvalidFeedCursor
+		return
+	}
+
+	_, err = fmt.Sscanf(string(raw), "%d:%d", &cursor.GivenAt, &cursor.RecognitionID)
+	if err != nil {
+		err = ErrInvalidFeedCursor
+	}
+	return
+}
+
+// FeedQuery - which page of which organization's feed to load, and for whom
+type FeedQuery struct {
+	OrgID    int
+	ViewerID int
+	Cursor   *FeedCursor
+	Limit    int
+}
+
+// FeedPage - one page of the feed. NextCursor is empty on the last page. BadgeGrants
+// holds the grants made between the cursor and the last recognition of the page.
+type FeedPage struct {
+	Recognitions []FeedRecognition `json:"recognitions"`
+	BadgeGrants  []FeedBadgeGrant  `json:"badge_grants"`
+	NextCursor   string            `json:"next_cursor"`
+}
+
+// feedBadgeGrantRow - flat row scanned from listFeedBadgeGrantsQuery
+type feedBadgeGrantRow struct {
+	ID                  int64          `db:"id"`
+	BadgeID             int            `db:"badge_id"`
+	BadgeName           string         `db:"badge_name"`
+	Tier                string         `db:"tier"`
+	Note                string         `db:"note"`
+	AwardedAt           int64          `db:"awarded_at"`
+	UserID              int            `db:"user_id"`
+	UserName            sql.NullString `db:"user_name"`
+	UserDisplayName     sql.NullString `db:"user_display_name"`
+	UserProfileImageURL sql.NullString `db:"user_profile_image_url"`
+}
+
+// feedRow - flat row scanned from listRecognitionFeedQuery
+type feedRow struct {
+	ID                      int            `db:"id"`
+	Text                    string         `db:"text"`
+	GivenAt                 int64          `db:"given_at"`
+	GiverID                 int            `db:"giver_id"`
+	GiverName               sql.NullString `db:"giver_name"`
+	GiverDisplayName        sql.NullString `db:"giver_display_name"`
+	GiverProfileImageURL    sql.NullString `db:"giver_profile_image_url"`
+	ReceiverID              int            `db:"receiver_id"`
+	ReceiverName            sql.NullString `db:"receiver_name"`
+	ReceiverDisplayName     sql.NullString `db:"receiver_display_name"`
+	ReceiverProfileImageURL sql.NullString `db:"receiver_profile_image_url"`
+	CoreValueID             sql.NullInt64  `db:"core_value_id"`
+	CoreValueText           sql.NullString `db:"core_value_text"`
+	ParentCoreValueID       sql.NullInt64  `db:"parent_core_value_id"`
+	ParentCoreValueText     sql.NullString `db:"parent_core_value_text"`
+	Hi5Count                int            `db:"hi5_count"`
+	CommentCount            int            `db:"comment_count"`
+	HasHi5d                 bool           `db:"has_hi5d"`
+}
+
+func (row feedRow) feedRecognition() (recognition FeedRecognition) {
+	recognition = FeedRecognition{
+		ID:      row.ID,
+		Text:    row.Text,
+		GivenAt: row.GivenAt,
+		GivenBy: FeedUser{
+			ID:              row.GiverID,
+			Name:            row.GiverName.String,
+			DisplayName:     row.GiverDisplayName.String,
+			ProfileImageURL: row.GiverProfileImageURL.String,
+		},
+		GivenFor: FeedUser{
+			ID:              row.ReceiverID,
+			Name:            row.ReceiverName.String,
+			DisplayName:     row.ReceiverDisplayName.String,
+			ProfileImageURL: row.ReceiverProfileImageURL.String,
+		},
+		Hi5Count:     row.Hi5Count,
+		CommentCount: row.CommentCount,
+		HasHi5d:      row.HasHi5d,
+		Attachments:  make([]RecognitionAttachment, 0),
+	}
+
+	if row.CoreValueID.Valid {
+		recognition.CoreValue = &FeedCoreValue{ID: row.CoreValueID.Int64, Text: row.CoreValueText.String}
+		if row.ParentCoreValueID.Valid {
+			recognition.CoreValue.Parent = &FeedCoreValue{ID: row.ParentCoreValueID.Int64, Text: row.ParentCoreValueText.String}
+		}
+	}
+	return
+}
+
+// ListRecognitionFeed - loads a page of the feed with two queries, one for the recognitions
+// and one batched query for the attachments of the whole page
+func (s *pgStore) ListRecognitionFeed(ctx context.Context, query FeedQuery) (page FeedPage, err error) {
+	cursor := FeedCursor{GivenAt: math.MaxInt64, RecognitionID: math.MaxInt32}
+	if query.Cursor != nil {
+		cursor = *query.Cursor
+	}
+
+	// one extra row tells us whether there is a next page
+	rows := make([]feedRow, 0)
+	err = s.db.SelectContext(
+		ctx,
+		&rows,
+		listRecognitionFeedQuery,
+		query.OrgID,
+		query.ViewerID,
+		cursor.GivenAt,
+		cursor.RecognitionID,
+		query.Limit+1,
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": query.OrgID,
+		}).Error("Error while listing recognition feed")
+		return
+	}
+
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		last := rows[len(rows)-1]
+		page.NextCursor = FeedCursor{GivenAt: last.GivenAt, RecognitionID: last.ID}.Encode()
+	}
+
+	page.Recognitions = make([]FeedRecognition, 0, len(rows))
+	recognitionIDs := make([]int64, 0, len(rows))
+	positions := make(map[int64]int)
+	for i, row := range rows {
+		page.Recognitions = append(page.Recognitions, row.feedRecognition())
+		recognitionIDs = append(recognitionIDs, int64(row.ID))
+		positions[int64(row.ID)] = i
+	}
+
+	if len(recognitionIDs) > 0 {
+		var attachments []RecognitionAttachment
+		attachments, err = s.ListRecognitionAttachments(ctx, recognitionIDs)
+		if err != nil {
+			return
+		}
+
+		for _, attachment := range attachments {
+			i := positions[attachment.RecognitionID]
+			page.Recognitions[i].Attachments = append(page.Recognitions[i].Attachments, attachment)
+		}
+	}
+
+	// the last page shows every grant older than the cursor
+	since := int64(-1)
+	if page.NextCursor != "" {
+		since = rows[len(rows)-1].GivenAt
+	}
+	page.BadgeGrants, err = s.listFeedBadgeGrants(ctx, query.OrgID, since, cursor.GivenAt)
+	return
+}
+
+// listFeedBadgeGrants - manual grants awarded after since and up to until
+func (s *pgStore) listFeedBadgeGrants(ctx context.Context, orgID int, since, until int64) (grants []FeedBadgeGrant, err error) {
+	rows := make([]feedBadgeGrantRow, 0)
+	err = s.db.SelectContext(ctx, &rows, listFeedBadgeGrantsQuery, orgID, since, until)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing feed badge grants")
+		return
+	}
+
+	grants = make([]FeedBadgeGrant, 0, len(rows))
+	for _, row := range rows {
+		grants = append(grants, FeedBadgeGrant{
+			ID:        row.ID,
+			BadgeID:   row.BadgeID,
+			BadgeName: row.BadgeName,
+			Tier:      row.Tier,
+			Note:      row.Note,
+			AwardedAt: row.AwardedAt,
+			User: FeedUser{
+				ID:              row.UserID,
+				Name:            row.UserName.String,
+				DisplayName:     row.UserDisplayName.String,
+				ProfileImageURL: row.UserProfileImageURL.String,
+			},
+		})
+	}
+	return
+}
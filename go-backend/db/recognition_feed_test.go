@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionFeedTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *RecognitionFeedTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+	now = time.Now()
+}
+
+func (suite *RecognitionFeedTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *RecognitionFeedTestSuite) getMockedFeedRows() (mockedRows *sqlmock.Rows) {
+	mockedRows = suite.sqlmock.NewRows([]string{"id", "text", "given_at",
+		"giver_id", "giver_name", "giver_display_name", "giver_profile_image_url",
+		"receiver_id", "receiver_name", "receiver_display_name", "receiver_profile_image_url",
+		"core_value_id", "core_value_text", "parent_core_value_id", "parent_core_value_text",
+		"hi5_count", "comment_count", "has_hi5d"}).
+		AddRow(5, "thanks for the help", 200, 1, "Giver", "giver", "g.jpg", 2, "Receiver", "receiver", nil, 3, "Teamwork", 1, "Culture", 4, 2, true).
+		AddRow(4, "great demo", 100, 2, "Receiver", "receiver", nil, 1, "Giver", "giver", "g.jpg", nil, nil, nil, nil, 0, 0, false)
+	return
+}
+
+func (suite *RecognitionFeedTestSuite) TestListRecognitionFeedFirstPage() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognitions r").
+		WithArgs(1, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
+		WillReturnRows(suite.getMockedFeedRows())
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_attachments").
+		WithArgs(sqlmock.AnyArg(), AttachmentStatusUploaded).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "uploaded_by", "object_key", "file_name", "content_type", "size_bytes", "status", "created_at", "updated_at"}).
+			AddRow(1, 5, 1, "recognitions/5/abc.png", "team.png", "image/png", 1024, AttachmentStatusUploaded, now, now))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM user_badges ub").
+		WithArgs(1, 200, sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "badge_id", "badge_name", "tier", "note", "awarded_at",
+			"user_id", "user_name", "user_display_name", "user_profile_image_url"}).
+			AddRow(3, 2, "Hackathon winner", "", "Won the July hackathon", 250, 2, "Receiver", nil, nil))
+
+	page, err := suite.dbStore.ListRecognitionFeed(context.Background(), FeedQuery{OrgID: 1, ViewerID: 1, Limit: 1})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 1, len(page.Recognitions))
+	assert.Equal(suite.T(), FeedCursor{GivenAt: 200, RecognitionID: 5}.Encode(), page.NextCursor)
+
+	recognition := page.Recognitions[0]
+	assert.Equal(suite.T(), "Giver", recognition.GivenBy.Name)
+	assert.Equal(suite.T(), "", recognition.GivenFor.ProfileImageURL)
+	assert.Equal(suite.T(), "Teamwork", recognition.CoreValue.Text)
+	assert.Equal(suite.T(), "Culture", recognition.CoreValue.Parent.Text)
+	assert.Equal(suite.T(), 4, recognition.Hi5Count)
+	assert.Equal(suite.T(), 2, recognition.CommentCount)
+	assert.True(suite.T(), recognition.HasHi5d)
+	assert.Equal(suite.T(), 1, len(recognition.Attachments))
+	assert.Equal(suite.T(), []FeedBadgeGrant{{ID: 3, BadgeID: 2, BadgeName: "Hackathon winner", Note: "Won the July hackathon",
+		AwardedAt: 250, User: FeedUser{ID: 2, Name: "Receiver"}}}, page.BadgeGrants)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionFeedTestSuite) TestListRecognitionFeedLastPage() {
+	cursor := FeedCursor{GivenAt: 300, RecognitionID: 6}
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognitions r").
+		WithArgs(1, 1, 300, 6, 21).
+		WillReturnRows(suite.getMockedFeedRows())
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_attachments").
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM user_badges ub").
+		WithArgs(1, -1, 300).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}))
+
+	page, err := suite.dbStore.ListRecognitionFeed(context.Background(), FeedQuery{OrgID: 1, ViewerID: 1, Cursor: &cursor, Limit: 20})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), 2, len(page.Recognitions))
+	assert.Equal(suite.T(), "", page.NextCursor)
+	assert.Nil(suite.T(), page.Recognitions[1].CoreValue)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionFeedTestSuite) TestDecodeFeedCursor() {
+	cursor := FeedCursor{GivenAt: 1588073442241, RecognitionID: 42}
+
+	decoded, err := DecodeFeedCursor(cursor.Encode())
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), cursor, decoded)
+
+	_, err = DecodeFeedCursor("not-a-cursor")
+	assert.Equal(suite.T(), ErrInvalidFeedCursor, err)
+}
@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	logger "github.com/sirupsen/logrus"
+	ae "joshsoftware/peerly/apperrors"
 	"time"
 )
 
@@ -12,9 +14,13 @@ const (
 		comment,
 		given_by,
 		given_at
-		) VALUES ($1, $2, $3, $4);`
+		) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (recognition_id, given_by) DO NOTHING;`
 
-	updateHi5QuotaQuery = `UPDATE users SET hi5_quota_balance = hi5_quota_balance - 1 where id = $1;`
+	deleteRecognitionHi5Query = `DELETE FROM recognition_hi5 WHERE recognition_id = $1 AND given_by = $2 RETURNING given_at;`
+
+	listRecognitionHi5sQuery = `SELECT id, recognition_id, COALESCE(comment, '') AS comment, given_by, given_at
+		FROM recognition_hi5 WHERE recognition_id = $1 ORDER BY given_at ASC, id ASC;`
 )
 
 type RecognitionHi5 struct {
@@ -38,7 +44,7 @@ func (reqHi5 *RecognitionHi5) CheckHi5QuotaBalance(hi5Quota int) (errorResponse
 }
 
 func (s *pgStore) CreateRecognitionHi5(ctx context.Context, reqHi5 RecognitionHi5, recognitionID int) (err error) {
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		logger.WithField("err:", err.Error()).Error("Error while initiating transaction")
 		return
@@ -52,13 +58,7 @@ func (s *pgStore) CreateRecognitionHi5(ctx context.Context, reqHi5 RecognitionHi
 		tx.Commit()
 	}()
 
-	_, err = tx.ExecContext(ctx, updateHi5QuotaQuery, reqHi5.GivenBy)
-	if err != nil {
-		logger.WithField("err:", err.Error()).Error("Error updating users hi5QuotaBalance")
-		return
-	}
-
-	_, err = tx.ExecContext(ctx,
+	result, err := tx.ExecContext(ctx,
 		createRecognitionHi5Query,
 		recognitionID,
 		reqHi5.Comment,
@@ -70,5 +70,79 @@ func (s *pgStore) CreateRecognitionHi5(ctx context.Context, reqHi5 RecognitionHi
 		return
 	}
 
+	err = checkRowsAffected(result)
+	if err == ae.ErrRecordNotFound {
+		err = ae.ErrHi5AlreadyGiven
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	_, err = appendHi5LedgerEntry(ctx, tx, Hi5LedgerEntry{
+		UserID:        reqHi5.GivenBy,
+		EntryType:     Hi5LedgerSpend,
+		Amount:        -1,
+		RecognitionID: &recognitionID,
+		Reason:        "Hi5 given",
+	}, 0)
+	return
+}
+
+// DeleteRecognitionHi5 - takes back the user's Hi5 on a recognition. The quota is only refunded when
+// the Hi5 was given at or after refundSince, i.e. in the current quota period.
+func (s *pgStore) DeleteRecognitionHi5(ctx context.Context, recognitionID, givenBy int, refundSince int64) (refunded bool, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err:", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	var givenAt int64
+	err = tx.GetContext(ctx, &givenAt, deleteRecognitionHi5Query, recognitionID, givenBy)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = ae.ErrRecordNotFound
+			return
+		}
+		logger.WithField("err:", err.Error()).Error("Error deleting recognition Hi5")
+		return
+	}
+
+	if givenAt < refundSince {
+		return
+	}
+
+	_, err = appendHi5LedgerEntry(ctx, tx, Hi5LedgerEntry{
+		UserID:        givenBy,
+		EntryType:     Hi5LedgerRefund,
+		Amount:        1,
+		RecognitionID: &recognitionID,
+		Reason:        "Hi5 taken back",
+	}, 0)
+	if err != nil {
+		return
+	}
+
+	refunded = true
+	return
+}
+
+func (s *pgStore) ListRecognitionHi5s(ctx context.Context, recognitionID int) (hi5s []RecognitionHi5, err error) {
+	hi5s = make([]RecognitionHi5, 0)
+	err = s.db.SelectContext(ctx, &hi5s, listRecognitionHi5sQuery, recognitionID)
+	if err != nil {
+		logger.WithField("err:", err.Error()).Error("Error listing recognition Hi5s")
+		return
+	}
+
 	return
 }
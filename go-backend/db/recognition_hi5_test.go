@@ -6,6 +6,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	ae "joshsoftware/peerly/apperrors"
 	"time"
 )
 
@@ -27,6 +28,20 @@ func (suite *RecognitionHi5TestSuite) TearDownTest() {
 	suite.db.Close()
 }
 
+// expectHi5LedgerEntry - expectations for appendHi5LedgerEntry locking the user and recording the entry
+func (suite *RecognitionHi5TestSuite) expectHi5LedgerEntry(userID, balance int, entryType string, amount int) {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(userID).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(balance))
+	suite.sqlmock.ExpectExec("UPDATE users SET hi5_quota_balance").
+		WithArgs(balance+amount, userID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_quota_ledger").
+		WithArgs(userID, entryType, amount, balance+amount, 1, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "entry_type", "amount", "balance_after"}).
+			AddRow(1, userID, entryType, amount, balance+amount))
+}
+
 func (suite *RecognitionHi5TestSuite) TestCreateRecognitionHi5Success() {
 	recognitionHi5 := RecognitionHi5{
 		RecognitionID: 1,
@@ -36,14 +51,12 @@ func (suite *RecognitionHi5TestSuite) TestCreateRecognitionHi5Success() {
 
 	suite.sqlmock.ExpectBegin()
 
-	suite.sqlmock.ExpectExec("UPDATE users").
-		WithArgs(1).
-		WillReturnResult(sqlmock.NewResult(1, 1))
-
 	suite.sqlmock.ExpectExec("INSERT INTO recognition_hi5").
 		WithArgs(1, "Test Comment", 1, time.Now().Unix()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
+	suite.expectHi5LedgerEntry(1, 5, Hi5LedgerSpend, -1)
+
 	suite.sqlmock.ExpectCommit()
 
 	err := suite.dbStore.CreateRecognitionHi5(context.Background(), recognitionHi5, recognitionHi5.RecognitionID)
@@ -64,10 +77,6 @@ func (suite *RecognitionHi5TestSuite) TestCreateRecognitionHi5Failure() {
 
 	suite.sqlmock.ExpectBegin()
 
-	suite.sqlmock.ExpectExec("UPDATE users").
-		WithArgs(1).
-		WillReturnResult(sqlmock.NewResult(1, 1))
-
 	suite.sqlmock.ExpectExec("INSERT INTO recognition_hi5").
 		WithArgs(1, "Test Comment", 1, time.Now().Unix()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
@@ -78,3 +87,94 @@ func (suite *RecognitionHi5TestSuite) TestCreateRecognitionHi5Failure() {
 
 	assert.NotNil(suite.T(), err)
 }
+
+func (suite *RecognitionHi5TestSuite) TestCreateRecognitionHi5WhenAlreadyGiven() {
+	recognitionHi5 := RecognitionHi5{RecognitionID: 1, Comment: "Test Comment", GivenBy: 1}
+
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("INSERT INTO recognition_hi5").
+		WithArgs(1, "Test Comment", 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectRollback()
+
+	err := suite.dbStore.CreateRecognitionHi5(context.Background(), recognitionHi5, recognitionHi5.RecognitionID)
+
+	assert.Equal(suite.T(), ae.ErrHi5AlreadyGiven, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionHi5TestSuite) TestCreateRecognitionHi5WhenQuotaExhausted() {
+	recognitionHi5 := RecognitionHi5{RecognitionID: 1, Comment: "Test Comment", GivenBy: 1}
+
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("INSERT INTO recognition_hi5").
+		WithArgs(1, "Test Comment", 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(0))
+	suite.sqlmock.ExpectRollback()
+
+	err := suite.dbStore.CreateRecognitionHi5(context.Background(), recognitionHi5, recognitionHi5.RecognitionID)
+
+	assert.Equal(suite.T(), ae.ErrInsufficientHi5Quota, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionHi5TestSuite) TestDeleteRecognitionHi5InCurrentPeriod() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("DELETE FROM recognition_hi5").
+		WithArgs(1, 2).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"given_at"}).AddRow(200))
+	suite.expectHi5LedgerEntry(2, 3, Hi5LedgerRefund, 1)
+	suite.sqlmock.ExpectCommit()
+
+	refunded, err := suite.dbStore.DeleteRecognitionHi5(context.Background(), 1, 2, 100)
+
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), refunded)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionHi5TestSuite) TestDeleteRecognitionHi5FromEarlierPeriod() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("DELETE FROM recognition_hi5").
+		WithArgs(1, 2).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"given_at"}).AddRow(50))
+	suite.sqlmock.ExpectCommit()
+
+	refunded, err := suite.dbStore.DeleteRecognitionHi5(context.Background(), 1, 2, 100)
+
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), refunded)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionHi5TestSuite) TestDeleteRecognitionHi5WhenNotGiven() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("DELETE FROM recognition_hi5").
+		WithArgs(1, 2).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"given_at"}))
+	suite.sqlmock.ExpectRollback()
+
+	_, err := suite.dbStore.DeleteRecognitionHi5(context.Background(), 1, 2, 100)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionHi5TestSuite) TestListRecognitionHi5s() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_hi5").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "comment", "given_by", "given_at"}).
+			AddRow(1, 1, "Nice", 2, 100).
+			AddRow(2, 1, "", 3, 200))
+
+	hi5s, err := suite.dbStore.ListRecognitionHi5s(context.Background(), 1)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []RecognitionHi5{
+		{ID: 1, RecognitionID: 1, Comment: "Nice", GivenBy: 2, GivenAt: 100},
+		{ID: 2, RecognitionID: 1, GivenBy: 3, GivenAt: 200},
+	}, hi5s)
+}
@@ -2,17 +2,61 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
+	"github.com/jmoiron/sqlx"
 	logger "github.com/sirupsen/logrus"
 )
 
 const (
 	createRecognitionModerationQuery = `INSERT INTO recognition_moderation (recognition_id, is_inappropriate,
-		moderator_comment, moderated_by, moderated_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, recognition_id, is_inappropriate, moderator_comment, moderated_by, moderated_at, created_at, updated_at`
+		moderator_comment, moderated_by, moderated_at, refund_hi5s, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, recognition_id, is_inappropriate, moderator_comment, moderated_by, moderated_at, refund_hi5s, created_at, updated_at`
+
+	// only published recognitions are hidden and only hidden ones restored, drafts are left alone
+	hideRecognitionQuery = `UPDATE recognitions SET status = 'hidden' WHERE id = $1 AND status = 'published'`
+
+	restoreRecognitionQuery = `UPDATE recognitions SET status = 'published' WHERE id = $1 AND status = 'hidden'`
+
+	// the decision that hid the recognition being restored, other than the restoring decision $2
+	latestHidingModerationQuery = `SELECT id FROM recognition_moderation
+		WHERE recognition_id = $1 AND is_inappropriate AND id <> $2 ORDER BY moderated_at DESC, id DESC LIMIT 1`
+
+	// the Hi5s are kept with the decision that removed them, so overturning it can give them back.
+	// Hi5s given at or after $3 are refunded.
+	deleteRecognitionHi5sQuery = `WITH removed AS (
+		DELETE FROM recognition_hi5 WHERE recognition_id = $1 RETURNING recognition_id, comment, given_by, given_at
+	) INSERT INTO removed_recognition_hi5s (moderation_id, recognition_id, comment, given_by, given_at, refunded)
+		SELECT $2, recognition_id, comment, given_by, given_at, given_at >= $3 FROM removed
+		RETURNING id, recognition_id, comment, given_by, given_at, refunded`
+
+	listRemovedRecognitionHi5sQuery = `SELECT id, recognition_id, comment, given_by, given_at, refunded
+		FROM removed_recognition_hi5s WHERE moderation_id = $1 AND restored_at IS NULL ORDER BY id FOR UPDATE`
+
+	restoreRecognitionHi5Query = `WITH restored AS (
+		UPDATE removed_recognition_hi5s SET restored_at = $2 WHERE id = $1
+		RETURNING recognition_id, comment, given_by, given_at
+	) INSERT INTO recognition_hi5 (recognition_id, comment, given_by, given_at)
+		SELECT recognition_id, comment, given_by, given_at FROM restored
+		ON CONFLICT (recognition_id, given_by) DO NOTHING`
+
+	// the giver and recipient are told when their recognition is hidden, so they can appeal
+	notifyRecognitionPartiesQuery = `INSERT INTO moderation_notifications (user_id, recognition_id, kind, created_at)
+		SELECT DISTINCT party, r.id, $2, $3 FROM recognitions r, unnest(ARRAY[r.given_by, r.given_for]) AS party
+		WHERE r.id = $1`
 )
 
+// removedRecognitionHi5 - a Hi5 taken back when its recognition was hidden
+type removedRecognitionHi5 struct {
+	ID            int64   `db:"id"`
+	RecognitionID int     `db:"recognition_id"`
+	Comment       *string `db:"comment"`
+	GivenBy       int     `db:"given_by"`
+	GivenAt       int64   `db:"given_at"`
+	Refunded      bool    `db:"refunded"`
+}
+
 type RecognitionModeration struct {
 	ID               int64     `db:"id" json:"id"`
 	RecognitionID    int64     `db:"recognition_id" json:"recognition_id"`
@@ -20,6 +64,7 @@ type RecognitionModeration struct {
 	ModeratorComment string    `db:"moderator_comment" json:"comment"`
 	ModeratedBy      int64     `db:"moderated_by" json:"moderated_by"`
 	ModeratedAt      int64     `db:"moderated_at" json:"moderated_at"`
+	RefundHi5s       bool      `db:"refund_hi5s" json:"refund_hi5s"`
 	CreatedAt        time.Time `db:"created_at" json:"-"`
 	UpdatedAt        time.Time `db:"updated_at" json:"-"`
 }
@@ -29,6 +74,8 @@ func (recognitionModeration *RecognitionModeration) Validate() (valid bool, errF
 
 	if recognitionModeration.IsInappropriate == nil {
 		errFields["is_inappropriate"] = "Can't be blank"
+	} else if recognitionModeration.RefundHi5s && !*recognitionModeration.IsInappropriate {
+		errFields["refund_hi5s"] = "Can only be set when hiding the recognition"
 	}
 
 	if len(errFields) == 0 {
@@ -37,9 +84,27 @@ func (recognitionModeration *RecognitionModeration) Validate() (valid bool, errF
 	return
 }
 
-func (s *pgStore) CreateRecognitionModeration(ctx context.Context, recognitionID int64, recognitionModeration RecognitionModeration) (resp RecognitionModeration, err error) {
+// CreateRecognitionModeration - records the decision, resolves every open report and any pending quarantine
+// of the recognition and hides or restores it. Hiding with RefundHi5s takes back its Hi5s, refunding those given at or after
+// refundSince, i.e. in the current quota period, and restoring gives them back. The giver and recipient are notified when
+// the recognition is actually hidden, not when it was a draft or hidden already.
+func (s *pgStore) CreateRecognitionModeration(ctx context.Context, recognitionID int64, recognitionModeration RecognitionModeration, refundSince int64) (resp RecognitionModeration, err error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
 	now := time.Now()
-	err = s.db.GetContext(
+	err = tx.GetContext(
 		ctx,
 		&resp,
 		createRecognitionModerationQuery,
@@ -48,6 +113,7 @@ func (s *pgStore) CreateRecognitionModeration(ctx context.Context, recognitionID
 		recognitionModeration.ModeratorComment,
 		recognitionModeration.ModeratedBy,
 		now.Unix(),
+		recognitionModeration.RefundHi5s,
 		now,
 		now,
 	)
@@ -60,5 +126,187 @@ func (s *pgStore) CreateRecognitionModeration(ctx context.Context, recognitionID
 		return
 	}
 
+	_, err = tx.ExecContext(ctx, resolveReportsQuery, recognitionID, now.Unix(), resp.ID, now)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while resolving reported recognitions")
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, reviewRecognitionQuarantineQuery, recognitionID, now.Unix(), resp.ID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while reviewing recognition quarantine")
+		return
+	}
+
+	if !*recognitionModeration.IsInappropriate {
+		err = restoreModeratedRecognition(ctx, tx, recognitionID, resp.ID)
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, hideRecognitionQuery, recognitionID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while hiding recognition")
+		return
+	}
+
+	// a draft or an already hidden recognition has nothing to appeal or refund
+	hidden, err := result.RowsAffected()
+	if err != nil || hidden == 0 {
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, notifyRecognitionPartiesQuery, recognitionID, RecognitionHiddenNotification, now.Unix())
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while notifying giver and recipient of recognition")
+		return
+	}
+
+	if recognitionModeration.RefundHi5s {
+		err = refundRecognitionHi5s(ctx, tx, int(recognitionID), resp.ID, refundSince)
+	}
+	return
+}
+
+// restoreModeratedRecognition - publishes a hidden recognition again along with the Hi5s taken by the
+// decision that hid it. A recognition that wasn't hidden keeps its Hi5s as they are.
+func restoreModeratedRecognition(ctx context.Context, tx *sqlx.Tx, recognitionID int64, moderationID int64) (err error) {
+	result, err := tx.ExecContext(ctx, restoreRecognitionQuery, recognitionID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while restoring recognition")
+		return
+	}
+
+	restored, err := result.RowsAffected()
+	if err != nil || restored == 0 {
+		return
+	}
+
+	var hidingModerationID int64
+	err = tx.GetContext(ctx, &hidingModerationID, latestHidingModerationQuery, recognitionID, moderationID)
+	if err == sql.ErrNoRows {
+		err = nil
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while fetching hiding recognition moderation")
+		return
+	}
+
+	return restoreRecognitionHi5s(ctx, tx, hidingModerationID)
+}
+
+// refundRecognitionHi5s - takes back every Hi5 of a hidden recognition, keeping track of the
+// refunded ones so restoring them charges their givers again
+func refundRecognitionHi5s(ctx context.Context, tx *sqlx.Tx, recognitionID int, moderationID int64, refundSince int64) (err error) {
+	hi5s := make([]removedRecognitionHi5, 0)
+	// Hi5s given before the last quota reset were already paid for by that period's quota
+	err = tx.SelectContext(ctx, &hi5s, deleteRecognitionHi5sQuery, recognitionID, moderationID, refundSince)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"recognitionID": recognitionID,
+		}).Error("Error while deleting recognition Hi5s")
+		return
+	}
+
+	for _, hi5 := range hi5s {
+		if !hi5.Refunded {
+			continue
+		}
+
+		_, err = appendHi5LedgerEntry(ctx, tx, Hi5LedgerEntry{
+			UserID:        hi5.GivenBy,
+			EntryType:     Hi5LedgerRefund,
+			Amount:        1,
+			RecognitionID: &recognitionID,
+			Reason:        "Recognition hidden by moderation",
+		}, 0)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// restoreRecognitionHi5s - gives back the Hi5s taken by the moderation decision. Givers who were refunded
+// are charged again, a Hi5 whose giver has no quota left to pay for it stays removed and one the giver
+// has given again since isn't restored or charged twice.
+func restoreRecognitionHi5s(ctx context.Context, tx *sqlx.Tx, moderationID int64) (err error) {
+	hi5s := make([]removedRecognitionHi5, 0)
+	err = tx.SelectContext(ctx, &hi5s, listRemovedRecognitionHi5sQuery, moderationID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":          err.Error(),
+			"moderationID": moderationID,
+		}).Error("Error while listing removed recognition Hi5s")
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, hi5 := range hi5s {
+		if hi5.Refunded {
+			var balance int
+			err = tx.GetContext(ctx, &balance, lockHi5QuotaBalanceQuery, hi5.GivenBy)
+			if err != nil {
+				logger.WithFields(logger.Fields{
+					"err":     err.Error(),
+					"user_id": hi5.GivenBy,
+				}).Error("Error while locking user's Hi5 quota balance")
+				return
+			}
+			if balance < 1 {
+				continue
+			}
+		}
+
+		var result sql.Result
+		result, err = tx.ExecContext(ctx, restoreRecognitionHi5Query, hi5.ID, now)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":          err.Error(),
+				"moderationID": moderationID,
+			}).Error("Error while restoring recognition Hi5s")
+			return
+		}
+
+		var restored int64
+		restored, err = result.RowsAffected()
+		if err != nil {
+			return
+		}
+		if restored == 0 || !hi5.Refunded {
+			continue
+		}
+
+		recognitionID := hi5.RecognitionID
+		_, err = appendHi5LedgerEntry(ctx, tx, Hi5LedgerEntry{
+			UserID:        hi5.GivenBy,
+			EntryType:     Hi5LedgerSpend,
+			Amount:        -1,
+			RecognitionID: &recognitionID,
+			Reason:        "Hi5 restored by moderation",
+		}, 0)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
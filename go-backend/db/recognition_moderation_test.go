@@ -50,14 +50,60 @@ func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationSucc
 		UpdatedAt:        now,
 	}
 
+	suite.sqlmock.ExpectBegin()
 	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
-		WithArgs(1, true, "Test Comment", 1, now.Unix(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(1, true, "Test Comment", 1, now.Unix(), false, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnRows(mockedRows)
+	suite.sqlmock.ExpectExec("UPDATE reported_recognitions SET status = 'resolved'").
+		WithArgs(1, sqlmock.AnyArg(), 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlmock.ExpectExec("UPDATE recognition_quarantines").
+		WithArgs(1, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'hidden'").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WithArgs(1, RecognitionHiddenNotification, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlmock.ExpectCommit()
 
-	resp, err := suite.dbStore.CreateRecognitionModeration(context.Background(), expectedRecognitionModeration.ID, expectedRecognitionModeration)
+	resp, err := suite.dbStore.CreateRecognitionModeration(context.Background(), expectedRecognitionModeration.ID, expectedRecognitionModeration, 0)
 
 	assert.Equal(suite.T(), expectedRecognitionModeration, resp)
 	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationOfHiddenRecognition() {
+	isInappropriate := true
+
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WithArgs(1, true, "Spam", 1, sqlmock.AnyArg(), true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderator_comment", "moderated_by", "refund_hi5s"}).
+			AddRow(3, 1, true, "Spam", 1, true))
+	suite.sqlmock.ExpectExec("UPDATE reported_recognitions SET status = 'resolved'").
+		WithArgs(1, sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognition_quarantines").
+		WithArgs(1, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// already hidden, so nobody is told about it again and its Hi5s are left alone
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'hidden'").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectCommit()
+
+	_, err := suite.dbStore.CreateRecognitionModeration(context.Background(), 1, RecognitionModeration{
+		IsInappropriate:  &isInappropriate,
+		ModeratorComment: "Spam",
+		ModeratedBy:      1,
+		RefundHi5s:       true,
+	}, 0)
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
 }
 
 func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationFailure() {
@@ -76,11 +122,130 @@ func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationFail
 	}
 
 	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
-		WithArgs(1, true, "Test Comment", 1, now.Unix(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(1, true, "Test Comment", 1, now.Unix(), false, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnRows(mockedRows)
 
-	resp, err := suite.dbStore.CreateRecognitionModeration(context.Background(), expectedRecognitionModeration.ID, expectedRecognitionModeration)
+	resp, err := suite.dbStore.CreateRecognitionModeration(context.Background(), expectedRecognitionModeration.ID, expectedRecognitionModeration, 0)
 
 	assert.Equal(suite.T(), RecognitionModeration{}, resp)
 	assert.NotNil(suite.T(), err)
 }
+
+func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationRestoresRecognition() {
+	isInappropriate := false
+
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WithArgs(1, false, "Looks fine", 1, sqlmock.AnyArg(), false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderator_comment", "moderated_by"}).
+			AddRow(2, 1, false, "Looks fine", 1))
+	suite.sqlmock.ExpectExec("UPDATE reported_recognitions SET status = 'resolved'").
+		WithArgs(1, sqlmock.AnyArg(), 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognition_quarantines").
+		WithArgs(1, sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'published'").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// the Hi5s taken by the decision that hid the recognition come back
+	suite.sqlmock.ExpectQuery("SELECT id FROM recognition_moderation WHERE recognition_id = \\$1 AND is_inappropriate AND id <> \\$2").
+		WithArgs(1, 2).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM removed_recognition_hi5s WHERE moderation_id = (.+) FOR UPDATE").
+		WithArgs(int64(1)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "comment", "given_by", "given_at", "refunded"}).
+			AddRow(1, 1, "Nice", 6, 900, false))
+	suite.sqlmock.ExpectExec("UPDATE removed_recognition_hi5s SET restored_at").
+		WithArgs(int64(1), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectCommit()
+
+	_, err := suite.dbStore.CreateRecognitionModeration(context.Background(), 1, RecognitionModeration{
+		IsInappropriate:  &isInappropriate,
+		ModeratorComment: "Looks fine",
+		ModeratedBy:      1,
+	}, 0)
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationOfPublishedRecognitionKeepsHi5s() {
+	isInappropriate := false
+
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WithArgs(1, false, "Looks fine", 1, sqlmock.AnyArg(), false, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderator_comment", "moderated_by"}).
+			AddRow(2, 1, false, "Looks fine", 1))
+	suite.sqlmock.ExpectExec("UPDATE reported_recognitions SET status = 'resolved'").
+		WithArgs(1, sqlmock.AnyArg(), 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognition_quarantines").
+		WithArgs(1, sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'published'").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectCommit()
+
+	_, err := suite.dbStore.CreateRecognitionModeration(context.Background(), 1, RecognitionModeration{
+		IsInappropriate:  &isInappropriate,
+		ModeratorComment: "Looks fine",
+		ModeratedBy:      1,
+	}, 0)
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionModerationTestSuite) TestCreateRecognitionModerationRefundsHi5sOfCurrentPeriod() {
+	isInappropriate := true
+
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_moderation").
+		WithArgs(1, true, "Spam", 1, sqlmock.AnyArg(), true, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "is_inappropriate", "moderator_comment", "moderated_by", "refund_hi5s"}).
+			AddRow(3, 1, true, "Spam", 1, true))
+	suite.sqlmock.ExpectExec("UPDATE reported_recognitions SET status = 'resolved'").
+		WithArgs(1, sqlmock.AnyArg(), 3, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("UPDATE recognition_quarantines").
+		WithArgs(1, sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'hidden'").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WithArgs(1, RecognitionHiddenNotification, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlmock.ExpectQuery("DELETE FROM recognition_hi5").
+		WithArgs(1, 3, int64(1000)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "recognition_id", "comment", "given_by", "given_at", "refunded"}).
+			AddRow(1, 1, "Nice", 4, 900, false).
+			AddRow(2, 1, nil, 5, 1100, true))
+	// only the Hi5 given after the last quota reset is refunded
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(5).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(2))
+	suite.sqlmock.ExpectExec("UPDATE users SET hi5_quota_balance").
+		WithArgs(3, 5).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_quota_ledger").
+		WithArgs(5, Hi5LedgerRefund, 1, 3, 1, "Recognition hidden by moderation", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "entry_type", "amount", "balance_after"}).
+			AddRow(1, 5, Hi5LedgerRefund, 1, 3))
+	suite.sqlmock.ExpectCommit()
+
+	resp, err := suite.dbStore.CreateRecognitionModeration(context.Background(), 1, RecognitionModeration{
+		IsInappropriate:  &isInappropriate,
+		ModeratorComment: "Spam",
+		ModeratedBy:      1,
+		RefundHi5s:       true,
+	}, 1000)
+
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), resp.RefundHi5s)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// QuarantineNotification - a recognition was hidden automatically and waits for review
+	QuarantineNotification = "quarantine"
+	// RecognitionHiddenNotification - tells the giver and recipient a moderator hid their recognition
+	RecognitionHiddenNotification = "recognition_hidden"
+
+	// MaxModerationNotifications - most notifications returned in one listing
+	MaxModerationNotifications = 50
+
+	// resolved reports were already reviewed, so they can't quarantine the recognition again.
+	// Only reports for reasons the organization lets trigger a quarantine are counted.
+	countRecentReportersQuery = `SELECT COUNT(DISTINCT reported_by) FROM reported_recognitions
+		WHERE recognition_id = $1 AND status <> 'resolved' AND reported_at >= $2
+		AND type_of_reporting IN (SELECT name FROM report_reasons WHERE org_id = $3 AND triggers_quarantine)`
+
+	insertRecognitionQuarantineQuery = `INSERT INTO recognition_quarantines (recognition_id, reporter_count, quarantined_at)
+		VALUES ($1, $2, $3) RETURNING id, recognition_id, reporter_count, quarantined_at, reviewed_at, moderation_id`
+
+	reviewRecognitionQuarantineQuery = `UPDATE recognition_quarantines SET reviewed_at = $2, moderation_id = $3
+		WHERE recognition_id = $1 AND reviewed_at IS NULL`
+
+	// every active admin of the organization moderates its recognitions
+	notifyModeratorsQuery = `INSERT INTO moderation_notifications (user_id, recognition_id, kind, created_at)
+		SELECT u.id, $2, $3, $4 FROM users u JOIN roles ON roles.id = u.role_id
+		WHERE u.org_id = $1 AND roles.name = 'Admin' AND u.soft_delete = FALSE`
+
+	listModerationNotificationsQuery = `SELECT id, user_id, recognition_id, kind, created_at, read_at
+		FROM moderation_notifications WHERE user_id = $1 ORDER BY id DESC LIMIT $2`
+
+	readModerationNotificationQuery = `UPDATE moderation_notifications SET read_at = COALESCE(read_at, $3)
+		WHERE id = $1 AND user_id = $2
+		RETURNING id, user_id, recognition_id, kind, created_at, read_at`
+)
+
+// RecognitionQuarantine - a recognition hidden automatically because of its reports.
+// It is pending until a moderation decision reviews it.
+type RecognitionQuarantine struct {
+	ID            int64  `db:"id" json:"id"`
+	RecognitionID int64  `db:"recognition_id" json:"recognition_id"`
+	ReporterCount int    `db:"reporter_count" json:"reporter_count"`
+	QuarantinedAt int64  `db:"quarantined_at" json:"quarantined_at"`
+	ReviewedAt    *int64 `db:"reviewed_at" json:"reviewed_at"`
+	ModerationID  *int64 `db:"moderation_id" json:"moderation_id"`
+}
+
+// ModerationNotification - tells a moderator about a recognition that needs their attention
+type ModerationNotification struct {
+	ID            int64  `db:"id" json:"id"`
+	UserID        int    `db:"user_id" json:"user_id"`
+	RecognitionID int64  `db:"recognition_id" json:"recognition_id"`
+	Kind          string `db:"kind" json:"kind"`
+	CreatedAt     int64  `db:"created_at" json:"created_at"`
+	ReadAt        *int64 `db:"read_at" json:"read_at"`
+}
+
+// QuarantineRecognition - hides a published recognition of the organization once enough distinct users
+// reported it within the settings' window, and notifies the organization's moderators. Recognitions
+// that are already hidden are left alone, so a recognition is quarantined at most once at a time.
+func (s *pgStore) QuarantineRecognition(ctx context.Context, orgID int, recognitionID int64, settings ModerationSettings) (quarantine *RecognitionQuarantine, err error) {
+	if settings.QuarantineReporterThreshold == 0 {
+		return
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	now := time.Now().Unix()
+	since := now - int64(settings.QuarantineWindowHours)*60*60
+
+	var reporterCount int
+	err = tx.GetContext(ctx, &reporterCount, countRecentReportersQuery, recognitionID, since, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while counting reporters of recognition")
+		return
+	}
+
+	if reporterCount < settings.QuarantineReporterThreshold {
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, hideRecognitionQuery, recognitionID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while hiding recognition")
+		return
+	}
+
+	hidden, err := result.RowsAffected()
+	if err != nil || hidden == 0 {
+		return
+	}
+
+	quarantine = &RecognitionQuarantine{}
+	err = tx.GetContext(ctx, quarantine, insertRecognitionQuarantineQuery, recognitionID, reporterCount, now)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while recording recognition quarantine")
+		quarantine = nil
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, notifyModeratorsQuery, orgID, recognitionID, QuarantineNotification, now)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while notifying moderators")
+		quarantine = nil
+	}
+	return
+}
+
+// ListModerationNotifications - the user's latest moderation notifications, newest first
+func (s *pgStore) ListModerationNotifications(ctx context.Context, userID int) (notifications []ModerationNotification, err error) {
+	notifications = make([]ModerationNotification, 0)
+	err = s.db.SelectContext(ctx, &notifications, listModerationNotificationsQuery, userID, MaxModerationNotifications)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while listing moderation notifications")
+		return
+	}
+
+	return
+}
+
+// ReadModerationNotification - marks one of the user's notifications as read.
+// Fails with ErrRecordNotFound if the user has no such notification.
+func (s *pgStore) ReadModerationNotification(ctx context.Context, userID int, notificationID int64) (notification ModerationNotification, err error) {
+	err = s.db.GetContext(ctx, &notification, readModerationNotificationQuery, notificationID, userID, time.Now().Unix())
+	if err == sql.ErrNoRows {
+		err = ae.ErrRecordNotFound
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"notification_id": notificationID,
+		}).Error("Error while reading moderation notification")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionQuarantineTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *RecognitionQuarantineTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *RecognitionQuarantineTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestGetModerationSettingsDefaults() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM moderation_settings").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id"}))
+
+	settings, err := suite.dbStore.GetModerationSettings(context.Background(), 1)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), DefaultModerationSettings(1), settings)
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestModerationSettingsValidate() {
+	ok, errFields := ModerationSettings{QuarantineReporterThreshold: -1}.Validate()
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), map[string]string{"quarantine_reporter_threshold": "Can't be negative"}, errFields)
+
+	ok, errFields = ModerationSettings{QuarantineReporterThreshold: 2}.Validate()
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), map[string]string{"quarantine_window_hours": "Must be greater than 0 when quarantine_reporter_threshold is set"}, errFields)
+
+	ok, _ = ModerationSettings{}.Validate()
+	assert.True(suite.T(), ok)
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestQuarantineRecognitionSuccess() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(DISTINCT reported_by\\) FROM reported_recognitions").
+		WithArgs(int64(8), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'hidden'").
+		WithArgs(int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition_quarantines").
+		WithArgs(int64(8), 3, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "recognition_id", "reporter_count", "quarantined_at", "reviewed_at", "moderation_id"}).
+			AddRow(1, 8, 3, 100, nil, nil))
+	suite.sqlmock.ExpectExec("INSERT INTO moderation_notifications").
+		WithArgs(1, int64(8), QuarantineNotification, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.sqlmock.ExpectCommit()
+
+	quarantine, err := suite.dbStore.QuarantineRecognition(context.Background(), 1, 8, DefaultModerationSettings(1))
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), &RecognitionQuarantine{ID: 1, RecognitionID: 8, ReporterCount: 3, QuarantinedAt: 100}, quarantine)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestQuarantineRecognitionBelowThreshold() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(DISTINCT reported_by\\) FROM reported_recognitions").
+		WithArgs(int64(8), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	suite.sqlmock.ExpectCommit()
+
+	quarantine, err := suite.dbStore.QuarantineRecognition(context.Background(), 1, 8, DefaultModerationSettings(1))
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), quarantine)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestQuarantineRecognitionAlreadyHidden() {
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectQuery("SELECT COUNT\\(DISTINCT reported_by\\) FROM reported_recognitions").
+		WithArgs(int64(8), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'hidden'").
+		WithArgs(int64(8)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectCommit()
+
+	quarantine, err := suite.dbStore.QuarantineRecognition(context.Background(), 1, 8, DefaultModerationSettings(1))
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), quarantine)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestQuarantineRecognitionWhenDisabled() {
+	quarantine, err := suite.dbStore.QuarantineRecognition(context.Background(), 1, 8, ModerationSettings{OrgID: 1})
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), quarantine)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *RecognitionQuarantineTestSuite) TestReadModerationNotificationNotFound() {
+	suite.sqlmock.ExpectQuery("UPDATE moderation_notifications").
+		WithArgs(int64(5), 1, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.dbStore.ReadModerationNotification(context.Background(), 1, 5)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+}
@@ -0,0 +1,280 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	secondsInDay = 24 * 60 * 60
+
+	getRecognitionRuleSettingsQuery = `SELECT org_id, allow_self_recognition, min_text_length,
+		recipient_daily_cap, reciprocal_limit, reciprocal_window_days FROM recognition_rule_settings WHERE org_id = $1`
+
+	upsertRecognitionRuleSettingsQuery = `INSERT INTO recognition_rule_settings (org_id, allow_self_recognition,
+		min_text_length, recipient_daily_cap, reciprocal_limit, reciprocal_window_days, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (org_id) DO UPDATE SET (allow_self_recognition, min_text_length,
+		recipient_daily_cap, reciprocal_limit, reciprocal_window_days, updated_at) =
+		(EXCLUDED.allow_self_recognition, EXCLUDED.min_text_length,
+		EXCLUDED.recipient_daily_cap, EXCLUDED.reciprocal_limit, EXCLUDED.reciprocal_window_days, EXCLUDED.updated_at)
+		RETURNING org_id, allow_self_recognition, min_text_length,
+		recipient_daily_cap, reciprocal_limit, reciprocal_window_days`
+
+	countRecognitionsGivenQuery = `SELECT COUNT(*) FROM recognitions WHERE given_by = $1 AND given_for = $2
+		AND status = 'published' AND given_at >= $3`
+)
+
+// RecognitionRuleSettings - per organization configuration of the anti-gaming rules.
+// A limit of 0 switches that rule off.
+type RecognitionRuleSettings struct {
+	OrgID                int  `db:"org_id" json:"org_id"`
+	AllowSelfRecognition bool `db:"allow_self_recognition" json:"allow_self_recognition"`
+	MinTextLength        int  `db:"min_text_length" json:"min_text_length"`
+	RecipientDailyCap    int  `db:"recipient_daily_cap" json:"recipient_daily_cap"`
+	ReciprocalLimit      int  `db:"reciprocal_limit" json:"reciprocal_limit"`
+	ReciprocalWindowDays int  `db:"reciprocal_window_days" json:"reciprocal_window_days"`
+}
+
+// DefaultRecognitionRuleSettings - settings used by organizations that haven't configured their own
+func DefaultRecognitionRuleSettings(orgID int) RecognitionRuleSettings {
+	return RecognitionRuleSettings{
+		OrgID:                orgID,
+		MinTextLength:        10,
+		RecipientDailyCap:    3,
+		ReciprocalLimit:      5,
+		ReciprocalWindowDays: 7,
+	}
+}
+
+// Validate - ensures none of the limits are negative and the reciprocal window is usable
+func (settings RecognitionRuleSettings) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if settings.MinTextLength < 0 {
+		errFields["min_text_length"] = "Can't be negative"
+	}
+	if settings.RecipientDailyCap < 0 {
+		errFields["recipient_daily_cap"] = "Can't be negative"
+	}
+	if settings.ReciprocalLimit < 0 {
+		errFields["reciprocal_limit"] = "Can't be negative"
+	}
+	if settings.ReciprocalLimit > 0 && settings.ReciprocalWindowDays <= 0 {
+		errFields["reciprocal_window_days"] = "Must be greater than 0 when reciprocal_limit is set"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// RecognitionRule - an anti-gaming check run before a recognition is created.
+// Violations are returned as field errors keyed by the offending request field.
+type RecognitionRule interface {
+	Check(context.Context, Storer, RecognitionRuleSettings, Recognition) (map[string]string, error)
+}
+
+// Hi5Rule - an anti-gaming check run before a Hi5 is given on a recognition
+type Hi5Rule interface {
+	Check(context.Context, Storer, RecognitionRuleSettings, Recognition, RecognitionHi5) (map[string]string, error)
+}
+
+// recognitionRules and hi5Rules are evaluated in order; add new rules here
+var recognitionRules = []RecognitionRule{
+	selfRecognitionRule{},
+	minTextLengthRule{},
+	recipientDailyCapRule{},
+	reciprocalPairRule{},
+}
+
+var hi5Rules = []Hi5Rule{
+	selfHi5Rule{},
+	recipientHi5Rule{},
+}
+
+// EvaluateRecognitionRules - runs every recognition rule and merges their field errors
+func EvaluateRecognitionRules(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition) (valid bool, errFields map[string]string, err error) {
+	errFields = make(map[string]string)
+	for _, rule := range recognitionRules {
+		var ruleErrFields map[string]string
+		ruleErrFields, err = rule.Check(ctx, store, settings, recognition)
+		if err != nil {
+			return
+		}
+		mergeErrFields(errFields, ruleErrFields)
+	}
+
+	valid = len(errFields) == 0
+	return
+}
+
+// EvaluateHi5Rules - runs every Hi5 rule and merges their field errors
+func EvaluateHi5Rules(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition, hi5 RecognitionHi5) (valid bool, errFields map[string]string, err error) {
+	errFields = make(map[string]string)
+	for _, rule := range hi5Rules {
+		var ruleErrFields map[string]string
+		ruleErrFields, err = rule.Check(ctx, store, settings, recognition, hi5)
+		if err != nil {
+			return
+		}
+		mergeErrFields(errFields, ruleErrFields)
+	}
+
+	valid = len(errFields) == 0
+	return
+}
+
+// mergeErrFields - keeps the first error reported for a field
+func mergeErrFields(errFields, ruleErrFields map[string]string) {
+	for field, message := range ruleErrFields {
+		if _, ok := errFields[field]; !ok {
+			errFields[field] = message
+		}
+	}
+}
+
+type selfRecognitionRule struct{}
+
+func (selfRecognitionRule) Check(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition) (errFields map[string]string, err error) {
+	if !settings.AllowSelfRecognition && recognition.GivenFor == recognition.GivenBy {
+		errFields = map[string]string{"given_for": "You can't recognize yourself"}
+	}
+	return
+}
+
+type minTextLengthRule struct{}
+
+func (minTextLengthRule) Check(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition) (errFields map[string]string, err error) {
+	if settings.MinTextLength > 0 && len([]rune(strings.TrimSpace(recognition.Text))) < settings.MinTextLength {
+		errFields = map[string]string{"text": fmt.Sprintf("text must be at least %d characters long", settings.MinTextLength)}
+	}
+	return
+}
+
+type recipientDailyCapRule struct{}
+
+func (recipientDailyCapRule) Check(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition) (errFields map[string]string, err error) {
+	if settings.RecipientDailyCap == 0 {
+		return
+	}
+
+	since := time.Now().Unix() - secondsInDay
+	count, err := store.CountRecognitionsGiven(ctx, recognition.GivenBy, recognition.GivenFor, since)
+	if err != nil {
+		return
+	}
+
+	if count >= settings.RecipientDailyCap {
+		errFields = map[string]string{"given_for": fmt.Sprintf("You can recognize the same person at most %d times a day", settings.RecipientDailyCap)}
+	}
+	return
+}
+
+type reciprocalPairRule struct{}
+
+func (reciprocalPairRule) Check(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition) (errFields map[string]string, err error) {
+	if settings.ReciprocalLimit == 0 {
+		return
+	}
+
+	since := time.Now().Unix() - int64(settings.ReciprocalWindowDays)*secondsInDay
+	given, err := store.CountRecognitionsGiven(ctx, recognition.GivenBy, recognition.GivenFor, since)
+	if err != nil {
+		return
+	}
+
+	received, err := store.CountRecognitionsGiven(ctx, recognition.GivenFor, recognition.GivenBy, since)
+	if err != nil {
+		return
+	}
+
+	// only pairs that recognize each other are limited; one-way recognitions fall under the daily cap
+	if received > 0 && given+received >= settings.ReciprocalLimit {
+		errFields = map[string]string{"given_for": fmt.Sprintf("You and this person have exchanged %d recognitions in the last %d days", given+received, settings.ReciprocalWindowDays)}
+	}
+	return
+}
+
+// selfHi5Rule and recipientHi5Rule apply to every organization, they can't be switched off
+type selfHi5Rule struct{}
+
+func (selfHi5Rule) Check(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition, hi5 RecognitionHi5) (errFields map[string]string, err error) {
+	if recognition.GivenBy == hi5.GivenBy {
+		errFields = map[string]string{"recognition_id": "You can't Hi5 your own recognition"}
+	}
+	return
+}
+
+type recipientHi5Rule struct{}
+
+func (recipientHi5Rule) Check(ctx context.Context, store Storer, settings RecognitionRuleSettings, recognition Recognition, hi5 RecognitionHi5) (errFields map[string]string, err error) {
+	if recognition.GivenFor == hi5.GivenBy {
+		errFields = map[string]string{"recognition_id": "You can't Hi5 a recognition given to you"}
+	}
+	return
+}
+
+// GetRecognitionRuleSettings - returns the organization's rule settings, or the defaults if none are saved
+func (s *pgStore) GetRecognitionRuleSettings(ctx context.Context, orgID int) (settings RecognitionRuleSettings, err error) {
+	err = s.db.GetContext(ctx, &settings, getRecognitionRuleSettingsQuery, orgID)
+	if err == sql.ErrNoRows {
+		settings = DefaultRecognitionRuleSettings(orgID)
+		err = nil
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while getting recognition rule settings")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) UpdateRecognitionRuleSettings(ctx context.Context, settings RecognitionRuleSettings) (updatedSettings RecognitionRuleSettings, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&updatedSettings,
+		upsertRecognitionRuleSettingsQuery,
+		settings.OrgID,
+		settings.AllowSelfRecognition,
+		settings.MinTextLength,
+		settings.RecipientDailyCap,
+		settings.ReciprocalLimit,
+		settings.ReciprocalWindowDays,
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"settings_params": settings,
+		}).Error("Error while updating recognition rule settings")
+		return
+	}
+
+	return
+}
+
+// CountRecognitionsGiven - number of published recognitions givenBy gave to givenFor since the given unix time
+func (s *pgStore) CountRecognitionsGiven(ctx context.Context, givenBy, givenFor int, since int64) (count int, err error) {
+	err = s.db.GetContext(ctx, &count, countRecognitionsGivenQuery, givenBy, givenFor, since)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"given_by":  givenBy,
+			"given_for": givenFor,
+		}).Error("Error while counting recognitions")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionRuleTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+	dbMock  *DBMockStore
+}
+
+func (suite *RecognitionRuleTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+	suite.dbMock = &DBMockStore{}
+}
+
+func (suite *RecognitionRuleTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *RecognitionRuleTestSuite) TestGetRecognitionRuleSettingsDefaults() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_rule_settings").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id"}))
+
+	settings, err := suite.dbStore.GetRecognitionRuleSettings(context.Background(), 1)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), DefaultRecognitionRuleSettings(1), settings)
+}
+
+func (suite *RecognitionRuleTestSuite) TestEvaluateRecognitionRulesSuccess() {
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	recognition := Recognition{GivenBy: 1, GivenFor: 2, Text: "Thanks for covering my on-call shift"}
+
+	valid, errFields, err := EvaluateRecognitionRules(context.Background(), suite.dbMock, DefaultRecognitionRuleSettings(1), recognition)
+
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), valid)
+	assert.Empty(suite.T(), errFields)
+}
+
+func (suite *RecognitionRuleTestSuite) TestEvaluateRecognitionRulesDailyCap() {
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, 1, 2, mock.Anything).Return(3, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, 2, 1, mock.Anything).Return(0, nil)
+	recognition := Recognition{GivenBy: 1, GivenFor: 2, Text: "Thanks for covering my on-call shift"}
+
+	valid, errFields, err := EvaluateRecognitionRules(context.Background(), suite.dbMock, DefaultRecognitionRuleSettings(1), recognition)
+
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), "You can recognize the same person at most 3 times a day", errFields["given_for"])
+}
+
+func (suite *RecognitionRuleTestSuite) TestEvaluateRecognitionRulesReciprocalPair() {
+	settings := DefaultRecognitionRuleSettings(1)
+	settings.RecipientDailyCap = 0
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, 1, 2, mock.Anything).Return(2, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, 2, 1, mock.Anything).Return(3, nil)
+	recognition := Recognition{GivenBy: 1, GivenFor: 2, Text: "Thanks for covering my on-call shift"}
+
+	valid, errFields, err := EvaluateRecognitionRules(context.Background(), suite.dbMock, settings, recognition)
+
+	assert.Nil(suite.T(), err)
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), "You and this person have exchanged 5 recognitions in the last 7 days", errFields["given_for"])
+}
+
+func (suite *RecognitionRuleTestSuite) TestEvaluateRecognitionRulesWhenSelfRecognitionAllowed() {
+	settings := RecognitionRuleSettings{OrgID: 1, AllowSelfRecognition: true}
+	recognition := Recognition{GivenBy: 1, GivenFor: 1, Text: "me"}
+
+	valid, _, err := EvaluateRecognitionRules(context.Background(), suite.dbMock, settings, recognition)
+
+	assert.Nil(suite.T(), err)
+	assert.True(suite.T(), valid)
+	suite.dbMock.AssertNotCalled(suite.T(), "CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionRuleTestSuite) TestValidateRecognitionRuleSettings() {
+	settings := RecognitionRuleSettings{OrgID: 1, RecipientDailyCap: -1, ReciprocalLimit: 2}
+
+	valid, errFields := settings.Validate()
+
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), "Can't be negative", errFields["recipient_daily_cap"])
+	assert.Contains(suite.T(), errFields, "reciprocal_window_days")
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	ae "joshsoftware/peerly/apperrors"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -40,19 +41,143 @@ func (suite *RecognitionTestSuite) TestCreateRecognitionSuccess() {
 
 	suite.sqlmock.ExpectBegin()
 
-	suite.sqlmock.ExpectExec("INSERT INTO recognition").
-		WithArgs(1, "Test Text", 1, 2, time.Now().Unix()).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition").
+		WithArgs(1, "Test Text", 1, 2, time.Now().Unix(), "published", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	suite.sqlmock.ExpectCommit()
 
 	result, err := suite.dbStore.CreateRecognition(context.Background(), recognition)
+
+	recognition.ID = 1
+	recognition.Status = RecognitionStatusPublished
+	recognition.GivenAt = result.GivenAt
 	assert.Equal(suite.T(), recognition, result)
 	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
 
 	assert.Nil(suite.T(), err)
 }
 
+func (suite *RecognitionTestSuite) TestCreateScheduledRecognitionSuccess() {
+	publishAt := time.Now().Add(time.Hour).Unix()
+	recognition := Recognition{
+		CoreValueID: 1,
+		Text:        "Test Text",
+		GivenFor:    1,
+		GivenBy:     2,
+		Status:      RecognitionStatusScheduled,
+		PublishAt:   &publishAt,
+	}
+
+	suite.sqlmock.ExpectBegin()
+
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition").
+		WithArgs(1, "Test Text", 1, 2, 0, "scheduled", publishAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	suite.sqlmock.ExpectCommit()
+
+	result, err := suite.dbStore.CreateRecognition(context.Background(), recognition)
+
+	assert.Equal(suite.T(), int64(0), result.GivenAt)
+	assert.Equal(suite.T(), RecognitionStatusScheduled, result.Status)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *RecognitionTestSuite) TestDeleteRecognitionDraftNotFound() {
+	suite.sqlmock.ExpectExec("DELETE FROM recognitions").
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.dbStore.DeleteRecognitionDraft(context.Background(), 1, 2)
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+// expectDueRecognition - the job finds recognition 3, scheduled by user 1 of organization 1 for user 2
+func (suite *RecognitionTestSuite) expectDueRecognition(text string, minTextLength int, blocklist ...string) {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognitions").
+		WithArgs(time.Now().Unix()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "core_value_id", "text", "given_for", "given_by", "given_at", "status", "publish_at", "org_id"}).
+			AddRow(3, 1, text, 2, 1, 0, RecognitionStatusScheduled, 100, 1))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM recognition_rule_settings").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"org_id", "allow_self_recognition", "min_text_length", "recipient_daily_cap", "reciprocal_limit", "reciprocal_window_days"}).
+			AddRow(1, false, minTextLength, 0, 0, 0))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM content_filter_settings").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"org_id"}))
+	terms := sqlmock.NewRows([]string{"term"})
+	for _, term := range blocklist {
+		terms.AddRow(term)
+	}
+	suite.sqlmock.ExpectQuery("SELECT term FROM content_blocklist_terms").
+		WithArgs(1).
+		WillReturnRows(terms)
+}
+
+func (suite *RecognitionTestSuite) TestPublishScheduledRecognitionsJob() {
+	suite.expectDueRecognition("Thanks for the help", 10)
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'published'").
+		WithArgs(3, "Thanks for the help").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// badges are evaluated for the recipient and then the giver
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM badges").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM badges").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err := suite.dbStore.PublishScheduledRecognitionsJob()
+
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *RecognitionTestSuite) TestPublishScheduledRecognitionsJobMovesRuleBreakingRecognitionToDrafts() {
+	// the organization raised its minimum text length after the recognition was scheduled
+	suite.expectDueRecognition("Thanks for the help", 50)
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'draft', publish_at = NULL").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.dbStore.PublishScheduledRecognitionsJob()
+
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *RecognitionTestSuite) TestPublishScheduledRecognitionsJobMovesRejectedContentToDrafts() {
+	// the term was blocked after the recognition was scheduled
+	suite.expectDueRecognition("Thanks for the help, slacker", 10, "slacker")
+	suite.sqlmock.ExpectExec("UPDATE recognitions SET status = 'draft', publish_at = NULL").
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.dbStore.PublishScheduledRecognitionsJob()
+
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+	assert.Nil(suite.T(), err)
+}
+
+func (suite *RecognitionTestSuite) TestValidateScheduledRecognitionWithoutPublishAt() {
+	recognition := Recognition{
+		CoreValueID: 1,
+		Text:        "Test Text",
+		GivenFor:    1,
+		GivenBy:     2,
+		Status:      RecognitionStatusScheduled,
+	}
+
+	valid, errFields := recognition.ValidateRecognition()
+
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), "publish_at must be present for a scheduled recognition", errFields["publish_at"])
+}
+
 func (suite *RecognitionHi5TestSuite) TestCreateRecognitionFailure() {
 	recognition := Recognition{
 		CoreValueID: 1,
@@ -65,9 +190,9 @@ func (suite *RecognitionHi5TestSuite) TestCreateRecognitionFailure() {
 
 	suite.sqlmock.ExpectBegin()
 
-	suite.sqlmock.ExpectExec("INSERT INTO recognition").
-		WithArgs(1, "Test Text", 1, 2, time.Now().Unix()).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO recognition").
+		WithArgs(1, "Test Text", 1, 2, time.Now().Unix(), "published", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	suite.sqlmock.ExpectRollback()
 
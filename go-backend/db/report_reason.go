@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/lib/pq"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	reportReasonColumns = `id, org_id, name, label, description, severity, triggers_quarantine, archived_at`
+
+	// $2 - whether archived reasons are listed too
+	listReportReasonsQuery = `SELECT ` + reportReasonColumns + ` FROM report_reasons
+		WHERE org_id = $1 AND ($2 OR archived_at IS NULL) ORDER BY id`
+
+	// organizations created after the reasons were made configurable start with the defaults
+	seedReportReasonsQuery = `INSERT INTO report_reasons (org_id, name, label, description, severity,
+		triggers_quarantine, created_at, updated_at)
+		SELECT $1, d.name, d.label, d.description, d.severity, d.triggers_quarantine, $7, $7
+		FROM unnest($2::text[], $3::text[], $4::text[], $5::text[], $6::boolean[])
+		AS d(name, label, description, severity, triggers_quarantine)
+		WHERE NOT EXISTS (SELECT 1 FROM report_reasons WHERE org_id = $1)
+		ON CONFLICT (org_id, name) DO NOTHING`
+
+	// creating a reason with the name of an archived one brings it back
+	createReportReasonQuery = `INSERT INTO report_reasons (org_id, name, label, description, severity,
+		triggers_quarantine, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (org_id, name) DO UPDATE SET (label, description, severity, triggers_quarantine, archived_at, updated_at) =
+		(EXCLUDED.label, EXCLUDED.description, EXCLUDED.severity, EXCLUDED.triggers_quarantine, NULL, EXCLUDED.updated_at)
+		WHERE report_reasons.archived_at IS NOT NULL
+		RETURNING ` + reportReasonColumns
+
+	updateReportReasonQuery = `UPDATE report_reasons SET (label, description, severity, triggers_quarantine, updated_at) =
+		($3, $4, $5, $6, $7) WHERE id = $1 AND org_id = $2 AND archived_at IS NULL
+		RETURNING ` + reportReasonColumns
+
+	archiveReportReasonQuery = `UPDATE report_reasons SET (archived_at, updated_at) = ($3, $3)
+		WHERE id = $1 AND org_id = $2 AND archived_at IS NULL`
+)
+
+// REPORT_REASON_SEVERITIES - from least to most severe
+var REPORT_REASON_SEVERITIES = []string{"low", "medium", "high"}
+
+var reportReasonNameFormat = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ReportReason - a reason users of the organization can give when reporting a recognition.
+// Reports refer to the reason by its name, which can't change once the reason is created.
+type ReportReason struct {
+	ID          int64  `db:"id" json:"id"`
+	OrgID       int    `db:"org_id" json:"org_id"`
+	Name        string `db:"name" json:"name"`
+	Label       string `db:"label" json:"label"`
+	Description string `db:"description" json:"description"`
+	Severity    string `db:"severity" json:"severity"`
+	// TriggersQuarantine - whether reports for this reason count towards the quarantine threshold
+	TriggersQuarantine bool `db:"triggers_quarantine" json:"triggers_quarantine"`
+	// ArchivedAt - archived reasons can't be given for new reports but old ones still refer to them
+	ArchivedAt *time.Time `db:"archived_at" json:"archived_at,omitempty"`
+}
+
+// DefaultReportReasons - the reasons every organization starts with
+func DefaultReportReasons(orgID int) []ReportReason {
+	return []ReportReason{
+		{OrgID: orgID, Name: "fraud", Label: "Fraud", Description: "The recognition is made up or given in exchange for something", Severity: "high", TriggersQuarantine: true},
+		{OrgID: orgID, Name: "not_relevant", Label: "Not relevant", Description: "The recognition has nothing to do with work", Severity: "low", TriggersQuarantine: true},
+		{OrgID: orgID, Name: "incorrect", Label: "Incorrect", Description: "The recognition gets the facts or the person wrong", Severity: "medium", TriggersQuarantine: true},
+	}
+}
+
+// Validate - the name is only checked for new reasons since it can't be changed afterwards
+func (reason ReportReason) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if reason.ID == 0 {
+		if reason.Name == "" {
+			errFields["name"] = "Can't be blank"
+		} else if !reportReasonNameFormat.MatchString(reason.Name) {
+			errFields["name"] = "Must start with a letter and have at most 50 lowercase letters, digits or underscores"
+		}
+	}
+
+	if reason.Label == "" {
+		errFields["label"] = "Can't be blank"
+	}
+
+	if !include(REPORT_REASON_SEVERITIES, reason.Severity) {
+		errFields["severity"] = "Must be one of low, medium or high"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// ListReportReasons - the organization's report reasons, seeding the defaults for organizations that have none
+func (s *pgStore) ListReportReasons(ctx context.Context, orgID int, includeArchived bool) (reasons []ReportReason, err error) {
+	reasons = make([]ReportReason, 0)
+	err = s.db.SelectContext(ctx, &reasons, listReportReasonsQuery, orgID, includeArchived)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing report reasons")
+		return
+	}
+
+	if len(reasons) > 0 {
+		return
+	}
+
+	seeded, err := s.seedReportReasons(ctx, orgID)
+	if err != nil || !seeded {
+		return
+	}
+
+	err = s.db.SelectContext(ctx, &reasons, listReportReasonsQuery, orgID, includeArchived)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing report reasons")
+		return
+	}
+
+	return
+}
+
+// seedReportReasons - saves the default reasons for an organization that has none, archived or not
+func (s *pgStore) seedReportReasons(ctx context.Context, orgID int) (seeded bool, err error) {
+	defaults := DefaultReportReasons(orgID)
+	var names, labels, descriptions, severities []string
+	var triggersQuarantine []bool
+	for _, reason := range defaults {
+		names = append(names, reason.Name)
+		labels = append(labels, reason.Label)
+		descriptions = append(descriptions, reason.Description)
+		severities = append(severities, reason.Severity)
+		triggersQuarantine = append(triggersQuarantine, reason.TriggersQuarantine)
+	}
+
+	result, err := s.db.ExecContext(
+		ctx,
+		seedReportReasonsQuery,
+		orgID,
+		pq.Array(names),
+		pq.Array(labels),
+		pq.Array(descriptions),
+		pq.Array(severities),
+		pq.Array(triggersQuarantine),
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while seeding report reasons")
+		return
+	}
+
+	count, err := result.RowsAffected()
+	seeded = count > 0
+	return
+}
+
+// CreateReportReason - adds a report reason to the organization, or brings back the archived reason
+// of the same name. Fails with ErrReportReasonExists if an active reason already has the name.
+func (s *pgStore) CreateReportReason(ctx context.Context, reason ReportReason) (createdReason ReportReason, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&createdReason,
+		createReportReasonQuery,
+		reason.OrgID,
+		reason.Name,
+		reason.Label,
+		reason.Description,
+		reason.Severity,
+		reason.TriggersQuarantine,
+		time.Now(),
+	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrReportReasonExists
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"reason_params": reason,
+		}).Error("Error while creating report reason")
+		return
+	}
+
+	return
+}
+
+// UpdateReportReason - changes everything but the name of an active report reason of the organization.
+// Fails with ErrRecordNotFound if the organization has no such active reason.
+func (s *pgStore) UpdateReportReason(ctx context.Context, reason ReportReason) (updatedReason ReportReason, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&updatedReason,
+		updateReportReasonQuery,
+		reason.ID,
+		reason.OrgID,
+		reason.Label,
+		reason.Description,
+		reason.Severity,
+		reason.TriggersQuarantine,
+		time.Now(),
+	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrRecordNotFound
+		return
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"reason_params": reason,
+		}).Error("Error while updating report reason")
+		return
+	}
+
+	return
+}
+
+// ArchiveReportReason - stops users from giving the reason for new reports.
+// Fails with ErrRecordNotFound if the organization has no such active reason.
+func (s *pgStore) ArchiveReportReason(ctx context.Context, orgID int, reasonID int64) (err error) {
+	result, err := s.db.ExecContext(ctx, archiveReportReasonQuery, reasonID, orgID, time.Now())
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":       err.Error(),
+			"reason_id": reasonID,
+		}).Error("Error while archiving report reason")
+		return
+	}
+
+	err = checkRowsAffected(result)
+	return
+}
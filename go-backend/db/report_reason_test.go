@@ -0,0 +1,139 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportReasonTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *ReportReasonTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *ReportReasonTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+var reportReasonTestColumns = []string{"id", "org_id", "name", "label", "description", "severity", "triggers_quarantine", "archived_at"}
+
+func (suite *ReportReasonTestSuite) TestReportReasonValidate() {
+	ok, errFields := ReportReason{Name: "Spam!", Severity: "critical"}.Validate()
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), map[string]string{
+		"name":     "Must start with a letter and have at most 50 lowercase letters, digits or underscores",
+		"label":    "Can't be blank",
+		"severity": "Must be one of low, medium or high",
+	}, errFields)
+
+	// the name can't change once the reason exists
+	ok, _ = ReportReason{ID: 4, Label: "Spam", Severity: "low"}.Validate()
+	assert.True(suite.T(), ok)
+}
+
+func (suite *ReportReasonTestSuite) TestReportedRecognitionValidateAgainstReasons() {
+	archivedAt := time.Now()
+	reasons := []ReportReason{{Name: "fraud"}, {Name: "spam", ArchivedAt: &archivedAt}}
+
+	ok, _ := (&ReportedRecognition{TypeOfReporting: "Fraud", ReasonForReporting: "Made up"}).Validate(reasons)
+	assert.True(suite.T(), ok)
+
+	ok, errFields := (&ReportedRecognition{TypeOfReporting: "spam", ReasonForReporting: "Ad"}).Validate(reasons)
+	assert.False(suite.T(), ok)
+	assert.Equal(suite.T(), "Invalid reported recognition type", errFields["mark_as"])
+}
+
+func (suite *ReportReasonTestSuite) TestListReportReasons() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM report_reasons").
+		WithArgs(1, false).
+		WillReturnRows(suite.sqlmock.NewRows(reportReasonTestColumns).
+			AddRow(1, 1, "fraud", "Fraud", "", "high", true, nil))
+
+	reasons, err := suite.dbStore.ListReportReasons(context.Background(), 1, false)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []ReportReason{{ID: 1, OrgID: 1, Name: "fraud", Label: "Fraud", Severity: "high", TriggersQuarantine: true}}, reasons)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *ReportReasonTestSuite) TestListReportReasonsSeedsDefaults() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM report_reasons").
+		WithArgs(2, false).
+		WillReturnRows(suite.sqlmock.NewRows(reportReasonTestColumns))
+	suite.sqlmock.ExpectExec("INSERT INTO report_reasons").
+		WithArgs(2, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM report_reasons").
+		WithArgs(2, false).
+		WillReturnRows(suite.sqlmock.NewRows(reportReasonTestColumns).
+			AddRow(4, 2, "fraud", "Fraud", "", "high", true, nil).
+			AddRow(5, 2, "not_relevant", "Not relevant", "", "low", true, nil).
+			AddRow(6, 2, "incorrect", "Incorrect", "", "medium", true, nil))
+
+	reasons, err := suite.dbStore.ListReportReasons(context.Background(), 2, false)
+
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), reasons, 3)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *ReportReasonTestSuite) TestListReportReasonsWhenAllArchived() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM report_reasons").
+		WithArgs(1, false).
+		WillReturnRows(suite.sqlmock.NewRows(reportReasonTestColumns))
+	suite.sqlmock.ExpectExec("INSERT INTO report_reasons").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	reasons, err := suite.dbStore.ListReportReasons(context.Background(), 1, false)
+
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), reasons)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *ReportReasonTestSuite) TestCreateReportReasonWhenActiveReasonExists() {
+	suite.sqlmock.ExpectQuery("INSERT INTO report_reasons").
+		WithArgs(1, "spam", "Spam", "", "low", false, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.dbStore.CreateReportReason(context.Background(), ReportReason{OrgID: 1, Name: "spam", Label: "Spam", Severity: "low"})
+
+	assert.Equal(suite.T(), ae.ErrReportReasonExists, err)
+}
+
+func (suite *ReportReasonTestSuite) TestUpdateReportReasonNotFound() {
+	suite.sqlmock.ExpectQuery("UPDATE report_reasons").
+		WithArgs(int64(9), 1, "Spam", "", "low", true, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := suite.dbStore.UpdateReportReason(context.Background(), ReportReason{ID: 9, OrgID: 1, Label: "Spam", Severity: "low", TriggersQuarantine: true})
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+}
+
+func (suite *ReportReasonTestSuite) TestArchiveReportReason() {
+	suite.sqlmock.ExpectExec("UPDATE report_reasons SET \\(archived_at, updated_at\\)").
+		WithArgs(int64(4), 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.dbStore.ArchiveReportReason(context.Background(), 1, 4)
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
@@ -2,18 +2,38 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"strings"
 	"time"
 
+	ae "joshsoftware/peerly/apperrors"
+
 	logger "github.com/sirupsen/logrus"
 )
 
-var REPORTED_RECOGNITION_TYPE = []string{"fraud", "not_relevant", "incorrect"}
+const (
+	// ReportOpenStatus - the report is waiting for a moderator
+	ReportOpenStatus = "open"
+	// ReportInReviewStatus - a moderator has been assigned to the reported recognition
+	ReportInReviewStatus = "in_review"
+	// ReportResolvedStatus - a moderation decision has been taken on the reported recognition
+	ReportResolvedStatus = "resolved"
+)
+
+// REPORT_STATUSES - in the order a report moves through them
+var REPORT_STATUSES = []string{ReportOpenStatus, ReportInReviewStatus, ReportResolvedStatus}
 
 const (
+	// a user can only have one unresolved report on a recognition
 	createReportedRecognitionQuery = `INSERT INTO reported_recognitions (recognition_id, type_of_reporting,
 		reason_for_reporting, reported_by, reported_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, recognition_id, type_of_reporting, reason_for_reporting, reported_by, reported_at, created_at, updated_at`
+		ON CONFLICT (recognition_id, reported_by) WHERE status <> 'resolved' DO NOTHING
+		RETURNING id, recognition_id, type_of_reporting, reason_for_reporting, reported_by, reported_at, status, created_at, updated_at`
+
+	listReviewedReportTypesQuery = `SELECT DISTINCT type_of_reporting FROM reported_recognitions
+		WHERE recognition_id = $1 AND status = 'resolved' AND moderation_id IS NOT NULL ORDER BY type_of_reporting`
+
+	countReportsByQuery = `SELECT COUNT(*) FROM reported_recognitions WHERE reported_by = $1 AND reported_at >= $2`
 )
 
 type ReportedRecognition struct {
@@ -23,6 +43,7 @@ type ReportedRecognition struct {
 	ReasonForReporting string    `db:"reason_for_reporting" json:"reason"`
 	ReportedBy         int64     `db:"reported_by" json:"reported_by"`
 	ReportedAt         int64     `db:"reported_at" json:"reported_at"`
+	Status             string    `db:"status" json:"status"`
 	CreatedAt          time.Time `db:"created_at" json:"-"`
 	UpdatedAt          time.Time `db:"updated_at" json:"-"`
 }
@@ -36,14 +57,21 @@ func include(slice []string, val string) bool {
 	return false
 }
 
-func (reportedRecognition *ReportedRecognition) Validate() (valid bool, errFields map[string]string) {
+// Validate - the report has to be for one of the organization's active report reasons
+func (reportedRecognition *ReportedRecognition) Validate(reasons []ReportReason) (valid bool, errFields map[string]string) {
 	errFields = make(map[string]string)
 
 	if reportedRecognition.TypeOfReporting == "" {
 		errFields["mark_as"] = "Can't be blank"
 	} else {
 		reportedRecognition.TypeOfReporting = strings.ToLower(reportedRecognition.TypeOfReporting)
-		ok := include(REPORTED_RECOGNITION_TYPE, reportedRecognition.TypeOfReporting)
+		ok := false
+		for _, reason := range reasons {
+			if reason.ArchivedAt == nil && reason.Name == reportedRecognition.TypeOfReporting {
+				ok = true
+				break
+			}
+		}
 		if !ok {
 			errFields["mark_as"] = "Invalid reported recognition type"
 		}
@@ -59,6 +87,27 @@ func (reportedRecognition *ReportedRecognition) Validate() (valid bool, errField
 	return
 }
 
+// EvaluateReportRules - users can't report their own recognitions, and once a moderator has reviewed
+// a recognition it can only be reported again for a reason the moderator hasn't looked at yet
+func EvaluateReportRules(recognition Recognition, reportedRecognition ReportedRecognition, reviewedTypes []string) (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	if int64(recognition.GivenBy) == reportedRecognition.ReportedBy {
+		errFields["recognition_id"] = "You can't report your own recognition"
+	}
+
+	if include(reviewedTypes, reportedRecognition.TypeOfReporting) {
+		errFields["mark_as"] = "Recognition was already reviewed for this reason"
+	}
+
+	if len(errFields) == 0 {
+		valid = true
+	}
+	return
+}
+
+// CreateReportedRecognition - files a report on the recognition. Fails with ErrAlreadyReported
+// if the reporter has a report on the recognition that isn't resolved yet.
 func (s *pgStore) CreateReportedRecognition(ctx context.Context, recognitionID int64, reportedRecognition ReportedRecognition) (resp ReportedRecognition, err error) {
 	now := time.Now()
 	err = s.db.GetContext(
@@ -73,6 +122,10 @@ func (s *pgStore) CreateReportedRecognition(ctx context.Context, recognitionID i
 		now,
 		now,
 	)
+	if err == sql.ErrNoRows {
+		err = ae.ErrAlreadyReported
+		return
+	}
 	if err != nil {
 		logger.WithFields(logger.Fields{
 			"err":                         err.Error(),
@@ -84,3 +137,32 @@ func (s *pgStore) CreateReportedRecognition(ctx context.Context, recognitionID i
 
 	return
 }
+
+// ListReviewedReportTypes - types of the reports a moderation decision on the recognition resolved
+func (s *pgStore) ListReviewedReportTypes(ctx context.Context, recognitionID int64) (types []string, err error) {
+	types = make([]string, 0)
+	err = s.db.SelectContext(ctx, &types, listReviewedReportTypesQuery, recognitionID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while listing reviewed report types")
+		return
+	}
+
+	return
+}
+
+// CountReportsBy - number of reports the user filed since the given unix time
+func (s *pgStore) CountReportsBy(ctx context.Context, reportedBy int64, since int64) (count int, err error) {
+	err = s.db.GetContext(ctx, &count, countReportsByQuery, reportedBy, since)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":         err.Error(),
+			"reported_by": reportedBy,
+		}).Error("Error while counting reports")
+		return
+	}
+
+	return
+}
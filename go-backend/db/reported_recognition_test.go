@@ -6,6 +6,7 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	ae "joshsoftware/peerly/apperrors"
 	"time"
 )
 
@@ -32,8 +33,8 @@ func (suite *ReportedRecognitionTestSuite) TearDownTest() {
 }
 
 func (suite *ReportedRecognitionTestSuite) getMockedRows() (mockedRows *sqlmock.Rows) {
-	mockedRows = suite.sqlmock.NewRows([]string{"id", "recognition_id", "type_of_reporting", "reason_for_reporting", "reported_by", "reported_at", "created_at", "updated_at"}).
-		AddRow(1, 1, "fraud", "Test Reason", 1, now.Unix(), now, now)
+	mockedRows = suite.sqlmock.NewRows([]string{"id", "recognition_id", "type_of_reporting", "reason_for_reporting", "reported_by", "reported_at", "status", "created_at", "updated_at"}).
+		AddRow(1, 1, "fraud", "Test Reason", 1, now.Unix(), "open", now, now)
 	return
 }
 
@@ -45,6 +46,7 @@ func (suite *ReportedRecognitionTestSuite) TestCreateReportedRecognitionSuccess(
 		ReasonForReporting: "Test Reason",
 		ReportedBy:         int64(1),
 		ReportedAt:         now.Unix(),
+		Status:             "open",
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
@@ -82,3 +84,43 @@ func (suite *ReportedRecognitionTestSuite) TestCreateReportedRecognitionFailure(
 	assert.Equal(suite.T(), ReportedRecognition{}, resp)
 	assert.NotNil(suite.T(), err)
 }
+
+func (suite *ReportedRecognitionTestSuite) TestCreateReportedRecognitionWhenAlreadyReported() {
+	suite.sqlmock.ExpectQuery("INSERT INTO reported_recognitions").
+		WithArgs(1, "fraud", "Test Reason", 1, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id"}))
+
+	_, err := suite.dbStore.CreateReportedRecognition(context.Background(), 1, ReportedRecognition{
+		TypeOfReporting:    "fraud",
+		ReasonForReporting: "Test Reason",
+		ReportedBy:         int64(1),
+	})
+
+	assert.Equal(suite.T(), ae.ErrAlreadyReported, err)
+}
+
+func (suite *ReportedRecognitionTestSuite) TestEvaluateReportRules() {
+	recognition := Recognition{ID: 1, GivenBy: 2, GivenFor: 3}
+
+	valid, errFields := EvaluateReportRules(recognition, ReportedRecognition{TypeOfReporting: "incorrect", ReportedBy: 3}, []string{"fraud"})
+	assert.True(suite.T(), valid)
+	assert.Empty(suite.T(), errFields)
+
+	valid, errFields = EvaluateReportRules(recognition, ReportedRecognition{TypeOfReporting: "fraud", ReportedBy: 2}, []string{"fraud"})
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"recognition_id": "You can't report your own recognition",
+		"mark_as":        "Recognition was already reviewed for this reason",
+	}, errFields)
+}
+
+func (suite *ReportedRecognitionTestSuite) TestListReviewedReportTypes() {
+	suite.sqlmock.ExpectQuery("SELECT DISTINCT type_of_reporting FROM reported_recognitions").
+		WithArgs(int64(1)).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"type_of_reporting"}).AddRow("fraud").AddRow("incorrect"))
+
+	types, err := suite.dbStore.ListReviewedReportTypes(context.Background(), 1)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []string{"fraud", "incorrect"}, types)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"strconv"
 
 	logger "github.com/sirupsen/logrus"
 )
@@ -21,7 +22,7 @@ type Role struct {
 func (s *pgStore) GetRoleByID(ctx context.Context, id int) (role Role, err error) {
 	err = s.db.Get(&role, getRoleByIDQuery, id)
 	if err != nil {
-		logger.WithField("err", err.Error()).Error("Error selecting role from database in GetRoleByID for id ", string(id))
+		logger.WithField("err", err.Error()).Error("Error selecting role from database in GetRoleByID for id ", strconv.Itoa(id))
 		return
 	}
 	return
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/lib/pq"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	getOrganizationDefaultLocaleQuery = `SELECT default_locale FROM organizations WHERE id = $1`
+
+	listCoreValueTranslationsQuery = `SELECT core_value_id, locale, text, description
+		FROM core_value_translations WHERE core_value_id = $1 ORDER BY locale`
+
+	saveCoreValueTranslationQuery = `INSERT INTO core_value_translations (core_value_id, locale, text, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (core_value_id, locale) DO UPDATE SET (text, description, updated_at) = ($3, $4, $5)
+		RETURNING core_value_id, locale, text, description`
+
+	deleteCoreValueTranslationQuery = `DELETE FROM core_value_translations WHERE core_value_id = $1 AND locale = $2`
+
+	listOrganizationCoreValueTranslationsQuery = `SELECT t.core_value_id, t.locale, t.text, t.description
+		FROM core_value_translations t JOIN core_values cv ON cv.id = t.core_value_id
+		WHERE cv.org_id = $1 AND t.locale = ANY($2)`
+
+	listBadgeTranslationsQuery = `SELECT badge_id, locale, name
+		FROM badge_translations WHERE badge_id = $1 ORDER BY locale`
+
+	saveBadgeTranslationQuery = `INSERT INTO badge_translations (badge_id, locale, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (badge_id, locale) DO UPDATE SET (name, updated_at) = ($3, $4)
+		RETURNING badge_id, locale, name`
+
+	deleteBadgeTranslationQuery = `DELETE FROM badge_translations WHERE badge_id = $1 AND locale = $2`
+
+	listOrganizationBadgeTranslationsQuery = `SELECT t.badge_id, t.locale, t.name
+		FROM badge_translations t JOIN badges b ON b.id = t.badge_id
+		WHERE b.org_id = $1 AND t.locale = ANY($2)`
+)
+
+// CoreValueTranslation - the text and description of a core value in another locale
+type CoreValueTranslation struct {
+	CoreValueID int64  `db:"core_value_id" json:"core_value_id"`
+	Locale      string `db:"locale" json:"locale"`
+	Text        string `db:"text" json:"text"`
+	Description string `db:"description" json:"description"`
+}
+
+// Validate - checks the translation and brings its locale to the canonical form it is stored in
+func (translation *CoreValueTranslation) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	locale, ok := CanonicalLocale(translation.Locale)
+	if ok {
+		translation.Locale = locale
+	} else {
+		errFields["locale"] = "Please enter a valid locale, e.g. en or de-DE"
+	}
+	if strings.TrimSpace(translation.Text) == "" {
+		errFields["text"] = "Can't be blank"
+	}
+	if strings.TrimSpace(translation.Description) == "" {
+		errFields["description"] = "Can't be blank"
+	}
+
+	valid = len(errFields) == 0
+	return
+}
+
+// BadgeTranslation - the name of a badge in another locale
+type BadgeTranslation struct {
+	BadgeID int    `db:"badge_id" json:"badge_id"`
+	Locale  string `db:"locale" json:"locale"`
+	Name    string `db:"name" json:"name"`
+}
+
+// Validate - checks the translation and brings its locale to the canonical form it is stored in
+func (translation *BadgeTranslation) Validate() (valid bool, errFields map[string]string) {
+	errFields = make(map[string]string)
+
+	locale, ok := CanonicalLocale(translation.Locale)
+	if ok {
+		translation.Locale = locale
+	} else {
+		errFields["locale"] = "Please enter a valid locale, e.g. en or de-DE"
+	}
+	if strings.TrimSpace(translation.Name) == "" {
+		errFields["name"] = "Can't be blank"
+	}
+
+	valid = len(errFields) == 0
+	return
+}
+
+func (s *pgStore) ListCoreValueTranslations(ctx context.Context, coreValueID int64) (translations []CoreValueTranslation, err error) {
+	translations = make([]CoreValueTranslation, 0)
+	err = s.db.SelectContext(ctx, &translations, listCoreValueTranslationsQuery, coreValueID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"core_value_id": coreValueID,
+		}).Error("Error while listing core value translations")
+		return
+	}
+
+	return
+}
+
+// SaveCoreValueTranslation - adds the translation, or replaces the one already there for its locale
+func (s *pgStore) SaveCoreValueTranslation(ctx context.Context, translation CoreValueTranslation) (resp CoreValueTranslation, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&resp,
+		saveCoreValueTranslationQuery,
+		translation.CoreValueID,
+		translation.Locale,
+		strings.TrimSpace(translation.Text),
+		strings.TrimSpace(translation.Description),
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":                err.Error(),
+			"translation_params": translation,
+		}).Error("Error while saving core value translation")
+		return
+	}
+
+	return
+}
+
+// DeleteCoreValueTranslation - fails with ErrRecordNotFound when the core value has no translation for the locale
+func (s *pgStore) DeleteCoreValueTranslation(ctx context.Context, coreValueID int64, locale string) (err error) {
+	result, err := s.db.ExecContext(ctx, deleteCoreValueTranslationQuery, coreValueID, locale)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":           err.Error(),
+			"core_value_id": coreValueID,
+			"locale":        locale,
+		}).Error("Error while deleting core value translation")
+		return
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if deleted == 0 {
+		err = ae.ErrRecordNotFound
+	}
+	return
+}
+
+// PreferredCoreValueTranslations - the best translation of each of the organization's core values for
+// the locales, most preferred first. Core values left out are best shown untranslated.
+func (s *pgStore) PreferredCoreValueTranslations(ctx context.Context, organisationID int64, locales []string) (translations map[int64]CoreValueTranslation, err error) {
+	translations = make(map[int64]CoreValueTranslation)
+
+	locales, err = s.translatableLocales(ctx, int(organisationID), locales)
+	if err != nil || len(locales) == 0 {
+		return
+	}
+
+	var candidates []CoreValueTranslation
+	err = s.db.SelectContext(ctx, &candidates, listOrganizationCoreValueTranslationsQuery, organisationID, pq.Array(locales))
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": organisationID,
+		}).Error("Error while listing core value translations")
+		return
+	}
+
+	for _, candidate := range candidates {
+		best, found := translations[candidate.CoreValueID]
+		if !found || localeRank(locales, candidate.Locale) < localeRank(locales, best.Locale) {
+			translations[candidate.CoreValueID] = candidate
+		}
+	}
+	return
+}
+
+func (s *pgStore) ListBadgeTranslations(ctx context.Context, badgeID int) (translations []BadgeTranslation, err error) {
+	translations = make([]BadgeTranslation, 0)
+	err = s.db.SelectContext(ctx, &translations, listBadgeTranslationsQuery, badgeID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":      err.Error(),
+			"badge_id": badgeID,
+		}).Error("Error while listing badge translations")
+		return
+	}
+
+	return
+}
+
+// SaveBadgeTranslation - adds the translation, or replaces the one already there for its locale
+func (s *pgStore) SaveBadgeTranslation(ctx context.Context, translation BadgeTranslation) (resp BadgeTranslation, err error) {
+	err = s.db.GetContext(
+		ctx,
+		&resp,
+		saveBadgeTranslationQuery,
+		translation.BadgeID,
+		translation.Locale,
+		strings.TrimSpace(translation.Name),
+		time.Now(),
+	)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":                err.Error(),
+			"translation_params": translation,
+		}).Error("Error while saving badge translation")
+		return
+	}
+
+	return
+}
+
+// DeleteBadgeTranslation - fails with ErrRecordNotFound when the badge has no translation for the locale
+func (s *pgStore) DeleteBadgeTranslation(ctx context.Context, badgeID int, locale string) (err error) {
+	result, err := s.db.ExecContext(ctx, deleteBadgeTranslationQuery, badgeID, locale)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":      err.Error(),
+			"badge_id": badgeID,
+			"locale":   locale,
+		}).Error("Error while deleting badge translation")
+		return
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return
+	}
+	if deleted == 0 {
+		err = ae.ErrRecordNotFound
+	}
+	return
+}
+
+// PreferredBadgeTranslations - the best translation of each of the organization's badges for
+// the locales, most preferred first. Badges left out are best shown untranslated.
+func (s *pgStore) PreferredBadgeTranslations(ctx context.Context, orgID int, locales []string) (translations map[int]BadgeTranslation, err error) {
+	translations = make(map[int]BadgeTranslation)
+
+	locales, err = s.translatableLocales(ctx, orgID, locales)
+	if err != nil || len(locales) == 0 {
+		return
+	}
+
+	var candidates []BadgeTranslation
+	err = s.db.SelectContext(ctx, &candidates, listOrganizationBadgeTranslationsQuery, orgID, pq.Array(locales))
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while listing badge translations")
+		return
+	}
+
+	for _, candidate := range candidates {
+		best, found := translations[candidate.BadgeID]
+		if !found || localeRank(locales, candidate.Locale) < localeRank(locales, best.Locale) {
+			translations[candidate.BadgeID] = candidate
+		}
+	}
+	return
+}
+
+// translatableLocales - the preferred locales that come before the organization's default locale
+func (s *pgStore) translatableLocales(ctx context.Context, orgID int, locales []string) (translatable []string, err error) {
+	var defaultLocale string
+	err = s.db.GetContext(ctx, &defaultLocale, getOrganizationDefaultLocaleQuery, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"org_id": orgID,
+		}).Error("Error while getting organization default locale")
+		return
+	}
+
+	translatable = preferredLocales(locales, defaultLocale)
+	return
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TranslationTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *TranslationTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *TranslationTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *TranslationTestSuite) TestCanonicalLocale() {
+	locale, ok := CanonicalLocale("de_de")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "de-DE", locale)
+
+	locale, ok = CanonicalLocale("JA")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "ja", locale)
+
+	_, ok = CanonicalLocale("not a locale")
+	assert.False(suite.T(), ok)
+
+	_, ok = CanonicalLocale("")
+	assert.False(suite.T(), ok)
+}
+
+func (suite *TranslationTestSuite) TestPreferredLocales() {
+	assert.Equal(suite.T(), []string{"de-DE", "de"}, preferredLocales([]string{"de-DE", "de", "en", "ja"}, "en"))
+	assert.Empty(suite.T(), preferredLocales([]string{"en", "de"}, "en"))
+	assert.Equal(suite.T(), []string{"ja"}, preferredLocales([]string{"ja"}, "en"))
+}
+
+func (suite *TranslationTestSuite) TestValidate() {
+	translation := CoreValueTranslation{Locale: "de_de", Text: " ", Description: "Zusammenarbeit"}
+	valid, errFields := translation.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{"text": "Can't be blank"}, errFields)
+	assert.Equal(suite.T(), "de-DE", translation.Locale)
+
+	badgeTranslation := BadgeTranslation{Locale: "??", Name: "Teamplayer"}
+	valid, errFields = badgeTranslation.Validate()
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{"locale": "Please enter a valid locale, e.g. en or de-DE"}, errFields)
+}
+
+func (suite *TranslationTestSuite) TestPreferredCoreValueTranslations() {
+	suite.sqlmock.ExpectQuery("SELECT default_locale FROM organizations").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"default_locale"}).AddRow("en"))
+	suite.sqlmock.ExpectQuery("SELECT t.core_value_id, t.locale, t.text, t.description").
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"core_value_id", "locale", "text", "description"}).
+			AddRow(1, "de", "Teamarbeit", "Zusammenarbeiten").
+			AddRow(1, "de-AT", "Teamwerk", "Gemeinsam arbeiten").
+			AddRow(2, "de", "Qualität", "Es richtig machen"))
+
+	translations, err := suite.dbStore.PreferredCoreValueTranslations(context.Background(), 1, []string{"de-AT", "de", "en", "ja"})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[int64]CoreValueTranslation{
+		1: {CoreValueID: 1, Locale: "de-AT", Text: "Teamwerk", Description: "Gemeinsam arbeiten"},
+		2: {CoreValueID: 2, Locale: "de", Text: "Qualität", Description: "Es richtig machen"},
+	}, translations)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *TranslationTestSuite) TestPreferredBadgeTranslationsInDefaultLocale() {
+	suite.sqlmock.ExpectQuery("SELECT default_locale FROM organizations").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"default_locale"}).AddRow("ja"))
+
+	translations, err := suite.dbStore.PreferredBadgeTranslations(context.Background(), 1, []string{"ja", "en"})
+
+	assert.Nil(suite.T(), err)
+	assert.Empty(suite.T(), translations)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *TranslationTestSuite) TestSaveBadgeTranslation() {
+	suite.sqlmock.ExpectQuery("INSERT INTO badge_translations").
+		WithArgs(2, "ja", "チームプレーヤー", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"badge_id", "locale", "name"}).AddRow(2, "ja", "チームプレーヤー"))
+
+	translation, err := suite.dbStore.SaveBadgeTranslation(context.Background(), BadgeTranslation{BadgeID: 2, Locale: "ja", Name: " チームプレーヤー "})
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), BadgeTranslation{BadgeID: 2, Locale: "ja", Name: "チームプレーヤー"}, translation)
+}
+
+func (suite *TranslationTestSuite) TestDeleteCoreValueTranslationNotFound() {
+	suite.sqlmock.ExpectExec("DELETE FROM core_value_translations").
+		WithArgs(1, "de").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.dbStore.DeleteCoreValueTranslation(context.Background(), 1, "de")
+
+	assert.Equal(suite.T(), ae.ErrRecordNotFound, err)
+}
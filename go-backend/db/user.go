@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	ae "joshsoftware/peerly/apperrors"
+	"strconv"
 	"time"
 
 	logger "github.com/sirupsen/logrus"
@@ -25,10 +26,9 @@ const (
 		email,
 		display_name,
 		profile_image_url,
-		role_id,
-		hi5_quota_balance
+		role_id
 		) = 
-		($1, $2, $3, $4, $5, $6) where id = $7 AND soft_delete = $8`
+		($1, $2, $3, $4, $5) where id = $6 AND soft_delete = $7`
 	getUserByEmailQuery = `SELECT * FROM users WHERE email=$1 LIMIT 1`
 	getUserByIDQuery    = `SELECT * FROM users WHERE id=$1 AND soft_delete = $2 LIMIT 1`
 	listUsersQuery      = `SELECT * FROM users ORDER BY name ASC`
@@ -86,7 +86,7 @@ func (s *pgStore) GetUserByEmail(ctx context.Context, email string) (user User,
 	org := Organization{}
 	err = s.db.Get(&org, getOrganizationByIDQuery, user.OrgID)
 	if err != nil {
-		logger.WithField("err", err.Error()).Error("Error selecting user's organization from database by email and id " + email + " " + string(user.OrgID))
+		logger.WithField("err", err.Error()).Error("Error selecting user's organization from database by email and id " + email + " " + strconv.Itoa(user.OrgID))
 		return
 	}
 	return
@@ -96,14 +96,14 @@ func (s *pgStore) GetUserByEmail(ctx context.Context, email string) (user User,
 func (s *pgStore) GetUserByID(ctx context.Context, id int) (user User, err error) {
 	err = s.db.Get(&user, getUserByIDQuery, id, false)
 	if err != nil {
-		logger.WithField("err", err.Error()).Error("Error selecting user from database by id " + string(id))
+		logger.WithField("err", err.Error()).Error("Error selecting user from database by id " + strconv.Itoa(id))
 		return
 	}
 	// Populate the user's organization data
 	org := Organization{}
 	err = s.db.Get(&org, getOrganizationByIDQuery, user.OrgID)
 	if err != nil {
-		logger.WithField("err", err.Error()).Error("Error selecting user's organization from database by user id and org id " + string(id) + " " + string(user.OrgID))
+		logger.WithField("err", err.Error()).Error("Error selecting user's organization from database by user id and org id " + strconv.Itoa(id) + " " + strconv.Itoa(user.OrgID))
 		return
 	}
 	return
@@ -148,6 +148,14 @@ func (s *pgStore) CreateNewUser(ctx context.Context, u User) (newUser User, err
 	if err != nil {
 		// FAIL: Could not run insert query
 		logger.WithField("err", err.Error()).Error("Error inserting user into database: " + u.Email)
+		tx.Rollback()
+		return
+	}
+	_, err = tx.Exec(grantNewUserHi5QuotaQuery, u.Email)
+	if err != nil {
+		// FAIL: Could not record the user's initial Hi5 quota
+		logger.WithField("err", err.Error()).Error("Error recording initial Hi5 quota for user: " + u.Email)
+		tx.Rollback()
 		return
 	}
 	err = tx.Commit()
@@ -204,7 +212,6 @@ func (s *pgStore) UpdateUser(ctx context.Context, userProfile User, userID int)
 		userProfile.DisplayName,
 		userProfile.ProfileImageURL,
 		userProfile.RoleID,
-		userProfile.Hi5QuotaBalance,
 		userID,
 		false,
 	)
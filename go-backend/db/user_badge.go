@@ -0,0 +1,370 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	logger "github.com/sirupsen/logrus"
+)
+
+const (
+	// AllTimeHi5Frequency - the badge counts every Hi5 the user has ever received
+	AllTimeHi5Frequency = "ALL_TIME"
+
+	// $1 org, $2 counted since, $3 core values (empty for all), $4 a single user or 0 for everyone
+	badgeRecognitionFilter = ` AND r.status = 'published' AND u.org_id = $1 AND u.soft_delete = FALSE
+		AND (cardinality($3::bigint[]) = 0 OR r.core_value_id = ANY($3))`
+
+	// each metric query also returns when the oldest counted activity happened, the value
+	// can only drop once that activity leaves the window
+	hi5sReceivedQuery = `SELECT r.given_for AS user_id, COUNT(*) AS achieved, MIN(h.given_at) AS oldest
+		FROM recognition_hi5 h
+		JOIN recognitions r ON r.id = h.recognition_id
+		JOIN users u ON u.id = r.given_for
+		WHERE h.given_at >= $2` + badgeRecognitionFilter + ` AND ($4 = 0 OR r.given_for = $4)
+		GROUP BY r.given_for`
+
+	recognitionsReceivedQuery = `SELECT r.given_for AS user_id, COUNT(*) AS achieved, MIN(r.given_at) AS oldest
+		FROM recognitions r JOIN users u ON u.id = r.given_for
+		WHERE r.given_at >= $2` + badgeRecognitionFilter + ` AND ($4 = 0 OR r.given_for = $4)
+		GROUP BY r.given_for`
+
+	recognitionsGivenQuery = `SELECT r.given_by AS user_id, COUNT(*) AS achieved, MIN(r.given_at) AS oldest
+		FROM recognitions r JOIN users u ON u.id = r.given_by
+		WHERE r.given_at >= $2` + badgeRecognitionFilter + ` AND ($4 = 0 OR r.given_by = $4)
+		GROUP BY r.given_by`
+
+	distinctGiversQuery = `SELECT r.given_for AS user_id, COUNT(DISTINCT r.given_by) AS achieved, MIN(r.given_at) AS oldest
+		FROM recognitions r JOIN users u ON u.id = r.given_for
+		WHERE r.given_at >= $2` + badgeRecognitionFilter + ` AND ($4 = 0 OR r.given_for = $4)
+		GROUP BY r.given_for`
+
+	// weeks are counted as in unixWeek
+	recognitionWeeksQuery = `SELECT DISTINCT r.given_for AS user_id, (r.given_at - 345600) / 604800 AS week
+		FROM recognitions r JOIN users u ON u.id = r.given_for
+		WHERE r.given_at >= $2` + badgeRecognitionFilter + ` AND ($4 = 0 OR r.given_for = $4)
+		ORDER BY user_id, week DESC`
+
+	// awards one level of a badge to many users at once, skipping those who already hold it
+	awardBadgeLevelQuery = `INSERT INTO user_badges (user_id, badge_id, tier, achieved_count, awarded_at)
+		SELECT unnest($1::bigint[]), $2, $3, unnest($4::bigint[]), $5
+		ON CONFLICT (user_id, badge_id, tier) DO NOTHING
+		RETURNING id, user_id, badge_id, tier, achieved_count, awarded_at, source`
+
+	listUserBadgesQuery = `SELECT ub.id, ub.user_id, ub.badge_id, b.name, b.image_key, ub.tier, ub.achieved_count, ub.awarded_at,
+		ub.source, ub.granted_by, ub.note
+		FROM user_badges ub JOIN badges b ON b.id = ub.badge_id
+		WHERE ub.user_id = $1 ORDER BY ub.awarded_at DESC, ub.id DESC`
+
+	listBadgeHoldersQuery = `SELECT u.id AS user_id, u.name, COALESCE(u.display_name, '') AS display_name,
+		COALESCE(u.profile_image_url, '') AS profile_image_url, ub.tier, ub.achieved_count, ub.awarded_at, ub.source
+		FROM user_badges ub
+		JOIN badges b ON b.id = ub.badge_id
+		JOIN users u ON u.id = ub.user_id
+		WHERE ub.badge_id = $1 AND b.org_id = $2 AND u.soft_delete = FALSE
+		ORDER BY ub.awarded_at ASC, ub.id ASC`
+
+	secondsInHi5FrequencyDay = 24 * 60 * 60
+	secondsInWeek            = 7 * secondsInHi5FrequencyDay
+	// 5th January 1970
+	firstUnixMonday = 4 * secondsInHi5FrequencyDay
+)
+
+var badgeMetricQueries = map[string]string{
+	Hi5sReceivedMetric:         hi5sReceivedQuery,
+	RecognitionsReceivedMetric: recognitionsReceivedQuery,
+	RecognitionsGivenMetric:    recognitionsGivenQuery,
+	DistinctGiversMetric:       distinctGiversQuery,
+}
+
+var (
+	// ErrInvalidHi5Frequency - the badge's hi5_frequency isn't a window the evaluator understands
+	ErrInvalidHi5Frequency = errors.New("Must be DAILY, WEEKLY, MONTHLY, QUARTERLY, YEARLY, ALL_TIME or a number of days")
+	// ErrInvalidBadgeCriteria - the badge can't be evaluated until its criteria are fixed
+	ErrInvalidBadgeCriteria = errors.New("Invalid badge criteria")
+)
+
+// hi5FrequencyDays - named windows a badge's Hi5s can be counted over
+var hi5FrequencyDays = map[string]int{
+	"DAILY":     1,
+	"WEEKLY":    7,
+	"MONTHLY":   30,
+	"QUARTERLY": 90,
+	"YEARLY":    365,
+}
+
+// UserBadge - a badge level awarded to a user, with what they had achieved when it was awarded.
+// Manual grants also record who granted them and why.
+type UserBadge struct {
+	ID            int64  `db:"id" json:"id"`
+	UserID        int    `db:"user_id" json:"user_id"`
+	BadgeID       int    `db:"badge_id" json:"badge_id"`
+	Name          string `db:"name" json:"name"`
+	Tier          string `db:"tier" json:"tier"`
+	AchievedCount int    `db:"achieved_count" json:"achieved_count"`
+	AwardedAt     int64  `db:"awarded_at" json:"awarded_at"`
+	Source        string `db:"source" json:"source"`
+	GrantedBy     *int   `db:"granted_by" json:"granted_by,omitempty"`
+	Note          string `db:"note" json:"note,omitempty"`
+	ImageKey      string `db:"image_key" json:"-"`
+	// Image - the badge's artwork, filled in by the handlers
+	Image *BadgeImage `db:"-" json:"image"`
+}
+
+// BadgeHolder - a user holding a badge
+type BadgeHolder struct {
+	UserID          int    `db:"user_id" json:"user_id"`
+	Name            string `db:"name" json:"full_name"`
+	DisplayName     string `db:"display_name" json:"display_name"`
+	ProfileImageURL string `db:"profile_image_url" json:"profile_image_url"`
+	Tier            string `db:"tier" json:"tier"`
+	AchievedCount   int    `db:"achieved_count" json:"achieved_count"`
+	AwardedAt       int64  `db:"awarded_at" json:"awarded_at"`
+	Source          string `db:"source" json:"source"`
+}
+
+// badgeProgress - what a user has achieved towards a badge's criteria and, for windowed
+// metrics and streaks, when that starts to drop. ExpiresAt is 0 when it never does.
+type badgeProgress struct {
+	UserID    int   `db:"user_id"`
+	Achieved  int   `db:"achieved"`
+	Oldest    int64 `db:"oldest"`
+	ExpiresAt int64 `db:"-"`
+}
+
+type recognitionWeek struct {
+	UserID int   `db:"user_id"`
+	Week   int64 `db:"week"`
+}
+
+// Hi5WindowDays - number of days before now the badge's Hi5s are counted over; 0 means all time.
+// hi5_frequency is either a named window or a number of days.
+func (badge Badge) Hi5WindowDays() (days int, err error) {
+	frequency := strings.ToUpper(strings.TrimSpace(badge.Hi5Frequency))
+	if frequency == "" || frequency == AllTimeHi5Frequency {
+		return
+	}
+
+	days, ok := hi5FrequencyDays[frequency]
+	if ok {
+		return
+	}
+
+	days, err = strconv.Atoi(frequency)
+	if err != nil || days <= 0 {
+		days = 0
+		err = ErrInvalidHi5Frequency
+	}
+	return
+}
+
+// AwardBadges - awards the organization's badges the user has earned and returns the new awards.
+// Badges already held are skipped, so it is safe to call after every Hi5 or recognition.
+func (s *pgStore) AwardBadges(ctx context.Context, orgID, userID int) (awarded []UserBadge, err error) {
+	awarded = make([]UserBadge, 0)
+
+	badges, err := s.ListBadges(ctx, orgID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, badge := range badges {
+		var badgeAwards []UserBadge
+		badgeAwards, err = s.awardBadge(ctx, badge, userID, now)
+		if err != nil {
+			return
+		}
+		for _, award := range badgeAwards {
+			award.Name = badge.Name
+			awarded = append(awarded, award)
+		}
+	}
+	return
+}
+
+// AwardBadgesJob - nightly backfill awarding every badge of every organization, which also catches
+// awards the request time and publish time evaluation missed, e.g. when evaluating a badge failed
+func (s *pgStore) AwardBadgesJob() (err error) {
+	ctx := context.Background()
+	organizations, err := s.ListOrganizations(ctx)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().Unix()
+	for _, organization := range organizations {
+		badges, listErr := s.ListBadges(ctx, organization.ID)
+		if listErr != nil {
+			err = listErr
+			continue
+		}
+
+		for _, badge := range badges {
+			awarded, awardErr := s.awardBadge(ctx, badge, 0, now)
+			if awardErr != nil {
+				err = awardErr
+				continue
+			}
+			if len(awarded) > 0 {
+				logger.WithFields(logger.Fields{
+					"badge_id": badge.ID,
+					"awarded":  len(awarded),
+				}).Info("Awarded badges")
+			}
+		}
+	}
+	return
+}
+
+// awardBadge - userID 0 evaluates the badge for every user of its organization
+func (s *pgStore) awardBadge(ctx context.Context, badge Badge, userID int, now int64) (awarded []UserBadge, err error) {
+	criteria, err := badge.EffectiveCriteria()
+	if err == nil && len(criteria.Validate()) > 0 {
+		err = ErrInvalidBadgeCriteria
+	}
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":      err.Error(),
+			"badge_id": badge.ID,
+		}).Error("Skipping badge with invalid criteria")
+		err = nil
+		return
+	}
+
+	progress, err := s.measureBadgeCriteria(ctx, badge.OrganizationID, criteria, userID, now)
+	if err != nil {
+		return
+	}
+
+	for _, level := range criteria.Levels() {
+		userIDs := make([]int64, 0)
+		achieved := make([]int64, 0)
+		for _, userProgress := range progress {
+			if userProgress.Achieved >= level.Threshold {
+				userIDs = append(userIDs, int64(userProgress.UserID))
+				achieved = append(achieved, int64(userProgress.Achieved))
+			}
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		var levelAwards []UserBadge
+		err = s.db.SelectContext(
+			ctx,
+			&levelAwards,
+			awardBadgeLevelQuery,
+			pq.Array(userIDs),
+			badge.ID,
+			level.Tier,
+			pq.Array(achieved),
+			now,
+		)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":      err.Error(),
+				"badge_id": badge.ID,
+				"tier":     level.Tier,
+			}).Error("Error while awarding badge")
+			return
+		}
+		awarded = append(awarded, levelAwards...)
+	}
+	return
+}
+
+// measureBadgeCriteria - what each user of the organization, or only userID when it isn't 0,
+// has achieved towards the criteria. Users who achieved nothing are left out.
+func (s *pgStore) measureBadgeCriteria(ctx context.Context, orgID int, criteria BadgeCriteria, userID int, now int64) (progress []badgeProgress, err error) {
+	var since int64
+	if criteria.WindowDays > 0 {
+		since = now - int64(criteria.WindowDays)*secondsInHi5FrequencyDay
+	}
+
+	coreValueIDs := criteria.CoreValueIDs
+	if coreValueIDs == nil {
+		coreValueIDs = []int64{}
+	}
+
+	progress = make([]badgeProgress, 0)
+	if criteria.Metric != WeeklyStreakMetric {
+		err = s.db.SelectContext(ctx, &progress, badgeMetricQueries[criteria.Metric],
+			orgID, since, pq.Array(coreValueIDs), userID)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err":    err.Error(),
+				"metric": criteria.Metric,
+			}).Error("Error while measuring badge criteria")
+			return
+		}
+
+		if criteria.WindowDays > 0 {
+			for i := range progress {
+				progress[i].ExpiresAt = progress[i].Oldest + int64(criteria.WindowDays)*secondsInHi5FrequencyDay
+			}
+		}
+		return
+	}
+
+	weeks := make([]recognitionWeek, 0)
+	err = s.db.SelectContext(ctx, &weeks, recognitionWeeksQuery, orgID, since, pq.Array(coreValueIDs), userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":    err.Error(),
+			"metric": criteria.Metric,
+		}).Error("Error while measuring badge criteria")
+		return
+	}
+
+	// rows come grouped by user, newest week first
+	currentWeek := unixWeek(now)
+	for start := 0; start < len(weeks); {
+		end := start
+		userWeeks := make([]int64, 0)
+		for ; end < len(weeks) && weeks[end].UserID == weeks[start].UserID; end++ {
+			userWeeks = append(userWeeks, weeks[end].Week)
+		}
+
+		streak := weeklyStreak(userWeeks, currentWeek)
+		if streak > 0 {
+			// the streak is lost unless there is a recognition in the week after the latest one
+			expiresAt := firstUnixMonday + (userWeeks[0]+2)*secondsInWeek
+			progress = append(progress, badgeProgress{UserID: weeks[start].UserID, Achieved: streak, ExpiresAt: expiresAt})
+		}
+		start = end
+	}
+	return
+}
+
+func (s *pgStore) ListUserBadges(ctx context.Context, userID int) (badges []UserBadge, err error) {
+	badges = make([]UserBadge, 0)
+	err = s.db.SelectContext(ctx, &badges, listUserBadgesQuery, userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while listing user badges")
+		return
+	}
+
+	return
+}
+
+func (s *pgStore) ListBadgeHolders(ctx context.Context, orgID, badgeID int) (holders []BadgeHolder, err error) {
+	holders = make([]BadgeHolder, 0)
+	err = s.db.SelectContext(ctx, &holders, listBadgeHoldersQuery, badgeID, orgID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":      err.Error(),
+			"badge_id": badgeID,
+		}).Error("Error while listing badge holders")
+		return
+	}
+
+	return
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserBadgeTestSuite struct {
+	suite.Suite
+	dbStore Storer
+	db      *sqlx.DB
+	sqlmock sqlmock.Sqlmock
+}
+
+func (suite *UserBadgeTestSuite) SetupTest() {
+	dbStore, dbConn, sqlmock := InitMockDB()
+	suite.dbStore = dbStore
+	suite.db = dbConn
+	suite.sqlmock = sqlmock
+}
+
+func (suite *UserBadgeTestSuite) TearDownTest() {
+	suite.db.Close()
+}
+
+func (suite *UserBadgeTestSuite) TestHi5WindowDays() {
+	for frequency, expected := range map[string]int{"": 0, "all_time": 0, "Monthly": 30, "WEEKLY": 7, "2": 2} {
+		days, err := Badge{Hi5Frequency: frequency}.Hi5WindowDays()
+		assert.Nil(suite.T(), err)
+		assert.Equal(suite.T(), expected, days, frequency)
+	}
+
+	for _, frequency := range []string{"FORTNIGHTLY", "0", "-3"} {
+		_, err := Badge{Hi5Frequency: frequency}.Hi5WindowDays()
+		assert.Equal(suite.T(), ErrInvalidHi5Frequency, err, frequency)
+	}
+}
+
+func (suite *UserBadgeTestSuite) TestValidate() {
+	badge := Badge{Name: "Star", Hi5CountRequired: 0, Hi5Frequency: "FORTNIGHTLY"}
+
+	errorResponse, valid := badge.Validate()
+
+	assert.False(suite.T(), valid)
+	assert.Equal(suite.T(), map[string]string{
+		"hi5_count_required": "Must be greater than 0",
+		"hi5_frequency":      ErrInvalidHi5Frequency.Error(),
+	}, errorResponse["error"].Fields)
+}
+
+func (suite *UserBadgeTestSuite) TestAwardBadges() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM badges").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "name", "org_id", "hi5_count_required", "hi5_frequency", "criteria"}).
+			AddRow(1, "Star", 1, 5, "ALL_TIME", nil).
+			AddRow(2, "Team player", 1, 0, "", []byte(`{"metric":"distinct_givers","window_days":7,"tiers":{"bronze":2,"silver":4,"gold":8}}`)))
+
+	suite.sqlmock.ExpectQuery("SELECT r.given_for AS user_id, COUNT\\(\\*\\) AS achieved, (.+) FROM recognition_hi5").
+		WithArgs(1, 0, "{}", 7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "achieved"}).AddRow(7, 4))
+
+	suite.sqlmock.ExpectQuery("SELECT r.given_for AS user_id, COUNT\\(DISTINCT r.given_by\\)").
+		WithArgs(1, sqlmock.AnyArg(), "{}", 7).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "achieved"}).AddRow(7, 5))
+
+	suite.sqlmock.ExpectQuery("INSERT INTO user_badges").
+		WithArgs("{7}", 2, BronzeTier, "{5}", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "badge_id", "tier", "achieved_count", "awarded_at"}))
+
+	suite.sqlmock.ExpectQuery("INSERT INTO user_badges").
+		WithArgs("{7}", 2, SilverTier, "{5}", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "badge_id", "tier", "achieved_count", "awarded_at"}).
+			AddRow(10, 7, 2, SilverTier, 5, 1594339200))
+
+	awarded, err := suite.dbStore.AwardBadges(context.Background(), 1, 7)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []UserBadge{{ID: 10, UserID: 7, BadgeID: 2, Name: "Team player", Tier: SilverTier, AchievedCount: 5, AwardedAt: 1594339200}}, awarded)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *UserBadgeTestSuite) TestAwardBadgesForWeeklyStreak() {
+	now := time.Now().Unix()
+	currentWeek := unixWeek(now)
+
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM badges").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "name", "org_id", "hi5_count_required", "hi5_frequency", "criteria"}).
+			AddRow(3, "Regular", 1, 0, "", []byte(`{"metric":"weekly_streak","core_value_ids":[4],"threshold":3}`)))
+
+	suite.sqlmock.ExpectQuery("SELECT DISTINCT r.given_for AS user_id").
+		WithArgs(1, 0, "{4}", 0).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "week"}).
+			AddRow(7, currentWeek-1).AddRow(7, currentWeek-2).AddRow(7, currentWeek-3).
+			AddRow(8, currentWeek).AddRow(8, currentWeek-2).AddRow(8, currentWeek-3))
+
+	suite.sqlmock.ExpectQuery("INSERT INTO user_badges").
+		WithArgs("{7}", 3, "", "{3}", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "badge_id", "tier", "achieved_count", "awarded_at"}).
+			AddRow(11, 7, 3, "", 3, now))
+
+	awarded, err := suite.dbStore.AwardBadges(context.Background(), 1, 0)
+
+	assert.Nil(suite.T(), err)
+	assert.Len(suite.T(), awarded, 1)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *UserBadgeTestSuite) TestWeeklyStreak() {
+	assert.Equal(suite.T(), 3, weeklyStreak([]int64{10, 9, 8, 6}, 10))
+	assert.Equal(suite.T(), 2, weeklyStreak([]int64{9, 8}, 10))
+	assert.Equal(suite.T(), 0, weeklyStreak([]int64{8, 7}, 10))
+	assert.Equal(suite.T(), 0, weeklyStreak([]int64{}, 10))
+}
+
+func (suite *UserBadgeTestSuite) TestValidateCriteria() {
+	errFields := BadgeCriteria{Metric: "hi5s_given", CoreValueIDs: []int64{2, 2}, Tiers: &BadgeTiers{Bronze: 5, Silver: 5, Gold: 10}}.Validate()
+
+	assert.Len(suite.T(), errFields, 3)
+	assert.Contains(suite.T(), errFields, "criteria.metric")
+	assert.Equal(suite.T(), "Must be distinct core value ids", errFields["criteria.core_value_ids"])
+	assert.Equal(suite.T(), "Must be greater than bronze", errFields["criteria.tiers.silver"])
+
+	errFields = BadgeCriteria{Metric: WeeklyStreakMetric, WindowDays: 30}.Validate()
+	assert.Equal(suite.T(), map[string]string{
+		"criteria.window_days": "Can't be set for streaks",
+		"criteria.threshold":   "Must be greater than 0",
+	}, errFields)
+
+	assert.Empty(suite.T(), BadgeCriteria{Metric: RecognitionsGivenMetric, WindowDays: 30, Threshold: 10}.Validate())
+}
+
+func (suite *UserBadgeTestSuite) TestAwardBadgesSkipsInvalidFrequency() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM badges").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "name", "org_id", "hi5_count_required", "hi5_frequency", "criteria"}).
+			AddRow(1, "Star", 1, 5, "FORTNIGHTLY", nil))
+
+	awarded, err := suite.dbStore.AwardBadges(context.Background(), 1, 7)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []UserBadge{}, awarded)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *UserBadgeTestSuite) TestListBadgeHolders() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM user_badges").
+		WithArgs(2, 1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"user_id", "name", "display_name", "profile_image_url", "tier", "achieved_count", "awarded_at"}).
+			AddRow(7, "Jane Doe", "jane", "", GoldTier, 3, 1594339200))
+
+	holders, err := suite.dbStore.ListBadgeHolders(context.Background(), 1, 2)
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), []BadgeHolder{{UserID: 7, Name: "Jane Doe", DisplayName: "jane", Tier: GoldTier, AchievedCount: 3, AwardedAt: 1594339200}}, holders)
+}
@@ -8,59 +8,116 @@ import (
 )
 
 const (
-	weeklyRenewalFrequency  = "WEEKLY"
-	monthlyRenewalFrequency = "MONTHLY"
-	startDayOfWeek          = "MONDAY"
-	firstDayInMonth         = 1
+	markHi5QuotaResetQuery = `UPDATE organizations SET hi5_quota_last_reset_at = $1
+		WHERE id = $2 AND hi5_quota_last_reset_at < $1`
 
-	updateHi5QuotaBalanceQuery = `UPDATE users SET hi5_quota_balance=$1 where org_id = $2 AND soft_delete = $3`
+	// every user of the organization with their balance and limit, locked until they are renewed. The limit
+	// is the user's override, else their role's, else the organization's ($1).
+	listHi5QuotaRenewalsQuery = `SELECT u.id, COALESCE(u.hi5_quota_balance, 0) AS balance,
+		COALESCE(user_override.hi5_limit, role_override.hi5_limit, $1) AS hi5_limit
+		FROM users u
+		LEFT JOIN hi5_limit_overrides user_override ON user_override.user_id = u.id
+		LEFT JOIN hi5_limit_overrides role_override ON role_override.org_id = u.org_id AND role_override.role_id = u.role_id
+		WHERE u.org_id = $2 AND u.soft_delete = $3 ORDER BY u.id FOR UPDATE OF u`
 )
 
-func (s *pgStore) UpdateHi5QuotaRenewalFrequencyOfUsers(organization Organization) (err error) {
-	_, err = s.db.Exec(
-		updateHi5QuotaBalanceQuery,
-		organization.Hi5Limit,
-		organization.ID,
-		false,
-	)
+// hi5QuotaRenewal - a user's balance and the limit it is renewed to
+type hi5QuotaRenewal struct {
+	UserID   int `db:"id"`
+	Balance  int `db:"balance"`
+	Hi5Limit int `db:"hi5_limit"`
+}
+
+// UpdateHi5QuotaRenewalFrequencyOfUsers - renews the quota of every user of the organization for the period
+// starting at resetAt to the balance its policy gives them, recording each renewal in the ledger. Marking the
+// organization first means a period is only ever renewed once, even if several instances run the job at the same time.
+func (s *pgStore) UpdateHi5QuotaRenewalFrequencyOfUsers(organization Organization, resetAt int64) (err error) {
+	policy, err := s.GetHi5QuotaPolicy(context.Background(), organization.ID)
 	if err != nil {
-		logger.WithField("err", err.Error()).Error("Error while updating user's Hi5 quota balance")
 		return
 	}
-	return
 
+	tx, err := s.db.Beginx()
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while initiating transaction")
+		return
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+			return
+		}
+		tx.Commit()
+	}()
+
+	result, err := tx.Exec(markHi5QuotaResetQuery, resetAt, organization.ID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while marking organization's Hi5 quota reset")
+		return
+	}
+
+	marked, err := result.RowsAffected()
+	if err != nil || marked == 0 {
+		return
+	}
+
+	renewals := make([]hi5QuotaRenewal, 0)
+	err = tx.Select(&renewals, listHi5QuotaRenewalsQuery, organization.Hi5Limit, organization.ID, false)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while listing users to renew Hi5 quota of")
+		return
+	}
+
+	for _, renewal := range renewals {
+		_, err = appendHi5LedgerEntry(context.Background(), tx, Hi5LedgerEntry{
+			UserID:    renewal.UserID,
+			EntryType: Hi5LedgerReset,
+			Amount:    policy.RenewedBalance(renewal.Balance, renewal.Hi5Limit) - renewal.Balance,
+			Reason:    "Quota period renewal",
+		}, 0)
+		if err != nil {
+			return
+		}
+	}
+	return
 }
 
-//ResetHi5QuotaBalanceJob - called to execute cron job for reset Hi5_quota_balance
+// ResetHi5QuotaBalanceJob - called to execute cron job for reset Hi5_quota_balance.
+// Each organization is renewed once its schedule, evaluated in its own timezone, has passed a reset
+// it hasn't applied yet, so resets missed while the server was down are applied on the next run.
 func (s *pgStore) ResetHi5QuotaBalanceJob() (err error) {
 	organizations, err := s.ListOrganizations(context.Background())
 	if err != nil {
 		logger.WithField("err", err.Error()).Error("Error while getting organization list")
+		return
 	}
+
+	now := time.Now()
 	for _, organization := range organizations {
+		schedule, scheduleErr := organization.Hi5QuotaSchedule()
+		if scheduleErr != nil {
+			logger.WithFields(logger.Fields{
+				"err":    scheduleErr.Error(),
+				"org_id": organization.ID,
+			}).Error("Error while reading organization's Hi5 quota renewal schedule")
+			continue
+		}
+
+		resetAt := schedule.PreviousReset(now).Unix()
+		if resetAt <= organization.Hi5QuotaLastResetAt {
+			continue
+		}
 
-		if organization.Hi5QuotaRenewalFrequency == weeklyRenewalFrequency {
-			weekday := time.Now().Weekday()
-			if weekday.String() == startDayOfWeek {
-				err = s.UpdateHi5QuotaRenewalFrequencyOfUsers(organization)
-				if err != nil {
-					logger.WithField("err", err.Error()).Error("Error while updating user's Hi5 quota balance")
-					return
-				}
-			}
-
-		} else if organization.Hi5QuotaRenewalFrequency == monthlyRenewalFrequency {
-			year, month, day := time.Now().Date()
-			firstDayOfMonth := time.Date(year, month, firstDayInMonth, 0, 0, 0, 0, time.Now().Location())
-			currentDay := time.Date(year, month, day, 0, 0, 0, 0, time.Now().Location())
-			if firstDayOfMonth.Equal(currentDay) {
-
-				err = s.UpdateHi5QuotaRenewalFrequencyOfUsers(organization)
-				if err != nil {
-					logger.WithField("err", err.Error()).Error("Error while updating user's Hi5 quota balance")
-					return
-				}
-			}
+		// one organization failing must not hold up the renewal of the others
+		updateErr := s.UpdateHi5QuotaRenewalFrequencyOfUsers(organization, resetAt)
+		if updateErr != nil {
+			logger.WithFields(logger.Fields{
+				"err":    updateErr.Error(),
+				"org_id": organization.ID,
+			}).Error("Error while updating user's Hi5 quota balance")
+			err = updateErr
+			continue
 		}
 	}
 	return
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -27,37 +28,103 @@ func (suite *UserHi5QuotaBalanceTestSuite) TearDownTest() {
 	suite.db.Close()
 }
 
-func (suite *UserHi5QuotaBalanceTestSuite) TestResetHi5QuotaBalanceJob() {
+func (suite *UserHi5QuotaBalanceTestSuite) getMockedOrganizationRows(lastResetAt int64) *sqlmock.Rows {
+	return suite.sqlmock.NewRows([]string{"id", "name", "contact_email", "domain_name", "subscription_status", "subscription_valid_upto", "hi5_limit", "hi5_quota_renewal_frequency", "hi5_quota_renewal_weekday", "timezone", "hi5_quota_last_reset_at"}).
+		AddRow(1, "test organization", "test@gmail.com", "www.testdomain.com", 1, 1588073442241, 10, "DAILY", "MONDAY", "Asia/Kolkata", lastResetAt)
+}
+
+// expectHi5QuotaRenewal - the ledger entry renewing the user's balance
+func (suite *UserHi5QuotaBalanceTestSuite) expectHi5QuotaRenewal(userID, balance, amount, renewed int) {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM users WHERE id = (.+) FOR UPDATE").
+		WithArgs(userID).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"hi5_quota_balance"}).AddRow(balance))
+	suite.sqlmock.ExpectExec("UPDATE users SET hi5_quota_balance").
+		WithArgs(renewed, userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("INSERT INTO hi5_quota_ledger").
+		WithArgs(userID, Hi5LedgerReset, amount, renewed, nil, "Quota period renewal", sqlmock.AnyArg()).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "user_id", "entry_type", "amount", "balance_after"}).
+			AddRow(1, userID, Hi5LedgerReset, amount, renewed))
+}
+
+func (suite *UserHi5QuotaBalanceTestSuite) TestResetHi5QuotaBalanceJobWhenResetIsDue() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM organizations").
+		WillReturnRows(suite.getMockedOrganizationRows(0))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_policies").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id", "rollover_percent", "max_carry_over", "balance_cap"}).AddRow(1, 50, 3, 15))
 	suite.sqlmock.ExpectBegin()
-	renewalFrequency := "WEEKLY"
-	startDay := time.Now().Weekday()
-	weekDay := time.Now().Weekday()
-	hi5Limit := 10
-	var org = Organization{
-		ID:                       1,
-		Name:                     "test organization",
-		ContactEmail:             "test@gmail.com",
-		DomainName:               "www.testdomain.com",
-		SubscriptionStatus:       1,
-		SubscriptionValidUpto:    1588073442241,
-		Hi5Limit:                 hi5Limit,
-		Hi5QuotaRenewalFrequency: renewalFrequency,
-		Timezone:                 "IST",
-	}
-	mockedOrganizationsRows := suite.sqlmock.NewRows([]string{"id", "name", "contact_email", "domain_name", "subscription_status", "subscription_valid_upto", "hi5_limit", "hi5_quota_renewal_frequency", "timezone"}).
-		AddRow(1, "test organization", "test@gmail.com", "www.testdomain.com", 1, 1588073442241, 5, "WEEKLY", "IST")
-
-	suite.sqlmock.ExpectQuery("SELECT organizations").
-		WillReturnRows(mockedOrganizationsRows)
-
-	if renewalFrequency == org.Hi5QuotaRenewalFrequency {
-		if startDay.String() == weekDay.String() {
-			suite.sqlmock.ExpectExec("UPDATE users").WithArgs(org.Hi5Limit, org.ID).
-				WillReturnResult(sqlmock.NewResult(1, 1))
-		}
-
-	}
+	suite.sqlmock.ExpectExec("UPDATE organizations SET hi5_quota_last_reset_at").
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT u.id, (.+) FROM users u (.+) FOR UPDATE OF u").
+		WithArgs(10, 1, false).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "balance", "hi5_limit"}).
+			AddRow(1, 8, 10).
+			AddRow(2, 0, 12))
+	// half of the 8 left is carried over, up to 3, and the cap of 15 stops nobody
+	suite.expectHi5QuotaRenewal(1, 8, 5, 13)
+	// nothing is left to carry over, the limit of the override is renewed in full
+	suite.expectHi5QuotaRenewal(2, 0, 12, 12)
+	suite.sqlmock.ExpectCommit()
+
+	err := suite.dbStore.ResetHi5QuotaBalanceJob()
+
+	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *UserHi5QuotaBalanceTestSuite) TestResetHi5QuotaBalanceJobWhenAlreadyReset() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM organizations").
+		WillReturnRows(suite.getMockedOrganizationRows(time.Now().Unix()))
+
 	err := suite.dbStore.ResetHi5QuotaBalanceJob()
+
+	assert.Nil(suite.T(), err)
 	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *UserHi5QuotaBalanceTestSuite) TestResetHi5QuotaBalanceJobWhenResetByAnotherInstance() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM organizations").
+		WillReturnRows(suite.getMockedOrganizationRows(0))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_policies").
+		WithArgs(1).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id", "rollover_percent", "max_carry_over", "balance_cap"}))
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("UPDATE organizations SET hi5_quota_last_reset_at").
+		WithArgs(sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.sqlmock.ExpectCommit()
+
+	err := suite.dbStore.ResetHi5QuotaBalanceJob()
+
 	assert.Nil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
+}
+
+func (suite *UserHi5QuotaBalanceTestSuite) TestResetHi5QuotaBalanceJobContinuesAfterFailure() {
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM organizations").
+		WillReturnRows(suite.getMockedOrganizationRows(0).
+			AddRow(2, "other organization", "other@gmail.com", "www.otherdomain.com", 1, 1588073442241, 10, "DAILY", "MONDAY", "UTC", 0))
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_policies").
+		WithArgs(1).
+		WillReturnError(errors.New("connection reset"))
+	// the second organization is still renewed
+	suite.sqlmock.ExpectQuery("SELECT (.+) FROM hi5_quota_policies").
+		WithArgs(2).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"org_id", "rollover_percent", "max_carry_over", "balance_cap"}))
+	suite.sqlmock.ExpectBegin()
+	suite.sqlmock.ExpectExec("UPDATE organizations SET hi5_quota_last_reset_at").
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.sqlmock.ExpectQuery("SELECT u.id, (.+) FROM users u (.+) FOR UPDATE OF u").
+		WithArgs(10, 2, false).
+		WillReturnRows(suite.sqlmock.NewRows([]string{"id", "balance", "hi5_limit"}).AddRow(3, 4, 10))
+	suite.expectHi5QuotaRenewal(3, 4, 6, 10)
+	suite.sqlmock.ExpectCommit()
+
+	err := suite.dbStore.ResetHi5QuotaBalanceJob()
+
+	assert.NotNil(suite.T(), err)
+	assert.Nil(suite.T(), suite.sqlmock.ExpectationsWereMet())
 }
@@ -24,5 +24,5 @@ require (
 	github.com/urfave/cli v1.22.4
 	github.com/urfave/negroni v1.0.0
 	golang.org/x/net v0.0.0-20200602114024-627f9648deb9 // indirect
-	golang.org/x/text v0.3.2 // indirect
+	golang.org/x/text v0.3.2
 )
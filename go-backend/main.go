@@ -4,6 +4,7 @@ package main
 // @APIDescription Main API for Microservices in Go!
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"joshsoftware/peerly/aws"
@@ -64,6 +65,19 @@ func main() {
 				return db.RollbackMigrations(c.Args().Get(0))
 			},
 		},
+		{
+			Name:  "reconcile_hi5_ledger",
+			Usage: "report users whose cached Hi5 quota balance doesn't match the ledger",
+			Flags: []cli.Flag{
+				cli.BoolFlag{
+					Name:  "fix",
+					Usage: "overwrite the cached balances with the ledger balances",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				return reconcileHi5Ledger(c.Bool("fix"))
+			},
+		},
 	}
 
 	if err := cliApp.Run(os.Args); err != nil {
@@ -103,3 +117,27 @@ func startApp() (err error) {
 	server.Run(addr)
 	return
 }
+
+func reconcileHi5Ledger(fix bool) (err error) {
+	store, err := db.Init()
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Database init failed")
+		return
+	}
+
+	discrepancies, err := store.ReconcileHi5Ledger(context.Background(), fix)
+	if err != nil {
+		return
+	}
+
+	for _, discrepancy := range discrepancies {
+		fmt.Printf("user %d: cached balance %d, ledger balance %d\n", discrepancy.UserID, discrepancy.CachedBalance, discrepancy.LedgerBalance)
+	}
+
+	if fix {
+		fmt.Printf("%d balance(s) fixed\n", len(discrepancies))
+	} else {
+		fmt.Printf("%d discrepancy(ies) found\n", len(discrepancies))
+	}
+	return
+}
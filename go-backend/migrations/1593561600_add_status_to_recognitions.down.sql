@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS recognitions_status_publish_at_idx;
+
+ALTER TABLE recognitions DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE recognitions DROP COLUMN IF EXISTS status;
@@ -0,0 +1,4 @@
+ALTER TABLE recognitions ADD COLUMN status varchar(20) NOT NULL DEFAULT 'published';
+ALTER TABLE recognitions ADD COLUMN publish_at BIGINT DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS recognitions_status_publish_at_idx ON recognitions(status, publish_at);
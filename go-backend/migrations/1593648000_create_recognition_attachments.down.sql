@@ -0,0 +1 @@
+DROP TABLE IF EXISTS recognition_attachments;
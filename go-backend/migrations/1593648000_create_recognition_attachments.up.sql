@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS recognition_attachments (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  recognition_id INTEGER NOT NULL REFERENCES recognitions(id) ON DELETE CASCADE,
+  uploaded_by INTEGER NOT NULL REFERENCES users(id),
+  object_key TEXT NOT NULL,
+  file_name varchar(255) NOT NULL,
+  content_type varchar(100) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  status varchar(20) NOT NULL DEFAULT 'pending',
+  created_at timestamp with time zone NOT NULL default current_timestamp,
+  updated_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS recognition_attachments_recognition_id_idx ON recognition_attachments(recognition_id);
+CREATE UNIQUE INDEX IF NOT EXISTS recognition_attachments_object_key_unique_idx ON recognition_attachments(object_key);
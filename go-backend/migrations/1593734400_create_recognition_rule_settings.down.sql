@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS recognitions_given_by_given_for_idx;
+
+DROP TABLE IF EXISTS recognition_rule_settings;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS recognition_rule_settings (
+  org_id BIGINT NOT NULL PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+  allow_self_recognition BOOLEAN NOT NULL DEFAULT FALSE,
+  min_text_length INTEGER NOT NULL DEFAULT 10,
+  recipient_daily_cap INTEGER NOT NULL DEFAULT 3,
+  reciprocal_limit INTEGER NOT NULL DEFAULT 5,
+  reciprocal_window_days INTEGER NOT NULL DEFAULT 7,
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS recognitions_given_by_given_for_idx ON recognitions(given_by, given_for, given_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS recognition_hi5_recognition_id_idx;
+DROP INDEX IF EXISTS recognitions_feed_idx;
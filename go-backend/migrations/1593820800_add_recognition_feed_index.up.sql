@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS recognitions_feed_idx ON recognitions(status, given_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS recognition_hi5_recognition_id_idx ON recognition_hi5(recognition_id);
@@ -0,0 +1,3 @@
+ALTER TABLE recognition_hi5 DROP CONSTRAINT IF EXISTS fk_recognition_hi5_recognition_id_recognitions_id;
+
+DROP INDEX IF EXISTS recognition_hi5_recognition_id_given_by_unique_idx;
@@ -0,0 +1,10 @@
+-- keep the first Hi5 when a user has Hi5'd the same recognition more than once
+DELETE FROM recognition_hi5 a USING recognition_hi5 b
+  WHERE a.recognition_id = b.recognition_id AND a.given_by = b.given_by AND a.id > b.id;
+
+DELETE FROM recognition_hi5 WHERE recognition_id NOT IN (SELECT id FROM recognitions);
+
+CREATE UNIQUE INDEX IF NOT EXISTS recognition_hi5_recognition_id_given_by_unique_idx ON recognition_hi5(recognition_id, given_by);
+
+ALTER TABLE recognition_hi5 ADD CONSTRAINT fk_recognition_hi5_recognition_id_recognitions_id
+  FOREIGN KEY (recognition_id) REFERENCES recognitions(id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS hi5_quota_ledger_user_id_idx;
+
+DROP TABLE IF EXISTS hi5_quota_ledger;
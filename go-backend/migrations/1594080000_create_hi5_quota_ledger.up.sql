@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS hi5_quota_ledger (
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  entry_type varchar(20) NOT NULL,
+  amount INTEGER NOT NULL,
+  balance_after INTEGER NOT NULL,
+  recognition_id INTEGER REFERENCES recognitions(id) ON DELETE SET NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  created_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS hi5_quota_ledger_user_id_idx ON hi5_quota_ledger(user_id, id);
+
+-- open the ledger with every user's current balance so users.hi5_quota_balance matches the ledger sum
+INSERT INTO hi5_quota_ledger (user_id, entry_type, amount, balance_after, reason)
+  SELECT id, 'adjustment', hi5_quota_balance, hi5_quota_balance, 'Opening balance'
+  FROM users WHERE hi5_quota_balance IS NOT NULL AND hi5_quota_balance <> 0;
@@ -0,0 +1,2 @@
+ALTER TABLE organizations DROP COLUMN IF EXISTS hi5_quota_last_reset_at;
+ALTER TABLE organizations DROP COLUMN IF EXISTS hi5_quota_renewal_weekday;
@@ -0,0 +1,17 @@
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS hi5_quota_renewal_weekday varchar(10) NOT NULL DEFAULT 'MONDAY';
+-- new organizations start their first period when they are created
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS hi5_quota_last_reset_at BIGINT NOT NULL DEFAULT EXTRACT(EPOCH FROM NOW())::BIGINT;
+
+-- free text frequencies like '1 Week' never matched the reset job; map them to the supported values
+UPDATE organizations SET hi5_quota_renewal_frequency = 'WEEKLY'
+  WHERE hi5_quota_renewal_frequency ILIKE '%week%' AND hi5_quota_renewal_frequency NOT ILIKE '%biweek%';
+UPDATE organizations SET hi5_quota_renewal_frequency = 'MONTHLY'
+  WHERE hi5_quota_renewal_frequency ILIKE '%month%';
+UPDATE organizations SET hi5_quota_renewal_frequency = UPPER(TRIM(hi5_quota_renewal_frequency))
+  WHERE UPPER(TRIM(hi5_quota_renewal_frequency)) IN ('DAILY', 'WEEKLY', 'BIWEEKLY', 'MONTHLY', 'QUARTERLY');
+UPDATE organizations SET hi5_quota_renewal_frequency = 'MONTHLY'
+  WHERE hi5_quota_renewal_frequency IS NULL
+  OR hi5_quota_renewal_frequency NOT IN ('DAILY', 'WEEKLY', 'BIWEEKLY', 'MONTHLY', 'QUARTERLY');
+
+UPDATE organizations SET timezone = 'UTC'
+  WHERE timezone IS NULL OR timezone NOT IN (SELECT name FROM pg_timezone_names);
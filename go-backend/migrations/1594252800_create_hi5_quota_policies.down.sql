@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS hi5_limit_overrides_user_id_idx;
+DROP INDEX IF EXISTS hi5_limit_overrides_org_id_role_id_idx;
+DROP TABLE IF EXISTS hi5_limit_overrides;
+DROP TABLE IF EXISTS hi5_quota_policies;
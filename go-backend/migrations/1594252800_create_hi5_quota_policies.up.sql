@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS hi5_quota_policies (
+  org_id BIGINT NOT NULL PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+  rollover_percent INTEGER NOT NULL DEFAULT 0 CHECK (rollover_percent BETWEEN 0 AND 100),
+  max_carry_over INTEGER NOT NULL DEFAULT 0 CHECK (max_carry_over >= 0),
+  balance_cap INTEGER NOT NULL DEFAULT 0 CHECK (balance_cap >= 0),
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+-- an override applies either to everyone with a role in the organization or to a single user
+CREATE TABLE IF NOT EXISTS hi5_limit_overrides (
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  org_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  role_id BIGINT REFERENCES roles(id) ON DELETE CASCADE,
+  user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+  hi5_limit INTEGER NOT NULL CHECK (hi5_limit >= 0),
+  updated_at timestamp with time zone NOT NULL default current_timestamp,
+  CHECK ((role_id IS NULL) <> (user_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS hi5_limit_overrides_org_id_role_id_idx ON hi5_limit_overrides(org_id, role_id) WHERE role_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS hi5_limit_overrides_user_id_idx ON hi5_limit_overrides(user_id) WHERE user_id IS NOT NULL;
+
+-- admins manage quota policies and grant bonus Hi5s
+INSERT INTO roles (id, name) VALUES (DEFAULT, 'Admin') ON CONFLICT (name) DO NOTHING;
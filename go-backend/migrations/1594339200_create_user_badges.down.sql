@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS recognitions_given_for_idx;
+DROP INDEX IF EXISTS recognition_hi5_recognition_id_given_at_idx;
+DROP INDEX IF EXISTS user_badges_badge_id_idx;
+DROP INDEX IF EXISTS user_badges_user_id_badge_id_idx;
+DROP TABLE IF EXISTS user_badges;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS user_badges (
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  badge_id INTEGER NOT NULL REFERENCES badges(id),
+  hi5_count INTEGER NOT NULL,
+  awarded_at BIGINT NOT NULL
+);
+
+-- a badge is awarded to a user at most once, which keeps re-evaluating it idempotent
+CREATE UNIQUE INDEX IF NOT EXISTS user_badges_user_id_badge_id_idx ON user_badges(user_id, badge_id);
+CREATE INDEX IF NOT EXISTS user_badges_badge_id_idx ON user_badges(badge_id, awarded_at);
+
+-- Hi5s received by a user are counted through the recipient of the recognition
+CREATE INDEX IF NOT EXISTS recognition_hi5_recognition_id_given_at_idx ON recognition_hi5(recognition_id, given_at);
+CREATE INDEX IF NOT EXISTS recognitions_given_for_idx ON recognitions(given_for);
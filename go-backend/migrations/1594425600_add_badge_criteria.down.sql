@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS recognitions_given_by_idx;
+
+DROP INDEX IF EXISTS user_badges_user_id_badge_id_tier_idx;
+-- only the first tier awarded is kept
+DELETE FROM user_badges WHERE id NOT IN (SELECT MIN(id) FROM user_badges GROUP BY user_id, badge_id);
+CREATE UNIQUE INDEX IF NOT EXISTS user_badges_user_id_badge_id_idx ON user_badges(user_id, badge_id);
+ALTER TABLE user_badges RENAME COLUMN achieved_count TO hi5_count;
+ALTER TABLE user_badges DROP COLUMN IF EXISTS tier;
+
+ALTER TABLE badges DROP COLUMN IF EXISTS criteria;
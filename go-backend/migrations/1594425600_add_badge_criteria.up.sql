@@ -0,0 +1,10 @@
+-- NULL criteria keep the original behaviour of counting Hi5s received within hi5_frequency
+ALTER TABLE badges ADD COLUMN IF NOT EXISTS criteria JSONB DEFAULT NULL;
+
+-- tiered badges are awarded once per tier; untiered badges use an empty tier
+ALTER TABLE user_badges ADD COLUMN IF NOT EXISTS tier VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE user_badges RENAME COLUMN hi5_count TO achieved_count;
+DROP INDEX IF EXISTS user_badges_user_id_badge_id_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS user_badges_user_id_badge_id_tier_idx ON user_badges(user_id, badge_id, tier);
+
+CREATE INDEX IF NOT EXISTS recognitions_given_by_idx ON recognitions(given_by);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS badge_award_audit_org_id_idx;
+DROP TABLE IF EXISTS badge_award_audit;
+
+DROP INDEX IF EXISTS user_badges_manual_awarded_at_idx;
+ALTER TABLE user_badges DROP COLUMN IF EXISTS note;
+ALTER TABLE user_badges DROP COLUMN IF EXISTS granted_by;
+ALTER TABLE user_badges DROP COLUMN IF EXISTS source;
@@ -0,0 +1,20 @@
+-- automatic awards come from the badge engine, manual ones are granted by an admin
+ALTER TABLE user_badges ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'automatic';
+ALTER TABLE user_badges ADD COLUMN IF NOT EXISTS granted_by BIGINT DEFAULT NULL REFERENCES users(id);
+ALTER TABLE user_badges ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS user_badges_manual_awarded_at_idx ON user_badges(awarded_at) WHERE source = 'manual';
+
+-- badge_id has no foreign key so the trail outlives deleted badges
+CREATE TABLE IF NOT EXISTS badge_award_audit (
+  id BIGSERIAL NOT NULL PRIMARY KEY,
+  org_id INTEGER NOT NULL,
+  user_id BIGINT NOT NULL REFERENCES users(id),
+  badge_id INTEGER NOT NULL,
+  tier VARCHAR(10) NOT NULL DEFAULT '',
+  action VARCHAR(10) NOT NULL,
+  actor_id BIGINT NOT NULL REFERENCES users(id),
+  note TEXT NOT NULL,
+  created_at BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS badge_award_audit_org_id_idx ON badge_award_audit(org_id, created_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS badge_image_uploads_object_key_unique_idx;
+DROP INDEX IF EXISTS badge_image_uploads_badge_id_idx;
+DROP TABLE IF EXISTS badge_image_uploads;
+
+ALTER TABLE badges DROP COLUMN IF EXISTS image_key;
@@ -0,0 +1,16 @@
+-- object key of the badge artwork, its thumbnails are stored next to it
+ALTER TABLE badges ADD COLUMN IF NOT EXISTS image_key TEXT NOT NULL DEFAULT '';
+
+-- upload slots handed out for new artwork, removed once the upload is confirmed
+CREATE TABLE IF NOT EXISTS badge_image_uploads (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  badge_id INTEGER NOT NULL REFERENCES badges(id) ON DELETE CASCADE,
+  uploaded_by INTEGER NOT NULL REFERENCES users(id),
+  object_key TEXT NOT NULL,
+  content_type varchar(100) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  created_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS badge_image_uploads_badge_id_idx ON badge_image_uploads(badge_id);
+CREATE UNIQUE INDEX IF NOT EXISTS badge_image_uploads_object_key_unique_idx ON badge_image_uploads(object_key);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS core_values_org_id_parent_id_position_idx;
+
+ALTER TABLE core_values DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE core_values DROP COLUMN IF EXISTS position;
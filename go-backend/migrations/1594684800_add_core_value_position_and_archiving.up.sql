@@ -0,0 +1,10 @@
+ALTER TABLE core_values ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0;
+-- core values are archived instead of deleted so old recognitions keep pointing at them
+ALTER TABLE core_values ADD COLUMN IF NOT EXISTS archived_at timestamp with time zone DEFAULT NULL;
+
+-- existing core values keep the order they were created in
+UPDATE core_values cv SET position = ordered.position
+  FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY org_id, parent_id ORDER BY id) - 1 AS position FROM core_values) ordered
+  WHERE cv.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS core_values_org_id_parent_id_position_idx ON core_values(org_id, parent_id, position);
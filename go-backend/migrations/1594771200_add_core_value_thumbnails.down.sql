@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS core_value_thumbnail_uploads_object_key_unique_idx;
+DROP INDEX IF EXISTS core_value_thumbnail_uploads_core_value_id_idx;
+DROP TABLE IF EXISTS core_value_thumbnail_uploads;
+
+ALTER TABLE core_values DROP COLUMN IF EXISTS thumbnail_key;
+ALTER TABLE core_values RENAME COLUMN legacy_thumbnail_url TO thumbnail_url;
@@ -0,0 +1,19 @@
+-- thumbnail_url held whatever link clients sent and was never served, thumbnails are now
+-- uploaded to storage and only the key of the resized copy is kept. The old links are kept
+-- aside until every core value has an uploaded thumbnail, a later migration drops them.
+ALTER TABLE core_values RENAME COLUMN thumbnail_url TO legacy_thumbnail_url;
+ALTER TABLE core_values ADD COLUMN IF NOT EXISTS thumbnail_key TEXT NOT NULL DEFAULT '';
+
+-- upload slots handed out for new thumbnails, removed once the upload is confirmed
+CREATE TABLE IF NOT EXISTS core_value_thumbnail_uploads (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  core_value_id INTEGER NOT NULL REFERENCES core_values(id) ON DELETE CASCADE,
+  uploaded_by INTEGER NOT NULL REFERENCES users(id),
+  object_key TEXT NOT NULL,
+  content_type varchar(100) NOT NULL,
+  size_bytes BIGINT NOT NULL,
+  created_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS core_value_thumbnail_uploads_core_value_id_idx ON core_value_thumbnail_uploads(core_value_id);
+CREATE UNIQUE INDEX IF NOT EXISTS core_value_thumbnail_uploads_object_key_unique_idx ON core_value_thumbnail_uploads(object_key);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS badge_translations_badge_id_locale_unique_idx;
+DROP TABLE IF EXISTS badge_translations;
+
+DROP INDEX IF EXISTS core_value_translations_core_value_id_locale_unique_idx;
+DROP TABLE IF EXISTS core_value_translations;
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS default_locale;
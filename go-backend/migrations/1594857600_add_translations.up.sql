@@ -0,0 +1,25 @@
+-- core value and badge names are written in the organization's default locale
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS default_locale VARCHAR(35) NOT NULL DEFAULT 'en';
+
+CREATE TABLE IF NOT EXISTS core_value_translations (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  core_value_id INTEGER NOT NULL REFERENCES core_values(id) ON DELETE CASCADE,
+  locale VARCHAR(35) NOT NULL,
+  text TEXT NOT NULL,
+  description TEXT NOT NULL,
+  created_at timestamp with time zone NOT NULL default current_timestamp,
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS core_value_translations_core_value_id_locale_unique_idx ON core_value_translations(core_value_id, locale);
+
+CREATE TABLE IF NOT EXISTS badge_translations (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  badge_id INTEGER NOT NULL REFERENCES badges(id) ON DELETE CASCADE,
+  locale VARCHAR(35) NOT NULL,
+  name TEXT NOT NULL,
+  created_at timestamp with time zone NOT NULL default current_timestamp,
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS badge_translations_badge_id_locale_unique_idx ON badge_translations(badge_id, locale);
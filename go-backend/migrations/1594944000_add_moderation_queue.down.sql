@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS recognition_moderation_assignments;
+
+DROP INDEX IF EXISTS reported_recognitions_recognition_id_status_idx;
+ALTER TABLE reported_recognitions DROP COLUMN IF EXISTS moderation_id;
+ALTER TABLE reported_recognitions DROP COLUMN IF EXISTS resolved_at;
+ALTER TABLE reported_recognitions DROP COLUMN IF EXISTS status;
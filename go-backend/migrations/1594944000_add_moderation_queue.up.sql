@@ -0,0 +1,14 @@
+-- reports move from open to in_review when a moderator is assigned and are
+-- resolved by the moderation decision taken on their recognition
+ALTER TABLE reported_recognitions ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'open';
+ALTER TABLE reported_recognitions ADD COLUMN IF NOT EXISTS resolved_at BIGINT;
+ALTER TABLE reported_recognitions ADD COLUMN IF NOT EXISTS moderation_id INTEGER REFERENCES recognition_moderation(id);
+
+CREATE INDEX IF NOT EXISTS reported_recognitions_recognition_id_status_idx ON reported_recognitions(recognition_id, status);
+
+CREATE TABLE IF NOT EXISTS recognition_moderation_assignments (
+  recognition_id INTEGER PRIMARY KEY NOT NULL REFERENCES recognitions(id) ON DELETE CASCADE,
+  assigned_to INTEGER NOT NULL REFERENCES users(id),
+  assigned_by INTEGER NOT NULL REFERENCES users(id),
+  assigned_at BIGINT NOT NULL
+);
@@ -0,0 +1,3 @@
+UPDATE recognitions SET status = 'published' WHERE status = 'hidden';
+
+ALTER TABLE recognition_moderation DROP COLUMN IF EXISTS refund_hi5s;
@@ -0,0 +1,2 @@
+-- recognitions judged inappropriate move to the 'hidden' status; hiding can also refund the Hi5s they received
+ALTER TABLE recognition_moderation ADD COLUMN IF NOT EXISTS refund_hi5s BOOLEAN NOT NULL DEFAULT FALSE;
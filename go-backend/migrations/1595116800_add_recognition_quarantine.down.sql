@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS moderation_notifications_user_id_idx;
+DROP TABLE IF EXISTS moderation_notifications;
+
+DROP INDEX IF EXISTS recognition_quarantines_pending_unique_idx;
+DROP TABLE IF EXISTS recognition_quarantines;
+
+DROP TABLE IF EXISTS moderation_settings;
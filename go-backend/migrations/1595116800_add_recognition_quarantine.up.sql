@@ -0,0 +1,30 @@
+-- a recognition reported by enough distinct users within the window is hidden until a moderator reviews it
+CREATE TABLE IF NOT EXISTS moderation_settings (
+  org_id INTEGER PRIMARY KEY NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  quarantine_reporter_threshold INTEGER NOT NULL DEFAULT 3,
+  quarantine_window_hours INTEGER NOT NULL DEFAULT 24,
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE TABLE IF NOT EXISTS recognition_quarantines (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  recognition_id INTEGER NOT NULL REFERENCES recognitions(id) ON DELETE CASCADE,
+  reporter_count INTEGER NOT NULL,
+  quarantined_at BIGINT NOT NULL,
+  reviewed_at BIGINT,
+  moderation_id INTEGER REFERENCES recognition_moderation(id)
+);
+
+-- at most one quarantine waits for review per recognition
+CREATE UNIQUE INDEX IF NOT EXISTS recognition_quarantines_pending_unique_idx ON recognition_quarantines(recognition_id) WHERE reviewed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS moderation_notifications (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  recognition_id INTEGER NOT NULL REFERENCES recognitions(id) ON DELETE CASCADE,
+  kind VARCHAR(20) NOT NULL,
+  created_at BIGINT NOT NULL,
+  read_at BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS moderation_notifications_user_id_idx ON moderation_notifications(user_id, id DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS content_blocklist_terms_org_id_term_idx;
+DROP TABLE IF EXISTS content_blocklist_terms;
+
+DROP TABLE IF EXISTS content_filter_settings;
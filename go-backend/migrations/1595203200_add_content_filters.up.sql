@@ -0,0 +1,21 @@
+-- each checker of the content filter pipeline either lets text through (off), rejects it,
+-- flags it for moderation or masks the offending part
+CREATE TABLE IF NOT EXISTS content_filter_settings (
+  org_id INTEGER PRIMARY KEY NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  blocklist_action VARCHAR(10) NOT NULL DEFAULT 'reject',
+  pii_action VARCHAR(10) NOT NULL DEFAULT 'mask',
+  max_text_length INTEGER NOT NULL DEFAULT 1000,
+  max_length_action VARCHAR(10) NOT NULL DEFAULT 'reject',
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+-- terms may use * as a wildcard for any run of letters or digits
+CREATE TABLE IF NOT EXISTS content_blocklist_terms (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  term VARCHAR(100) NOT NULL,
+  created_by INTEGER REFERENCES users(id),
+  created_at BIGINT NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS content_blocklist_terms_org_id_term_idx ON content_blocklist_terms(org_id, term);
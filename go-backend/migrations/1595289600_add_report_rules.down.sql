@@ -0,0 +1,5 @@
+ALTER TABLE moderation_settings DROP COLUMN IF EXISTS report_rate_window_hours;
+ALTER TABLE moderation_settings DROP COLUMN IF EXISTS report_rate_limit;
+
+DROP INDEX IF EXISTS reported_recognitions_reported_by_reported_at_idx;
+DROP INDEX IF EXISTS reported_recognitions_unresolved_reporter_idx;
@@ -0,0 +1,14 @@
+-- a user can only have one unresolved report on a recognition, later duplicates are closed
+UPDATE reported_recognitions r SET status = 'resolved', resolved_at = r.reported_at
+  WHERE r.status <> 'resolved' AND EXISTS (
+    SELECT 1 FROM reported_recognitions o WHERE o.recognition_id = r.recognition_id
+    AND o.reported_by = r.reported_by AND o.status <> 'resolved' AND o.id < r.id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS reported_recognitions_unresolved_reporter_idx ON reported_recognitions(recognition_id, reported_by)
+  WHERE status <> 'resolved';
+
+CREATE INDEX IF NOT EXISTS reported_recognitions_reported_by_reported_at_idx ON reported_recognitions(reported_by, reported_at);
+
+-- users can file at most report_rate_limit reports within report_rate_window_hours, 0 lifts the limit
+ALTER TABLE moderation_settings ADD COLUMN IF NOT EXISTS report_rate_limit INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE moderation_settings ADD COLUMN IF NOT EXISTS report_rate_window_hours INTEGER NOT NULL DEFAULT 24;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS report_reasons_org_id_name_idx;
+DROP TABLE IF EXISTS report_reasons;
@@ -0,0 +1,26 @@
+-- the reasons users can give when reporting a recognition; reports refer to a reason by its name,
+-- so reasons are archived instead of deleted
+CREATE TABLE IF NOT EXISTS report_reasons (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  org_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+  name VARCHAR(50) NOT NULL,
+  label VARCHAR(100) NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  severity VARCHAR(10) NOT NULL DEFAULT 'medium',
+  triggers_quarantine BOOLEAN NOT NULL DEFAULT TRUE,
+  archived_at timestamp with time zone DEFAULT NULL,
+  created_at timestamp with time zone NOT NULL default current_timestamp,
+  updated_at timestamp with time zone NOT NULL default current_timestamp
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS report_reasons_org_id_name_idx ON report_reasons(org_id, name);
+
+-- every organization starts with the reasons that used to be hard coded
+INSERT INTO report_reasons (org_id, name, label, description, severity, triggers_quarantine)
+  SELECT o.id, d.name, d.label, d.description, d.severity, TRUE FROM organizations o
+  CROSS JOIN (VALUES
+    ('fraud', 'Fraud', 'The recognition is made up or given in exchange for something', 'high'),
+    ('not_relevant', 'Not relevant', 'The recognition has nothing to do with work', 'low'),
+    ('incorrect', 'Incorrect', 'The recognition gets the facts or the person wrong', 'medium')
+  ) AS d(name, label, description, severity)
+  ON CONFLICT (org_id, name) DO NOTHING;
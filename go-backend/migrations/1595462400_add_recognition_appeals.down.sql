@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS recognition_appeals_assigned_to_status_idx;
+DROP INDEX IF EXISTS recognition_appeals_moderation_id_unique_idx;
+DROP TABLE IF EXISTS recognition_appeals;
+DROP INDEX IF EXISTS removed_recognition_hi5s_moderation_id_idx;
+DROP TABLE IF EXISTS removed_recognition_hi5s;
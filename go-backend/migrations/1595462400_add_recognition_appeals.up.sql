@@ -0,0 +1,34 @@
+-- Hi5s taken back when a recognition is hidden are kept so overturning the decision can give them back
+CREATE TABLE IF NOT EXISTS removed_recognition_hi5s (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  moderation_id INTEGER NOT NULL REFERENCES recognition_moderation(id),
+  recognition_id INTEGER NOT NULL REFERENCES recognitions(id) ON DELETE CASCADE,
+  comment TEXT,
+  given_by INTEGER NOT NULL REFERENCES users(id),
+  given_at BIGINT NOT NULL,
+  restored_at BIGINT
+);
+
+CREATE INDEX IF NOT EXISTS removed_recognition_hi5s_moderation_id_idx ON removed_recognition_hi5s(moderation_id);
+
+-- the giver or recipient contesting a decision that hid their recognition. Decisions and appeals
+-- are never updated away: overturning records a new moderation decision next to the appeal.
+CREATE TABLE IF NOT EXISTS recognition_appeals (
+  id SERIAL PRIMARY KEY NOT NULL UNIQUE,
+  recognition_id INTEGER NOT NULL REFERENCES recognitions(id) ON DELETE CASCADE,
+  moderation_id INTEGER NOT NULL REFERENCES recognition_moderation(id),
+  appealed_by INTEGER NOT NULL REFERENCES users(id),
+  statement TEXT NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'open',
+  assigned_to INTEGER REFERENCES users(id),
+  assigned_at BIGINT,
+  decided_by INTEGER REFERENCES users(id),
+  decided_at BIGINT,
+  decision_comment TEXT NOT NULL DEFAULT '',
+  overturn_moderation_id INTEGER REFERENCES recognition_moderation(id),
+  appealed_at BIGINT NOT NULL
+);
+
+-- a decision can be appealed once, by whichever of the giver or recipient gets there first
+CREATE UNIQUE INDEX IF NOT EXISTS recognition_appeals_moderation_id_unique_idx ON recognition_appeals(moderation_id);
+CREATE INDEX IF NOT EXISTS recognition_appeals_assigned_to_status_idx ON recognition_appeals(assigned_to, status);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS reported_recognitions_reported_at_idx;
+ALTER TABLE reported_recognitions DROP COLUMN IF EXISTS reviewed_at;
@@ -0,0 +1,12 @@
+-- when a moderator first picked the report up, either by being assigned or by deciding on it
+ALTER TABLE reported_recognitions ADD COLUMN IF NOT EXISTS reviewed_at BIGINT;
+
+-- only the latest assignment of a recognition is kept, so it counts only if it falls within the report's lifetime
+UPDATE reported_recognitions rr SET reviewed_at = a.assigned_at
+  FROM recognition_moderation_assignments a
+  WHERE a.recognition_id = rr.recognition_id AND rr.status <> 'open'
+  AND a.assigned_at >= rr.reported_at AND (rr.resolved_at IS NULL OR a.assigned_at <= rr.resolved_at);
+
+UPDATE reported_recognitions SET reviewed_at = resolved_at WHERE reviewed_at IS NULL AND status = 'resolved';
+
+CREATE INDEX IF NOT EXISTS reported_recognitions_reported_at_idx ON reported_recognitions(reported_at);
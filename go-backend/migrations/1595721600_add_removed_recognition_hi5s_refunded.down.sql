@@ -0,0 +1 @@
+ALTER TABLE removed_recognition_hi5s DROP COLUMN IF EXISTS refunded;
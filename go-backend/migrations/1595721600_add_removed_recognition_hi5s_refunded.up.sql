@@ -0,0 +1,2 @@
+-- whether the giver got their quota back when the Hi5 was taken, so restoring it charges them again
+ALTER TABLE removed_recognition_hi5s ADD COLUMN IF NOT EXISTS refunded BOOLEAN NOT NULL DEFAULT FALSE;
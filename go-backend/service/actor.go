@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// currentActorKey - request context key holding the db.User resolved from the JWT
+const currentActorKey = "currentActor"
+
+// withCurrentActor - returns a copy of the request carrying the authenticated user
+func withCurrentActor(req *http.Request, actor db.User) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), currentActorKey, actor))
+}
+
+// getCurrentActor - the user every write is attributed to. It is only ever set by
+// jwtAuthMiddleware, so handlers must never take the actor from the request body.
+func getCurrentActor(req *http.Request) (actor db.User, err error) {
+	actor, ok := req.Context().Value(currentActorKey).(db.User)
+	if !ok {
+		err = ae.ErrInvalidToken
+	}
+	return
+}
+
+// decodeWithoutActorFields - decodes the JSON body into dest, reporting any of the
+// given actor fields the client tried to set. dest is left untouched when errFields is returned.
+func decodeWithoutActorFields(req *http.Request, dest interface{}, actorFields ...string) (errFields map[string]string, err error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return
+	}
+
+	var rawFields map[string]json.RawMessage
+	err = json.Unmarshal(body, &rawFields)
+	if err != nil {
+		return
+	}
+
+	errFields = make(map[string]string)
+	for _, field := range actorFields {
+		if _, ok := rawFields[field]; ok {
+			errFields[field] = "Can't be set, it is taken from the authenticated user"
+		}
+	}
+	if len(errFields) > 0 {
+		return
+	}
+
+	errFields = nil
+	err = json.NewDecoder(bytes.NewReader(body)).Decode(dest)
+	return
+}
+
+// actorFieldsResponse - 400 response for a body that tries to act on behalf of someone else
+func actorFieldsResponse(rw http.ResponseWriter, errFields map[string]string) {
+	repsonse(rw, http.StatusBadRequest, errorResponse{
+		Error: errorObject{
+			Code:          "actor-field-not-allowed",
+			Fields:        errFields,
+			messageObject: messageObject{"Request can't set the acting user"},
+		},
+	})
+}
+
+// currentActorErrorResponse - the middleware didn't resolve an actor, which should never happen on authenticated routes
+func currentActorErrorResponse(rw http.ResponseWriter, err error) {
+	logger.WithField("err", err.Error()).Error("Error while reading the current user from request context")
+	repsonse(rw, http.StatusUnauthorized, errorResponse{
+		Error: messageObject{
+			Message: "Unauthorized",
+		},
+	})
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type CurrentActorTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *CurrentActorTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+}
+
+// actorEchoHandler - writes back the id of the actor the middleware resolved
+func actorEchoHandler(rw http.ResponseWriter, req *http.Request) {
+	actor, err := getCurrentActor(req)
+	if err != nil {
+		currentActorErrorResponse(rw, err)
+		return
+	}
+	fmt.Fprint(rw, actor.ID)
+}
+
+func (suite *CurrentActorTestSuite) serveWithToken(token string) (recorder *httptest.ResponseRecorder) {
+	req, _ := http.NewRequest(http.MethodGet, "/actor", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	recorder = httptest.NewRecorder()
+	jwtAuthMiddleware(http.HandlerFunc(actorEchoHandler), Dependencies{Store: suite.dbMock}).ServeHTTP(recorder, req)
+	return
+}
+
+func (suite *CurrentActorTestSuite) TestJWTAuthMiddlewareSetsCurrentActor() {
+	suite.dbMock.On("GetUser", mock.Anything, 4).Return(db.User{ID: 4, OrgID: 2}, nil)
+	token, _ := newJWT(4, 2)
+
+	recorder := suite.serveWithToken(token)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), "4", recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *CurrentActorTestSuite) TestJWTAuthMiddlewareWhenOrganizationMismatch() {
+	suite.dbMock.On("GetUser", mock.Anything, 4).Return(db.User{ID: 4, OrgID: 3}, nil)
+	token, _ := newJWT(4, 2)
+
+	recorder := suite.serveWithToken(token)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, recorder.Code)
+}
+
+func (suite *CurrentActorTestSuite) TestGetCurrentActorWithoutMiddleware() {
+	recorder := makeHTTPCall(http.MethodGet, "/actor", "/actor", "", actorEchoHandler)
+
+	assert.Equal(suite.T(), http.StatusUnauthorized, recorder.Code)
+}
+
+func (suite *CurrentActorTestSuite) TestDecodeWithoutActorFields() {
+	req, _ := http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"comment":"nice","given_by":3}`))
+	var hi5 db.RecognitionHi5
+
+	errFields, err := decodeWithoutActorFields(req, &hi5, "given_by")
+
+	assert.Nil(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"given_by": "Can't be set, it is taken from the authenticated user"}, errFields)
+	assert.Equal(suite.T(), db.RecognitionHi5{}, hi5)
+}
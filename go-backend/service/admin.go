@@ -0,0 +1,45 @@
+package service
+
+import (
+	"net/http"
+
+	"joshsoftware/peerly/db"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// requireOrgAdmin - writes the error response and returns false unless the actor
+// is an admin of the given organization
+func requireOrgAdmin(rw http.ResponseWriter, req *http.Request, deps Dependencies, actor db.User, organizationID int) (ok bool) {
+	if actor.OrgID != organizationID {
+		repsonse(rw, http.StatusForbidden, errorResponse{
+			Error: messageObject{
+				Message: "User doesn't belong to given organization",
+			},
+		})
+		return
+	}
+
+	role, err := deps.Store.GetRoleByID(req.Context(), actor.RoleID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching role of current user")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	if role.Name != db.AdminRoleName {
+		repsonse(rw, http.StatusForbidden, errorResponse{
+			Error: messageObject{
+				Message: "Only admins can perform this action",
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title grantBadgeHandler
+// @Description grant a badge to a user of the organization, admins only
+// @Router /organizations/:organization_id/badges/:id/grants [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func grantBadgeHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		grant, badge, ok := badgeGrantFromRequest(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		userBadge, err := deps.Store.GrantBadge(req.Context(), grant)
+		if err == ae.ErrBadgeAlreadyAwarded {
+			repsonse(rw, http.StatusConflict, errorResponse{
+				Error: messageObject{
+					Message: "User already holds this badge",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while granting badge")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		userBadge.Name = badge.Name
+		userBadge.Image, err = signBadgeImage(req, deps, badge.ImageKey)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: userBadge})
+	})
+}
+
+// @Title revokeBadgeHandler
+// @Description revoke a badge granted to a user of the organization, admins only
+// @Router /organizations/:organization_id/badges/:id/revocations [post]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func revokeBadgeHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		grant, _, ok := badgeGrantFromRequest(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		entry, err := deps.Store.RevokeBadge(req.Context(), grant)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "User holds no granted badge to revoke",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while revoking badge")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: entry})
+	})
+}
+
+// @Title listBadgeAwardAuditHandler
+// @Description list the organization's badge grants and revocations, admins only
+// @Router /organizations/:organization_id/badge_audit [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listBadgeAwardAuditHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		entries, err := deps.Store.ListBadgeAwardAudit(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while listing badge award audit")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: entries})
+	})
+}
+
+// badgeGrantFromRequest - reads and checks a grant or revocation made by an admin, along with the
+// badge it is for, writing the error response and returning false when it can't be applied
+func badgeGrantFromRequest(rw http.ResponseWriter, req *http.Request, deps Dependencies) (grant db.BadgeGrant, badge db.Badge, ok bool) {
+	vars := mux.Vars(req)
+	organizationID, err := strconv.Atoi(vars["organization_id"])
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	badgeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error id key is missing")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	actor, err := getCurrentActor(req)
+	if err != nil {
+		currentActorErrorResponse(rw, err)
+		return
+	}
+
+	if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+		return
+	}
+
+	err = json.NewDecoder(req.Body).Decode(&grant)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while decoding request data")
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: messageObject{
+				Message: "Invalid json request body",
+			},
+		})
+		return
+	}
+	grant.OrgID = organizationID
+	grant.BadgeID = badgeID
+	grant.ActorID = actor.ID
+
+	badge, err = deps.Store.ShowBadge(req.Context(), db.Badge{ID: badgeID, OrganizationID: organizationID})
+	if err == sql.ErrNoRows {
+		repsonse(rw, http.StatusNotFound, errorResponse{
+			Error: messageObject{
+				Message: "Badge not found",
+			},
+		})
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching badge")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	valid, errFields := grant.Validate(badge)
+	if !valid {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "invalid-badge-grant",
+				Fields:        errFields,
+				messageObject: messageObject{"Invalid badge grant"},
+			},
+		})
+		return
+	}
+
+	_, err = deps.Store.GetUserByOrganization(req.Context(), grant.UserID, organizationID)
+	if err == sql.ErrNoRows {
+		repsonse(rw, http.StatusNotFound, errorResponse{
+			Error: messageObject{
+				Message: "User not found",
+			},
+		})
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching user")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type BadgeGrantHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+var testGrantedBadge = db.Badge{ID: 2, Name: "Hackathon winner", OrganizationID: 1, Hi5CountRequired: 100}
+
+func (suite *BadgeGrantHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+}
+
+func (suite *BadgeGrantHandlerTestSuite) TestGrantBadgeSuccess() {
+	grant := db.BadgeGrant{OrgID: 1, BadgeID: 2, ActorID: 1, UserID: 7, Note: "Won the July hackathon"}
+	grantedBy := 1
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{ID: 7, OrgID: 1}, nil)
+	suite.dbMock.On("GrantBadge", mock.Anything, grant).Return(db.UserBadge{
+		ID: 3, UserID: 7, BadgeID: 2, AwardedAt: 1594512000, Source: db.ManualBadgeAward, GrantedBy: &grantedBy, Note: grant.Note,
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/grants",
+		"/organizations/1/badges/2/grants",
+		`{"user_id":7,"note":"Won the July hackathon"}`,
+		testAdmin,
+		grantBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":{"id":3,"user_id":7,"badge_id":2,"name":"Hackathon winner","tier":"","achieved_count":0,"awarded_at":1594512000,"source":"manual","granted_by":1,"note":"Won the July hackathon","image":null}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *BadgeGrantHandlerTestSuite) TestGrantBadgeWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 1).Return(db.Role{ID: 1, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/grants",
+		"/organizations/1/badges/2/grants",
+		`{"user_id":7,"note":"Won the July hackathon"}`,
+		db.User{ID: 3, OrgID: 1, RoleID: 1},
+		grantBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GrantBadge", mock.Anything, mock.Anything)
+}
+
+func (suite *BadgeGrantHandlerTestSuite) TestGrantBadgeAlreadyAwarded() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{ID: 7, OrgID: 1}, nil)
+	suite.dbMock.On("GrantBadge", mock.Anything, mock.Anything).Return(db.UserBadge{}, ae.ErrBadgeAlreadyAwarded)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/grants",
+		"/organizations/1/badges/2/grants",
+		`{"user_id":7,"note":"Won the July hackathon"}`,
+		testAdmin,
+		grantBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"message":"User already holds this badge"}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusConflict, recorder.Code)
+}
+
+func (suite *BadgeGrantHandlerTestSuite) TestGrantBadgeOfAnotherOrganization() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(db.Badge{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/grants",
+		"/organizations/1/badges/2/grants",
+		`{"user_id":7,"note":"Won the July hackathon"}`,
+		testAdmin,
+		grantBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GrantBadge", mock.Anything, mock.Anything)
+}
+
+func (suite *BadgeGrantHandlerTestSuite) TestRevokeAutomaticBadge() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{ID: 7, OrgID: 1}, nil)
+	suite.dbMock.On("RevokeBadge", mock.Anything, mock.Anything).Return(db.BadgeAwardAuditEntry{}, ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/revocations",
+		"/organizations/1/badges/2/revocations",
+		`{"user_id":7,"note":"Granted by mistake"}`,
+		testAdmin,
+		revokeBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"message":"User holds no granted badge to revoke"}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+}
@@ -42,23 +42,7 @@ func createBadgeHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		errorResp, valid := badge.Validate()
-		if !valid {
-			_, err := json.Marshal(errorResp)
-			if err != nil {
-				logger.WithField("err", err.Error()).Error("Error marshaling badge data")
-				repsonse(rw, http.StatusInternalServerError, errorResponse{
-					Error: messageObject{
-						Message: "Internal server error",
-					},
-				})
-				return
-			}
-			repsonse(rw, http.StatusBadRequest, errorResponse{
-				Error: messageObject{
-					Message: "Invalid json request body",
-				},
-			})
+		if !validateBadge(rw, req, deps, org_id, badge) {
 			return
 		}
 
@@ -74,6 +58,16 @@ func createBadgeHandler(deps Dependencies) http.HandlerFunc {
 			logger.WithField("err", err.Error()).Error("Error create badge")
 			return
 		}
+
+		createdBadge.Image, err = signBadgeImage(req, deps, createdBadge.ImageKey)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
 		repsonse(rw, http.StatusOK, successResponse{Data: createdBadge})
 
 	})
@@ -104,6 +98,26 @@ func listBadgesHandler(deps Dependencies) http.HandlerFunc {
 			})
 			return
 		}
+
+		err = localizeBadges(req, deps, org_id, badges)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		err = signBadgeImages(req, deps, badges)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
 		repsonse(rw, http.StatusOK, successResponse{Data: badges})
 
 	})
@@ -146,24 +160,7 @@ func updateBadgeHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		errResp, valid := badge.Validate()
-		if !valid {
-			_, err := json.Marshal(errResp)
-			if err != nil {
-				logger.WithField("err", err.Error()).Error("Error marshaling badge data")
-				repsonse(rw, http.StatusInternalServerError, errorResponse{
-					Error: messageObject{
-						Message: "Internal server error",
-					},
-				})
-				return
-			}
-
-			repsonse(rw, http.StatusBadRequest, errorResponse{
-				Error: messageObject{
-					Message: "Invalid json request body",
-				},
-			})
+		if !validateBadge(rw, req, deps, org_id, badge) {
 			return
 		}
 
@@ -181,6 +178,16 @@ func updateBadgeHandler(deps Dependencies) http.HandlerFunc {
 			logger.WithField("err", err.Error()).Error("Error update badge")
 			return
 		}
+
+		updatedBadge.Image, err = signBadgeImage(req, deps, updatedBadge.ImageKey)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
 		repsonse(rw, http.StatusOK, successResponse{Data: updatedBadge})
 
 	})
@@ -226,6 +233,29 @@ func showBadgeHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		names, err := localizedBadgeNames(req, deps, org_id)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+		if name, ok := names[latestbadge.ID]; ok {
+			latestbadge.Name = name
+		}
+
+		latestbadge.Image, err = signBadgeImage(req, deps, latestbadge.ImageKey)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
 		repsonse(rw, http.StatusOK, successResponse{Data: latestbadge})
 
 	})
@@ -256,7 +286,7 @@ func deleteBadgeHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		err = deps.Store.DeleteBadge(req.Context(), org_id, badge_id)
+		objectKeys, err := deps.Store.DeleteBadge(req.Context(), org_id, badge_id)
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while deleting badge")
 			repsonse(rw, http.StatusInternalServerError, errorResponse{
@@ -266,8 +296,56 @@ func deleteBadgeHandler(deps Dependencies) http.HandlerFunc {
 			})
 			return
 		}
+		deleteStoredObjects(req.Context(), deps, objectKeys)
 
 		rw.WriteHeader(http.StatusOK)
 		rw.Header().Add("Content-Type", "application/json")
 	})
 }
+
+// validateBadge - writes the error response and returns false when the badge is invalid,
+// including criteria restricted to core values of another organization
+func validateBadge(rw http.ResponseWriter, req *http.Request, deps Dependencies, organizationID int, badge db.Badge) (ok bool) {
+	errResp, valid := badge.Validate()
+	fields := errResp["error"].Fields
+	if fields == nil {
+		fields = make(map[string]string)
+	}
+
+	if valid && badge.Criteria != nil && len(badge.Criteria.CoreValueIDs) > 0 {
+		coreValues, err := deps.Store.ListCoreValues(req.Context(), int64(organizationID), false)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching core values")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		orgCoreValues := make(map[int64]bool)
+		for _, coreValue := range coreValues {
+			orgCoreValues[coreValue.ID] = true
+		}
+		for _, coreValueID := range badge.Criteria.CoreValueIDs {
+			if !orgCoreValues[coreValueID] {
+				fields["criteria.core_value_ids"] = "Must be core values of the organization"
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "invalid-badge",
+				Fields:        fields,
+				messageObject: messageObject{"Please provide valid badge data"},
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
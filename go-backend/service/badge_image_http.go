@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/config"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// badgeImageUploadSlot - response for an upload slot: the pending upload plus where to PUT the file
+type badgeImageUploadSlot struct {
+	db.BadgeImageUpload
+	UploadURL string `json:"upload_url"`
+}
+
+// @Title createBadgeImageUploadHandler
+// @Description hand out an upload slot for new badge artwork, admins only
+// @Router /organizations/:organization_id/badges/:id/image_uploads [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func createBadgeImageUploadHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, badge, ok := badgeForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		var upload db.BadgeImageUpload
+		err := json.NewDecoder(req.Body).Decode(&upload)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		upload.BadgeID = badge.ID
+		upload.UploadedBy = actor.ID
+
+		uploadURL, ok := createUploadSlot(rw, req, deps, badgeImageUploadKind(), upload.Validate, func(ctx context.Context) (string, string, error) {
+			upload, err = deps.Store.CreateBadgeImageUpload(ctx, upload)
+			return upload.ObjectKey, upload.ContentType, err
+		})
+		if !ok {
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: badgeImageUploadSlot{
+			BadgeImageUpload: upload,
+			UploadURL:        uploadURL,
+		}})
+	})
+}
+
+// @Title confirmBadgeImageUploadHandler
+// @Description verify uploaded badge artwork, generate its thumbnails and make it the badge's image, admins only
+// @Router /organizations/:organization_id/badges/:id/image_uploads/:upload_id/confirm [post]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func confirmBadgeImageUploadHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, badge, ok := badgeForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		uploadID, err := strconv.ParseInt(mux.Vars(req)["upload_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error upload_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		upload, err := deps.Store.GetBadgeImageUpload(req.Context(), badge.ID, uploadID)
+		if err != nil {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Pending badge image upload not found",
+				},
+			})
+			return
+		}
+
+		pending := pendingUpload{
+			uploadKind: badgeImageUploadKind(),
+			objectKey:  upload.ObjectKey,
+			verify:     upload.VerifyUpload,
+			discard: func(ctx context.Context) error {
+				return deps.Store.DeleteBadgeImageUpload(ctx, upload.BadgeID, upload.ID)
+			},
+		}
+		if _, ok = verifyUploadedObject(rw, req, deps, pending); !ok {
+			return
+		}
+
+		ok = storeResizedImages(rw, req, deps, pending, resizedImages{
+			format:       upload.Format(),
+			maxDimension: db.MaxBadgeImageDimension,
+			sizes:        db.BadgeThumbnailSizes,
+			objectKey: func(size int) string {
+				return db.BadgeThumbnailKey(upload.ObjectKey, size)
+			},
+			contentType: db.BadgeThumbnailContentType,
+		})
+		if !ok {
+			return
+		}
+
+		updatedBadge, err := deps.Store.ConfirmBadgeImageUpload(req.Context(), badge.OrganizationID, upload)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Pending badge image upload not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while confirming badge image upload")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		// the replaced artwork is no longer referenced by anything
+		deleteStoredObjects(req.Context(), deps, db.BadgeImageObjectKeys(badge.ImageKey))
+
+		updatedBadge.Image, err = signBadgeImage(req, deps, updatedBadge.ImageKey)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedBadge})
+	})
+}
+
+// badgeForAdmin - checks the actor is an admin of the badge's organization and fetches the badge,
+// writing the error response and returning false when the request can't go ahead
+func badgeForAdmin(rw http.ResponseWriter, req *http.Request, deps Dependencies) (actor db.User, badge db.Badge, ok bool) {
+	vars := mux.Vars(req)
+	organizationID, err := strconv.Atoi(vars["organization_id"])
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	badgeID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error id key is missing")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	actor, err = getCurrentActor(req)
+	if err != nil {
+		currentActorErrorResponse(rw, err)
+		return
+	}
+
+	if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+		return
+	}
+
+	badge, err = deps.Store.ShowBadge(req.Context(), db.Badge{ID: badgeID, OrganizationID: organizationID})
+	if err == sql.ErrNoRows {
+		repsonse(rw, http.StatusNotFound, errorResponse{
+			Error: messageObject{
+				Message: "Badge not found",
+			},
+		})
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching badge")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
+
+// badgeImageUploadKind - badge artwork is stored in the images bucket
+func badgeImageUploadKind() uploadKind {
+	return uploadKind{bucket: config.ImagesBucket(), errorCode: "invalid-badge-image", name: "badge image"}
+}
+
+// signBadgeImage - short-lived download URLs of the artwork and its thumbnails, nil when there is no artwork
+func signBadgeImage(req *http.Request, deps Dependencies, imageKey string) (image *db.BadgeImage, err error) {
+	if imageKey == "" {
+		return
+	}
+
+	bucket := config.ImagesBucket()
+	signedURL, err := deps.AWSStore.GetAWSS3DownloadURL(req.Context(), bucket, imageKey)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while retrieving download URL")
+		return
+	}
+
+	signed := db.BadgeImage{URL: signedURL.S3SignedURL, Thumbnails: []db.BadgeThumbnail{}}
+	for _, size := range db.BadgeThumbnailSizes {
+		signedURL, err = deps.AWSStore.GetAWSS3DownloadURL(req.Context(), bucket, db.BadgeThumbnailKey(imageKey, size))
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while retrieving download URL")
+			return
+		}
+		signed.Thumbnails = append(signed.Thumbnails, db.BadgeThumbnail{Size: size, URL: signedURL.S3SignedURL})
+	}
+
+	image = &signed
+	return
+}
+
+// signBadgeImages - fills in the image of each badge
+func signBadgeImages(req *http.Request, deps Dependencies, badges []db.Badge) (err error) {
+	for i := range badges {
+		badges[i].Image, err = signBadgeImage(req, deps, badges[i].ImageKey)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
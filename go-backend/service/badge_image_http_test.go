@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+
+	"joshsoftware/peerly/aws"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var testBadgeImageUpload = db.BadgeImageUpload{
+	ID:          4,
+	BadgeID:     2,
+	UploadedBy:  1,
+	ObjectKey:   "badges/2/new.png",
+	ContentType: "image/png",
+	SizeBytes:   1024,
+}
+
+type BadgeImageHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock  *db.DBMockStore
+	awsMock *aws.AWSMockStore
+}
+
+func (suite *BadgeImageHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.awsMock = &aws.AWSMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+}
+
+func (suite *BadgeImageHandlerTestSuite) deps() Dependencies {
+	return Dependencies{Store: suite.dbMock, AWSStore: suite.awsMock}
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestCreateBadgeImageUploadSuccess() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("CreateBadgeImageUpload", mock.Anything, db.BadgeImageUpload{
+		BadgeID: 2, UploadedBy: 1, ContentType: "image/png", SizeBytes: 1024,
+	}).Return(testBadgeImageUpload, nil)
+	suite.awsMock.On("GetAWSS3UploadURL", mock.Anything, "peerly-images", "badges/2/new.png", "image/png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://upload.example.com"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads",
+		"/organizations/1/badges/2/image_uploads",
+		`{"content_type":"image/png","size_bytes":1024}`,
+		testAdmin,
+		createBadgeImageUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":4,"badge_id":2,"uploaded_by":1,"content_type":"image/png","size_bytes":1024,"upload_url":"https://upload.example.com"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestCreateBadgeImageUploadWithUnsupportedType() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads",
+		"/organizations/1/badges/2/image_uploads",
+		`{"content_type":"image/svg+xml","size_bytes":1024}`,
+		testAdmin,
+		createBadgeImageUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-badge-image","message":"Invalid badge image data","fields":{"content_type":"Must be image/png, image/jpeg or image/gif"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateBadgeImageUpload", mock.Anything, mock.Anything)
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestCreateBadgeImageUploadWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 1).Return(db.Role{ID: 1, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads",
+		"/organizations/1/badges/2/image_uploads",
+		`{"content_type":"image/png","size_bytes":1024}`,
+		db.User{ID: 3, OrgID: 1, RoleID: 1},
+		createBadgeImageUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateBadgeImageUpload", mock.Anything, mock.Anything)
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestConfirmBadgeImageUploadSuccess() {
+	artwork := image.NewRGBA(image.Rect(0, 0, 512, 256))
+	var buf bytes.Buffer
+	png.Encode(&buf, artwork)
+	upload := testBadgeImageUpload
+	upload.SizeBytes = int64(buf.Len())
+
+	badge := testGrantedBadge
+	badge.ImageKey = "badges/2/old.png"
+	updatedBadge := testGrantedBadge
+	updatedBadge.ImageKey = upload.ObjectKey
+
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(badge, nil)
+	suite.dbMock.On("GetBadgeImageUpload", mock.Anything, 2, int64(4)).Return(upload, nil)
+	suite.dbMock.On("ConfirmBadgeImageUpload", mock.Anything, 1, upload).Return(updatedBadge, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-images", "badges/2/new.png").Return(
+		aws.S3ObjectInfo{Size: upload.SizeBytes, ContentType: "image/png"}, nil)
+	suite.awsMock.On("GetAWSS3Object", mock.Anything, "peerly-images", "badges/2/new.png").Return(buf.Bytes(), nil)
+	for _, key := range []string{"badges/2/new_64.png", "badges/2/new_128.png", "badges/2/new_256.png"} {
+		suite.awsMock.On("PutAWSS3Object", mock.Anything, "peerly-images", key, "image/png", mock.Anything).Return(nil)
+	}
+	for _, key := range []string{"badges/2/old.png", "badges/2/old_64.png", "badges/2/old_128.png", "badges/2/old_256.png"} {
+		suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", key).Return(nil)
+	}
+	suite.awsMock.On("GetAWSS3DownloadURL", mock.Anything, "peerly-images", mock.Anything).Return(
+		aws.S3SignedURL{S3SignedURL: "https://download.example.com"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads/{upload_id:[0-9]+}/confirm",
+		"/organizations/1/badges/2/image_uploads/4/confirm",
+		"",
+		testAdmin,
+		confirmBadgeImageUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":2,"name":"Hackathon winner","org_id":1,"hi5_count_required":100,"hi5_frequency":"","image":{"url":"https://download.example.com","thumbnails":[{"size":64,"url":"https://download.example.com"},{"size":128,"url":"https://download.example.com"},{"size":256,"url":"https://download.example.com"}]}}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestConfirmBadgeImageUploadThatIsNotAnImage() {
+	upload := testBadgeImageUpload
+	upload.SizeBytes = 9
+
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("GetBadgeImageUpload", mock.Anything, 2, int64(4)).Return(upload, nil)
+	suite.dbMock.On("DeleteBadgeImageUpload", mock.Anything, 2, int64(4)).Return(nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-images", "badges/2/new.png").Return(
+		aws.S3ObjectInfo{Size: 9, ContentType: "image/png"}, nil)
+	suite.awsMock.On("GetAWSS3Object", mock.Anything, "peerly-images", "badges/2/new.png").Return([]byte("not a png"), nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "badges/2/new.png").Return(nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads/{upload_id:[0-9]+}/confirm",
+		"/organizations/1/badges/2/image_uploads/4/confirm",
+		"",
+		testAdmin,
+		confirmBadgeImageUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-badge-image","message":"Invalid badge image upload","fields":{"file":"Must be a valid image of its content type"}}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+	suite.dbMock.AssertNotCalled(suite.T(), "ConfirmBadgeImageUpload", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestConfirmBadgeImageUploadNotUploaded() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("GetBadgeImageUpload", mock.Anything, 2, int64(4)).Return(testBadgeImageUpload, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-images", "badges/2/new.png").Return(
+		aws.S3ObjectInfo{}, errors.New("NotFound"))
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads/{upload_id:[0-9]+}/confirm",
+		"/organizations/1/badges/2/image_uploads/4/confirm",
+		"",
+		testAdmin,
+		confirmBadgeImageUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-badge-image","message":"Invalid badge image upload","fields":{"file":"File has not been uploaded"}}}`, recorder.Body.String())
+	suite.awsMock.AssertNotCalled(suite.T(), "GetAWSS3Object", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *BadgeImageHandlerTestSuite) TestDeleteBadgeRemovesStoredObjects() {
+	suite.dbMock.On("DeleteBadge", mock.Anything, 1, 2).Return([]string{"badges/2/art.png", "badges/2/art_64.png"}, nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "badges/2/art.png").Return(nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "badges/2/art_64.png").Return(nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodDelete,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}",
+		"/organizations/1/badges/2",
+		"",
+		deleteBadgeHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "DeleteBadge", mock.Anything, 1, 2)
+	suite.awsMock.AssertExpectations(suite.T())
+}
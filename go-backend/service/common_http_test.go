@@ -12,23 +12,51 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/urfave/negroni"
 	"joshsoftware/peerly/config"
+	"joshsoftware/peerly/db"
 )
 
 func TestExampleTestSuite(t *testing.T) {
 	config.Load("application_test")
+	suite.Run(t, new(CurrentActorTestSuite))
 	suite.Run(t, new(UsersHandlerTestSuite))
 	suite.Run(t, new(OrganizationHandlerTestSuite))
 	suite.Run(t, new(RecognitionHi5HandlerTestSuite))
+	suite.Run(t, new(RecognitionsHandlerTestSuite))
+	suite.Run(t, new(RecognitionAttachmentHandlerTestSuite))
+	suite.Run(t, new(RecognitionRuleHandlerTestSuite))
+	suite.Run(t, new(RecognitionFeedHandlerTestSuite))
 	suite.Run(t, new(CoreValueHandlerTestSuite))
 	suite.Run(t, new(ReportedRecognitionHandlerTestSuite))
 	suite.Run(t, new(RecognitionModerationHandlerTestSuite))
+	suite.Run(t, new(Hi5QuotaLedgerHandlerTestSuite))
+	suite.Run(t, new(Hi5QuotaScheduleHandlerTestSuite))
+	suite.Run(t, new(Hi5QuotaPolicyHandlerTestSuite))
+	suite.Run(t, new(UserBadgeHandlerTestSuite))
+	suite.Run(t, new(BadgeGrantHandlerTestSuite))
+	suite.Run(t, new(BadgeImageHandlerTestSuite))
+	suite.Run(t, new(CoreValueThumbnailHandlerTestSuite))
+	suite.Run(t, new(TranslationHandlerTestSuite))
+	suite.Run(t, new(ModerationQueueHandlerTestSuite))
+	suite.Run(t, new(ModerationSettingsHandlerTestSuite))
+	suite.Run(t, new(ContentFilterHandlerTestSuite))
+	suite.Run(t, new(ReportReasonHandlerTestSuite))
+	suite.Run(t, new(RecognitionAppealHandlerTestSuite))
+	suite.Run(t, new(ModerationMetricsHandlerTestSuite))
 }
 
 // path: is used to configure router path (eg: /users/{id})
 // requestURL: current request path (eg: /users/1)
 func makeHTTPCall(method, path, requestURL, body string, handlerFunc http.HandlerFunc) (recorder *httptest.ResponseRecorder) {
+	return makeHTTPCallWithHeaders(method, path, requestURL, body, nil, handlerFunc)
+}
+
+// headers: extra request headers (eg: Accept-Language)
+func makeHTTPCallWithHeaders(method, path, requestURL, body string, headers map[string]string, handlerFunc http.HandlerFunc) (recorder *httptest.ResponseRecorder) {
 	// create a http request using the given parameters
 	req, _ := http.NewRequest(method, requestURL, strings.NewReader(body))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
 
 	// test recorder created for capturing api responses
 	recorder = httptest.NewRecorder()
@@ -45,12 +73,25 @@ func makeHTTPCall(method, path, requestURL, body string, handlerFunc http.Handle
 // path: is used to configure router path (eg: /users/{id})
 // requestURL: current request path (eg: /users/1)
 func makeHTTPCallWithJWTMiddleware(method, path, requestURL, body string, handlerFunc http.HandlerFunc) (recorder *httptest.ResponseRecorder) {
+	return makeHTTPCallAsActor(method, path, requestURL, body, db.User{ID: 1, OrgID: 1}, handlerFunc)
+}
+
+// actor: the user jwtAuthMiddleware would resolve from the token and set as the current actor
+func makeHTTPCallAsActor(method, path, requestURL, body string, actor db.User, handlerFunc http.HandlerFunc) (recorder *httptest.ResponseRecorder) {
+	return makeHTTPCallAsActorWithHeaders(method, path, requestURL, body, actor, nil, handlerFunc)
+}
+
+// headers: extra request headers (eg: Accept-Language)
+func makeHTTPCallAsActorWithHeaders(method, path, requestURL, body string, actor db.User, headers map[string]string, handlerFunc http.HandlerFunc) (recorder *httptest.ResponseRecorder) {
 	// create jwt token with userID
-	JWTToken, _ := newJWT(1)
+	JWTToken, _ := newJWT(actor.ID, actor.OrgID)
 
 	// create a http request using the given parameters
 	req, _ := http.NewRequest(method, requestURL, strings.NewReader(body))
-	req.Header.Set("Authorization", "Bearer " + JWTToken)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Authorization", "Bearer "+JWTToken)
 
 	// test recorder created for capturing api responses
 	recorder = httptest.NewRecorder()
@@ -65,6 +106,9 @@ func makeHTTPCallWithJWTMiddleware(method, path, requestURL, body string, handle
 	})
 	router.Handle(path, negroni.New(
 		negroni.HandlerFunc(jwtMiddleware.HandlerWithNext),
+		negroni.HandlerFunc(func(rw http.ResponseWriter, req *http.Request, next http.HandlerFunc) {
+			next(rw, withCurrentActor(req, actor))
+		}),
 		negroni.Wrap(http.HandlerFunc(handlerFunc)),
 	)).Methods(method)
 
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title getContentFilterSettingsHandler
+// @Description get the content filter settings of an organization, admins only
+// @Router /organizations/:organization_id/content_filter [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getContentFilterSettingsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		settings, err := deps.Store.GetContentFilterSettings(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching content filter settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: settings})
+	})
+}
+
+// @Title updateContentFilterSettingsHandler
+// @Description update the content filter settings of an organization, admins only
+// @Router /organizations/:organization_id/content_filter [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func updateContentFilterSettingsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var settings db.ContentFilterSettings
+		err = json.NewDecoder(req.Body).Decode(&settings)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		settings.OrgID = organizationID
+
+		ok, errFields := settings.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-content-filter-settings",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid content filter settings"},
+				},
+			})
+			return
+		}
+
+		updatedSettings, err := deps.Store.UpdateContentFilterSettings(req.Context(), settings)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while updating content filter settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedSettings})
+	})
+}
+
+// @Title listContentBlocklistHandler
+// @Description terms blocked in recognitions and Hi5 comments of an organization, admins only
+// @Router /organizations/:organization_id/content_filter/blocklist [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listContentBlocklistHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		terms, err := deps.Store.ListContentBlocklistTerms(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching content blocklist")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: terms})
+	})
+}
+
+// @Title createContentBlocklistTermHandler
+// @Description block a term in recognitions and Hi5 comments of an organization, * matches any run of letters or digits, admins only
+// @Router /organizations/:organization_id/content_filter/blocklist [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func createContentBlocklistTermHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var term db.ContentBlocklistTerm
+		actorErrFields, err := decodeWithoutActorFields(req, &term, "created_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		createdBy := int64(actor.ID)
+		term.OrgID = organizationID
+		term.CreatedBy = &createdBy
+
+		ok, errFields := term.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-blocklist-term",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid blocklist term"},
+				},
+			})
+			return
+		}
+
+		createdTerm, err := deps.Store.CreateContentBlocklistTerm(req.Context(), term)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while creating content blocklist term")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: createdTerm})
+	})
+}
+
+// @Title deleteContentBlocklistTermHandler
+// @Description unblock a term of an organization, admins only
+// @Router /organizations/:organization_id/content_filter/blocklist/:id [delete]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func deleteContentBlocklistTermHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		termID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		err = deps.Store.DeleteContentBlocklistTerm(req.Context(), organizationID, termID)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Blocklist term not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while deleting content blocklist term")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, nil)
+	})
+}
+
+// filterContent - runs the organization's content filter on text the user wrote in field. It responds
+// and returns false when the text is rejected, otherwise it returns the text to save, masked where the
+// organization asked for it, and what should be flagged for moderation once the text is saved.
+func filterContent(rw http.ResponseWriter, req *http.Request, deps Dependencies, organizationID int, field, text string) (filtered string, flags []string, ok bool) {
+	settings, err := deps.Store.GetContentFilterSettings(req.Context(), organizationID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching content filter settings")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	result := db.FilterContent(settings, text)
+	if result.Rejected != "" {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "content-rejected",
+				Fields:        map[string]string{field: "Can't contain " + result.Rejected},
+				messageObject: messageObject{"Content breaks organization content rules"},
+			},
+		})
+		return
+	}
+
+	for _, flag := range result.Flags {
+		flags = append(flags, fmt.Sprintf("%s contains %s", field, flag))
+	}
+	return result.Text, flags, true
+}
+
+// flagContent - puts the recognition in the moderation queue with what the content filter flagged.
+// Flagging is best effort: a failure is only logged since the text is already saved.
+func flagContent(ctx context.Context, deps Dependencies, recognitionID int64, flags []string) {
+	if len(flags) == 0 {
+		return
+	}
+
+	err := deps.Store.FlagRecognitionContent(ctx, recognitionID, flags)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while flagging recognition content")
+	}
+}
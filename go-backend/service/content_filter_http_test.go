@@ -0,0 +1,147 @@
+package service
+
+import (
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ContentFilterHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *ContentFilterHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestUpdateContentFilterSettingsSuccess() {
+	settings := db.ContentFilterSettings{OrgID: 1, BlocklistAction: "flag", PIIAction: "reject", MaxTextLength: 500, MaxLengthAction: "mask"}
+	suite.dbMock.On("UpdateContentFilterSettings", mock.Anything, settings).Return(settings, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/content_filter",
+		"/organizations/1/content_filter",
+		`{"blocklist_action":"flag","pii_action":"reject","max_text_length":500,"max_length_action":"mask"}`,
+		testAdmin,
+		updateContentFilterSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"org_id":1,"blocklist_action":"flag","pii_action":"reject","max_text_length":500,"max_length_action":"mask"}}`, recorder.Body.String())
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestUpdateContentFilterSettingsWithInvalidAction() {
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/content_filter",
+		"/organizations/1/content_filter",
+		`{"blocklist_action":"delete","pii_action":"mask","max_text_length":500,"max_length_action":"reject"}`,
+		testAdmin,
+		updateContentFilterSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-content-filter-settings","message":"Invalid content filter settings","fields":{"blocklist_action":"Must be one of off, reject, flag or mask"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateContentFilterSettings", mock.Anything, mock.Anything)
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestCreateContentBlocklistTermSuccess() {
+	createdBy := int64(1)
+	term := db.ContentBlocklistTerm{OrgID: 1, Term: "slack*", CreatedBy: &createdBy}
+	suite.dbMock.On("CreateContentBlocklistTerm", mock.Anything, term).
+		Return(db.ContentBlocklistTerm{ID: 4, OrgID: 1, Term: "slack*", CreatedBy: &createdBy, CreatedAt: 100}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/content_filter/blocklist",
+		"/organizations/1/content_filter/blocklist",
+		`{"term":" Slack* "}`,
+		testAdmin,
+		createContentBlocklistTermHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":4,"org_id":1,"term":"slack*","created_by":1,"created_at":100}}`, recorder.Body.String())
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestCreateContentBlocklistTermWhenNotAdmin() {
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/content_filter/blocklist",
+		"/organizations/1/content_filter/blocklist",
+		`{"term":"slack*"}`,
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		createContentBlocklistTermHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateContentBlocklistTerm", mock.Anything, mock.Anything)
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestDeleteContentBlocklistTermNotFound() {
+	suite.dbMock.On("DeleteContentBlocklistTerm", mock.Anything, 1, int64(9)).Return(ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/organizations/{organization_id:[0-9]+}/content_filter/blocklist/{id:[0-9]+}",
+		"/organizations/1/content_filter/blocklist/9",
+		"",
+		testAdmin,
+		deleteContentBlocklistTermHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Blocklist term not found"}}`, recorder.Body.String())
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestCreateRecognitionWithBlockedTerm() {
+	settings := db.DefaultContentFilterSettings(22)
+	settings.Blocklist = []string{"slack*"}
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 22).Return(db.DefaultRecognitionRuleSettings(22), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 22).Return(settings, nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(22), mock.Anything).Return(db.CoreValue{ID: 1, OrgID: 22}, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		`{"core_value_id":1,"text":"thanks for not being a slacker","given_for":1}`,
+		db.User{ID: 2, OrgID: 22},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"content-rejected","message":"Content breaks organization content rules","fields":{"text":"Can't contain blocked terms"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything, mock.Anything)
+}
+
+func (suite *ContentFilterHandlerTestSuite) TestCreateRecognitionHi5FlagsComment() {
+	settings := db.DefaultContentFilterSettings(1)
+	settings.PIIAction = db.ContentActionFlag
+	hi5 := db.RecognitionHi5{RecognitionID: 1, Comment: "Ping me at jo@example.com", GivenBy: 1}
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(settings, nil)
+	suite.dbMock.On("CreateRecognitionHi5", mock.Anything, hi5, 1).Return(nil)
+	suite.dbMock.On("FlagRecognitionContent", mock.Anything, int64(1), []string{"comment contains personal information (email)"}).Return(nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, testPublishedRecognition.GivenFor).Return([]db.UserBadge{}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		`{"recognition_id": 1, "comment": "Ping me at jo@example.com"}`,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, hi5, 1)
+	suite.dbMock.AssertCalled(suite.T(), "FlagRecognitionContent", mock.Anything, int64(1), []string{"comment contains personal information (email)"})
+}
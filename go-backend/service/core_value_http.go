@@ -7,9 +7,12 @@ import (
 
 	"github.com/gorilla/mux"
 	logger "github.com/sirupsen/logrus"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 )
 
+// listCoreValuesHandler - lists the active core values, archived ones too with ?include_archived=true.
+// With ?tree=true sub core values are nested under their parents.
 func listCoreValuesHandler(deps Dependencies) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
@@ -20,7 +23,8 @@ func listCoreValuesHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		coreValues, err := deps.Store.ListCoreValues(req.Context(), organisationID)
+		params := req.URL.Query()
+		coreValues, err := deps.Store.ListCoreValues(req.Context(), organisationID, params.Get("include_archived") == "true")
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while fetching data")
 			rw.WriteHeader(http.StatusInternalServerError)
@@ -32,6 +36,31 @@ func listCoreValuesHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		err = localizeCoreValues(req, deps, organisationID, coreValues)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		err = signCoreValueThumbnails(req, deps, coreValues)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
+		if params.Get("tree") == "true" {
+			repsonse(rw, http.StatusOK, successResponse{Data: db.CoreValueTree(coreValues)})
+			return
+		}
+
 		repsonse(rw, http.StatusOK, successResponse{Data: coreValues})
 	})
 }
@@ -64,6 +93,28 @@ func getCoreValueHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		localized := []db.CoreValue{coreValue}
+		err = localizeCoreValues(req, deps, organisationID, localized)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+		coreValue = localized[0]
+
+		err = signCoreValueThumbnail(req, deps, &coreValue)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
 		repsonse(rw, http.StatusOK, successResponse{Data: coreValue})
 	})
 }
@@ -117,7 +168,8 @@ func createCoreValueHandler(deps Dependencies) http.HandlerFunc {
 	})
 }
 
-func deleteCoreValueHandler(deps Dependencies) http.HandlerFunc {
+// archiveCoreValueHandler - DELETE archives the core value along with its sub core values, admins only
+func archiveCoreValueHandler(deps Dependencies) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		vars := mux.Vars(req)
 		organisationID, err := strconv.ParseInt(vars["organisation_id"], 10, 64)
@@ -134,9 +186,19 @@ func deleteCoreValueHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		err = deps.Store.DeleteCoreValue(req.Context(), organisationID, coreValueID)
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, int(organisationID)) {
+			return
+		}
+
+		err = deps.Store.ArchiveCoreValue(req.Context(), organisationID, coreValueID)
 		if err != nil {
-			logger.WithField("err", err.Error()).Error("Error while deleting core value")
+			logger.WithField("err", err.Error()).Error("Error while archiving core value")
 			repsonse(rw, http.StatusInternalServerError, errorResponse{
 				Error: messageObject{
 					Message: "Internal server error",
@@ -201,6 +263,96 @@ func updateCoreValueHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		err = signCoreValueThumbnail(req, deps, &resp)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
 		repsonse(rw, http.StatusOK, successResponse{Data: resp})
 	})
 }
+
+// reorderCoreValuesHandler - sets the order of the core values sharing a parent, admins only
+func reorderCoreValuesHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organisationID, err := strconv.ParseInt(vars["organisation_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing organisation_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, int(organisationID)) {
+			return
+		}
+
+		var order db.CoreValueOrder
+		err = json.NewDecoder(req.Body).Decode(&order)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+
+		ok, errFields := order.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-core-value-order",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid core value order"},
+				},
+			})
+			return
+		}
+
+		coreValues, err := deps.Store.ReorderCoreValues(req.Context(), organisationID, order)
+		if err == ae.ErrCoreValueOrderMismatch {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-core-value-order",
+					Fields:        map[string]string{"core_value_ids": "Must list every active core value under the parent exactly once"},
+					messageObject: messageObject{"Invalid core value order"},
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while reordering core values")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		err = signCoreValueThumbnails(req, deps, coreValues)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: coreValues})
+	})
+}
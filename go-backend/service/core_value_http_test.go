@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 	"net/http"
 
@@ -21,7 +22,7 @@ func (suite *CoreValueHandlerTestSuite) SetupTest() {
 }
 
 func (suite *CoreValueHandlerTestSuite) TestListCoreValuesSuccess() {
-	suite.dbMock.On("ListCoreValues", mock.Anything, mock.Anything).Return(
+	suite.dbMock.On("ListCoreValues", mock.Anything, mock.Anything, false).Return(
 		[]db.CoreValue{
 			db.CoreValue{
 				ID:          1,
@@ -43,12 +44,12 @@ func (suite *CoreValueHandlerTestSuite) TestListCoreValuesSuccess() {
 	)
 
 	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
-	assert.Equal(suite.T(), `{"data":[{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null}]}`, recorder.Body.String())
+	assert.Equal(suite.T(), `{"data":[{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null,"position":0}]}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
 func (suite *CoreValueHandlerTestSuite) TestListCoreValuesWhenDBFailure() {
-	suite.dbMock.On("ListCoreValues", mock.Anything, mock.Anything).Return(
+	suite.dbMock.On("ListCoreValues", mock.Anything, mock.Anything, false).Return(
 		[]db.CoreValue{},
 		errors.New("error fetching core values"),
 	)
@@ -87,7 +88,7 @@ func (suite *CoreValueHandlerTestSuite) TestGetCoreValueSuccess() {
 	)
 
 	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
-	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null}}`, recorder.Body.String())
+	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null,"position":0}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
@@ -109,30 +110,34 @@ func (suite *CoreValueHandlerTestSuite) TestGetCoreValuesWhenDBFailure() {
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
-func (suite *CoreValueHandlerTestSuite) TestDeleteCoreValueSuccess() {
-	suite.dbMock.On("DeleteCoreValue", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+func (suite *CoreValueHandlerTestSuite) TestArchiveCoreValueSuccess() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("ArchiveCoreValue", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	recorder := makeHTTPCall(
+	recorder := makeHTTPCallAsActor(
 		http.MethodGet,
 		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}",
 		"/organisations/1/core_values/1",
 		"",
-		deleteCoreValueHandler(Dependencies{Store: suite.dbMock}),
+		testAdmin,
+		archiveCoreValueHandler(Dependencies{Store: suite.dbMock}),
 	)
 
 	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
-func (suite *CoreValueHandlerTestSuite) TestDeleteCoreValueWhenDBFailure() {
-	suite.dbMock.On("DeleteCoreValue", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("Error while deleting core value"))
+func (suite *CoreValueHandlerTestSuite) TestArchiveCoreValueWhenDBFailure() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("ArchiveCoreValue", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("Error while archiving core value"))
 
-	recorder := makeHTTPCall(
+	recorder := makeHTTPCallAsActor(
 		http.MethodGet,
 		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}",
 		"/organisations/1/core_values/1",
 		"",
-		deleteCoreValueHandler(Dependencies{Store: suite.dbMock}),
+		testAdmin,
+		archiveCoreValueHandler(Dependencies{Store: suite.dbMock}),
 	)
 
 	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
@@ -163,7 +168,7 @@ func (suite *CoreValueHandlerTestSuite) TestCreateCoreValueSuccess() {
 	)
 
 	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
-	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null}}`, recorder.Body.String())
+	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null,"position":0}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
@@ -288,7 +293,7 @@ func (suite *CoreValueHandlerTestSuite) TestUpdateCoreValueSuccess() {
 	)
 
 	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
-	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null}}`, recorder.Body.String())
+	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"text":"TEST","description":"Description TEST","parent_id":null,"thumbnail_url":null,"position":0}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
@@ -351,3 +356,131 @@ func (suite *CoreValueHandlerTestSuite) TestUpdateCoreValueWhenInvalidJSONFormat
 	assert.Equal(suite.T(), `{"error":{"message":"Invalid json request body"}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
+
+func (suite *CoreValueHandlerTestSuite) TestListCoreValuesAsTree() {
+	parentID := int64(1)
+	suite.dbMock.On("ListCoreValues", mock.Anything, int64(1), true).Return(
+		[]db.CoreValue{
+			{ID: 1, OrgID: 1, Text: "Teamwork", Description: "Working together"},
+			{ID: 2, OrgID: 1, Text: "Mentoring", Description: "Helping others grow", ParentID: &parentID},
+			{ID: 3, OrgID: 1, Text: "Quality", Description: "Doing it right", Position: 1},
+		},
+		nil,
+	)
+
+	recorder := makeHTTPCall(
+		http.MethodGet,
+		"/organisations/{organisation_id:[0-9]+}/core_values",
+		"/organisations/1/core_values?tree=true&include_archived=true",
+		"",
+		listCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":1,"org_id":1,"text":"Teamwork","description":"Working together","parent_id":null,"thumbnail_url":null,"position":0,"children":[{"id":2,"org_id":1,"text":"Mentoring","description":"Helping others grow","parent_id":1,"thumbnail_url":null,"position":0}]},{"id":3,"org_id":1,"text":"Quality","description":"Doing it right","parent_id":null,"thumbnail_url":null,"position":1,"children":[]}]}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *CoreValueHandlerTestSuite) TestReorderCoreValuesSuccess() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("ReorderCoreValues", mock.Anything, int64(1), db.CoreValueOrder{CoreValueIDs: []int64{3, 1}}).Return(
+		[]db.CoreValue{
+			{ID: 3, OrgID: 1, Text: "Quality", Description: "Doing it right"},
+			{ID: 1, OrgID: 1, Text: "Teamwork", Description: "Working together", Position: 1},
+		},
+		nil,
+	)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organisations/{organisation_id:[0-9]+}/core_values/order",
+		"/organisations/1/core_values/order",
+		`{"parent_id":null,"core_value_ids":[3,1]}`,
+		testAdmin,
+		reorderCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":3,"org_id":1,"text":"Quality","description":"Doing it right","parent_id":null,"thumbnail_url":null,"position":0},{"id":1,"org_id":1,"text":"Teamwork","description":"Working together","parent_id":null,"thumbnail_url":null,"position":1}]}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *CoreValueHandlerTestSuite) TestReorderCoreValuesWhenOrderDoesNotMatch() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("ReorderCoreValues", mock.Anything, int64(1), mock.Anything).Return([]db.CoreValue{}, ae.ErrCoreValueOrderMismatch)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organisations/{organisation_id:[0-9]+}/core_values/order",
+		"/organisations/1/core_values/order",
+		`{"core_value_ids":[3]}`,
+		testAdmin,
+		reorderCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-core-value-order","message":"Invalid core value order","fields":{"core_value_ids":"Must list every active core value under the parent exactly once"}}}`, recorder.Body.String())
+}
+
+func (suite *CoreValueHandlerTestSuite) TestReorderCoreValuesWithDuplicates() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organisations/{organisation_id:[0-9]+}/core_values/order",
+		"/organisations/1/core_values/order",
+		`{"core_value_ids":[3,3]}`,
+		testAdmin,
+		reorderCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-core-value-order","message":"Invalid core value order","fields":{"core_value_ids":"Can't contain duplicates"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ReorderCoreValues", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CoreValueHandlerTestSuite) TestArchiveCoreValueWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodDelete,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}",
+		"/organisations/1/core_values/1",
+		"",
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		archiveCoreValueHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ArchiveCoreValue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CoreValueHandlerTestSuite) TestReorderCoreValuesOfAnotherOrganization() {
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organisations/{organisation_id:[0-9]+}/core_values/order",
+		"/organisations/2/core_values/order",
+		`{"core_value_ids":[3,1]}`,
+		testAdmin,
+		reorderCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"User doesn't belong to given organization"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ReorderCoreValues", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CoreValueHandlerTestSuite) TestReorderCoreValuesWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organisations/{organisation_id:[0-9]+}/core_values/order",
+		"/organisations/1/core_values/order",
+		`{"core_value_ids":[3,1]}`,
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		reorderCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ReorderCoreValues", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/config"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// coreValueThumbnailUploadSlot - response for an upload slot: the pending upload plus where to PUT the file
+type coreValueThumbnailUploadSlot struct {
+	db.CoreValueThumbnailUpload
+	UploadURL string `json:"upload_url"`
+}
+
+// @Title createCoreValueThumbnailUploadHandler
+// @Description hand out an upload slot for a new core value thumbnail, admins only
+// @Router /organisations/:organisation_id/core_values/:id/thumbnail_uploads [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func createCoreValueThumbnailUploadHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, coreValue, ok := coreValueForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		var upload db.CoreValueThumbnailUpload
+		err := json.NewDecoder(req.Body).Decode(&upload)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		upload.CoreValueID = coreValue.ID
+		upload.UploadedBy = actor.ID
+
+		uploadURL, ok := createUploadSlot(rw, req, deps, coreValueThumbnailUploadKind(), upload.Validate, func(ctx context.Context) (string, string, error) {
+			upload, err = deps.Store.CreateCoreValueThumbnailUpload(ctx, upload)
+			return upload.ObjectKey, upload.ContentType, err
+		})
+		if !ok {
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: coreValueThumbnailUploadSlot{
+			CoreValueThumbnailUpload: upload,
+			UploadURL:                uploadURL,
+		}})
+	})
+}
+
+// @Title confirmCoreValueThumbnailUploadHandler
+// @Description verify an uploaded thumbnail, resize it and make it the core value's thumbnail, admins only
+// @Router /organisations/:organisation_id/core_values/:id/thumbnail_uploads/:upload_id/confirm [post]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func confirmCoreValueThumbnailUploadHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, coreValue, ok := coreValueForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		uploadID, err := strconv.ParseInt(mux.Vars(req)["upload_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error upload_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		upload, err := deps.Store.GetCoreValueThumbnailUpload(req.Context(), coreValue.ID, uploadID)
+		if err != nil {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Pending core value thumbnail upload not found",
+				},
+			})
+			return
+		}
+
+		pending := pendingUpload{
+			uploadKind: coreValueThumbnailUploadKind(),
+			objectKey:  upload.ObjectKey,
+			verify:     upload.VerifyUpload,
+			discard: func(ctx context.Context) error {
+				return deps.Store.DeleteCoreValueThumbnailUpload(ctx, upload.CoreValueID, upload.ID)
+			},
+		}
+		if _, ok = verifyUploadedObject(rw, req, deps, pending); !ok {
+			return
+		}
+
+		ok = storeResizedImages(rw, req, deps, pending, resizedImages{
+			format:       upload.Format(),
+			maxDimension: db.MaxCoreValueThumbnailDimension,
+			sizes:        []int{db.CoreValueThumbnailSize},
+			objectKey: func(int) string {
+				return upload.ThumbnailKey()
+			},
+			contentType: db.CoreValueThumbnailContentType,
+		})
+		if !ok {
+			return
+		}
+
+		updatedCoreValue, err := deps.Store.ConfirmCoreValueThumbnailUpload(req.Context(), coreValue.OrgID, upload)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Pending core value thumbnail upload not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while confirming core value thumbnail upload")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		// only the resized copy is served, and the replaced thumbnail is no longer referenced by anything
+		staleKeys := []string{upload.ObjectKey}
+		if coreValue.ThumbnailKey != "" {
+			staleKeys = append(staleKeys, coreValue.ThumbnailKey)
+		}
+		deleteStoredObjects(req.Context(), deps, staleKeys)
+
+		err = signCoreValueThumbnail(req, deps, &updatedCoreValue)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedCoreValue})
+	})
+}
+
+// coreValueForAdmin - checks the actor is an admin of the core value's organisation and fetches the
+// core value, writing the error response and returning false when the request can't go ahead
+func coreValueForAdmin(rw http.ResponseWriter, req *http.Request, deps Dependencies) (actor db.User, coreValue db.CoreValue, ok bool) {
+	vars := mux.Vars(req)
+	organisationID, err := strconv.ParseInt(vars["organisation_id"], 10, 64)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while parsing organisation_id from url")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	coreValueID, err := strconv.ParseInt(vars["id"], 10, 64)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while parsing core value id from url")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	actor, err = getCurrentActor(req)
+	if err != nil {
+		currentActorErrorResponse(rw, err)
+		return
+	}
+
+	if !requireOrgAdmin(rw, req, deps, actor, int(organisationID)) {
+		return
+	}
+
+	coreValue, err = deps.Store.GetCoreValue(req.Context(), organisationID, coreValueID)
+	if err == sql.ErrNoRows {
+		repsonse(rw, http.StatusNotFound, errorResponse{
+			Error: messageObject{
+				Message: "Core value not found",
+			},
+		})
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching core value")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
+
+// coreValueThumbnailUploadKind - core value thumbnails are stored in the images bucket
+func coreValueThumbnailUploadKind() uploadKind {
+	return uploadKind{bucket: config.ImagesBucket(), errorCode: "invalid-core-value-thumbnail", name: "core value thumbnail"}
+}
+
+// signCoreValueThumbnail - fills in a short-lived download URL of the thumbnail, left nil when there is none
+func signCoreValueThumbnail(req *http.Request, deps Dependencies, coreValue *db.CoreValue) (err error) {
+	if coreValue.ThumbnailKey == "" {
+		return
+	}
+
+	signedURL, err := deps.AWSStore.GetAWSS3DownloadURL(req.Context(), config.ImagesBucket(), coreValue.ThumbnailKey)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while retrieving download URL")
+		return
+	}
+
+	coreValue.ThumbnailURL = &signedURL.S3SignedURL
+	return
+}
+
+// signCoreValueThumbnails - fills in the thumbnail URL of each core value
+func signCoreValueThumbnails(req *http.Request, deps Dependencies, coreValues []db.CoreValue) (err error) {
+	for i := range coreValues {
+		err = signCoreValueThumbnail(req, deps, &coreValues[i])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+
+	"joshsoftware/peerly/aws"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var testCoreValueThumbnailUpload = db.CoreValueThumbnailUpload{
+	ID:          4,
+	CoreValueID: 3,
+	UploadedBy:  1,
+	ObjectKey:   "core_values/3/new.jpeg",
+	ContentType: "image/jpeg",
+	SizeBytes:   1024,
+}
+
+var testThumbnailCoreValue = db.CoreValue{ID: 3, OrgID: 1, Text: "Teamwork", Description: "Working together"}
+
+type CoreValueThumbnailHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock  *db.DBMockStore
+	awsMock *aws.AWSMockStore
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.awsMock = &aws.AWSMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) deps() Dependencies {
+	return Dependencies{Store: suite.dbMock, AWSStore: suite.awsMock}
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestCreateCoreValueThumbnailUploadSuccess() {
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), int64(3)).Return(testThumbnailCoreValue, nil)
+	suite.dbMock.On("CreateCoreValueThumbnailUpload", mock.Anything, db.CoreValueThumbnailUpload{
+		CoreValueID: 3, UploadedBy: 1, ContentType: "image/jpeg", SizeBytes: 1024,
+	}).Return(testCoreValueThumbnailUpload, nil)
+	suite.awsMock.On("GetAWSS3UploadURL", mock.Anything, "peerly-images", "core_values/3/new.jpeg", "image/jpeg").Return(
+		aws.S3SignedURL{S3SignedURL: "https://upload.example.com"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads",
+		"/organisations/1/core_values/3/thumbnail_uploads",
+		`{"content_type":"image/jpeg","size_bytes":1024}`,
+		testAdmin,
+		createCoreValueThumbnailUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":4,"core_value_id":3,"uploaded_by":1,"content_type":"image/jpeg","size_bytes":1024,"upload_url":"https://upload.example.com"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestCreateCoreValueThumbnailUploadTooLarge() {
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), int64(3)).Return(testThumbnailCoreValue, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads",
+		"/organisations/1/core_values/3/thumbnail_uploads",
+		`{"content_type":"image/png","size_bytes":3000000}`,
+		testAdmin,
+		createCoreValueThumbnailUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-core-value-thumbnail","message":"Invalid core value thumbnail data","fields":{"size_bytes":"Must be between 1 and 2097152 bytes"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateCoreValueThumbnailUpload", mock.Anything, mock.Anything)
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestCreateCoreValueThumbnailUploadWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 1).Return(db.Role{ID: 1, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads",
+		"/organisations/1/core_values/3/thumbnail_uploads",
+		`{"content_type":"image/png","size_bytes":1024}`,
+		db.User{ID: 5, OrgID: 1, RoleID: 1},
+		createCoreValueThumbnailUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GetCoreValue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestConfirmCoreValueThumbnailUploadReplacesOldThumbnail() {
+	picture := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	var buf bytes.Buffer
+	png.Encode(&buf, picture)
+	upload := testCoreValueThumbnailUpload
+	upload.ObjectKey = "core_values/3/new.png"
+	upload.ContentType = "image/png"
+	upload.SizeBytes = int64(buf.Len())
+
+	coreValue := testThumbnailCoreValue
+	coreValue.ThumbnailKey = "core_values/3/old_128.png"
+	updatedCoreValue := testThumbnailCoreValue
+	updatedCoreValue.ThumbnailKey = "core_values/3/new_128.png"
+
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), int64(3)).Return(coreValue, nil)
+	suite.dbMock.On("GetCoreValueThumbnailUpload", mock.Anything, int64(3), int64(4)).Return(upload, nil)
+	suite.dbMock.On("ConfirmCoreValueThumbnailUpload", mock.Anything, int64(1), upload).Return(updatedCoreValue, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-images", "core_values/3/new.png").Return(
+		aws.S3ObjectInfo{Size: upload.SizeBytes, ContentType: "image/png"}, nil)
+	suite.awsMock.On("GetAWSS3Object", mock.Anything, "peerly-images", "core_values/3/new.png").Return(buf.Bytes(), nil)
+	suite.awsMock.On("PutAWSS3Object", mock.Anything, "peerly-images", "core_values/3/new_128.png", "image/png", mock.Anything).Return(nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "core_values/3/new.png").Return(nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "core_values/3/old_128.png").Return(nil)
+	suite.awsMock.On("GetAWSS3DownloadURL", mock.Anything, "peerly-images", "core_values/3/new_128.png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://download.example.com"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads/{upload_id:[0-9]+}/confirm",
+		"/organisations/1/core_values/3/thumbnail_uploads/4/confirm",
+		"",
+		testAdmin,
+		confirmCoreValueThumbnailUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":3,"org_id":1,"text":"Teamwork","description":"Working together","parent_id":null,"thumbnail_url":"https://download.example.com","position":0}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestConfirmCoreValueThumbnailUploadWithMismatchedFile() {
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), int64(3)).Return(testThumbnailCoreValue, nil)
+	suite.dbMock.On("GetCoreValueThumbnailUpload", mock.Anything, int64(3), int64(4)).Return(testCoreValueThumbnailUpload, nil)
+	suite.dbMock.On("DeleteCoreValueThumbnailUpload", mock.Anything, int64(3), int64(4)).Return(nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-images", "core_values/3/new.jpeg").Return(
+		aws.S3ObjectInfo{Size: 5000, ContentType: "image/jpeg"}, nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "core_values/3/new.jpeg").Return(nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads/{upload_id:[0-9]+}/confirm",
+		"/organisations/1/core_values/3/thumbnail_uploads/4/confirm",
+		"",
+		testAdmin,
+		confirmCoreValueThumbnailUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-core-value-thumbnail","message":"Invalid core value thumbnail upload","fields":{"size_bytes":"Uploaded file size doesn't match the requested size"}}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertNotCalled(suite.T(), "GetAWSS3Object", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestListCoreValuesSignsThumbnails() {
+	coreValue := testThumbnailCoreValue
+	coreValue.ThumbnailKey = "core_values/3/art_128.png"
+	suite.dbMock.On("ListCoreValues", mock.Anything, int64(1), false).Return([]db.CoreValue{coreValue}, nil)
+	suite.awsMock.On("GetAWSS3DownloadURL", mock.Anything, "peerly-images", "core_values/3/art_128.png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://download.example.com"}, nil)
+
+	recorder := makeHTTPCall(http.MethodGet,
+		"/organisations/{organisation_id:[0-9]+}/core_values",
+		"/organisations/1/core_values",
+		"",
+		listCoreValuesHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":3,"org_id":1,"text":"Teamwork","description":"Working together","parent_id":null,"thumbnail_url":"https://download.example.com","position":0}]}`, recorder.Body.String())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *CoreValueThumbnailHandlerTestSuite) TestConfirmCoreValueThumbnailUploadWhenRejectingFails() {
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), int64(3)).Return(testThumbnailCoreValue, nil)
+	suite.dbMock.On("GetCoreValueThumbnailUpload", mock.Anything, int64(3), int64(4)).Return(testCoreValueThumbnailUpload, nil)
+	suite.dbMock.On("DeleteCoreValueThumbnailUpload", mock.Anything, int64(3), int64(4)).Return(errors.New("connection lost"))
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-images", "core_values/3/new.jpeg").Return(
+		aws.S3ObjectInfo{Size: 5000, ContentType: "image/jpeg"}, nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-images", "core_values/3/new.jpeg").Return(nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads/{upload_id:[0-9]+}/confirm",
+		"/organisations/1/core_values/3/thumbnail_uploads/4/confirm",
+		"",
+		testAdmin,
+		confirmCoreValueThumbnailUploadHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"net/http"
+
+	"joshsoftware/peerly/db"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// hi5Ledger - the caller's balance along with the entries that explain it
+type hi5Ledger struct {
+	Balance int                 `json:"balance"`
+	Entries []db.Hi5LedgerEntry `json:"entries"`
+}
+
+// @Title listMyHi5LedgerHandler
+// @Description the current user's Hi5 quota balance and most recent ledger entries
+// @Router /me/hi5-ledger
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listMyHi5LedgerHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		entries, err := deps.Store.ListHi5LedgerEntries(req.Context(), actor.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching Hi5 ledger entries")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: hi5Ledger{
+			Balance: actor.Hi5QuotaBalance,
+			Entries: entries,
+		}})
+	})
+}
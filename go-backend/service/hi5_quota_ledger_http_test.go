@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type Hi5QuotaLedgerHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *Hi5QuotaLedgerHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+}
+
+func (suite *Hi5QuotaLedgerHandlerTestSuite) TestListMyHi5LedgerSuccess() {
+	createdAt := time.Date(2020, 7, 6, 0, 0, 0, 0, time.UTC)
+	suite.dbMock.On("ListHi5LedgerEntries", mock.Anything, 1).Return([]db.Hi5LedgerEntry{
+		{ID: 1, UserID: 1, EntryType: db.Hi5LedgerGrant, Amount: 5, BalanceAfter: 5, Reason: "Initial quota", CreatedAt: createdAt},
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodGet,
+		"/me/hi5-ledger",
+		"/me/hi5-ledger",
+		"",
+		db.User{ID: 1, OrgID: 1, Hi5QuotaBalance: 5},
+		listMyHi5LedgerHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"balance":5,"entries":[{"id":1,"user_id":1,"entry_type":"grant","amount":5,"balance_after":5,"recognition_id":null,"reason":"Initial quota","created_at":"2020-07-06T00:00:00Z"}]}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *Hi5QuotaLedgerHandlerTestSuite) TestListMyHi5LedgerWhenDBFailure() {
+	suite.dbMock.On("ListHi5LedgerEntries", mock.Anything, 1).Return([]db.Hi5LedgerEntry{}, errors.New("Error while fetching data"))
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/me/hi5-ledger",
+		"/me/hi5-ledger",
+		"",
+		listMyHi5LedgerHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
@@ -0,0 +1,522 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// myHi5Quota - what the current user gets at each reset and what they'll start the next period with
+type myHi5Quota struct {
+	Balance     int               `json:"balance"`
+	Hi5Limit    int               `json:"hi5_limit"`
+	NextBalance int               `json:"next_balance"`
+	NextResetAt int64             `json:"next_reset_at,omitempty"`
+	Policy      db.Hi5QuotaPolicy `json:"policy"`
+}
+
+// @Title getHi5QuotaPolicyHandler
+// @Description get the rollover and cap policy of an organization
+// @Router /organizations/:id/hi5_quota_policy [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getHi5QuotaPolicyHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		policy, err := deps.Store.GetHi5QuotaPolicy(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching Hi5 quota policy")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: policy})
+	})
+}
+
+// @Title updateHi5QuotaPolicyHandler
+// @Description update the rollover and cap policy of an organization, admins only
+// @Router /organizations/:id/hi5_quota_policy [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func updateHi5QuotaPolicyHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var policy db.Hi5QuotaPolicy
+		err = json.NewDecoder(req.Body).Decode(&policy)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		policy.OrgID = organizationID
+
+		ok, errFields := policy.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-hi5-quota-policy",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid Hi5 quota policy"},
+				},
+			})
+			return
+		}
+
+		updatedPolicy, err := deps.Store.UpdateHi5QuotaPolicy(req.Context(), policy)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while updating Hi5 quota policy")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedPolicy})
+	})
+}
+
+// @Title listHi5LimitOverridesHandler
+// @Description list the per-role and per-user Hi5 limits of an organization, admins only
+// @Router /organizations/:id/hi5_limit_overrides [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listHi5LimitOverridesHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		overrides, err := deps.Store.ListHi5LimitOverrides(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while listing Hi5 limit overrides")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: overrides})
+	})
+}
+
+// @Title setHi5LimitOverrideHandler
+// @Description set the Hi5 limit of a role or a user, admins only
+// @Router /organizations/:id/hi5_limit_overrides/roles/:role_id [put]
+// @Router /organizations/:id/hi5_limit_overrides/users/:user_id [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func setHi5LimitOverrideHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		override, err := hi5LimitOverrideFromRequest(req)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error override keys are missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, override.OrgID) {
+			return
+		}
+
+		var body struct {
+			Hi5Limit int `json:"hi5_limit"`
+		}
+		err = json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		override.Hi5Limit = body.Hi5Limit
+
+		ok, errFields := override.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-hi5-limit-override",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid Hi5 limit"},
+				},
+			})
+			return
+		}
+
+		if override.RoleID != nil {
+			_, err = deps.Store.GetRoleByID(req.Context(), *override.RoleID)
+			if err == sql.ErrNoRows {
+				repsonse(rw, http.StatusNotFound, errorResponse{
+					Error: messageObject{
+						Message: "Role not found",
+					},
+				})
+				return
+			}
+			if err != nil {
+				logger.WithField("err", err.Error()).Error("Error while fetching role")
+				repsonse(rw, http.StatusInternalServerError, errorResponse{
+					Error: messageObject{
+						Message: "Internal server error",
+					},
+				})
+				return
+			}
+		}
+
+		savedOverride, err := deps.Store.SetHi5LimitOverride(req.Context(), override)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "User not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while saving Hi5 limit override")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: savedOverride})
+	})
+}
+
+// @Title deleteHi5LimitOverrideHandler
+// @Description remove the Hi5 limit of a role or a user so the organization's limit applies again, admins only
+// @Router /organizations/:id/hi5_limit_overrides/roles/:role_id [delete]
+// @Router /organizations/:id/hi5_limit_overrides/users/:user_id [delete]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func deleteHi5LimitOverrideHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		override, err := hi5LimitOverrideFromRequest(req)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error override keys are missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, override.OrgID) {
+			return
+		}
+
+		err = deps.Store.DeleteHi5LimitOverride(req.Context(), override)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Hi5 limit override not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while deleting Hi5 limit override")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		rw.WriteHeader(http.StatusOK)
+	})
+}
+
+// hi5LimitOverrideFromRequest - the organization and the role or user the override is for
+func hi5LimitOverrideFromRequest(req *http.Request) (override db.Hi5LimitOverride, err error) {
+	vars := mux.Vars(req)
+	override.OrgID, err = strconv.Atoi(vars["id"])
+	if err != nil {
+		return
+	}
+
+	if roleID, ok := vars["role_id"]; ok {
+		var id int
+		id, err = strconv.Atoi(roleID)
+		override.RoleID = &id
+		return
+	}
+
+	var id int
+	id, err = strconv.Atoi(vars["user_id"])
+	override.UserID = &id
+	return
+}
+
+// @Title grantBonusHi5sHandler
+// @Description give a user one-off Hi5s on top of their quota, admins only
+// @Router /organizations/:id/users/:user_id/hi5_bonus [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func grantBonusHi5sHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		userID, err := strconv.Atoi(vars["user_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error user_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var grant db.Hi5BonusGrant
+		err = json.NewDecoder(req.Body).Decode(&grant)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+
+		ok, errFields := grant.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-hi5-bonus",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid Hi5 bonus"},
+				},
+			})
+			return
+		}
+
+		_, err = deps.Store.GetUserByOrganization(req.Context(), userID, organizationID)
+		if err == sql.ErrNoRows {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "User not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching user")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		policy, err := deps.Store.GetHi5QuotaPolicy(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching Hi5 quota policy")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		entry, err := deps.Store.GrantBonusHi5s(req.Context(), userID, grant, policy.BalanceCap)
+		if err == ae.ErrHi5BalanceCapExceeded {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "hi5-balance-cap-exceeded",
+					Fields:        map[string]string{"amount": "Would take the balance above the organization's cap of " + strconv.Itoa(policy.BalanceCap)},
+					messageObject: messageObject{"Invalid Hi5 bonus"},
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while granting bonus Hi5s")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: entry})
+	})
+}
+
+// @Title getMyHi5QuotaHandler
+// @Description the current user's balance, limit and the policy applied at the next reset
+// @Router /me/hi5_quota [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getMyHi5QuotaHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		organization, err := deps.Store.GetOrganization(req.Context(), actor.OrgID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching organization")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		hi5Limit, err := deps.Store.GetEffectiveHi5Limit(req.Context(), actor.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching Hi5 limit")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		policy, err := deps.Store.GetHi5QuotaPolicy(req.Context(), actor.OrgID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching Hi5 quota policy")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		quota := myHi5Quota{
+			Balance:     actor.Hi5QuotaBalance,
+			Hi5Limit:    hi5Limit,
+			NextBalance: policy.RenewedBalance(actor.Hi5QuotaBalance, hi5Limit),
+			Policy:      policy,
+		}
+
+		// an unreadable schedule shouldn't hide the rest of the quota, so next_reset_at is left out
+		schedule, err := organization.Hi5QuotaSchedule()
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while reading organization's Hi5 quota renewal schedule")
+		} else {
+			quota.NextResetAt = schedule.NextReset(time.Now()).Unix()
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: quota})
+	})
+}
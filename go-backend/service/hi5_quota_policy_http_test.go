@@ -0,0 +1,158 @@
+package service
+
+import (
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var testAdmin = db.User{ID: 1, OrgID: 1, RoleID: 2, Hi5QuotaBalance: 4}
+
+type Hi5QuotaPolicyHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestUpdateHi5QuotaPolicySuccess() {
+	policy := db.Hi5QuotaPolicy{OrgID: 1, RolloverPercent: 50, MaxCarryOver: 3, BalanceCap: 15}
+	suite.dbMock.On("UpdateHi5QuotaPolicy", mock.Anything, policy).Return(policy, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{id:[0-9]+}/hi5_quota_policy",
+		"/organizations/1/hi5_quota_policy",
+		`{"rollover_percent":50,"max_carry_over":3,"balance_cap":15}`,
+		testAdmin,
+		updateHi5QuotaPolicyHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":{"org_id":1,"rollover_percent":50,"max_carry_over":3,"balance_cap":15}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestUpdateHi5QuotaPolicyWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 1).Return(db.Role{ID: 1, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{id:[0-9]+}/hi5_quota_policy",
+		"/organizations/1/hi5_quota_policy",
+		`{"rollover_percent":50}`,
+		db.User{ID: 3, OrgID: 1, RoleID: 1},
+		updateHi5QuotaPolicyHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"message":"Only admins can perform this action"}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateHi5QuotaPolicy", mock.Anything, mock.Anything)
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestUpdateHi5QuotaPolicyWithInvalidValues() {
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{id:[0-9]+}/hi5_quota_policy",
+		"/organizations/1/hi5_quota_policy",
+		`{"rollover_percent":150}`,
+		testAdmin,
+		updateHi5QuotaPolicyHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-hi5-quota-policy","message":"Invalid Hi5 quota policy","fields":{"rollover_percent":"Must be between 0 and 100"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestSetRoleHi5LimitOverride() {
+	roleID := 3
+	override := db.Hi5LimitOverride{OrgID: 1, RoleID: &roleID, Hi5Limit: 20}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Manager"}, nil)
+	suite.dbMock.On("SetHi5LimitOverride", mock.Anything, override).Return(db.Hi5LimitOverride{ID: 1, OrgID: 1, RoleID: &roleID, Hi5Limit: 20}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{id:[0-9]+}/hi5_limit_overrides/roles/{role_id:[0-9]+}",
+		"/organizations/1/hi5_limit_overrides/roles/3",
+		`{"hi5_limit":20}`,
+		testAdmin,
+		setHi5LimitOverrideHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":{"id":1,"org_id":1,"role_id":3,"user_id":null,"hi5_limit":20}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestDeleteUserHi5LimitOverrideWhenMissing() {
+	userID := 5
+	suite.dbMock.On("DeleteHi5LimitOverride", mock.Anything, db.Hi5LimitOverride{OrgID: 1, UserID: &userID}).Return(ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/organizations/{id:[0-9]+}/hi5_limit_overrides/users/{user_id:[0-9]+}",
+		"/organizations/1/hi5_limit_overrides/users/5",
+		"",
+		testAdmin,
+		deleteHi5LimitOverrideHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestGrantBonusHi5sSuccess() {
+	grant := db.Hi5BonusGrant{Amount: 2, Reason: "Hackathon winner"}
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 5, 1).Return(db.User{ID: 5, OrgID: 1}, nil)
+	suite.dbMock.On("GetHi5QuotaPolicy", mock.Anything, 1).Return(db.Hi5QuotaPolicy{OrgID: 1, BalanceCap: 10}, nil)
+	suite.dbMock.On("GrantBonusHi5s", mock.Anything, 5, grant, 10).Return(db.Hi5LedgerEntry{ID: 7, UserID: 5, EntryType: db.Hi5LedgerGrant, Amount: 2, BalanceAfter: 6, Reason: "Hackathon winner"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{id:[0-9]+}/users/{user_id:[0-9]+}/hi5_bonus",
+		"/organizations/1/users/5/hi5_bonus",
+		`{"amount":2,"reason":"Hackathon winner"}`,
+		testAdmin,
+		grantBonusHi5sHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestGrantBonusHi5sAboveCap() {
+	grant := db.Hi5BonusGrant{Amount: 2, Reason: "Hackathon winner"}
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 5, 1).Return(db.User{ID: 5, OrgID: 1}, nil)
+	suite.dbMock.On("GetHi5QuotaPolicy", mock.Anything, 1).Return(db.Hi5QuotaPolicy{OrgID: 1, BalanceCap: 10}, nil)
+	suite.dbMock.On("GrantBonusHi5s", mock.Anything, 5, grant, 10).Return(db.Hi5LedgerEntry{}, ae.ErrHi5BalanceCapExceeded)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{id:[0-9]+}/users/{user_id:[0-9]+}/hi5_bonus",
+		"/organizations/1/users/5/hi5_bonus",
+		`{"amount":2,"reason":"Hackathon winner"}`,
+		testAdmin,
+		grantBonusHi5sHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"hi5-balance-cap-exceeded","message":"Invalid Hi5 bonus","fields":{"amount":"Would take the balance above the organization's cap of 10"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+}
+
+func (suite *Hi5QuotaPolicyHandlerTestSuite) TestGetMyHi5Quota() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 1).Return(db.Organization{ID: 1, Hi5QuotaRenewalFrequency: "bad"}, nil)
+	suite.dbMock.On("GetEffectiveHi5Limit", mock.Anything, 1).Return(10, nil)
+	suite.dbMock.On("GetHi5QuotaPolicy", mock.Anything, 1).Return(db.Hi5QuotaPolicy{OrgID: 1, RolloverPercent: 50, BalanceCap: 11}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/me/hi5_quota",
+		"/me/hi5_quota",
+		"",
+		testAdmin,
+		getMyHi5QuotaHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":{"balance":4,"hi5_limit":10,"next_balance":11,"policy":{"org_id":1,"rollover_percent":50,"max_carry_over":0,"balance_cap":11}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+}
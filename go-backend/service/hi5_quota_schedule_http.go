@@ -0,0 +1,97 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// hi5QuotaSchedule - an organization's renewal schedule along with its last and next reset
+type hi5QuotaSchedule struct {
+	Frequency   string `json:"frequency"`
+	Weekday     string `json:"weekday,omitempty"`
+	Timezone    string `json:"timezone"`
+	LastResetAt int64  `json:"last_reset_at"`
+	NextResetAt int64  `json:"next_reset_at"`
+}
+
+// @Title getHi5QuotaScheduleHandler
+// @Description when the organization's Hi5 quotas were last renewed and when they will be renewed next
+// @Router /organizations/:id/hi5_quota_schedule [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getHi5QuotaScheduleHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		organization, err := deps.Store.GetOrganization(req.Context(), organizationID)
+		if err == sql.ErrNoRows {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Organization not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching organization")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		schedule, err := organization.Hi5QuotaSchedule()
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while reading organization's Hi5 quota renewal schedule")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		data := hi5QuotaSchedule{
+			Frequency:   schedule.Frequency,
+			Timezone:    schedule.Location.String(),
+			LastResetAt: organization.Hi5QuotaLastResetAt,
+			NextResetAt: schedule.NextReset(time.Now()).Unix(),
+		}
+		if schedule.Frequency == db.WeeklyRenewalFrequency || schedule.Frequency == db.BiweeklyRenewalFrequency {
+			data.Weekday = organization.Hi5QuotaRenewalWeekday
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: data})
+	})
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type Hi5QuotaScheduleHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *Hi5QuotaScheduleHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+}
+
+func (suite *Hi5QuotaScheduleHandlerTestSuite) TestGetHi5QuotaScheduleSuccess() {
+	organization := db.Organization{
+		ID:                       1,
+		Hi5QuotaRenewalFrequency: "WEEKLY",
+		Hi5QuotaRenewalWeekday:   "FRIDAY",
+		Timezone:                 "Asia/Kolkata",
+		Hi5QuotaLastResetAt:      1594000000,
+	}
+	suite.dbMock.On("GetOrganization", mock.Anything, 1).Return(organization, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodGet,
+		"/organizations/{id:[0-9]+}/hi5_quota_schedule",
+		"/organizations/1/hi5_quota_schedule",
+		"",
+		getHi5QuotaScheduleHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	var body struct {
+		Data hi5QuotaSchedule `json:"data"`
+	}
+	json.Unmarshal(recorder.Body.Bytes(), &body)
+
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	nextReset := time.Unix(body.Data.NextResetAt, 0).In(kolkata)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), "WEEKLY", body.Data.Frequency)
+	assert.Equal(suite.T(), "FRIDAY", body.Data.Weekday)
+	assert.Equal(suite.T(), "Asia/Kolkata", body.Data.Timezone)
+	assert.Equal(suite.T(), int64(1594000000), body.Data.LastResetAt)
+	assert.Equal(suite.T(), time.Friday, nextReset.Weekday())
+	assert.Equal(suite.T(), 0, nextReset.Hour())
+	assert.True(suite.T(), nextReset.After(time.Now()))
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *Hi5QuotaScheduleHandlerTestSuite) TestGetHi5QuotaScheduleForAnotherOrganization() {
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodGet,
+		"/organizations/{id:[0-9]+}/hi5_quota_schedule",
+		"/organizations/2/hi5_quota_schedule",
+		"",
+		getHi5QuotaScheduleHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GetOrganization", mock.Anything, mock.Anything)
+}
+
+func (suite *Hi5QuotaScheduleHandlerTestSuite) TestGetHi5QuotaScheduleWhenOrganizationNotFound() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 1).Return(db.Organization{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodGet,
+		"/organizations/{id:[0-9]+}/hi5_quota_schedule",
+		"/organizations/1/hi5_quota_schedule",
+		"",
+		getHi5QuotaScheduleHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"message":"Organization not found"}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+}
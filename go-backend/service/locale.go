@@ -0,0 +1,139 @@
+package service
+
+import (
+	"net/http"
+
+	"joshsoftware/peerly/db"
+
+	logger "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+)
+
+// requestLocales - the locales of the Accept-Language header, most preferred first. A regional locale
+// is followed by its language so that de-AT still picks German translations up.
+func requestLocales(req *http.Request) (locales []string) {
+	header := req.Header.Get("Accept-Language")
+	if header == "" {
+		return
+	}
+
+	tags, weights, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":             err.Error(),
+			"accept_language": header,
+		}).Warn("Ignoring invalid Accept-Language header")
+		return
+	}
+
+	seen := make(map[string]bool)
+	for i, tag := range tags {
+		if weights[i] <= 0 || tag == language.Und {
+			continue
+		}
+
+		base, _ := tag.Base()
+		for _, locale := range []string{tag.String(), base.String()} {
+			if !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+		}
+	}
+	return
+}
+
+// localizeCoreValues - replaces the text and description of each core value with its translation best
+// matching the request's Accept-Language, leaving those in the organization's default locale as they are
+func localizeCoreValues(req *http.Request, deps Dependencies, organisationID int64, coreValues []db.CoreValue) (err error) {
+	locales := requestLocales(req)
+	if len(locales) == 0 {
+		return
+	}
+
+	translations, err := deps.Store.PreferredCoreValueTranslations(req.Context(), organisationID, locales)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching core value translations")
+		return
+	}
+
+	for i := range coreValues {
+		if translation, ok := translations[coreValues[i].ID]; ok {
+			coreValues[i].Text = translation.Text
+			coreValues[i].Description = translation.Description
+		}
+	}
+	return
+}
+
+// localizedBadgeNames - badge names in the locale best matching the request's Accept-Language, by badge ID.
+// Badges to be shown in the organization's default locale are left out.
+func localizedBadgeNames(req *http.Request, deps Dependencies, orgID int) (names map[int]string, err error) {
+	names = make(map[int]string)
+
+	locales := requestLocales(req)
+	if len(locales) == 0 {
+		return
+	}
+
+	translations, err := deps.Store.PreferredBadgeTranslations(req.Context(), orgID, locales)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching badge translations")
+		return
+	}
+
+	for badgeID, translation := range translations {
+		names[badgeID] = translation.Name
+	}
+	return
+}
+
+// localizeBadges - replaces the name of each badge with its translation best matching the request's Accept-Language
+func localizeBadges(req *http.Request, deps Dependencies, orgID int, badges []db.Badge) (err error) {
+	names, err := localizedBadgeNames(req, deps, orgID)
+	if err != nil {
+		return
+	}
+
+	for i := range badges {
+		if name, ok := names[badges[i].ID]; ok {
+			badges[i].Name = name
+		}
+	}
+	return
+}
+
+// localizeFeed - replaces the core value texts, parents included, and badge names embedded in a feed page
+// with their translations best matching the request's Accept-Language, like localizeCoreValues and localizeBadges
+func localizeFeed(req *http.Request, deps Dependencies, orgID int, page *db.FeedPage) (err error) {
+	locales := requestLocales(req)
+	if len(locales) == 0 {
+		return
+	}
+
+	translations, err := deps.Store.PreferredCoreValueTranslations(req.Context(), int64(orgID), locales)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching core value translations")
+		return
+	}
+
+	for i := range page.Recognitions {
+		for coreValue := page.Recognitions[i].CoreValue; coreValue != nil; coreValue = coreValue.Parent {
+			if translation, ok := translations[coreValue.ID]; ok {
+				coreValue.Text = translation.Text
+			}
+		}
+	}
+
+	names, err := localizedBadgeNames(req, deps, orgID)
+	if err != nil {
+		return
+	}
+
+	for i := range page.BadgeGrants {
+		if name, ok := names[page.BadgeGrants[i].BadgeID]; ok {
+			page.BadgeGrants[i].BadgeName = name
+		}
+	}
+	return
+}
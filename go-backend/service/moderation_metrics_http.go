@@ -0,0 +1,192 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title getModerationMetricsHandler
+// @Description time to first review, time to resolution and reports per reason of the reports filed between from and to
+// (unix seconds, the last 30 days by default), outcomes per moderator of the decisions taken in that range and the age
+// of every report still waiting for a decision, admins only
+// @Router /organizations/:organization_id/moderation/metrics?from=&to= [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getModerationMetricsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		metrics, ok := moderationMetricsFromRequest(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: metrics})
+	})
+}
+
+// @Title exportModerationMetricsHandler
+// @Description the same metrics as getModerationMetricsHandler as a CSV file with one value per row, admins only
+// @Router /organizations/:organization_id/moderation/metrics/export?from=&to= [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func exportModerationMetricsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		metrics, ok := moderationMetricsFromRequest(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		filename := fmt.Sprintf("moderation_metrics_%d_%d.csv", metrics.From, metrics.To)
+		rw.Header().Add("Content-Type", "text/csv")
+		rw.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		rw.WriteHeader(http.StatusOK)
+
+		writer := csv.NewWriter(rw)
+		err := writer.WriteAll(moderationMetricsRecords(metrics))
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while writing moderation metrics csv")
+		}
+	})
+}
+
+// moderationMetricsFromRequest - writes the error response and returns false unless the
+// metrics asked for could be computed for an admin of the organization
+func moderationMetricsFromRequest(rw http.ResponseWriter, req *http.Request, deps Dependencies) (metrics db.ModerationMetrics, ok bool) {
+	vars := mux.Vars(req)
+	organizationID, err := strconv.Atoi(vars["organization_id"])
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	actor, err := getCurrentActor(req)
+	if err != nil {
+		currentActorErrorResponse(rw, err)
+		return
+	}
+
+	if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+		return
+	}
+
+	query, errFields := moderationMetricsQueryFromRequest(req)
+	if len(errFields) > 0 {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "invalid-moderation-metrics-params",
+				Fields:        errFields,
+				messageObject: messageObject{"Invalid moderation metrics parameters"},
+			},
+		})
+		return
+	}
+	query.OrgID = organizationID
+
+	metrics, err = deps.Store.GetModerationMetrics(req.Context(), query)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching moderation metrics")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
+
+func moderationMetricsQueryFromRequest(req *http.Request) (query db.ModerationMetricsQuery, errFields map[string]string) {
+	errFields = make(map[string]string)
+	params := req.URL.Query()
+
+	query.To = time.Now().Unix()
+	if to := params.Get("to"); to != "" {
+		var err error
+		query.To, err = strconv.ParseInt(to, 10, 64)
+		if err != nil || query.To < 0 {
+			errFields["to"] = "Must be a unix timestamp"
+		}
+	}
+
+	query.From = query.To - int64(db.DefaultModerationMetricsDays*24*time.Hour/time.Second)
+	if from := params.Get("from"); from != "" {
+		var err error
+		query.From, err = strconv.ParseInt(from, 10, 64)
+		if err != nil || query.From < 0 {
+			errFields["from"] = "Must be a unix timestamp"
+		}
+	}
+
+	if len(errFields) == 0 && query.To <= query.From {
+		errFields["to"] = "Must be after from"
+	}
+	return
+}
+
+// moderationMetricsRecords - the metrics as metric, dimension, statistic, value rows
+// with the values that couldn't be computed left empty
+func moderationMetricsRecords(metrics db.ModerationMetrics) (records [][]string) {
+	formatSeconds := func(seconds *int64) string {
+		if seconds == nil {
+			return ""
+		}
+		return strconv.FormatInt(*seconds, 10)
+	}
+
+	records = append(records,
+		[]string{"metric", "dimension", "statistic", "value"},
+		[]string{"range", "", "from", strconv.FormatInt(metrics.From, 10)},
+		[]string{"range", "", "to", strconv.FormatInt(metrics.To, 10)},
+	)
+
+	durations := []struct {
+		metric string
+		stats  db.DurationStats
+	}{
+		{"time_to_first_review", metrics.TimeToFirstReview},
+		{"time_to_resolution", metrics.TimeToResolution},
+	}
+	for _, duration := range durations {
+		records = append(records,
+			[]string{duration.metric, "", "count", strconv.Itoa(duration.stats.Count)},
+			[]string{duration.metric, "", "average_seconds", formatSeconds(duration.stats.AverageSeconds)},
+			[]string{duration.metric, "", "median_seconds", formatSeconds(duration.stats.MedianSeconds)},
+			[]string{duration.metric, "", "p90_seconds", formatSeconds(duration.stats.P90Seconds)},
+		)
+	}
+
+	for _, reason := range metrics.ReportsPerReason {
+		records = append(records, []string{"reports_per_reason", reason.Reason, "count", strconv.Itoa(reason.Count)})
+	}
+
+	for _, outcome := range metrics.ModeratorOutcomes {
+		moderator := strconv.FormatInt(outcome.ModeratedBy, 10)
+		records = append(records,
+			[]string{"moderator_outcomes", moderator, "decisions", strconv.Itoa(outcome.Decisions)},
+			[]string{"moderator_outcomes", moderator, "hidden", strconv.Itoa(outcome.Hidden)},
+			[]string{"moderator_outcomes", moderator, "restored", strconv.Itoa(outcome.Restored)},
+			[]string{"moderator_outcomes", moderator, "overturned_on_appeal", strconv.Itoa(outcome.OverturnedOnAppeal)},
+		)
+	}
+
+	records = append(records,
+		[]string{"backlog", "", "count", strconv.Itoa(metrics.Backlog.Count)},
+		[]string{"backlog", "", "open", strconv.Itoa(metrics.Backlog.Open)},
+		[]string{"backlog", "", "in_review", strconv.Itoa(metrics.Backlog.InReview)},
+		[]string{"backlog", "", "average_age_seconds", formatSeconds(metrics.Backlog.AverageAgeSeconds)},
+		[]string{"backlog", "", "oldest_age_seconds", formatSeconds(metrics.Backlog.OldestAgeSeconds)},
+	)
+	return
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ModerationMetricsHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+}
+
+func testModerationMetrics() db.ModerationMetrics {
+	average, median, p90, age := int64(600), int64(300), int64(1500), int64(7200)
+	return db.ModerationMetrics{
+		From:              1595000000,
+		To:                1595600000,
+		TimeToFirstReview: db.DurationStats{Count: 3, AverageSeconds: &average, MedianSeconds: &median, P90Seconds: &p90},
+		ReportsPerReason:  []db.ReasonReportCount{{Reason: "fraud", Label: "Fraud", Count: 2}},
+		ModeratorOutcomes: []db.ModeratorOutcome{{ModeratedBy: 1, Decisions: 2, Hidden: 1, Restored: 1, OverturnedOnAppeal: 1}},
+		Backlog:           db.BacklogAge{Count: 1, InReview: 1, AverageAgeSeconds: &age, OldestAgeSeconds: &age},
+	}
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestGetModerationMetricsSuccess() {
+	query := db.ModerationMetricsQuery{OrgID: 1, From: 1595000000, To: 1595600000}
+	suite.dbMock.On("GetModerationMetrics", mock.Anything, query).Return(testModerationMetrics(), nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics",
+		"/organizations/1/moderation/metrics?from=1595000000&to=1595600000",
+		"",
+		testAdmin,
+		getModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"from":1595000000,"to":1595600000,`+
+		`"time_to_first_review":{"count":3,"average_seconds":600,"median_seconds":300,"p90_seconds":1500},`+
+		`"time_to_resolution":{"count":0,"average_seconds":null,"median_seconds":null,"p90_seconds":null},`+
+		`"reports_per_reason":[{"reason":"fraud","label":"Fraud","count":2}],`+
+		`"moderator_outcomes":[{"moderated_by":1,"decisions":2,"hidden":1,"restored":1,"overturned_on_appeal":1}],`+
+		`"backlog":{"count":1,"open":0,"in_review":1,"average_age_seconds":7200,"oldest_age_seconds":7200}}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "GetModerationMetrics", mock.Anything, query)
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestGetModerationMetricsDefaultsToLast30Days() {
+	suite.dbMock.On("GetModerationMetrics", mock.Anything, mock.Anything).Return(db.ModerationMetrics{}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics",
+		"/organizations/1/moderation/metrics",
+		"",
+		testAdmin,
+		getModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "GetModerationMetrics", mock.Anything, mock.MatchedBy(func(query db.ModerationMetricsQuery) bool {
+		return query.OrgID == 1 && query.To-query.From == 30*24*60*60
+	}))
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestGetModerationMetricsWithInvalidParams() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics",
+		"/organizations/1/moderation/metrics?from=yesterday&to=1595600000",
+		"",
+		testAdmin,
+		getModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-metrics-params","message":"Invalid moderation metrics parameters","fields":{"from":"Must be a unix timestamp"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "GetModerationMetrics", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestGetModerationMetricsWithEmptyRange() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics",
+		"/organizations/1/moderation/metrics?from=1595600000&to=1595600000",
+		"",
+		testAdmin,
+		getModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-metrics-params","message":"Invalid moderation metrics parameters","fields":{"to":"Must be after from"}}}`, recorder.Body.String())
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestGetModerationMetricsWhenNotAdmin() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics",
+		"/organizations/1/moderation/metrics",
+		"",
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		getModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GetModerationMetrics", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestGetModerationMetricsFailure() {
+	suite.dbMock.On("GetModerationMetrics", mock.Anything, mock.Anything).Return(db.ModerationMetrics{}, errors.New("connection lost"))
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics",
+		"/organizations/1/moderation/metrics",
+		"",
+		testAdmin,
+		getModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+}
+
+func (suite *ModerationMetricsHandlerTestSuite) TestExportModerationMetrics() {
+	query := db.ModerationMetricsQuery{OrgID: 1, From: 1595000000, To: 1595600000}
+	suite.dbMock.On("GetModerationMetrics", mock.Anything, query).Return(testModerationMetrics(), nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/metrics/export",
+		"/organizations/1/moderation/metrics/export?from=1595000000&to=1595600000",
+		"",
+		testAdmin,
+		exportModerationMetricsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(suite.T(), `attachment; filename="moderation_metrics_1595000000_1595600000.csv"`, recorder.Header().Get("Content-Disposition"))
+	assert.Equal(suite.T(), "metric,dimension,statistic,value\n"+
+		"range,,from,1595000000\n"+
+		"range,,to,1595600000\n"+
+		"time_to_first_review,,count,3\n"+
+		"time_to_first_review,,average_seconds,600\n"+
+		"time_to_first_review,,median_seconds,300\n"+
+		"time_to_first_review,,p90_seconds,1500\n"+
+		"time_to_resolution,,count,0\n"+
+		"time_to_resolution,,average_seconds,\n"+
+		"time_to_resolution,,median_seconds,\n"+
+		"time_to_resolution,,p90_seconds,\n"+
+		"reports_per_reason,fraud,count,2\n"+
+		"moderator_outcomes,1,decisions,2\n"+
+		"moderator_outcomes,1,hidden,1\n"+
+		"moderator_outcomes,1,restored,1\n"+
+		"moderator_outcomes,1,overturned_on_appeal,1\n"+
+		"backlog,,count,1\n"+
+		"backlog,,open,0\n"+
+		"backlog,,in_review,1\n"+
+		"backlog,,average_age_seconds,7200\n"+
+		"backlog,,oldest_age_seconds,7200\n", recorder.Body.String())
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title listMyModerationNotificationsHandler
+// @Description the current user's latest moderation notifications, newest first
+// @Router /me/moderation_notifications [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listMyModerationNotificationsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		notifications, err := deps.Store.ListModerationNotifications(req.Context(), actor.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching moderation notifications")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: notifications})
+	})
+}
+
+// @Title readMyModerationNotificationHandler
+// @Description mark one of the current user's moderation notifications as read
+// @Router /me/moderation_notifications/:id/read [post]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func readMyModerationNotificationHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		notificationID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		notification, err := deps.Store.ReadModerationNotification(req.Context(), actor.ID, notificationID)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Notification not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while reading moderation notification")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: notification})
+	})
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title listModerationQueueHandler
+// @Description reported recognitions of the organization grouped by recognition, admins only
+// @Router /organizations/:organization_id/moderation/queue?status=&assigned_to=&mark_as=&cursor=&limit= [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listModerationQueueHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		query, errFields := moderationQueueQueryFromRequest(req)
+		if len(errFields) > 0 {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-moderation-queue-params",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid moderation queue parameters"},
+				},
+			})
+			return
+		}
+		query.OrgID = organizationID
+
+		page, err := deps.Store.ListModerationQueue(req.Context(), query)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching moderation queue")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: page})
+	})
+}
+
+// @Title assignModerationHandler
+// @Description assign an admin of the organization to review a reported recognition, admins only
+// @Router /organizations/:organization_id/moderation/queue/:recognition_id/assignment [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func assignModerationHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		recognitionID, err := strconv.ParseInt(vars["recognition_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing recognition_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var assignment db.ModerationAssignment
+		actorErrFields, err := decodeWithoutActorFields(req, &assignment, "assigned_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		assignment.RecognitionID = recognitionID
+		assignment.AssignedBy = int64(actor.ID)
+
+		if !validateModerator(rw, req, deps, organizationID, assignment.AssignedTo) {
+			return
+		}
+
+		resp, err := deps.Store.AssignModeration(req.Context(), organizationID, assignment)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition has no reports to review",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while assigning moderation")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: resp})
+	})
+}
+
+// moderationQueueQueryFromRequest - reads the filters, cursor and limit query params
+func moderationQueueQueryFromRequest(req *http.Request) (query db.ModerationQueueQuery, errFields map[string]string) {
+	errFields = make(map[string]string)
+	params := req.URL.Query()
+
+	if status := params.Get("status"); status != "" {
+		for _, reportStatus := range db.REPORT_STATUSES {
+			if status == reportStatus {
+				query.Status = status
+			}
+		}
+		if query.Status == "" {
+			errFields["status"] = "Must be one of open, in_review or resolved"
+		}
+	}
+
+	if assignedTo := params.Get("assigned_to"); assignedTo != "" {
+		var err error
+		query.AssignedTo, err = strconv.ParseInt(assignedTo, 10, 64)
+		if err != nil || query.AssignedTo < 1 {
+			errFields["assigned_to"] = "Must be a user id"
+		}
+	}
+
+	query.Type = params.Get("mark_as")
+
+	query.Limit = db.DefaultModerationQueueLimit
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > db.MaxModerationQueueLimit {
+			errFields["limit"] = "Must be between 1 and " + strconv.Itoa(db.MaxModerationQueueLimit)
+		}
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		decodedCursor, err := db.DecodeModerationQueueCursor(cursor)
+		if err != nil {
+			errFields["cursor"] = err.Error()
+		} else {
+			query.Cursor = &decodedCursor
+		}
+	}
+	return
+}
+
+// validateModerator - writes the error response and returns false unless the user
+// is an admin of the organization, the only ones who can moderate recognitions
+func validateModerator(rw http.ResponseWriter, req *http.Request, deps Dependencies, organizationID int, userID int64) (ok bool) {
+	invalidModerator := func(message string) {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "invalid-moderation-assignment",
+				Fields:        map[string]string{"assigned_to": message},
+				messageObject: messageObject{"Invalid moderation assignment"},
+			},
+		})
+	}
+
+	if userID < 1 {
+		invalidModerator("Can't be blank")
+		return
+	}
+
+	user, err := deps.Store.GetUserByOrganization(req.Context(), int(userID), organizationID)
+	if err == sql.ErrNoRows {
+		invalidModerator("Must be an admin of the organization")
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching user")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	role, err := deps.Store.GetRoleByID(req.Context(), user.RoleID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching role of moderator")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	if role.Name != db.AdminRoleName {
+		invalidModerator("Must be an admin of the organization")
+		return
+	}
+
+	ok = true
+	return
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ModerationQueueHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *ModerationQueueHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestListModerationQueueSuccess() {
+	assignedTo := int64(3)
+	assignedAt := int64(130)
+	cursor := db.ModerationQueueCursor{FirstReportedAt: 100, RecognitionID: 7}
+	suite.dbMock.On("ListModerationQueue", mock.Anything, db.ModerationQueueQuery{
+		OrgID:      1,
+		Status:     db.ReportInReviewStatus,
+		AssignedTo: 3,
+		Type:       "fraud",
+		Cursor:     &cursor,
+		Limit:      10,
+	}).Return(db.ModerationQueuePage{
+		Items: []db.ModerationQueueItem{{
+			RecognitionID:   8,
+			Text:            "Great demo",
+			GivenBy:         1,
+			GivenFor:        2,
+			GivenAt:         90,
+			ReportCount:     2,
+			Types:           []string{"fraud", "incorrect"},
+			Reasons:         []string{"Not true", "Wrong person"},
+			FirstReportedAt: 101,
+			LastReportedAt:  120,
+			Status:          db.ReportInReviewStatus,
+			AssignedTo:      &assignedTo,
+			AssignedAt:      &assignedAt,
+		}},
+		NextCursor: "",
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue",
+		"/organizations/1/moderation/queue?status=in_review&assigned_to=3&mark_as=fraud&limit=10&cursor="+cursor.Encode(),
+		"",
+		testAdmin,
+		listModerationQueueHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"items":[{"recognition_id":8,"text":"Great demo","given_by":1,"given_for":2,"given_at":90,"report_count":2,"mark_as":["fraud","incorrect"],"reasons":["Not true","Wrong person"],"first_reported_at":101,"last_reported_at":120,"status":"in_review","quarantined":false,"assigned_to":3,"assigned_at":130}],"next_cursor":""}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "ListModerationQueue", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestListModerationQueueWithInvalidParams() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue",
+		"/organizations/1/moderation/queue?status=closed&assigned_to=me&limit=500",
+		"",
+		testAdmin,
+		listModerationQueueHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-queue-params","message":"Invalid moderation queue parameters","fields":{"assigned_to":"Must be a user id","limit":"Must be between 1 and 100","status":"Must be one of open, in_review or resolved"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ListModerationQueue", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestListModerationQueueWhenNotAdmin() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue",
+		"/organizations/1/moderation/queue",
+		"",
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		listModerationQueueHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Only admins can perform this action"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ListModerationQueue", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestAssignModerationSuccess() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 5, 1).Return(db.User{ID: 5, OrgID: 1, RoleID: 2}, nil)
+	suite.dbMock.On("AssignModeration", mock.Anything, 1, db.ModerationAssignment{RecognitionID: 8, AssignedTo: 5, AssignedBy: 1}).
+		Return(db.ModerationAssignment{RecognitionID: 8, AssignedTo: 5, AssignedBy: 1, AssignedAt: 130}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue/{recognition_id:[0-9]+}/assignment",
+		"/organizations/1/moderation/queue/8/assignment",
+		`{"assigned_to":5}`,
+		testAdmin,
+		assignModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"recognition_id":8,"assigned_to":5,"assigned_by":1,"assigned_at":130}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "AssignModeration", mock.Anything, 1, db.ModerationAssignment{RecognitionID: 8, AssignedTo: 5, AssignedBy: 1})
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestAssignModerationToNonAdmin() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 4, 1).Return(db.User{ID: 4, OrgID: 1, RoleID: 3}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue/{recognition_id:[0-9]+}/assignment",
+		"/organizations/1/moderation/queue/8/assignment",
+		`{"assigned_to":4}`,
+		testAdmin,
+		assignModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-assignment","message":"Invalid moderation assignment","fields":{"assigned_to":"Must be an admin of the organization"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "AssignModeration", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestAssignModerationToUserOfAnotherOrganization() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 9, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue/{recognition_id:[0-9]+}/assignment",
+		"/organizations/1/moderation/queue/8/assignment",
+		`{"assigned_to":9}`,
+		testAdmin,
+		assignModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-assignment","message":"Invalid moderation assignment","fields":{"assigned_to":"Must be an admin of the organization"}}}`, recorder.Body.String())
+}
+
+func (suite *ModerationQueueHandlerTestSuite) TestAssignModerationWithoutReports() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 5, 1).Return(db.User{ID: 5, OrgID: 1, RoleID: 2}, nil)
+	suite.dbMock.On("AssignModeration", mock.Anything, 1, db.ModerationAssignment{RecognitionID: 8, AssignedTo: 5, AssignedBy: 1}).
+		Return(db.ModerationAssignment{}, ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/queue/{recognition_id:[0-9]+}/assignment",
+		"/organizations/1/moderation/queue/8/assignment",
+		`{"assigned_to":5}`,
+		testAdmin,
+		assignModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Recognition has no reports to review"}}`, recorder.Body.String())
+}
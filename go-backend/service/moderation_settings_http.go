@@ -0,0 +1,119 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title getModerationSettingsHandler
+// @Description get the automatic quarantine settings of an organization, admins only
+// @Router /organizations/:organization_id/moderation/settings [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getModerationSettingsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		settings, err := deps.Store.GetModerationSettings(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching moderation settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: settings})
+	})
+}
+
+// @Title updateModerationSettingsHandler
+// @Description update the automatic quarantine settings of an organization, admins only
+// @Router /organizations/:organization_id/moderation/settings [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func updateModerationSettingsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var settings db.ModerationSettings
+		err = json.NewDecoder(req.Body).Decode(&settings)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		settings.OrgID = organizationID
+
+		ok, errFields := settings.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-moderation-settings",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid moderation settings"},
+				},
+			})
+			return
+		}
+
+		updatedSettings, err := deps.Store.UpdateModerationSettings(req.Context(), settings)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while updating moderation settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedSettings})
+	})
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ModerationSettingsHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) TestGetModerationSettingsSuccess() {
+	suite.dbMock.On("GetModerationSettings", mock.Anything, 1).Return(db.DefaultModerationSettings(1), nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/settings",
+		"/organizations/1/moderation/settings",
+		"",
+		testAdmin,
+		getModerationSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"org_id":1,"quarantine_reporter_threshold":3,"quarantine_window_hours":24,"report_rate_limit":10,"report_rate_window_hours":24}}`, recorder.Body.String())
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) TestUpdateModerationSettingsSuccess() {
+	settings := db.ModerationSettings{OrgID: 1, QuarantineReporterThreshold: 5, QuarantineWindowHours: 12, ReportRateLimit: 20, ReportRateWindowHours: 1}
+	suite.dbMock.On("UpdateModerationSettings", mock.Anything, settings).Return(settings, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/settings",
+		"/organizations/1/moderation/settings",
+		`{"quarantine_reporter_threshold":5,"quarantine_window_hours":12,"report_rate_limit":20,"report_rate_window_hours":1}`,
+		testAdmin,
+		updateModerationSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"org_id":1,"quarantine_reporter_threshold":5,"quarantine_window_hours":12,"report_rate_limit":20,"report_rate_window_hours":1}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "UpdateModerationSettings", mock.Anything, settings)
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) TestUpdateModerationSettingsWithInvalidWindow() {
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/settings",
+		"/organizations/1/moderation/settings",
+		`{"quarantine_reporter_threshold":5,"quarantine_window_hours":0}`,
+		testAdmin,
+		updateModerationSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-settings","message":"Invalid moderation settings","fields":{"quarantine_window_hours":"Must be greater than 0 when quarantine_reporter_threshold is set"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateModerationSettings", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) TestUpdateModerationSettingsWhenNotAdmin() {
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/settings",
+		"/organizations/1/moderation/settings",
+		`{"quarantine_reporter_threshold":0}`,
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		updateModerationSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateModerationSettings", mock.Anything, mock.Anything)
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) TestListMyModerationNotificationsSuccess() {
+	suite.dbMock.On("ListModerationNotifications", mock.Anything, 1).Return([]db.ModerationNotification{
+		{ID: 2, UserID: 1, RecognitionID: 8, Kind: db.QuarantineNotification, CreatedAt: 100},
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/me/moderation_notifications",
+		"/me/moderation_notifications",
+		"",
+		testAdmin,
+		listMyModerationNotificationsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":2,"user_id":1,"recognition_id":8,"kind":"quarantine","created_at":100,"read_at":null}]}`, recorder.Body.String())
+}
+
+func (suite *ModerationSettingsHandlerTestSuite) TestReadMyModerationNotificationNotFound() {
+	suite.dbMock.On("ReadModerationNotification", mock.Anything, 1, int64(7)).Return(db.ModerationNotification{}, ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/me/moderation_notifications/{id:[0-9]+}/read",
+		"/me/moderation_notifications/7/read",
+		"",
+		testAdmin,
+		readMyModerationNotificationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Notification not found"}}`, recorder.Body.String())
+}
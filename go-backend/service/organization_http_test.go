@@ -20,8 +20,10 @@ var testOrganization = db.Organization{
 	SubscriptionStatus:       1,
 	SubscriptionValidUpto:    1588073442241,
 	Hi5Limit:                 5,
-	Hi5QuotaRenewalFrequency: "2",
-	Timezone:                 "IST",
+	Hi5QuotaRenewalFrequency: "WEEKLY",
+	Hi5QuotaRenewalWeekday:   "MONDAY",
+	Timezone:                 "Asia/Kolkata",
+	DefaultLocale:            "en",
 }
 
 // Define the suite, and absorb the built-in basic suite
@@ -47,8 +49,10 @@ func (suite *OrganizationHandlerTestSuite) TestListOrganizationsSuccess() {
 				SubscriptionStatus:       1,
 				SubscriptionValidUpto:    1588073442241,
 				Hi5Limit:                 5,
-				Hi5QuotaRenewalFrequency: "2",
-				Timezone:                 "IST",
+				Hi5QuotaRenewalFrequency: "WEEKLY",
+				Hi5QuotaRenewalWeekday:   "MONDAY",
+				Timezone:                 "Asia/Kolkata",
+				DefaultLocale:            "en",
 				CreatedAt:                time.Now().UTC(),
 			},
 		},
@@ -93,7 +97,7 @@ func (suite *OrganizationHandlerTestSuite) TestCreateOrganizationSuccess() {
 	testCreateOrganization.CreatedAt = testTime
 	suite.dbMock.On("CreateOrganization", mock.Anything, testOrganization).Return(testCreateOrganization, nil)
 
-	body := `{"name":"test organization","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST"}`
+	body := `{"name":"test organization","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata"}`
 
 	recorder := makeHTTPCall(http.MethodPost,
 		"/organizations",
@@ -103,14 +107,14 @@ func (suite *OrganizationHandlerTestSuite) TestCreateOrganizationSuccess() {
 	)
 
 	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
-	assert.Equal(suite.T(), `{"id":1,"name":"test organization","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST","created_at":"2006-01-02T15:04:05Z"}`, recorder.Body.String())
+	assert.Equal(suite.T(), `{"id":1,"name":"test organization","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata","default_locale":"en","created_at":"2006-01-02T15:04:05Z"}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
 func (suite *OrganizationHandlerTestSuite) TestCreateOrganizationDbFailure() {
 	suite.dbMock.On("CreateOrganization", mock.Anything, testOrganization).Return(db.Organization{}, errors.New("Error while creating organization"))
 
-	body := `{"name":"test organization","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST"}`
+	body := `{"name":"test organization","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata"}`
 
 	recorder := makeHTTPCall(http.MethodPost,
 		"/organizations",
@@ -125,7 +129,7 @@ func (suite *OrganizationHandlerTestSuite) TestCreateOrganizationDbFailure() {
 
 func (suite *OrganizationHandlerTestSuite) TestCreateOrganizationValidationFailure() {
 
-	body := `{"name":"","email":"","domain_name":"","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST"}`
+	body := `{"name":"","email":"","domain_name":"","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata"}`
 
 	recorder := makeHTTPCall(http.MethodPost,
 		"/organizations",
@@ -145,7 +149,7 @@ func (suite *OrganizationHandlerTestSuite) TestUpdateOrganizationSuccess() {
 	testUpdateOrganization.Name = "test organization (updated)"
 	suite.dbMock.On("UpdateOrganization", mock.Anything, testUpdateOrganization, testUpdateOrganization.ID).Return(testUpdateOrganization, nil)
 
-	body := `{"id":1,"name":"test organization (updated)","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST"}`
+	body := `{"id":1,"name":"test organization (updated)","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata"}`
 
 	recorder := makeHTTPCall(http.MethodPut,
 		"/organizations/{id:[0-9]+}",
@@ -170,7 +174,7 @@ func (suite *OrganizationHandlerTestSuite) TestUpdateOrganizationDbFailure() {
 
 	suite.dbMock.On("UpdateOrganization", mock.Anything, testUpdateOrganization, testUpdateOrganization.ID).Return(db.Organization{}, errors.New("Error while updating organization"))
 
-	body := `{"id":1,"name":"test organization (updated)","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST"}`
+	body := `{"id":1,"name":"test organization (updated)","email":"test@gmail.com","domain_name":"www.testdomain.com","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata"}`
 
 	recorder := makeHTTPCall(http.MethodPut,
 		"/organizations/{id:[0-9]+}",
@@ -185,7 +189,7 @@ func (suite *OrganizationHandlerTestSuite) TestUpdateOrganizationDbFailure() {
 
 func (suite *OrganizationHandlerTestSuite) TestUpdateOrganizationValidationFailure() {
 
-	body := `{"name":"name","email":"invalid email","domain_name":"invalid domain","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"2","timezone":"IST"}`
+	body := `{"name":"name","email":"invalid email","domain_name":"invalid domain","subscription_status":1,"subscription_valid_upto":1588073442241,"hi5_limit":5,"hi5_quota_renewal_frequency":"WEEKLY","hi5_quota_renewal_weekday":"MONDAY","timezone":"Asia/Kolkata"}`
 
 	recorder := makeHTTPCall(http.MethodPut,
 		"/organizations/{id:[0-9]+}",
@@ -0,0 +1,480 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title createRecognitionAppealHandler
+// @Description appeal the moderation decision that hid a recognition, giver or recipient only
+// @Router /recognitions/:recognition_id/appeals [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func createRecognitionAppealHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.ParseInt(vars["recognition_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing recognition_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, int(recognitionID), actor.OrgID)
+		if !ok {
+			return
+		}
+
+		if actor.ID != recognition.GivenBy && actor.ID != recognition.GivenFor {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "Only the giver or recipient of the recognition can appeal",
+				},
+			})
+			return
+		}
+
+		var appeal db.RecognitionAppeal
+		actorErrFields, err := decodeWithoutActorFields(req, &appeal, "appealed_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		appeal.RecognitionID = recognitionID
+		appeal.AppealedBy = int64(actor.ID)
+
+		ok, errFields := appeal.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-recognition-appeal",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid recognition appeal"},
+				},
+			})
+			return
+		}
+
+		resp, err := deps.Store.CreateRecognitionAppeal(req.Context(), actor.OrgID, appeal)
+		if err == ae.ErrNothingToAppeal {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition has no moderation decision to appeal",
+				},
+			})
+			return
+		}
+		if err == ae.ErrAlreadyAppealed {
+			repsonse(rw, http.StatusConflict, errorResponse{
+				Error: messageObject{
+					Message: "Moderation decision was already appealed",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while creating recognition appeal")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: resp})
+	})
+}
+
+// @Title listRecognitionAppealsHandler
+// @Description appeals against moderation decisions of an organization, oldest first, filtered by ?status and ?assigned_to, admins only
+// @Router /organizations/:organization_id/moderation/appeals [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listRecognitionAppealsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		errFields := make(map[string]string)
+		params := req.URL.Query()
+
+		status := params.Get("status")
+		if status != "" {
+			valid := false
+			for _, appealStatus := range db.APPEAL_STATUSES {
+				if status == appealStatus {
+					valid = true
+				}
+			}
+			if !valid {
+				errFields["status"] = "Must be one of open, upheld or overturned"
+			}
+		}
+
+		var assignedTo int64
+		if param := params.Get("assigned_to"); param != "" {
+			assignedTo, err = strconv.ParseInt(param, 10, 64)
+			if err != nil || assignedTo < 1 {
+				errFields["assigned_to"] = "Must be a user id"
+			}
+		}
+
+		if len(errFields) > 0 {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-recognition-appeal-params",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid recognition appeal parameters"},
+				},
+			})
+			return
+		}
+
+		appeals, err := deps.Store.ListRecognitionAppeals(req.Context(), organizationID, status, assignedTo)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition appeals")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: appeals})
+	})
+}
+
+// @Title assignRecognitionAppealHandler
+// @Description hand an open appeal to another admin, who can't be the one who took the appealed decision, admins only
+// @Router /organizations/:organization_id/moderation/appeals/:id/assignment [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func assignRecognitionAppealHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		appealID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var assignment db.ModerationAssignment
+		actorErrFields, err := decodeWithoutActorFields(req, &assignment, "assigned_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+
+		appeal, ok := openAppealOfOrganization(rw, req, deps, organizationID, appealID)
+		if !ok {
+			return
+		}
+
+		recognition, err := deps.Store.ShowRecognition(req.Context(), int(appeal.RecognitionID))
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching appealed recognition")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		// like the automatic pick, neither the original moderator nor the giver or recipient can review the appeal
+		assignedTo := assignment.AssignedTo
+		if assignedTo == appeal.ModeratedBy || assignedTo == int64(recognition.GivenBy) || assignedTo == int64(recognition.GivenFor) {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-moderation-assignment",
+					Fields:        map[string]string{"assigned_to": "Can't be the moderator who took the decision, the giver or the recipient"},
+					messageObject: messageObject{"Invalid moderation assignment"},
+				},
+			})
+			return
+		}
+
+		if !validateModerator(rw, req, deps, organizationID, assignment.AssignedTo) {
+			return
+		}
+
+		resp, err := deps.Store.AssignRecognitionAppeal(req.Context(), appealID, assignment.AssignedTo)
+		if err == ae.ErrAppealAlreadyDecided {
+			appealDecidedResponse(rw)
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while assigning recognition appeal")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: resp})
+	})
+}
+
+// @Title decideRecognitionAppealHandler
+// @Description uphold or overturn an appeal, overturning restores the recognition and its Hi5s, assigned moderator only
+// @Router /organizations/:organization_id/moderation/appeals/:id/decision [post]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func decideRecognitionAppealHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		appealID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var decision db.AppealDecision
+		actorErrFields, err := decodeWithoutActorFields(req, &decision, "decided_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		decision.AppealID = appealID
+		decision.DecidedBy = int64(actor.ID)
+
+		ok, errFields := decision.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-appeal-decision",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid appeal decision"},
+				},
+			})
+			return
+		}
+
+		appeal, ok := openAppealOfOrganization(rw, req, deps, organizationID, appealID)
+		if !ok {
+			return
+		}
+
+		if appeal.AssignedTo == nil || *appeal.AssignedTo != decision.DecidedBy {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "Only the moderator assigned to the appeal can decide it",
+				},
+			})
+			return
+		}
+
+		resp, err := deps.Store.DecideRecognitionAppeal(req.Context(), decision)
+		if err == ae.ErrAppealAlreadyDecided {
+			appealDecidedResponse(rw)
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while deciding recognition appeal")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		// a restored recognition counts towards the badges of both parties again
+		if resp.Status == db.AppealOverturnedStatus {
+			recognition, err := deps.Store.ShowRecognition(req.Context(), int(resp.RecognitionID))
+			if err != nil {
+				logger.WithField("err", err.Error()).Error("Error while fetching restored recognition")
+			} else {
+				awardBadges(req.Context(), deps, organizationID, recognition.GivenFor)
+				awardBadges(req.Context(), deps, organizationID, recognition.GivenBy)
+			}
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: resp})
+	})
+}
+
+// @Title getRecognitionModerationHistoryHandler
+// @Description every moderation decision on a recognition and every appeal against them, for admins and the giver or recipient
+// @Router /recognitions/:recognition_id/moderation_history [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getRecognitionModerationHistoryHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.ParseInt(vars["recognition_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing recognition_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, int(recognitionID), actor.OrgID)
+		if !ok {
+			return
+		}
+
+		if actor.ID != recognition.GivenBy && actor.ID != recognition.GivenFor &&
+			!requireOrgAdmin(rw, req, deps, actor, actor.OrgID) {
+			return
+		}
+
+		history, err := deps.Store.ListRecognitionModerationHistory(req.Context(), recognitionID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition moderation history")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: history})
+	})
+}
+
+// openAppealOfOrganization - loads an appeal of the organization that is still open, writing
+// the error response and returning false otherwise
+func openAppealOfOrganization(rw http.ResponseWriter, req *http.Request, deps Dependencies, organizationID int, appealID int64) (appeal db.RecognitionAppeal, ok bool) {
+	appeal, err := deps.Store.GetRecognitionAppeal(req.Context(), organizationID, appealID)
+	if err == ae.ErrRecordNotFound {
+		repsonse(rw, http.StatusNotFound, errorResponse{
+			Error: messageObject{
+				Message: "Appeal not found",
+			},
+		})
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching recognition appeal")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	if appeal.Status != db.AppealOpenStatus {
+		appealDecidedResponse(rw)
+		return
+	}
+
+	ok = true
+	return
+}
+
+// appealDecidedResponse - 409 response for acting on an appeal that was upheld or overturned already
+func appealDecidedResponse(rw http.ResponseWriter) {
+	repsonse(rw, http.StatusConflict, errorResponse{
+		Error: messageObject{
+			Message: "Appeal was already decided",
+		},
+	})
+}
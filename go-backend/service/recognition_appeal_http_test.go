@@ -0,0 +1,287 @@
+package service
+
+import (
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var testHiddenRecognition = db.Recognition{ID: 8, CoreValueID: 1, Text: "Great demo", GivenFor: 4, GivenBy: 3, Status: db.RecognitionStatusHidden}
+
+type RecognitionAppealHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testHiddenRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 3, 1).Return(db.User{ID: 3, OrgID: 1, RoleID: 3}, nil)
+}
+
+// openAppeal - appeal by the recipient against a decision of admin 1, assigned to admin 5
+func openAppeal() db.RecognitionAppeal {
+	assignedTo := int64(5)
+	return db.RecognitionAppeal{
+		ID:            1,
+		RecognitionID: 8,
+		ModerationID:  3,
+		ModeratedBy:   1,
+		AppealedBy:    4,
+		Statement:     "It was a real demo",
+		Status:        db.AppealOpenStatus,
+		AssignedTo:    &assignedTo,
+	}
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestCreateRecognitionAppealByRecipient() {
+	suite.dbMock.On("CreateRecognitionAppeal", mock.Anything, 1, db.RecognitionAppeal{RecognitionID: 8, AppealedBy: 4, Statement: "It was a real demo"}).
+		Return(openAppeal(), nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/appeals",
+		"/recognitions/8/appeals",
+		`{"statement":"It was a real demo"}`,
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		createRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":1,"recognition_id":8,"moderation_id":3,"moderated_by":1,"appealed_by":4,"statement":"It was a real demo","status":"open","assigned_to":5,"assigned_at":null,"decided_by":null,"decided_at":null,"decision_comment":"","overturn_moderation_id":null,"appealed_at":0}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestCreateRecognitionAppealByAnotherUser() {
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/appeals",
+		"/recognitions/8/appeals",
+		`{"statement":"It was a real demo"}`,
+		db.User{ID: 6, OrgID: 1, RoleID: 3},
+		createRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Only the giver or recipient of the recognition can appeal"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionAppeal", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestCreateRecognitionAppealWithoutStatement() {
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/appeals",
+		"/recognitions/8/appeals",
+		`{"statement":""}`,
+		db.User{ID: 3, OrgID: 1, RoleID: 3},
+		createRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recognition-appeal","message":"Invalid recognition appeal","fields":{"statement":"Can't be blank"}}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestCreateRecognitionAppealWhenAlreadyAppealed() {
+	suite.dbMock.On("CreateRecognitionAppeal", mock.Anything, 1, mock.Anything).Return(db.RecognitionAppeal{}, ae.ErrAlreadyAppealed)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/appeals",
+		"/recognitions/8/appeals",
+		`{"statement":"Please check again"}`,
+		db.User{ID: 3, OrgID: 1, RoleID: 3},
+		createRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusConflict, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Moderation decision was already appealed"}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestCreateRecognitionAppealWithoutDecision() {
+	suite.dbMock.On("CreateRecognitionAppeal", mock.Anything, 1, mock.Anything).Return(db.RecognitionAppeal{}, ae.ErrNothingToAppeal)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/appeals",
+		"/recognitions/8/appeals",
+		`{"statement":"Please check again"}`,
+		db.User{ID: 3, OrgID: 1, RoleID: 3},
+		createRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Recognition has no moderation decision to appeal"}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestListRecognitionAppealsWithInvalidParams() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals",
+		"/organizations/1/moderation/appeals?status=closed&assigned_to=me",
+		"",
+		testAdmin,
+		listRecognitionAppealsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recognition-appeal-params","message":"Invalid recognition appeal parameters","fields":{"assigned_to":"Must be a user id","status":"Must be one of open, upheld or overturned"}}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestListRecognitionAppealsSuccess() {
+	suite.dbMock.On("ListRecognitionAppeals", mock.Anything, 1, db.AppealOpenStatus, int64(5)).Return([]db.RecognitionAppeal{openAppeal()}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals",
+		"/organizations/1/moderation/appeals?status=open&assigned_to=5",
+		"",
+		testAdmin,
+		listRecognitionAppealsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "ListRecognitionAppeals", mock.Anything, 1, db.AppealOpenStatus, int64(5))
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestAssignRecognitionAppealToOriginalModerator() {
+	suite.dbMock.On("GetRecognitionAppeal", mock.Anything, 1, int64(1)).Return(openAppeal(), nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/assignment",
+		"/organizations/1/moderation/appeals/1/assignment",
+		`{"assigned_to":1}`,
+		testAdmin,
+		assignRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-assignment","message":"Invalid moderation assignment","fields":{"assigned_to":"Can't be the moderator who took the decision, the giver or the recipient"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "AssignRecognitionAppeal", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestAssignRecognitionAppealToOtherParty() {
+	suite.dbMock.On("GetRecognitionAppeal", mock.Anything, 1, int64(1)).Return(openAppeal(), nil)
+
+	// the recipient appealed, the giver is a party too
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/assignment",
+		"/organizations/1/moderation/appeals/1/assignment",
+		`{"assigned_to":3}`,
+		testAdmin,
+		assignRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-moderation-assignment","message":"Invalid moderation assignment","fields":{"assigned_to":"Can't be the moderator who took the decision, the giver or the recipient"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "AssignRecognitionAppeal", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestAssignRecognitionAppealSuccess() {
+	suite.dbMock.On("GetRecognitionAppeal", mock.Anything, 1, int64(1)).Return(openAppeal(), nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{ID: 7, OrgID: 1, RoleID: 2}, nil)
+	reassigned := openAppeal()
+	assignedTo := int64(7)
+	reassigned.AssignedTo = &assignedTo
+	suite.dbMock.On("AssignRecognitionAppeal", mock.Anything, int64(1), int64(7)).Return(reassigned, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/assignment",
+		"/organizations/1/moderation/appeals/1/assignment",
+		`{"assigned_to":7}`,
+		testAdmin,
+		assignRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "AssignRecognitionAppeal", mock.Anything, int64(1), int64(7))
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestDecideRecognitionAppealByAnotherModerator() {
+	suite.dbMock.On("GetRecognitionAppeal", mock.Anything, 1, int64(1)).Return(openAppeal(), nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/decision",
+		"/organizations/1/moderation/appeals/1/decision",
+		`{"decision":"upheld"}`,
+		testAdmin,
+		decideRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Only the moderator assigned to the appeal can decide it"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "DecideRecognitionAppeal", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestDecideRecognitionAppealAlreadyDecided() {
+	decided := openAppeal()
+	decided.Status = db.AppealUpheldStatus
+	suite.dbMock.On("GetRecognitionAppeal", mock.Anything, 1, int64(1)).Return(decided, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/decision",
+		"/organizations/1/moderation/appeals/1/decision",
+		`{"decision":"overturned"}`,
+		db.User{ID: 5, OrgID: 1, RoleID: 2},
+		decideRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusConflict, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Appeal was already decided"}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestDecideRecognitionAppealOverturned() {
+	decision := db.AppealDecision{AppealID: 1, Decision: db.AppealOverturnedStatus, Comment: "Demo happened", DecidedBy: 5}
+	overturned := openAppeal()
+	overturned.Status = db.AppealOverturnedStatus
+	suite.dbMock.On("GetRecognitionAppeal", mock.Anything, 1, int64(1)).Return(openAppeal(), nil)
+	suite.dbMock.On("DecideRecognitionAppeal", mock.Anything, decision).Return(overturned, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, 4).Return([]db.UserBadge{}, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, 3).Return([]db.UserBadge{}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/decision",
+		"/organizations/1/moderation/appeals/1/decision",
+		`{"decision":"overturned","comment":"Demo happened"}`,
+		db.User{ID: 5, OrgID: 1, RoleID: 2},
+		decideRecognitionAppealHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "DecideRecognitionAppeal", mock.Anything, decision)
+	// the restored recognition counts towards the recipient's and the giver's badges again
+	suite.dbMock.AssertCalled(suite.T(), "AwardBadges", mock.Anything, 1, 4)
+	suite.dbMock.AssertCalled(suite.T(), "AwardBadges", mock.Anything, 1, 3)
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestGetRecognitionModerationHistoryByGiver() {
+	isInappropriate := true
+	suite.dbMock.On("ListRecognitionModerationHistory", mock.Anything, int64(8)).Return(db.ModerationHistory{
+		Moderations: []db.RecognitionModeration{{ID: 3, RecognitionID: 8, IsInappropriate: &isInappropriate, ModeratedBy: 1}},
+		Appeals:     []db.RecognitionAppeal{openAppeal()},
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/moderation_history",
+		"/recognitions/8/moderation_history",
+		"",
+		db.User{ID: 3, OrgID: 1, RoleID: 3},
+		getRecognitionModerationHistoryHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "ListRecognitionModerationHistory", mock.Anything, int64(8))
+}
+
+func (suite *RecognitionAppealHandlerTestSuite) TestGetRecognitionModerationHistoryByAnotherEmployee() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/moderation_history",
+		"/recognitions/8/moderation_history",
+		"",
+		db.User{ID: 6, OrgID: 1, RoleID: 3},
+		getRecognitionModerationHistoryHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Only admins can perform this action"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionModerationHistory", mock.Anything, mock.Anything)
+}
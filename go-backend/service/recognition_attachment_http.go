@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"joshsoftware/peerly/config"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// attachmentUploadSlot - response for an upload slot: the pending attachment plus where to PUT the file
+type attachmentUploadSlot struct {
+	db.RecognitionAttachment
+	UploadURL string `json:"upload_url"`
+}
+
+func createRecognitionAttachmentHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.ParseInt(vars["recognition_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing recognition_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, int(recognitionID), actor.OrgID)
+		if !ok {
+			return
+		}
+
+		// a recognition hidden by a moderator can't get new attachments, as in the list handler
+		if recognition.IsHidden() {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition not found",
+				},
+			})
+			return
+		}
+
+		// only the giver can attach files to a recognition
+		if recognition.GivenBy != actor.ID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "Only the giver can attach files to a recognition",
+				},
+			})
+			return
+		}
+
+		var attachment db.RecognitionAttachment
+		actorErrFields, err := decodeWithoutActorFields(req, &attachment, "uploaded_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		attachment.RecognitionID = recognitionID
+		attachment.UploadedBy = int64(actor.ID)
+
+		uploadURL, ok := createUploadSlot(rw, req, deps, attachmentUploadKind(), attachment.Validate, func(ctx context.Context) (string, string, error) {
+			attachment, err = deps.Store.CreateRecognitionAttachment(ctx, attachment)
+			return attachment.ObjectKey, attachment.ContentType, err
+		})
+		if !ok {
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: attachmentUploadSlot{
+			RecognitionAttachment: attachment,
+			UploadURL:             uploadURL,
+		}})
+	})
+}
+
+func confirmRecognitionAttachmentHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.ParseInt(vars["recognition_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing recognition_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		attachmentID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing attachment id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		attachment, err := deps.Store.GetRecognitionAttachment(req.Context(), recognitionID, attachmentID)
+		if err != nil || attachment.UploadedBy != int64(actor.ID) || attachment.Status != db.AttachmentStatusPending {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Pending attachment not found",
+				},
+			})
+			return
+		}
+
+		objectInfo, ok := verifyUploadedObject(rw, req, deps, pendingUpload{
+			uploadKind: attachmentUploadKind(),
+			objectKey:  attachment.ObjectKey,
+			verify:     attachment.VerifyUpload,
+			discard: func(ctx context.Context) error {
+				return deps.Store.DeleteRecognitionAttachment(ctx, recognitionID, attachmentID)
+			},
+		})
+		if !ok {
+			return
+		}
+
+		confirmedAttachment, err := deps.Store.ConfirmRecognitionAttachment(req.Context(), attachmentID, objectInfo.Size)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while confirming recognition attachment")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		signedURL, err := deps.AWSStore.GetAWSS3DownloadURL(req.Context(), config.AttachmentsBucket(), confirmedAttachment.ObjectKey)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while retrieving download URL")
+		}
+		confirmedAttachment.URL = signedURL.S3SignedURL
+
+		repsonse(rw, http.StatusOK, successResponse{Data: confirmedAttachment})
+	})
+}
+
+func listRecognitionAttachmentsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.ParseInt(vars["recognition_id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while parsing recognition_id from url")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, int(recognitionID), actor.OrgID)
+		if !ok {
+			return
+		}
+
+		// attachments go out of sight along with a recognition hidden by a moderator,
+		// and only the giver sees them before the recognition is published
+		if recognition.IsHidden() || (!recognition.IsPublished() && recognition.GivenBy != actor.ID) {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition not found",
+				},
+			})
+			return
+		}
+
+		attachments, err := deps.Store.ListRecognitionAttachments(req.Context(), []int64{recognitionID})
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition attachments")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		err = signAttachmentURLs(req, deps, attachments)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Error while retrieving URL",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: attachments})
+	})
+}
+
+// attachmentUploadKind - attachments are kept apart from images in their own bucket
+func attachmentUploadKind() uploadKind {
+	return uploadKind{bucket: config.AttachmentsBucket(), errorCode: "invalid-attachment", name: "attachment"}
+}
+
+// signAttachmentURLs - fills in a short-lived download URL on each attachment
+func signAttachmentURLs(req *http.Request, deps Dependencies, attachments []db.RecognitionAttachment) (err error) {
+	bucket := config.AttachmentsBucket()
+	for i := range attachments {
+		signedURL, err := deps.AWSStore.GetAWSS3DownloadURL(req.Context(), bucket, attachments[i].ObjectKey)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while retrieving download URL")
+			return err
+		}
+		attachments[i].URL = signedURL.S3SignedURL
+	}
+	return
+}
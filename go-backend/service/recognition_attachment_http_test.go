@@ -0,0 +1,308 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"joshsoftware/peerly/aws"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+var testPendingAttachment = db.RecognitionAttachment{
+	ID:            1,
+	RecognitionID: 1,
+	UploadedBy:    1,
+	ObjectKey:     "recognitions/1/abc.png",
+	FileName:      "team.png",
+	ContentType:   "image/png",
+	SizeBytes:     1024,
+	Status:        db.AttachmentStatusPending,
+}
+
+type RecognitionAttachmentHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock  *db.DBMockStore
+	awsMock *aws.AWSMockStore
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.awsMock = &aws.AWSMockStore{}
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) deps() Dependencies {
+	return Dependencies{Store: suite.dbMock, AWSStore: suite.awsMock}
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestCreateRecognitionAttachmentSuccess() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1}, nil)
+	suite.dbMock.On("CreateRecognitionAttachment", mock.Anything, mock.Anything).Return(testPendingAttachment, nil)
+	suite.awsMock.On("GetAWSS3UploadURL", mock.Anything, "peerly-attachments", "recognitions/1/abc.png", "image/png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://upload.example.com"}, nil)
+
+	body := `{"file_name": "team.png", "content_type": "image/png", "size_bytes": 1024}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		body,
+		createRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":1,"recognition_id":1,"uploaded_by":1,"file_name":"team.png","content_type":"image/png","size_bytes":1024,"status":"pending","upload_url":"https://upload.example.com"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestCreateRecognitionAttachmentByOtherUser() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{ID: 7, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 7}, nil)
+
+	body := `{"file_name": "team.png", "content_type": "image/png", "size_bytes": 1024}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		body,
+		createRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionAttachment", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestCreateRecognitionAttachmentWithInvalidType() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1}, nil)
+
+	body := `{"file_name": "run.exe", "content_type": "application/octet-stream", "size_bytes": 1024}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		body,
+		createRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-attachment","message":"Invalid attachment data","fields":{"content_type":"Unsupported content type"}}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestConfirmRecognitionAttachmentSuccess() {
+	uploadedAttachment := testPendingAttachment
+	uploadedAttachment.Status = db.AttachmentStatusUploaded
+
+	suite.dbMock.On("GetRecognitionAttachment", mock.Anything, int64(1), int64(1)).Return(testPendingAttachment, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(
+		aws.S3ObjectInfo{Size: 1024, ContentType: "image/png"}, nil)
+	suite.dbMock.On("ConfirmRecognitionAttachment", mock.Anything, int64(1), int64(1024)).Return(uploadedAttachment, nil)
+	suite.awsMock.On("GetAWSS3DownloadURL", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://download.example.com"}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments/{id:[0-9]+}/confirm",
+		"/recognitions/1/attachments/1/confirm",
+		"",
+		confirmRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":1,"recognition_id":1,"uploaded_by":1,"file_name":"team.png","content_type":"image/png","size_bytes":1024,"status":"uploaded","url":"https://download.example.com"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestConfirmRecognitionAttachmentWhenNotUploaded() {
+	suite.dbMock.On("GetRecognitionAttachment", mock.Anything, int64(1), int64(1)).Return(testPendingAttachment, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(
+		aws.S3ObjectInfo{}, errors.New("NotFound"))
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments/{id:[0-9]+}/confirm",
+		"/recognitions/1/attachments/1/confirm",
+		"",
+		confirmRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ConfirmRecognitionAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestConfirmRecognitionAttachmentWithMismatchedUpload() {
+	suite.dbMock.On("GetRecognitionAttachment", mock.Anything, int64(1), int64(1)).Return(testPendingAttachment, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(
+		aws.S3ObjectInfo{Size: 4096, ContentType: "image/png"}, nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(nil)
+	suite.dbMock.On("DeleteRecognitionAttachment", mock.Anything, int64(1), int64(1)).Return(nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments/{id:[0-9]+}/confirm",
+		"/recognitions/1/attachments/1/confirm",
+		"",
+		confirmRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestListRecognitionAttachmentsSuccess() {
+	uploadedAttachment := testPendingAttachment
+	uploadedAttachment.Status = db.AttachmentStatusUploaded
+
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1, Status: db.RecognitionStatusPublished}, nil)
+	suite.dbMock.On("ListRecognitionAttachments", mock.Anything, []int64{1}).Return([]db.RecognitionAttachment{uploadedAttachment}, nil)
+	suite.awsMock.On("GetAWSS3DownloadURL", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://download.example.com"}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		"",
+		listRecognitionAttachmentsHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":1,"recognition_id":1,"uploaded_by":1,"file_name":"team.png","content_type":"image/png","size_bytes":1024,"status":"uploaded","url":"https://download.example.com"}]}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestCreateRecognitionAttachmentWhenUploadedByIsSet() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1}, nil)
+
+	body := `{"file_name": "team.png", "content_type": "image/png", "size_bytes": 1024, "uploaded_by": 7}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		body,
+		createRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionAttachment", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestCreateRecognitionAttachmentOfHiddenRecognition() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1, Status: db.RecognitionStatusHidden}, nil)
+
+	body := `{"file_name": "team.png", "content_type": "image/png", "size_bytes": 1024}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		body,
+		createRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Recognition not found"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionAttachment", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestCreateRecognitionAttachmentOfAnotherOrganization() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 5, 1).Return(db.User{}, sql.ErrNoRows)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 5, Status: db.RecognitionStatusPublished}, nil)
+
+	body := `{"file_name": "team.png", "content_type": "image/png", "size_bytes": 1024}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		body,
+		createRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionAttachment", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestListRecognitionAttachmentsOfHiddenRecognition() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1, Status: db.RecognitionStatusHidden}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		"",
+		listRecognitionAttachmentsHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Recognition not found"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionAttachments", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestListRecognitionAttachmentsOfAnotherOrganization() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 5, 1).Return(db.User{}, sql.ErrNoRows)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 5, Status: db.RecognitionStatusPublished}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		"",
+		listRecognitionAttachmentsHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionAttachments", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestListRecognitionAttachmentsOfAnotherUsersDraft() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1, Status: db.RecognitionStatusDraft}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/attachments",
+		"/recognitions/1/attachments",
+		"",
+		db.User{ID: 2, OrgID: 1},
+		listRecognitionAttachmentsHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionAttachments", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionAttachmentHandlerTestSuite) TestConfirmRecognitionAttachmentWhenRejectingFails() {
+	suite.dbMock.On("GetRecognitionAttachment", mock.Anything, int64(1), int64(1)).Return(testPendingAttachment, nil)
+	suite.awsMock.On("GetAWSS3ObjectInfo", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(
+		aws.S3ObjectInfo{Size: 4096, ContentType: "image/png"}, nil)
+	suite.awsMock.On("DeleteAWSS3Object", mock.Anything, "peerly-attachments", "recognitions/1/abc.png").Return(errors.New("access denied"))
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/attachments/{id:[0-9]+}/confirm",
+		"/recognitions/1/attachments/1/confirm",
+		"",
+		confirmRecognitionAttachmentHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "DeleteRecognitionAttachment", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"net/http"
+	"strconv"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title listRecognitionFeedHandler
+// @Description published recognitions of the organization with users, core values, Hi5s and attachments embedded
+// @Router /organisations/{id:[0-9]+}/feed?cursor=&limit=
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listRecognitionFeedHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["orgnization_id"])
+		if err != nil {
+			logger.Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		query, errFields := feedQueryFromRequest(req)
+		if len(errFields) > 0 {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-feed-params",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid feed parameters"},
+				},
+			})
+			return
+		}
+		query.OrgID = organizationID
+		query.ViewerID = actor.ID
+
+		page, err := deps.Store.ListRecognitionFeed(req.Context(), query)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition feed")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		err = localizeFeed(req, deps, organizationID, &page)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		for i := range page.Recognitions {
+			err = signAttachmentURLs(req, deps, page.Recognitions[i].Attachments)
+			if err != nil {
+				repsonse(rw, http.StatusInternalServerError, errorResponse{
+					Error: messageObject{
+						Message: "Error while retrieving URL",
+					},
+				})
+				return
+			}
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: page})
+	})
+}
+
+// feedQueryFromRequest - reads the cursor and limit query params
+func feedQueryFromRequest(req *http.Request) (query db.FeedQuery, errFields map[string]string) {
+	errFields = make(map[string]string)
+	params := req.URL.Query()
+
+	query.Limit = db.DefaultFeedLimit
+	if limit := params.Get("limit"); limit != "" {
+		var err error
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit < 1 || query.Limit > db.MaxFeedLimit {
+			errFields["limit"] = "Must be between 1 and " + strconv.Itoa(db.MaxFeedLimit)
+		}
+	}
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		decodedCursor, err := db.DecodeFeedCursor(cursor)
+		if err != nil {
+			errFields["cursor"] = err.Error()
+		} else {
+			query.Cursor = &decodedCursor
+		}
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"net/http"
+
+	"joshsoftware/peerly/aws"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionFeedHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock  *db.DBMockStore
+	awsMock *aws.AWSMockStore
+}
+
+func (suite *RecognitionFeedHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.awsMock = &aws.AWSMockStore{}
+}
+
+func (suite *RecognitionFeedHandlerTestSuite) deps() Dependencies {
+	return Dependencies{Store: suite.dbMock, AWSStore: suite.awsMock}
+}
+
+func (suite *RecognitionFeedHandlerTestSuite) TestListRecognitionFeedSuccess() {
+	cursor := db.FeedCursor{GivenAt: 300, RecognitionID: 6}
+	suite.dbMock.On("ListRecognitionFeed", mock.Anything, db.FeedQuery{OrgID: 1, ViewerID: 1, Cursor: &cursor, Limit: 10}).Return(db.FeedPage{
+		Recognitions: []db.FeedRecognition{{
+			ID:          5,
+			Text:        "thanks for the help",
+			GivenBy:     db.FeedUser{ID: 1, Name: "Giver"},
+			GivenFor:    db.FeedUser{ID: 2, Name: "Receiver"},
+			CoreValue:   &db.FeedCoreValue{ID: 3, Text: "Teamwork", Parent: &db.FeedCoreValue{ID: 1, Text: "Culture"}},
+			Hi5Count:    4,
+			HasHi5d:     true,
+			Attachments: []db.RecognitionAttachment{{ID: 1, RecognitionID: 5, ObjectKey: "recognitions/5/abc.png"}},
+		}},
+		BadgeGrants: []db.FeedBadgeGrant{{ID: 3, BadgeID: 2, BadgeName: "Hackathon winner", Note: "Won the July hackathon",
+			AwardedAt: 250, User: db.FeedUser{ID: 2, Name: "Receiver"}}},
+		NextCursor: "next",
+	}, nil)
+	suite.awsMock.On("GetAWSS3DownloadURL", mock.Anything, "peerly-attachments", "recognitions/5/abc.png").Return(
+		aws.S3SignedURL{S3SignedURL: "https://download.example.com"}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/organisations/{orgnization_id:[0-9]+}/feed",
+		"/organisations/1/feed?limit=10&cursor="+cursor.Encode(),
+		"",
+		listRecognitionFeedHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"recognitions":[{"id":5,"text":"thanks for the help","given_at":0,"given_by":{"id":1,"full_name":"Giver","display_name":"","profile_image_url":""},"given_for":{"id":2,"full_name":"Receiver","display_name":"","profile_image_url":""},"core_value":{"id":3,"text":"Teamwork","parent":{"id":1,"text":"Culture","parent":null}},"hi5_count":4,"comment_count":0,"has_hi5d":true,"attachments":[{"id":1,"recognition_id":5,"uploaded_by":0,"file_name":"","content_type":"","size_bytes":0,"status":"","url":"https://download.example.com"}]}],"badge_grants":[{"id":3,"badge_id":2,"badge_name":"Hackathon winner","tier":"","note":"Won the July hackathon","awarded_at":250,"user":{"id":2,"full_name":"Receiver","display_name":"","profile_image_url":""}}],"next_cursor":"next"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+	suite.awsMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionFeedHandlerTestSuite) TestListRecognitionFeedInRequestedLocale() {
+	suite.dbMock.On("ListRecognitionFeed", mock.Anything, db.FeedQuery{OrgID: 1, ViewerID: 1, Limit: db.DefaultFeedLimit}).Return(db.FeedPage{
+		Recognitions: []db.FeedRecognition{{
+			ID:        5,
+			Text:      "thanks for the help",
+			CoreValue: &db.FeedCoreValue{ID: 3, Text: "Teamwork", Parent: &db.FeedCoreValue{ID: 1, Text: "Culture"}},
+		}},
+		BadgeGrants: []db.FeedBadgeGrant{
+			{ID: 3, BadgeID: 2, BadgeName: "Hackathon winner", AwardedAt: 250},
+			{ID: 4, BadgeID: 6, BadgeName: "Mentor", AwardedAt: 200},
+		},
+	}, nil)
+	suite.dbMock.On("PreferredCoreValueTranslations", mock.Anything, int64(1), []string{"de"}).Return(
+		map[int64]db.CoreValueTranslation{3: {CoreValueID: 3, Locale: "de", Text: "Teamarbeit"}}, nil)
+	suite.dbMock.On("PreferredBadgeTranslations", mock.Anything, 1, []string{"de"}).Return(
+		map[int]db.BadgeTranslation{2: {BadgeID: 2, Locale: "de", Name: "Hackathon-Sieger"}}, nil)
+
+	recorder := makeHTTPCallAsActorWithHeaders(
+		http.MethodGet,
+		"/organisations/{orgnization_id:[0-9]+}/feed",
+		"/organisations/1/feed",
+		"",
+		db.User{ID: 1, OrgID: 1},
+		map[string]string{"Accept-Language": "de"},
+		listRecognitionFeedHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"recognitions":[{"id":5,"text":"thanks for the help","given_at":0,"given_by":{"id":0,"full_name":"","display_name":"","profile_image_url":""},"given_for":{"id":0,"full_name":"","display_name":"","profile_image_url":""},"core_value":{"id":3,"text":"Teamarbeit","parent":{"id":1,"text":"Culture","parent":null}},"hi5_count":0,"comment_count":0,"has_hi5d":false,"attachments":null}],"badge_grants":[{"id":3,"badge_id":2,"badge_name":"Hackathon-Sieger","tier":"","note":"","awarded_at":250,"user":{"id":0,"full_name":"","display_name":"","profile_image_url":""}},{"id":4,"badge_id":6,"badge_name":"Mentor","tier":"","note":"","awarded_at":200,"user":{"id":0,"full_name":"","display_name":"","profile_image_url":""}}],"next_cursor":""}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionFeedHandlerTestSuite) TestListRecognitionFeedWithInvalidParams() {
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/organisations/{orgnization_id:[0-9]+}/feed",
+		"/organisations/1/feed?limit=500&cursor=abc",
+		"",
+		listRecognitionFeedHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-feed-params","message":"Invalid feed parameters","fields":{"cursor":"Invalid feed cursor","limit":"Must be between 1 and 100"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionFeed", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionFeedHandlerTestSuite) TestListRecognitionFeedForAnotherOrganization() {
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/organisations/{orgnization_id:[0-9]+}/feed",
+		"/organisations/2/feed",
+		"",
+		listRecognitionFeedHandler(suite.deps()),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionFeed", mock.Anything, mock.Anything)
+}
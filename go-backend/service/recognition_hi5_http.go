@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/gorilla/mux"
 	logger "github.com/sirupsen/logrus"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 	"net/http"
 	"strconv"
@@ -19,21 +20,75 @@ func createRecognitionHi5Handler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		currentUser, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
 		var recognitionHi5 db.RecognitionHi5
-		err = json.NewDecoder(req.Body).Decode(&recognitionHi5)
+		actorErrFields, err := decodeWithoutActorFields(req, &recognitionHi5, "given_by")
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error Decoding recognitionHi5 data")
 			rw.WriteHeader(http.StatusBadRequest)
 			return
 		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		recognitionHi5.GivenBy = currentUser.ID
+
+		// Hi5s can only be given once a draft or scheduled recognition has been published
+		recognition, ok := recognitionOfOrganization(rw, req, deps, recognitionID, currentUser.OrgID)
+		if !ok {
+			return
+		}
+
+		if !recognition.IsPublished() {
+			logger.Error("Hi5 given for unpublished recognition ", recognitionID)
+			repsonse(rw, http.StatusBadRequest, map[string]db.ErrorResponse{
+				"error": db.ErrorResponse{
+					Code:    "recognition_not_published",
+					Message: "Recognition is not published yet.",
+				},
+			})
+			return
+		}
 
-		currentUser, err := deps.Store.GetUser(req.Context(), recognitionHi5.GivenBy)
+		settings, err := deps.Store.GetRecognitionRuleSettings(req.Context(), currentUser.OrgID)
 		if err != nil {
-			logger.WithField("err", err.Error()).Error("Error while fetching User")
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition rule settings")
 			rw.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 
+		valid, errFields, err := db.EvaluateHi5Rules(req.Context(), deps.Store, settings, recognition, recognitionHi5)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while evaluating Hi5 rules")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		if !valid {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "hi5-rule-violation",
+					Fields:        errFields,
+					messageObject: messageObject{"Hi5 breaks organization rules"},
+				},
+			})
+			return
+		}
+
+		var flags []string
+		if recognitionHi5.Comment != "" {
+			recognitionHi5.Comment, flags, ok = filterContent(rw, req, deps, currentUser.OrgID, "comment", recognitionHi5.Comment)
+			if !ok {
+				return
+			}
+		}
+
 		errorResponse := recognitionHi5.CheckHi5QuotaBalance(currentUser.Hi5QuotaBalance)
 		if len(errorResponse) > 0 {
 			logger.Error("Insufficient hi5 quota balance for ", currentUser.ID)
@@ -50,13 +105,124 @@ func createRecognitionHi5Handler(deps Dependencies) http.HandlerFunc {
 		}
 
 		err = deps.Store.CreateRecognitionHi5(req.Context(), recognitionHi5, recognitionID)
+		if err == ae.ErrHi5AlreadyGiven {
+			repsonse(rw, http.StatusConflict, map[string]db.ErrorResponse{
+				"error": db.ErrorResponse{
+					Code:    "hi5_already_given",
+					Message: "You have already given a Hi5 for this recognition.",
+				},
+			})
+			return
+		}
+		if err == ae.ErrInsufficientHi5Quota {
+			repsonse(rw, http.StatusBadRequest, recognitionHi5.CheckHi5QuotaBalance(0))
+			return
+		}
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error in creating recognition hi5")
 			rw.WriteHeader(http.StatusBadRequest)
 			return
 		}
 
+		flagContent(req.Context(), deps, int64(recognitionID), flags)
+		awardBadges(req.Context(), deps, currentUser.OrgID, recognition.GivenFor)
+
 		rw.WriteHeader(http.StatusCreated)
 		return
 	})
 }
+
+func deleteRecognitionHi5Handler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.Atoi(vars["recognition_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error recognition_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		currentUser, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, recognitionID, currentUser.OrgID)
+		if !ok {
+			return
+		}
+
+		// Hi5s of a hidden recognition were already taken back by the moderator
+		if !recognition.IsPublished() {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition not found",
+				},
+			})
+			return
+		}
+
+		organization, err := deps.Store.GetOrganization(req.Context(), currentUser.OrgID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching organization")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// Hi5s given before the last quota reset were already paid for by that period's quota
+		refunded, err := deps.Store.DeleteRecognitionHi5(req.Context(), recognitionID, currentUser.ID, organization.Hi5QuotaLastResetAt)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Hi5 not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error in deleting recognition hi5")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: map[string]bool{"refunded": refunded}})
+	})
+}
+
+func listRecognitionHi5sHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.Atoi(vars["recognition_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error recognition_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		currentUser, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, recognitionID, currentUser.OrgID)
+		if !ok {
+			return
+		}
+
+		if !recognition.IsPublished() {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		hi5s, err := deps.Store.ListRecognitionHi5s(req.Context(), recognitionID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition hi5s")
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: hi5s})
+	})
+}
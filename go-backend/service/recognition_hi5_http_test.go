@@ -1,10 +1,12 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 	"net/http"
 )
@@ -16,6 +18,18 @@ var testRecognitionHi5 = db.RecognitionHi5{
 	GivenBy:       1,
 }
 
+var testPublishedRecognition = db.Recognition{
+	ID:      1,
+	GivenBy: 2,
+	Status:  db.RecognitionStatusPublished,
+}
+
+var testHi5Giver = db.User{
+	ID:              1,
+	OrgID:           1,
+	Hi5QuotaBalance: 5,
+}
+
 type RecognitionHi5HandlerTestSuite struct {
 	suite.Suite
 	dbMock *db.DBMockStore
@@ -27,24 +41,19 @@ func (suite *RecognitionHi5HandlerTestSuite) SetupTest() {
 
 func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5Success() {
 	suite.dbMock.On("CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID).Return(nil)
-	suite.dbMock.On("GetUser", mock.Anything, testRecognitionHi5.GivenBy).Return(
-		db.User{
-			ID:              testRecognitionHi5.GivenBy,
-			OrgID:           1,
-			Name:            "test2",
-			Email:           "test@gmail.com",
-			DisplayName:     "test",
-			ProfileImageURL: "test.jpg",
-			RoleID:          10,
-			Hi5QuotaBalance: 5,
-		}, nil)
-
-	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment", "given_by": 1}`
-
-	recorder := makeHTTPCall(http.MethodPost,
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, testPublishedRecognition.GivenFor).Return([]db.UserBadge{}, nil)
+
+	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/hi5",
 		"/recognitions/1/hi5",
 		body,
+		testHi5Giver,
 		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
 	)
 
@@ -54,56 +63,276 @@ func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5Success() {
 
 func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5Failure() {
 	suite.dbMock.On("CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID).Return(nil)
-	suite.dbMock.On("GetUser", mock.Anything, testRecognitionHi5.GivenBy).Return(
-		db.User{
-			ID:              testRecognitionHi5.GivenBy,
-			OrgID:           1,
-			Name:            "test2",
-			Email:           "test@gmail.com",
-			DisplayName:     "test",
-			ProfileImageURL: "test.jpg",
-			RoleID:          10,
-			Hi5QuotaBalance: 0,
-		}, nil)
-
-	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment", "given_by": 1}`
-
-	recorder := makeHTTPCall(http.MethodPost,
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+
+	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/hi5",
 		"/recognitions/1/hi5",
 		body,
+		db.User{ID: 1, OrgID: 1, Hi5QuotaBalance: 0},
 		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
 	)
 
 	assert.Equal(suite.T(), `{"error":{"code":"insufficient_hi5_quota_balance","message":"Insufficient Hi5 quota balance.","fields":null}}`, recorder.Body.String())
 	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
 	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID)
-	suite.dbMock.AssertCalled(suite.T(), "GetUser", mock.Anything, testRecognitionHi5.GivenBy)
 }
 
 func (suite *RecognitionHi5HandlerTestSuite) TestRecognitionHi5DBFailure() {
 	suite.dbMock.On("CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID).Return(errors.New("Error in creating recognition hi5"))
-	suite.dbMock.On("GetUser", mock.Anything, testRecognitionHi5.GivenBy).Return(
-		db.User{
-			ID:              testRecognitionHi5.GivenBy,
-			OrgID:           1,
-			Name:            "test2",
-			Email:           "test@gmail.com",
-			DisplayName:     "test",
-			ProfileImageURL: "test.jpg",
-			RoleID:          10,
-			Hi5QuotaBalance: 5,
-		}, nil)
-
-	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment", "given_by": 1}`
-
-	recorder := makeHTTPCall(http.MethodPost,
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+
+	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/hi5",
 		"/recognitions/1/hi5",
 		body,
+		testHi5Giver,
 		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
 	)
 
 	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
 	suite.dbMock.AssertExpectations(suite.T())
 }
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5ForDraftRecognition() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(
+		db.Recognition{ID: 1, GivenBy: 2, Status: db.RecognitionStatusDraft}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+
+	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		body,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"recognition_not_published","message":"Recognition is not published yet.","fields":null}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5ForOwnRecognition() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(
+		db.Recognition{ID: 1, GivenBy: 1, Status: db.RecognitionStatusPublished}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+
+	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		body,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"hi5-rule-violation","message":"Hi5 breaks organization rules","fields":{"recognition_id":"You can't Hi5 your own recognition"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5WhenGivenByIsSet() {
+	body := `{"comment": "Test Comment", "given_by": 2}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		body,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"actor-field-not-allowed","message":"Request can't set the acting user","fields":{"given_by":"Can't be set, it is taken from the authenticated user"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5WhenAlreadyGiven() {
+	suite.dbMock.On("CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID).Return(ae.ErrHi5AlreadyGiven)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+
+	body := `{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		body,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"hi5_already_given","message":"You have already given a Hi5 for this recognition.","fields":null}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusConflict, recorder.Code)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5ForRecognitionGivenToMe() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(
+		db.Recognition{ID: 1, GivenBy: 2, GivenFor: 1, Status: db.RecognitionStatusPublished}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+
+	body := `{"comment": "Test Comment"}`
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		body,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"hi5-rule-violation","message":"Hi5 breaks organization rules","fields":{"recognition_id":"You can't Hi5 a recognition given to you"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestDeleteRecognitionHi5Success() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{ID: 2, OrgID: 1}, nil)
+	suite.dbMock.On("GetOrganization", mock.Anything, 1).Return(db.Organization{ID: 1, Hi5QuotaLastResetAt: 1594000000}, nil)
+	suite.dbMock.On("DeleteRecognitionHi5", mock.Anything, 1, 1, int64(1594000000)).Return(true, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		"",
+		testHi5Giver,
+		deleteRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":{"refunded":true}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestDeleteRecognitionHi5WhenNotGiven() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{ID: 2, OrgID: 1}, nil)
+	suite.dbMock.On("GetOrganization", mock.Anything, 1).Return(db.Organization{ID: 1, Hi5QuotaRenewalFrequency: "WEEKLY"}, nil)
+	suite.dbMock.On("DeleteRecognitionHi5", mock.Anything, 1, 1, mock.Anything).Return(false, ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		"",
+		testHi5Giver,
+		deleteRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestListRecognitionHi5sSuccess() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("ListRecognitionHi5s", mock.Anything, 1).Return([]db.RecognitionHi5{testRecognitionHi5}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/hi5s",
+		"/recognitions/1/hi5s",
+		"",
+		listRecognitionHi5sHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":[{"id":1,"recognition_id":1,"comment":"Test Comment","given_by":1,"given_at":0}]}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5ForAnotherOrganization() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		`{"comment": "Test Comment"}`,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestCreateRecognitionHi5WhenFetchingRecognitionFails() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{}, errors.New("connection lost"))
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		`{"comment": "Test Comment"}`,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionHi5", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestDeleteRecognitionHi5OfAnotherOrganization() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		"",
+		testHi5Giver,
+		deleteRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "DeleteRecognitionHi5", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestDeleteRecognitionHi5OfHiddenRecognition() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(
+		db.Recognition{ID: 1, GivenBy: 2, Status: db.RecognitionStatusHidden}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{ID: 2, OrgID: 1}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		"",
+		testHi5Giver,
+		deleteRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "DeleteRecognitionHi5", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionHi5HandlerTestSuite) TestListRecognitionHi5sOfAnotherOrganization() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodGet,
+		"/recognitions/{recognition_id:[0-9]+}/hi5s",
+		"/recognitions/1/hi5s",
+		"",
+		listRecognitionHi5sHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListRecognitionHi5s", mock.Anything, mock.Anything)
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"database/sql"
 	"encoding/json"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 	"net/http"
 	"strconv"
@@ -69,7 +71,7 @@ func createRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		err = json.NewDecoder(req.Body).Decode(&recognition)
+		actorErrFields, err := decodeWithoutActorFields(req, &recognition, "given_by")
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while decoding recognition data")
 			repsonse(rw, http.StatusBadRequest, errorResponse{
@@ -79,18 +81,27 @@ func createRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			})
 			return
 		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
 
-		// validates that recognition giving user is belongs to current specified organization or not
-		_, err = deps.Store.GetUserByOrganization(req.Context(), recognition.GivenBy, organizationID)
+		actor, err := getCurrentActor(req)
 		if err != nil {
-			logger.WithField("err", err.Error()).Error("User is not belongs to given organization")
-			repsonse(rw, http.StatusInternalServerError, errorResponse{
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		// recognitions are always given by the authenticated user, who must belong to the organization
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
 				Error: messageObject{
-					Message: "Error while validating user with given organization",
+					Message: "User doesn't belong to given organization",
 				},
 			})
 			return
 		}
+		recognition.GivenBy = actor.ID
 
 		ok, errFields := recognition.ValidateRecognition()
 		if !ok {
@@ -104,20 +115,114 @@ func createRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		_, err = deps.Store.CreateRecognition(req.Context(), recognition)
+		if !checkRecognitionCoreValue(rw, req, deps, organizationID, recognition) {
+			return
+		}
+
+		if !checkRecognitionRules(rw, req, deps, organizationID, recognition) {
+			return
+		}
+
+		text, flags, ok := filterContent(rw, req, deps, organizationID, "text", recognition.Text)
+		if !ok {
+			return
+		}
+		recognition.Text = text
+
+		createdRecognition, err := deps.Store.CreateRecognition(req.Context(), recognition)
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while creating recognition")
 			repsonse(rw, http.StatusInternalServerError, errorResponse{
 				Error: messageObject{
-					Message: "Internal serverdddd error",
+					Message: "Internal server error",
 				},
 			})
 			return
 		}
-		rw.WriteHeader(http.StatusCreated)
+
+		// the recipient may have earned a badge for recognitions received and the giver one for recognitions given
+		if createdRecognition.IsPublished() {
+			awardBadges(req.Context(), deps, organizationID, createdRecognition.GivenFor)
+			awardBadges(req.Context(), deps, organizationID, createdRecognition.GivenBy)
+		}
+
+		flagContent(req.Context(), deps, int64(createdRecognition.ID), flags)
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: createdRecognition})
 	})
 }
 
+// checkRecognitionCoreValue - new recognitions can only be given for active core values of the organization,
+// writing the error response when the core value can't be used
+func checkRecognitionCoreValue(rw http.ResponseWriter, req *http.Request, deps Dependencies, organizationID int, recognition db.Recognition) (ok bool) {
+	coreValue, err := deps.Store.GetCoreValue(req.Context(), int64(organizationID), int64(recognition.CoreValueID))
+	if err != nil && err != sql.ErrNoRows {
+		logger.WithField("err", err.Error()).Error("Error while fetching core value")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	var message string
+	if err == sql.ErrNoRows {
+		message = "Must be a core value of the organization"
+	} else if coreValue.IsArchived() {
+		message = "Core value has been archived"
+	}
+	if message != "" {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "invalid-recogintion",
+				Fields:        map[string]string{"core_value_id": message},
+				messageObject: messageObject{"Invalid recogintion data"},
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
+
+// checkRecognitionRules - runs the organization's anti-gaming rules, writing the error response when they fail
+func checkRecognitionRules(rw http.ResponseWriter, req *http.Request, deps Dependencies, organizationID int, recognition db.Recognition) (ok bool) {
+	settings, err := deps.Store.GetRecognitionRuleSettings(req.Context(), organizationID)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching recognition rule settings")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	ok, errFields, err := db.EvaluateRecognitionRules(req.Context(), deps.Store, settings, recognition)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while evaluating recognition rules")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	if !ok {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "recognition-rule-violation",
+				Fields:        errFields,
+				messageObject: messageObject{"Recognition breaks organization rules"},
+			},
+		})
+	}
+	return
+}
+
 // @Title getRecognitionHandler
 // @Description get recognition
 // @Router /organisations/{id:[0-9]+}/recognitions/{id:[0-9]+}
@@ -142,6 +247,12 @@ func getRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		// drafts and scheduled recognitions are only reachable through the drafts endpoints
+		if !recognition.IsPublished() {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
 		respBytes, err := json.Marshal(recognition)
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error marshaling recognition data")
@@ -190,3 +301,191 @@ func listRecognitionsHandler(deps Dependencies) http.HandlerFunc {
 		rw.Write(respBytes)
 	})
 }
+
+// @Title listRecognitionDraftsHandler
+// @Description list the current user's draft and scheduled recognitions
+// @Router /organisations/{id:[0-9]+}/recognitions/drafts
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listRecognitionDraftsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		recognitions, err := deps.Store.ListRecognitionDrafts(req.Context(), actor.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error fetching recognition drafts")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: recognitions})
+	})
+}
+
+// @Title updateRecognitionDraftHandler
+// @Description edit, reschedule or publish a draft or scheduled recognition
+// @Router /organisations/{id:[0-9]+}/recognitions/{id:[0-9]+}
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func updateRecognitionDraftHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.Atoi(vars["recognition_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error recognition_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		organizationID, err := strconv.Atoi(vars["orgnization_id"])
+		if err != nil {
+			logger.Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		// drafts are checked against the giver's own organization only
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		var recognition db.Recognition
+		actorErrFields, err := decodeWithoutActorFields(req, &recognition, "given_by")
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding recognition data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		recognition.ID = recognitionID
+		recognition.GivenBy = actor.ID
+
+		ok, errFields := recognition.ValidateRecognition()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-recogintion",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid recogintion data"},
+				},
+			})
+			return
+		}
+
+		if !checkRecognitionCoreValue(rw, req, deps, organizationID, recognition) {
+			return
+		}
+
+		if !checkRecognitionRules(rw, req, deps, organizationID, recognition) {
+			return
+		}
+
+		text, flags, ok := filterContent(rw, req, deps, organizationID, "text", recognition.Text)
+		if !ok {
+			return
+		}
+		recognition.Text = text
+
+		updatedRecognition, err := deps.Store.UpdateRecognitionDraft(req.Context(), recognition)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition draft not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while updating recognition draft")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		// publishing a draft has the same side effects as creating a published recognition
+		if updatedRecognition.IsPublished() {
+			awardBadges(req.Context(), deps, organizationID, updatedRecognition.GivenFor)
+			awardBadges(req.Context(), deps, organizationID, updatedRecognition.GivenBy)
+		}
+
+		flagContent(req.Context(), deps, int64(updatedRecognition.ID), flags)
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedRecognition})
+	})
+}
+
+// @Title deleteRecognitionDraftHandler
+// @Description cancel a draft or scheduled recognition
+// @Router /organisations/{id:[0-9]+}/recognitions/{id:[0-9]+}
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func deleteRecognitionDraftHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		recognitionID, err := strconv.Atoi(vars["recognition_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error recognition_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		err = deps.Store.DeleteRecognitionDraft(req.Context(), recognitionID, actor.ID)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Recognition draft not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while deleting recognition draft")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, nil)
+	})
+}
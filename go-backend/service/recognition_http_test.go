@@ -1,9 +1,12 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 	"net/http"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -24,7 +27,7 @@ func (suite *RecognitionsHandlerTestSuite) SetupTest() {
 
 func (suite *RecognitionsHandlerTestSuite) TestShowRecognitionSuccess() {
 	suite.dbMock.On("ShowRecognition", mock.Anything, mock.Anything).Return(
-		db.Recognition{ID: 1},
+		db.Recognition{ID: 1, Status: db.RecognitionStatusPublished},
 		nil,
 	)
 
@@ -59,39 +62,69 @@ func (suite *RecognitionsHandlerTestSuite) TestShowRecognitionWhenDBFailure() {
 }
 
 func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionSuccess() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 22).Return(db.DefaultRecognitionRuleSettings(22), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 22).Return(db.DefaultContentFilterSettings(22), nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(22), mock.Anything).Return(db.CoreValue{ID: 1, OrgID: 22}, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
 	suite.dbMock.On("CreateRecognition", mock.Anything, mock.Anything).Return(db.Recognition{
+		ID:          1,
 		CoreValueID: 1,
 		Text:        "test",
 		GivenFor:    1,
 		GivenBy:     2,
 		GivenAt:     1588073442241,
+		Status:      db.RecognitionStatusPublished,
 	}, nil)
-	body := `{"core_value_id":1,"text":"test","given_for":1,"given_by":2,"given_at":1588073442241}`
+	suite.dbMock.On("AwardBadges", mock.Anything, 22, 1).Return([]db.UserBadge{}, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 22, 2).Return([]db.UserBadge{}, nil)
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":1}`
 
-	recorder := makeHTTPCall(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/organisations/{orgnization_id:[0-9]+}/recognitions",
 		"/organisations/22/recognitions",
 		body,
+		db.User{ID: 2, OrgID: 22},
 		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
 	)
 
-	assert.Equal(suite.T(), `{"id":0,"core_value_id":1,"text":"test","given_for":1,"given_by":2,"given_at":1588073442241}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":1,"core_value_id":1,"text":"test","given_for":1,"given_by":2,"given_at":1588073442241,"status":"published","publish_at":null}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
+func (suite *RecognitionsHandlerTestSuite) TestCreateScheduledRecognitionWithoutPublishAt() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	body := `{"core_value_id":1,"text":"test","given_for":1,"status":"scheduled"}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		body,
+		db.User{ID: 2, OrgID: 22},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recogintion","message":"Invalid recogintion data","fields":{"publish_at":"publish_at must be present for a scheduled recognition"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything)
+}
+
 func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionWhenCoreValueIDNotPresent() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
 	body := `{
 		"text": "text",
-		"given_for": 1,
-		"given_by": 2
+		"given_for": 1
 	}`
 
-	recorder := makeHTTPCall(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/organisations/{orgnization_id:[0-9]+}/recognitions",
 		"/organisations/22/recognitions",
 		body,
+		db.User{ID: 2, OrgID: 22},
 		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
 	)
 
@@ -100,18 +133,19 @@ func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionWhenCoreValueIDN
 }
 
 func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionWhenSomeKeyHasTypo() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
 
 	body := `{
 		"text": "text",
-		"given_for": 1,
-		"given_by": 2
+		"given_for": 1
 	}`
 
-	recorder := makeHTTPCall(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/organisations/{orgnization_id:[0-9]+}/recognitions",
 		"/organisations/22/recognitions",
 		body,
+		db.User{ID: 2, OrgID: 22},
 		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
 	)
 
@@ -134,7 +168,7 @@ func (suite *RecognitionsHandlerTestSuite) TestListRecognitionSuccess() {
 	)
 
 	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
-	assert.Equal(suite.T(), `[{"id":1,"core_value_id":0,"text":"","given_for":0,"given_by":0,"given_at":0}]`, recorder.Body.String())
+	assert.Equal(suite.T(), `[{"id":1,"core_value_id":0,"text":"","given_for":0,"given_by":0,"given_at":0,"status":"","publish_at":null}]`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
@@ -171,7 +205,7 @@ func (suite *RecognitionsHandlerTestSuite) TestListRecognitionWithFiltersSuccess
 	)
 
 	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
-	assert.Equal(suite.T(), `[{"id":1,"core_value_id":1,"text":"","given_for":0,"given_by":0,"given_at":0}]`, recorder.Body.String())
+	assert.Equal(suite.T(), `[{"id":1,"core_value_id":1,"text":"","given_for":0,"given_by":0,"given_at":0,"status":"","publish_at":null}]`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
 
@@ -192,3 +226,210 @@ func (suite *RecognitionsHandlerTestSuite) TestListRecognitionWithFiltersWhenDBF
 	assert.Equal(suite.T(), http.StatusInternalServerError, recorder.Code)
 	suite.dbMock.AssertExpectations(suite.T())
 }
+
+func (suite *RecognitionsHandlerTestSuite) TestListRecognitionDraftsSuccess() {
+	publishAt := int64(1893456000)
+	suite.dbMock.On("ListRecognitionDrafts", mock.Anything, 1).Return(
+		[]db.Recognition{db.Recognition{ID: 3, GivenBy: 1, Status: db.RecognitionStatusScheduled, PublishAt: &publishAt}},
+		nil,
+	)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodGet,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions/drafts",
+		"/organisations/1/recognitions/drafts",
+		"",
+		listRecognitionDraftsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":3,"core_value_id":0,"text":"","given_for":0,"given_by":1,"given_at":0,"status":"scheduled","publish_at":1893456000}]}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestUpdateRecognitionDraftSuccess() {
+	recognition := db.Recognition{ID: 3, CoreValueID: 1, Text: "thanks for the help", GivenFor: 2, GivenBy: 1, Status: db.RecognitionStatusPublished}
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), mock.Anything).Return(db.CoreValue{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	suite.dbMock.On("UpdateRecognitionDraft", mock.Anything, recognition).Return(recognition, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, 2).Return([]db.UserBadge{}, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, 1).Return([]db.UserBadge{}, nil)
+
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":2,"status":"published"}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPut,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}",
+		"/organisations/1/recognitions/3",
+		body,
+		updateRecognitionDraftHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestUpdateRecognitionDraftWhenAlreadyPublished() {
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), mock.Anything).Return(db.CoreValue{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	suite.dbMock.On("UpdateRecognitionDraft", mock.Anything, mock.Anything).Return(db.Recognition{}, ae.ErrRecordNotFound)
+
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":2,"status":"draft"}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPut,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}",
+		"/organisations/1/recognitions/3",
+		body,
+		updateRecognitionDraftHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestUpdateRecognitionDraftOfAnotherOrganization() {
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":2,"status":"published"}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPut,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}",
+		"/organisations/22/recognitions/3",
+		body,
+		updateRecognitionDraftHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GetRecognitionRuleSettings", mock.Anything, mock.Anything)
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateRecognitionDraft", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestDeleteRecognitionDraftSuccess() {
+	suite.dbMock.On("DeleteRecognitionDraft", mock.Anything, 3, 1).Return(nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodDelete,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}",
+		"/organisations/1/recognitions/3",
+		"",
+		deleteRecognitionDraftHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionForSelf() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 22).Return(db.DefaultRecognitionRuleSettings(22), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 22).Return(db.DefaultContentFilterSettings(22), nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(22), mock.Anything).Return(db.CoreValue{ID: 1, OrgID: 22}, nil)
+	suite.dbMock.On("CountRecognitionsGiven", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(0, nil)
+	body := `{"core_value_id":1,"text":"ok","given_for":2}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		body,
+		db.User{ID: 2, OrgID: 22},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"recognition-rule-violation","message":"Recognition breaks organization rules","fields":{"given_for":"You can't recognize yourself","text":"text must be at least 10 characters long"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything)
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionWhenGivenByIsSet() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":1,"given_by":3}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		body,
+		db.User{ID: 2, OrgID: 22},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"actor-field-not-allowed","message":"Request can't set the acting user","fields":{"given_by":"Can't be set, it is taken from the authenticated user"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionForAnotherOrganization() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":1}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		body,
+		db.User{ID: 2, OrgID: 7},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestUpdateRecognitionDraftWhenGivenByIsSet() {
+	body := `{"core_value_id":1,"text":"thanks for the help","given_for":2,"given_by":5,"status":"published"}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPut,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}",
+		"/organisations/1/recognitions/3",
+		body,
+		updateRecognitionDraftHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateRecognitionDraft", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionForArchivedCoreValue() {
+	archivedAt := time.Now()
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(22), int64(4)).Return(db.CoreValue{ID: 4, OrgID: 22, ArchivedAt: &archivedAt}, nil)
+	body := `{"core_value_id":4,"text":"thanks for the help","given_for":1}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		body,
+		db.User{ID: 2, OrgID: 22},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recogintion","message":"Invalid recogintion data","fields":{"core_value_id":"Core value has been archived"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionsHandlerTestSuite) TestCreateRecognitionForCoreValueOfAnotherOrganization() {
+	suite.dbMock.On("GetOrganization", mock.Anything, 22).Return(db.Organization{ID: 22}, nil)
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(22), int64(4)).Return(db.CoreValue{}, sql.ErrNoRows)
+	body := `{"core_value_id":4,"text":"thanks for the help","given_for":1}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/organisations/{orgnization_id:[0-9]+}/recognitions",
+		"/organisations/22/recognitions",
+		body,
+		db.User{ID: 2, OrgID: 22},
+		createRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recogintion","message":"Invalid recogintion data","fields":{"core_value_id":"Must be a core value of the organization"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognition", mock.Anything, mock.Anything)
+}
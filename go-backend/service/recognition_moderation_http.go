@@ -1,31 +1,27 @@
 package service
 
 import (
-	"encoding/json"
+	"database/sql"
 	"net/http"
 	"strconv"
 
-	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 
-	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	logger "github.com/sirupsen/logrus"
 )
 
+// @Title createRecognitionModerationHandler
+// @Description hide or restore a recognition of the organization, resolving its reports, admins only
+// @Router /recognitions/:recognition_id/review [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
 func createRecognitionModerationHandler(deps Dependencies) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		parsedToken, ok := req.Context().Value("user").(*jwt.Token)
-		if !ok {
-			logger.Error("Error parsing JSON for token response")
-			rw.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		claims := parsedToken.Claims.(jwt.MapClaims)
-		userID, err := strconv.Atoi(claims["sub"].(string))
+		actor, err := getCurrentActor(req)
 		if err != nil {
-			logger.Error(ae.ErrJSONParseFail, "Error parsing JSON for token response", err)
-			ae.JSONError(rw, http.StatusInternalServerError, err)
+			currentActorErrorResponse(rw, err)
 			return
 		}
 
@@ -37,8 +33,17 @@ func createRecognitionModerationHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		if !requireOrgAdmin(rw, req, deps, actor, actor.OrgID) {
+			return
+		}
+
+		recognition, ok := recognitionOfOrganization(rw, req, deps, int(recognitionID), actor.OrgID)
+		if !ok {
+			return
+		}
+
 		var recognitionModeration db.RecognitionModeration
-		err = json.NewDecoder(req.Body).Decode(&recognitionModeration)
+		actorErrFields, err := decodeWithoutActorFields(req, &recognitionModeration, "moderated_by")
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while decoding request data")
 			repsonse(rw, http.StatusBadRequest, errorResponse{
@@ -48,7 +53,11 @@ func createRecognitionModerationHandler(deps Dependencies) http.HandlerFunc {
 			})
 			return
 		}
-		recognitionModeration.ModeratedBy = int64(userID)
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		recognitionModeration.ModeratedBy = int64(actor.ID)
 
 		ok, errFields := recognitionModeration.Validate()
 		if !ok {
@@ -62,7 +71,22 @@ func createRecognitionModerationHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
-		resp, err := deps.Store.CreateRecognitionModeration(req.Context(), recognitionID, recognitionModeration)
+		var refundSince int64
+		if recognitionModeration.RefundHi5s {
+			organization, err := deps.Store.GetOrganization(req.Context(), actor.OrgID)
+			if err != nil {
+				logger.WithField("err", err.Error()).Error("Error while fetching organization")
+				repsonse(rw, http.StatusInternalServerError, errorResponse{
+					Error: messageObject{
+						Message: "Internal server error",
+					},
+				})
+				return
+			}
+			refundSince = organization.Hi5QuotaLastResetAt
+		}
+
+		resp, err := deps.Store.CreateRecognitionModeration(req.Context(), recognitionID, recognitionModeration, refundSince)
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while creating recognition moderation")
 			repsonse(rw, http.StatusInternalServerError, errorResponse{
@@ -73,6 +97,57 @@ func createRecognitionModerationHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		// a restored recognition counts towards the badges of both parties again
+		if recognition.IsHidden() && !*resp.IsInappropriate {
+			awardBadges(req.Context(), deps, actor.OrgID, recognition.GivenFor)
+			awardBadges(req.Context(), deps, actor.OrgID, recognition.GivenBy)
+		}
+
 		repsonse(rw, http.StatusCreated, successResponse{Data: resp})
 	})
 }
+
+// recognitionOfOrganization - loads a recognition given by a user of the organization, writing
+// a 404 and returning false when there is no such recognition
+func recognitionOfOrganization(rw http.ResponseWriter, req *http.Request, deps Dependencies, recognitionID, organizationID int) (recognition db.Recognition, ok bool) {
+	notFound := func() {
+		repsonse(rw, http.StatusNotFound, errorResponse{
+			Error: messageObject{
+				Message: "Recognition not found",
+			},
+		})
+	}
+
+	recognition, err := deps.Store.ShowRecognition(req.Context(), recognitionID)
+	if err == sql.ErrNoRows {
+		notFound()
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching recognition")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	_, err = deps.Store.GetUserByOrganization(req.Context(), recognition.GivenBy, organizationID)
+	if err == sql.ErrNoRows {
+		notFound()
+		return
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching giver of recognition")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	ok = true
+	return
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,6 +13,8 @@ import (
 	"joshsoftware/peerly/db"
 )
 
+var testModeratedRecognition = db.Recognition{ID: 1, CoreValueID: 1, Text: "Great demo", GivenFor: 4, GivenBy: 3, Status: db.RecognitionStatusPublished}
+
 type RecognitionModerationHandlerTestSuite struct {
 	suite.Suite
 
@@ -20,12 +23,15 @@ type RecognitionModerationHandlerTestSuite struct {
 
 func (suite *RecognitionModerationHandlerTestSuite) SetupTest() {
 	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 3, 1).Return(db.User{ID: 3, OrgID: 1}, nil)
 }
 
 func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationSuccess() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
 	now := time.Now().Unix()
 	isInappropriate := false
-	suite.dbMock.On("CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything).Return(db.RecognitionModeration{
+	suite.dbMock.On("CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything, int64(0)).Return(db.RecognitionModeration{
 		ID:               1,
 		RecognitionID:    int64(1),
 		IsInappropriate:  &isInappropriate,
@@ -39,14 +45,15 @@ func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerat
 		"comment": "Comment Test"
 	}`
 
-	recorder := makeHTTPCallWithJWTMiddleware(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/review",
 		"/recognitions/1/review",
 		body,
+		testAdmin,
 		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
 	)
-	expectedBody := fmt.Sprintf(`{"data":{"id":1,"recognition_id":1,"is_inappropriate":false,"comment":"Comment Test","moderated_by":1,"moderated_at":%v}}`, now)
+	expectedBody := fmt.Sprintf(`{"data":{"id":1,"recognition_id":1,"is_inappropriate":false,"comment":"Comment Test","moderated_by":1,"moderated_at":%v,"refund_hi5s":false}}`, now)
 
 	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
 	assert.Equal(suite.T(), expectedBody, recorder.Body.String())
@@ -54,16 +61,18 @@ func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerat
 }
 
 func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationWhenInvalidJSONFormat() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
 	body := `{
 		"is_inappropriate": false
 		"comment": "Comment Test"
 	}`
 
-	recorder := makeHTTPCallWithJWTMiddleware(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/review",
 		"/recognitions/1/review",
 		body,
+		testAdmin,
 		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
 	)
 
@@ -73,15 +82,17 @@ func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerat
 }
 
 func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationWhenEmptyIsInappropriate() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
 	body := `{
 		"comment": "Comment Test"
 	}`
 
-	recorder := makeHTTPCallWithJWTMiddleware(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/review",
 		"/recognitions/1/review",
 		body,
+		testAdmin,
 		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
 	)
 
@@ -91,18 +102,20 @@ func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerat
 }
 
 func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationWhenDBFailure() {
-	suite.dbMock.On("CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything).Return(db.RecognitionModeration{}, errors.New("error creating reported recognition"))
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
+	suite.dbMock.On("CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything, int64(0)).Return(db.RecognitionModeration{}, errors.New("error creating reported recognition"))
 
 	body := `{
 		"is_inappropriate": false,
 		"reason": "Comment Test"
 	}`
 
-	recorder := makeHTTPCallWithJWTMiddleware(
+	recorder := makeHTTPCallAsActor(
 		http.MethodPost,
 		"/recognitions/{recognition_id:[0-9]+}/review",
 		"/recognitions/1/review",
 		body,
+		testAdmin,
 		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
 	)
 
@@ -110,3 +123,125 @@ func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerat
 	assert.Equal(suite.T(), `{"error":{"message":"Internal server error"}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
+
+func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationWhenModeratedByIsSet() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
+	body := `{
+		"is_inappropriate": true,
+		"comment": "Comment Test",
+		"moderated_by": 2
+	}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/review",
+		"/recognitions/1/review",
+		body,
+		testAdmin,
+		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"actor-field-not-allowed","message":"Request can't set the acting user","fields":{"moderated_by":"Can't be set, it is taken from the authenticated user"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/review",
+		"/recognitions/1/review",
+		`{"is_inappropriate": true}`,
+		db.User{ID: 5, OrgID: 1, RoleID: 3},
+		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Only admins can perform this action"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "ShowRecognition", mock.Anything)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationOfAnotherOrganization() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 2, GivenBy: 6, GivenFor: 7, Status: db.RecognitionStatusPublished}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 6, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/review",
+		"/recognitions/2/review",
+		`{"is_inappropriate": true}`,
+		testAdmin,
+		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Recognition not found"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateRecognitionModeration", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationWithHi5Refunds() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
+	isInappropriate := true
+	suite.dbMock.On("GetOrganization", mock.Anything, 1).Return(db.Organization{ID: 1, Hi5QuotaLastResetAt: 1000}, nil)
+	suite.dbMock.On("CreateRecognitionModeration", mock.Anything, int64(1), db.RecognitionModeration{
+		IsInappropriate: &isInappropriate,
+		ModeratedBy:     1,
+		RefundHi5s:      true,
+	}, int64(1000)).Return(db.RecognitionModeration{ID: 1, RecognitionID: 1, IsInappropriate: &isInappropriate, ModeratedBy: 1, RefundHi5s: true}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/review",
+		"/recognitions/1/review",
+		`{"is_inappropriate": true, "refund_hi5s": true}`,
+		testAdmin,
+		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":1,"recognition_id":1,"is_inappropriate":true,"comment":"","moderated_by":1,"moderated_at":0,"refund_hi5s":true}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *RecognitionModerationHandlerTestSuite) TestCreateRecognitionModerationRefundsOnlyWhenHiding() {
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testModeratedRecognition, nil)
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/review",
+		"/recognitions/1/review",
+		`{"is_inappropriate": false, "refund_hi5s": true}`,
+		testAdmin,
+		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recognition-moderation","message":"Invalid recognition moderation data","fields":{"refund_hi5s":"Can only be set when hiding the recognition"}}}`, recorder.Body.String())
+}
+
+func (suite *RecognitionModerationHandlerTestSuite) TestRestoringRecognitionAwardsBadges() {
+	isInappropriate := false
+	hidden := testModeratedRecognition
+	hidden.ID = 3
+	hidden.Status = db.RecognitionStatusHidden
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(hidden, nil)
+	suite.dbMock.On("CreateRecognitionModeration", mock.Anything, int64(3), mock.Anything, int64(0)).
+		Return(db.RecognitionModeration{ID: 2, RecognitionID: 3, IsInappropriate: &isInappropriate, ModeratedBy: 1}, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, 4).Return([]db.UserBadge{}, nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, 3).Return([]db.UserBadge{}, nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/review",
+		"/recognitions/3/review",
+		`{"is_inappropriate": false}`,
+		testAdmin,
+		createRecognitionModerationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "AwardBadges", mock.Anything, 1, 4)
+	suite.dbMock.AssertCalled(suite.T(), "AwardBadges", mock.Anything, 1, 3)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title getRecognitionRuleSettingsHandler
+// @Description get the anti-gaming rule settings of the current user's organization
+// @Router /organizations/:id/recognition_rules [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func getRecognitionRuleSettingsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		settings, err := deps.Store.GetRecognitionRuleSettings(req.Context(), organizationID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching recognition rule settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: settings})
+	})
+}
+
+// @Title updateRecognitionRuleSettingsHandler
+// @Description update the anti-gaming rule settings of an organization, admins only
+// @Router /organizations/:id/recognition_rules [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func updateRecognitionRuleSettingsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var settings db.RecognitionRuleSettings
+		err = json.NewDecoder(req.Body).Decode(&settings)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		settings.OrgID = organizationID
+
+		ok, errFields := settings.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-recognition-rules",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid recognition rule settings"},
+				},
+			})
+			return
+		}
+
+		updatedSettings, err := deps.Store.UpdateRecognitionRuleSettings(req.Context(), settings)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while updating recognition rule settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedSettings})
+	})
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"net/http"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionRuleHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) TestGetRecognitionRuleSettingsSuccess() {
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodGet,
+		"/organizations/{id:[0-9]+}/recognition_rules",
+		"/organizations/1/recognition_rules",
+		"",
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		getRecognitionRuleSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"org_id":1,"allow_self_recognition":false,"min_text_length":10,"recipient_daily_cap":3,"reciprocal_limit":5,"reciprocal_window_days":7}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "GetRecognitionRuleSettings", mock.Anything, 1)
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) TestUpdateRecognitionRuleSettingsSuccess() {
+	settings := db.RecognitionRuleSettings{OrgID: 1, MinTextLength: 20, RecipientDailyCap: 1, ReciprocalLimit: 2, ReciprocalWindowDays: 14}
+	suite.dbMock.On("UpdateRecognitionRuleSettings", mock.Anything, settings).Return(settings, nil)
+
+	body := `{"min_text_length":20,"recipient_daily_cap":1,"reciprocal_limit":2,"reciprocal_window_days":14}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organizations/{id:[0-9]+}/recognition_rules",
+		"/organizations/1/recognition_rules",
+		body,
+		testAdmin,
+		updateRecognitionRuleSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "UpdateRecognitionRuleSettings", mock.Anything, settings)
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) TestGetRecognitionRuleSettingsOfAnotherOrganization() {
+	recorder := makeHTTPCallAsActor(
+		http.MethodGet,
+		"/organizations/{id:[0-9]+}/recognition_rules",
+		"/organizations/2/recognition_rules",
+		"",
+		testAdmin,
+		getRecognitionRuleSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "GetRecognitionRuleSettings", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) TestUpdateRecognitionRuleSettingsWhenNotAdmin() {
+	body := `{"allow_self_recognition":true}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organizations/{id:[0-9]+}/recognition_rules",
+		"/organizations/1/recognition_rules",
+		body,
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		updateRecognitionRuleSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateRecognitionRuleSettings", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) TestUpdateRecognitionRuleSettingsOfAnotherOrganization() {
+	body := `{"allow_self_recognition":true}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organizations/{id:[0-9]+}/recognition_rules",
+		"/organizations/2/recognition_rules",
+		body,
+		testAdmin,
+		updateRecognitionRuleSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateRecognitionRuleSettings", mock.Anything, mock.Anything)
+}
+
+func (suite *RecognitionRuleHandlerTestSuite) TestUpdateRecognitionRuleSettingsWhenInvalid() {
+	body := `{"min_text_length":-1}`
+
+	recorder := makeHTTPCallAsActor(
+		http.MethodPut,
+		"/organizations/{id:[0-9]+}/recognition_rules",
+		"/organizations/1/recognition_rules",
+		body,
+		testAdmin,
+		updateRecognitionRuleSettingsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-recognition-rules","message":"Invalid recognition rule settings","fields":{"min_text_length":"Can't be negative"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "UpdateRecognitionRuleSettings", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title listReportReasonsHandler
+// @Description reasons users of an organization can give when reporting a recognition, archived ones too with ?include_archived=true
+// @Router /organizations/:organization_id/report_reasons [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listReportReasonsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		includeArchived := req.URL.Query().Get("include_archived") == "true"
+		reasons, err := deps.Store.ListReportReasons(req.Context(), organizationID, includeArchived)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching report reasons")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: reasons})
+	})
+}
+
+// @Title createReportReasonHandler
+// @Description add a report reason to an organization, bringing back an archived reason of the same name, admins only
+// @Router /organizations/:organization_id/report_reasons [post]
+// @Accept  json
+// @Success 201 {object}
+// @Failure 400 {object}
+func createReportReasonHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var reason db.ReportReason
+		err = json.NewDecoder(req.Body).Decode(&reason)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		reason.ID = 0
+		reason.OrgID = organizationID
+		reason.ArchivedAt = nil
+
+		ok, errFields := reason.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-report-reason",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid report reason"},
+				},
+			})
+			return
+		}
+
+		createdReason, err := deps.Store.CreateReportReason(req.Context(), reason)
+		if err == ae.ErrReportReasonExists {
+			repsonse(rw, http.StatusConflict, errorResponse{
+				Error: messageObject{
+					Message: "Report reason already exists",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while creating report reason")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusCreated, successResponse{Data: createdReason})
+	})
+}
+
+// @Title updateReportReasonHandler
+// @Description update the label, description, severity and quarantine trigger of a report reason, admins only
+// @Router /organizations/:organization_id/report_reasons/:id [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func updateReportReasonHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		reasonID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		var reason db.ReportReason
+		err = json.NewDecoder(req.Body).Decode(&reason)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		// reports refer to the reason by name, so it stays as it was created
+		reason.ID = reasonID
+		reason.OrgID = organizationID
+		reason.Name = ""
+		reason.ArchivedAt = nil
+
+		ok, errFields := reason.Validate()
+		if !ok {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-report-reason",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid report reason"},
+				},
+			})
+			return
+		}
+
+		updatedReason, err := deps.Store.UpdateReportReason(req.Context(), reason)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Report reason not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while updating report reason")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: updatedReason})
+	})
+}
+
+// @Title archiveReportReasonHandler
+// @Description archive a report reason so it can't be given for new reports, admins only
+// @Router /organizations/:organization_id/report_reasons/:id [delete]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func archiveReportReasonHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		reasonID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if !requireOrgAdmin(rw, req, deps, actor, organizationID) {
+			return
+		}
+
+		err = deps.Store.ArchiveReportReason(req.Context(), organizationID, reasonID)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Report reason not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while archiving report reason")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, nil)
+	})
+}
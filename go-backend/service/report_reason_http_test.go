@@ -0,0 +1,162 @@
+package service
+
+import (
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportReasonHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *ReportReasonHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+	suite.dbMock.On("GetRoleByID", mock.Anything, 3).Return(db.Role{ID: 3, Name: "Employee"}, nil)
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestListReportReasonsAsEmployee() {
+	suite.dbMock.On("ListReportReasons", mock.Anything, 1, true).Return([]db.ReportReason{
+		{ID: 1, OrgID: 1, Name: "fraud", Label: "Fraud", Description: "Made up", Severity: "high", TriggersQuarantine: true},
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/report_reasons",
+		"/organizations/1/report_reasons?include_archived=true",
+		"",
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		listReportReasonsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":1,"org_id":1,"name":"fraud","label":"Fraud","description":"Made up","severity":"high","triggers_quarantine":true}]}`, recorder.Body.String())
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestListReportReasonsOfAnotherOrganization() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/report_reasons",
+		"/organizations/2/report_reasons",
+		"",
+		testAdmin,
+		listReportReasonsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListReportReasons", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestCreateReportReasonSuccess() {
+	reason := db.ReportReason{OrgID: 1, Name: "spam", Label: "Spam", Severity: "low"}
+	created := reason
+	created.ID = 4
+	suite.dbMock.On("CreateReportReason", mock.Anything, reason).Return(created, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/report_reasons",
+		"/organizations/1/report_reasons",
+		`{"id":7,"org_id":2,"name":"spam","label":"Spam","severity":"low"}`,
+		testAdmin,
+		createReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":4,"org_id":1,"name":"spam","label":"Spam","description":"","severity":"low","triggers_quarantine":false}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "CreateReportReason", mock.Anything, reason)
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestCreateReportReasonWhenNotAdmin() {
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/report_reasons",
+		"/organizations/1/report_reasons",
+		`{"name":"spam","label":"Spam","severity":"low"}`,
+		db.User{ID: 4, OrgID: 1, RoleID: 3},
+		createReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Only admins can perform this action"}}`, recorder.Body.String())
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestCreateReportReasonWithInvalidData() {
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/report_reasons",
+		"/organizations/1/report_reasons",
+		`{"name":"","label":"Spam","severity":"urgent"}`,
+		testAdmin,
+		createReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-report-reason","message":"Invalid report reason","fields":{"name":"Can't be blank","severity":"Must be one of low, medium or high"}}}`, recorder.Body.String())
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestCreateReportReasonWhenExists() {
+	suite.dbMock.On("CreateReportReason", mock.Anything, mock.Anything).Return(db.ReportReason{}, ae.ErrReportReasonExists)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/report_reasons",
+		"/organizations/1/report_reasons",
+		`{"name":"fraud","label":"Fraud","severity":"high"}`,
+		testAdmin,
+		createReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusConflict, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Report reason already exists"}}`, recorder.Body.String())
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestUpdateReportReasonKeepsName() {
+	reason := db.ReportReason{ID: 4, OrgID: 1, Label: "Spam or ads", Severity: "medium", TriggersQuarantine: true}
+	suite.dbMock.On("UpdateReportReason", mock.Anything, reason).
+		Return(db.ReportReason{ID: 4, OrgID: 1, Name: "spam", Label: "Spam or ads", Severity: "medium", TriggersQuarantine: true}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/report_reasons/{id:[0-9]+}",
+		"/organizations/1/report_reasons/4",
+		`{"name":"ads","label":"Spam or ads","severity":"medium","triggers_quarantine":true}`,
+		testAdmin,
+		updateReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":4,"org_id":1,"name":"spam","label":"Spam or ads","description":"","severity":"medium","triggers_quarantine":true}}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "UpdateReportReason", mock.Anything, reason)
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestUpdateReportReasonNotFound() {
+	suite.dbMock.On("UpdateReportReason", mock.Anything, mock.Anything).Return(db.ReportReason{}, ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/report_reasons/{id:[0-9]+}",
+		"/organizations/1/report_reasons/9",
+		`{"label":"Spam","severity":"low"}`,
+		testAdmin,
+		updateReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Report reason not found"}}`, recorder.Body.String())
+}
+
+func (suite *ReportReasonHandlerTestSuite) TestArchiveReportReasonSuccess() {
+	suite.dbMock.On("ArchiveReportReason", mock.Anything, 1, int64(4)).Return(nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/organizations/{organization_id:[0-9]+}/report_reasons/{id:[0-9]+}",
+		"/organizations/1/report_reasons/4",
+		"",
+		testAdmin,
+		archiveReportReasonHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertCalled(suite.T(), "ArchiveReportReason", mock.Anything, 1, int64(4))
+}
@@ -1,31 +1,24 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 
-	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	logger "github.com/sirupsen/logrus"
 )
 
 func createReportedRecognitionHandler(deps Dependencies) http.HandlerFunc {
 	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
-		parsedToken, ok := req.Context().Value("user").(*jwt.Token)
-		if !ok {
-			logger.Error("Error parsing JSON for token response")
-			rw.WriteHeader(http.StatusInternalServerError)
-			return
-		}
-		claims := parsedToken.Claims.(jwt.MapClaims)
-		userID, err := strconv.Atoi(claims["sub"].(string))
+		actor, err := getCurrentActor(req)
 		if err != nil {
-			logger.Error(ae.ErrJSONParseFail, "Error parsing JSON for token response", err)
-			ae.JSONError(rw, http.StatusInternalServerError, err)
+			currentActorErrorResponse(rw, err)
 			return
 		}
 
@@ -37,8 +30,13 @@ func createReportedRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		recognition, ok := recognitionOfOrganization(rw, req, deps, int(recognitionID), actor.OrgID)
+		if !ok {
+			return
+		}
+
 		var reportedRecognition db.ReportedRecognition
-		err = json.NewDecoder(req.Body).Decode(&reportedRecognition)
+		actorErrFields, err := decodeWithoutActorFields(req, &reportedRecognition, "reported_by")
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while decoding request data")
 			repsonse(rw, http.StatusBadRequest, errorResponse{
@@ -48,9 +46,24 @@ func createReportedRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			})
 			return
 		}
-		reportedRecognition.ReportedBy = int64(userID)
+		if len(actorErrFields) > 0 {
+			actorFieldsResponse(rw, actorErrFields)
+			return
+		}
+		reportedRecognition.ReportedBy = int64(actor.ID)
 
-		ok, errFields := reportedRecognition.Validate()
+		reasons, err := deps.Store.ListReportReasons(req.Context(), actor.OrgID, false)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching report reasons")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		ok, errFields := reportedRecognition.Validate(reasons)
 		if !ok {
 			repsonse(rw, http.StatusBadRequest, errorResponse{
 				Error: errorObject{
@@ -62,7 +75,30 @@ func createReportedRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		settings, err := deps.Store.GetModerationSettings(req.Context(), actor.OrgID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching moderation settings")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		if !checkReportRules(rw, req, deps, settings, recognition, reportedRecognition) {
+			return
+		}
+
 		resp, err := deps.Store.CreateReportedRecognition(req.Context(), recognitionID, reportedRecognition)
+		if err == ae.ErrAlreadyReported {
+			repsonse(rw, http.StatusConflict, errorResponse{
+				Error: messageObject{
+					Message: "You have already reported this recognition",
+				},
+			})
+			return
+		}
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while creating reported recognition")
 			repsonse(rw, http.StatusInternalServerError, errorResponse{
@@ -73,6 +109,81 @@ func createReportedRecognitionHandler(deps Dependencies) http.HandlerFunc {
 			return
 		}
 
+		quarantineRecognition(req.Context(), deps, actor.OrgID, recognitionID, settings)
+
 		repsonse(rw, http.StatusCreated, successResponse{Data: resp})
 	})
 }
+
+// checkReportRules - responds and returns false when the report breaks the reporting rules or the
+// reporter has hit the organization's report rate limit
+func checkReportRules(rw http.ResponseWriter, req *http.Request, deps Dependencies, settings db.ModerationSettings, recognition db.Recognition, reportedRecognition db.ReportedRecognition) (ok bool) {
+	reviewedTypes, err := deps.Store.ListReviewedReportTypes(req.Context(), int64(recognition.ID))
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching reviewed report types")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	valid, errFields := db.EvaluateReportRules(recognition, reportedRecognition, reviewedTypes)
+	if !valid {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "report-rule-violation",
+				Fields:        errFields,
+				messageObject: messageObject{"Report breaks reporting rules"},
+			},
+		})
+		return
+	}
+
+	if settings.ReportRateLimit > 0 {
+		since := time.Now().Unix() - int64(settings.ReportRateWindowHours)*60*60
+		count, err := deps.Store.CountReportsBy(req.Context(), reportedRecognition.ReportedBy, since)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while counting reports")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		if count >= settings.ReportRateLimit {
+			repsonse(rw, http.StatusTooManyRequests, errorResponse{
+				Error: messageObject{
+					Message: fmt.Sprintf("You can report at most %d recognitions every %d hours", settings.ReportRateLimit, settings.ReportRateWindowHours),
+				},
+			})
+			return
+		}
+	}
+
+	ok = true
+	return
+}
+
+// quarantineRecognition - hides the recognition pending review once enough users reported it. Quarantine
+// is best effort: a failure is only logged since the next report evaluates the recognition again.
+func quarantineRecognition(ctx context.Context, deps Dependencies, organizationID int, recognitionID int64, settings db.ModerationSettings) {
+	quarantine, err := deps.Store.QuarantineRecognition(ctx, organizationID, recognitionID, settings)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":            err.Error(),
+			"recognition_id": recognitionID,
+		}).Error("Error while quarantining recognition")
+		return
+	}
+
+	if quarantine != nil {
+		logger.WithFields(logger.Fields{
+			"recognition_id": recognitionID,
+			"reporter_count": quarantine.ReporterCount,
+		}).Info("Recognition quarantined")
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	ae "joshsoftware/peerly/apperrors"
 	"joshsoftware/peerly/db"
 )
 
@@ -20,9 +22,20 @@ type ReportedRecognitionHandlerTestSuite struct {
 
 func (suite *ReportedRecognitionHandlerTestSuite) SetupTest() {
 	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 2, GivenFor: 3, Status: db.RecognitionStatusPublished}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{ID: 2, OrgID: 1}, nil)
+	suite.dbMock.On("ListReportReasons", mock.Anything, 1, false).Return(db.DefaultReportReasons(1), nil)
+}
+
+// mockReportRules - settings and history of a reporter who may file the report
+func (suite *ReportedRecognitionHandlerTestSuite) mockReportRules() {
+	suite.dbMock.On("GetModerationSettings", mock.Anything, 1).Return(db.DefaultModerationSettings(1), nil)
+	suite.dbMock.On("ListReviewedReportTypes", mock.Anything, int64(1)).Return([]string{}, nil)
+	suite.dbMock.On("CountReportsBy", mock.Anything, int64(1), mock.Anything).Return(0, nil)
 }
 
 func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionSuccess() {
+	suite.mockReportRules()
 	now := time.Now().Unix()
 	suite.dbMock.On("CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything).Return(db.ReportedRecognition{
 		ID:                 1,
@@ -31,7 +44,9 @@ func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionS
 		ReasonForReporting: "Reason Test",
 		ReportedBy:         int64(1),
 		ReportedAt:         now,
+		Status:             "open",
 	}, nil)
+	suite.dbMock.On("QuarantineRecognition", mock.Anything, 1, int64(1), db.DefaultModerationSettings(1)).Return((*db.RecognitionQuarantine)(nil), nil)
 
 	body := `{
 		"mark_as": "fraud",
@@ -45,7 +60,7 @@ func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionS
 		body,
 		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
 	)
-	expectedBody := fmt.Sprintf(`{"data":{"id":1,"recognition_id":1,"mark_as":"fraud","reason":"Reason Test","reported_by":1,"reported_at":%v}}`, now)
+	expectedBody := fmt.Sprintf(`{"data":{"id":1,"recognition_id":1,"mark_as":"fraud","reason":"Reason Test","reported_by":1,"reported_at":%v,"status":"open"}}`, now)
 
 	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
 	assert.Equal(suite.T(), expectedBody, recorder.Body.String())
@@ -68,7 +83,7 @@ func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionW
 
 	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
 	assert.Equal(suite.T(), `{"error":{"message":"Invalid json request body"}}`, recorder.Body.String())
-	suite.dbMock.AssertExpectations(suite.T())
+	suite.dbMock.AssertNotCalled(suite.T(), "ListReportReasons", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionWhenEmptyReportedRecognitionType() {
@@ -129,6 +144,7 @@ func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionW
 }
 
 func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionWhenDBFailure() {
+	suite.mockReportRules()
 	suite.dbMock.On("CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything).Return(db.ReportedRecognition{}, errors.New("error creating reported recognition"))
 
 	body := `{
@@ -148,3 +164,189 @@ func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionW
 	assert.Equal(suite.T(), `{"error":{"message":"Internal server error"}}`, recorder.Body.String())
 	suite.dbMock.AssertExpectations(suite.T())
 }
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionWhenReportedByIsSet() {
+	body := `{
+		"mark_as": "fraud",
+		"reason": "Reason Test",
+		"reported_by": 2
+	}`
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		body,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"actor-field-not-allowed","message":"Request can't set the acting user","fields":{"reported_by":"Can't be set, it is taken from the authenticated user"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionQuarantinesRecognition() {
+	suite.mockReportRules()
+	suite.dbMock.On("CreateReportedRecognition", mock.Anything, int64(1), mock.Anything).Return(db.ReportedRecognition{
+		ID:                 3,
+		RecognitionID:      int64(1),
+		TypeOfReporting:    "fraud",
+		ReasonForReporting: "Reason Test",
+		ReportedBy:         int64(1),
+		ReportedAt:         100,
+		Status:             "open",
+	}, nil)
+	suite.dbMock.On("QuarantineRecognition", mock.Anything, 1, int64(1), db.DefaultModerationSettings(1)).
+		Return(&db.RecognitionQuarantine{ID: 1, RecognitionID: 1, ReporterCount: 3, QuarantinedAt: 100}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "fraud", "reason": "Reason Test"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":3,"recognition_id":1,"mark_as":"fraud","reason":"Reason Test","reported_by":1,"reported_at":100,"status":"open"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionWhenQuarantineFails() {
+	suite.mockReportRules()
+	suite.dbMock.On("CreateReportedRecognition", mock.Anything, int64(1), mock.Anything).Return(db.ReportedRecognition{
+		ID:                 3,
+		RecognitionID:      int64(1),
+		TypeOfReporting:    "fraud",
+		ReasonForReporting: "Reason Test",
+		ReportedBy:         int64(1),
+		ReportedAt:         100,
+		Status:             "open",
+	}, nil)
+	suite.dbMock.On("QuarantineRecognition", mock.Anything, 1, int64(1), db.DefaultModerationSettings(1)).
+		Return((*db.RecognitionQuarantine)(nil), errors.New("error quarantining recognition"))
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "fraud", "reason": "Reason Test"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionOfAnotherOrganization() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 9, GivenFor: 3}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 9, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "fraud", "reason": "Reason Test"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Recognition not found"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionOfOwnRecognition() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 1, GivenFor: 3}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 1, 1).Return(db.User{ID: 1, OrgID: 1}, nil)
+	suite.dbMock.On("ListReportReasons", mock.Anything, 1, false).Return(db.DefaultReportReasons(1), nil)
+	suite.mockReportRules()
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "fraud", "reason": "Reason Test"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"report-rule-violation","message":"Report breaks reporting rules","fields":{"recognition_id":"You can't report your own recognition"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionForReviewedReason() {
+	suite.dbMock.On("GetModerationSettings", mock.Anything, 1).Return(db.DefaultModerationSettings(1), nil)
+	suite.dbMock.On("ListReviewedReportTypes", mock.Anything, int64(1)).Return([]string{"fraud"}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "fraud", "reason": "Still fraud"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"report-rule-violation","message":"Report breaks reporting rules","fields":{"mark_as":"Recognition was already reviewed for this reason"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionOverRateLimit() {
+	suite.dbMock.On("GetModerationSettings", mock.Anything, 1).Return(db.DefaultModerationSettings(1), nil)
+	suite.dbMock.On("ListReviewedReportTypes", mock.Anything, int64(1)).Return([]string{"fraud"}, nil)
+	suite.dbMock.On("CountReportsBy", mock.Anything, int64(1), mock.Anything).Return(10, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "incorrect", "reason": "Wrong person"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"You can report at most 10 recognitions every 24 hours"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionWhenAlreadyReported() {
+	suite.mockReportRules()
+	suite.dbMock.On("CreateReportedRecognition", mock.Anything, int64(1), mock.Anything).Return(db.ReportedRecognition{}, ae.ErrAlreadyReported)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "fraud", "reason": "Reason Test"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusConflict, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"You have already reported this recognition"}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "QuarantineRecognition", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *ReportedRecognitionHandlerTestSuite) TestCreateReportedRecognitionForArchivedReason() {
+	archivedAt := time.Now()
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(db.Recognition{ID: 1, GivenBy: 2, GivenFor: 3, Status: db.RecognitionStatusPublished}, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 2, 1).Return(db.User{ID: 2, OrgID: 1}, nil)
+	suite.dbMock.On("ListReportReasons", mock.Anything, 1, false).Return([]db.ReportReason{
+		{ID: 1, OrgID: 1, Name: "fraud"},
+		{ID: 4, OrgID: 1, Name: "spam", ArchivedAt: &archivedAt},
+	}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(
+		http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/report",
+		"/recognitions/1/report",
+		`{"mark_as": "spam", "reason": "Looks like an ad"}`,
+		createReportedRecognitionHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-reported-recognition","message":"Invalid reported recognition data","fields":{"mark_as":"Invalid reported recognition type"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateReportedRecognition", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -36,16 +36,73 @@ func InitRouter(deps Dependencies) (router *mux.Router) {
 
 	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values", jwtAuthMiddleware(createCoreValueHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
 
-	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}", jwtAuthMiddleware(deleteCoreValueHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}", jwtAuthMiddleware(archiveCoreValueHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
 
 	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}", jwtAuthMiddleware(updateCoreValueHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
 
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/order", jwtAuthMiddleware(reorderCoreValuesHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads", jwtAuthMiddleware(createCoreValueThumbnailUploadHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/thumbnail_uploads/{upload_id:[0-9]+}/confirm", jwtAuthMiddleware(confirmCoreValueThumbnailUploadHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/translations", jwtAuthMiddleware(listCoreValueTranslationsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/translations/{locale}", jwtAuthMiddleware(saveCoreValueTranslationHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/translations/{locale}", jwtAuthMiddleware(deleteCoreValueTranslationHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
 	//reported recognition
 	router.Handle("/recognitions/{recognition_id:[0-9]+}/report", jwtAuthMiddleware(createReportedRecognitionHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
 
 	//recognition moderation
 	router.Handle("/recognitions/{recognition_id:[0-9]+}/review", jwtAuthMiddleware(createRecognitionModerationHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
 
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/appeals", jwtAuthMiddleware(createRecognitionAppealHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/moderation_history", jwtAuthMiddleware(getRecognitionModerationHistoryHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/queue", jwtAuthMiddleware(listModerationQueueHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/queue/{recognition_id:[0-9]+}/assignment", jwtAuthMiddleware(assignModerationHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/appeals", jwtAuthMiddleware(listRecognitionAppealsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/assignment", jwtAuthMiddleware(assignRecognitionAppealHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/appeals/{id:[0-9]+}/decision", jwtAuthMiddleware(decideRecognitionAppealHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/metrics", jwtAuthMiddleware(getModerationMetricsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/metrics/export", jwtAuthMiddleware(exportModerationMetricsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/settings", jwtAuthMiddleware(getModerationSettingsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/moderation/settings", jwtAuthMiddleware(updateModerationSettingsHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/report_reasons", jwtAuthMiddleware(listReportReasonsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/report_reasons", jwtAuthMiddleware(createReportReasonHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/report_reasons/{id:[0-9]+}", jwtAuthMiddleware(updateReportReasonHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/report_reasons/{id:[0-9]+}", jwtAuthMiddleware(archiveReportReasonHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
+	//content filter
+	router.Handle("/organizations/{organization_id:[0-9]+}/content_filter", jwtAuthMiddleware(getContentFilterSettingsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/content_filter", jwtAuthMiddleware(updateContentFilterSettingsHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/content_filter/blocklist", jwtAuthMiddleware(listContentBlocklistHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/content_filter/blocklist", jwtAuthMiddleware(createContentBlocklistTermHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/content_filter/blocklist/{id:[0-9]+}", jwtAuthMiddleware(deleteContentBlocklistTermHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
+	router.Handle("/me/moderation_notifications", jwtAuthMiddleware(listMyModerationNotificationsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/me/moderation_notifications/{id:[0-9]+}/read", jwtAuthMiddleware(readMyModerationNotificationHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
 	//users
 	router.Handle("/users", jwtAuthMiddleware(listUsersHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
 
@@ -55,6 +112,12 @@ func InitRouter(deps Dependencies) (router *mux.Router) {
 
 	router.Handle("/users/{email}", jwtAuthMiddleware(getUserByEmailHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
 
+	router.Handle("/me/hi5-ledger", jwtAuthMiddleware(listMyHi5LedgerHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/me/hi5_quota", jwtAuthMiddleware(getMyHi5QuotaHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/me/badges/progress", jwtAuthMiddleware(listMyBadgeProgressHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
 	// Basic logout
 	router.Handle("/logout", jwtAuthMiddleware(handleLogout(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
 
@@ -74,6 +137,28 @@ func InitRouter(deps Dependencies) (router *mux.Router) {
 
 	router.Handle("/organizations/{id:[0-9]+}", jwtAuthMiddleware(updateOrganizationHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
 
+	router.Handle("/organizations/{id:[0-9]+}/recognition_rules", jwtAuthMiddleware(getRecognitionRuleSettingsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/recognition_rules", jwtAuthMiddleware(updateRecognitionRuleSettingsHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_quota_schedule", jwtAuthMiddleware(getHi5QuotaScheduleHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_quota_policy", jwtAuthMiddleware(getHi5QuotaPolicyHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_quota_policy", jwtAuthMiddleware(updateHi5QuotaPolicyHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_limit_overrides", jwtAuthMiddleware(listHi5LimitOverridesHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_limit_overrides/roles/{role_id:[0-9]+}", jwtAuthMiddleware(setHi5LimitOverrideHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_limit_overrides/roles/{role_id:[0-9]+}", jwtAuthMiddleware(deleteHi5LimitOverrideHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_limit_overrides/users/{user_id:[0-9]+}", jwtAuthMiddleware(setHi5LimitOverrideHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/hi5_limit_overrides/users/{user_id:[0-9]+}", jwtAuthMiddleware(deleteHi5LimitOverrideHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{id:[0-9]+}/users/{user_id:[0-9]+}/hi5_bonus", jwtAuthMiddleware(grantBonusHi5sHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
 	// badges routes
 	router.Handle("/organizations/{organization_id:[0-9]+}/badges", jwtAuthMiddleware(createBadgeHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
 
@@ -85,6 +170,26 @@ func InitRouter(deps Dependencies) (router *mux.Router) {
 
 	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}", jwtAuthMiddleware(deleteBadgeHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
 
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/holders", jwtAuthMiddleware(listBadgeHoldersHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/grants", jwtAuthMiddleware(grantBadgeHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/revocations", jwtAuthMiddleware(revokeBadgeHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads", jwtAuthMiddleware(createBadgeImageUploadHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/image_uploads/{upload_id:[0-9]+}/confirm", jwtAuthMiddleware(confirmBadgeImageUploadHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/translations", jwtAuthMiddleware(listBadgeTranslationsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/translations/{locale}", jwtAuthMiddleware(saveBadgeTranslationHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/translations/{locale}", jwtAuthMiddleware(deleteBadgeTranslationHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
+	router.Handle("/organizations/{organization_id:[0-9]+}/badge_audit", jwtAuthMiddleware(listBadgeAwardAuditHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/users/{id:[0-9]+}/badges", jwtAuthMiddleware(listUserBadgesHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
 	// Get S3 signed URL
 	router.Handle("/s3_signed_url", jwtAuthMiddleware(getS3SignedURLHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
 
@@ -92,12 +197,31 @@ func InitRouter(deps Dependencies) (router *mux.Router) {
 
 	router.Handle("/recognitions/{recognition_id:[0-9]+}/hi5", jwtAuthMiddleware(createRecognitionHi5Handler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
 
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/hi5", jwtAuthMiddleware(deleteRecognitionHi5Handler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/hi5s", jwtAuthMiddleware(listRecognitionHi5sHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	// Recognition attachments
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/attachments", jwtAuthMiddleware(createRecognitionAttachmentHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/attachments/{id:[0-9]+}/confirm", jwtAuthMiddleware(confirmRecognitionAttachmentHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
+
+	router.Handle("/recognitions/{recognition_id:[0-9]+}/attachments", jwtAuthMiddleware(listRecognitionAttachmentsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
 	// Recognitions
 	router.Handle("/organisations/{orgnization_id:[0-9]+}/recognitions", jwtAuthMiddleware(createRecognitionHandler(deps), deps)).Methods(http.MethodPost).Headers(versionHeader, v1)
 
 	router.Handle("/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}", jwtAuthMiddleware(getRecognitionHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
 
+	router.Handle("/organisations/{orgnization_id:[0-9]+}/recognitions/drafts", jwtAuthMiddleware(listRecognitionDraftsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}", jwtAuthMiddleware(updateRecognitionDraftHandler(deps), deps)).Methods(http.MethodPut).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{orgnization_id:[0-9]+}/recognitions/{recognition_id:[0-9]+}", jwtAuthMiddleware(deleteRecognitionDraftHandler(deps), deps)).Methods(http.MethodDelete).Headers(versionHeader, v1)
+
 	router.Handle("/organisations/{orgnization_id:[0-9]+}/recognitions", jwtAuthMiddleware(listRecognitionsHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
+
+	router.Handle("/organisations/{orgnization_id:[0-9]+}/feed", jwtAuthMiddleware(listRecognitionFeedHandler(deps), deps)).Methods(http.MethodGet).Headers(versionHeader, v1)
 	return
 }
 
@@ -138,18 +262,21 @@ func jwtAuthMiddleware(next http.Handler, deps Dependencies) http.Handler {
 		userID, err := strconv.Atoi(claims["sub"].(string))
 		if err != nil {
 			logger.Error(ae.ErrJSONParseFail, "Error parsing JSON for token response", err)
+			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
 		orgID, err := strconv.Atoi(claims["org"].(string))
 		if err != nil {
 			logger.Error(ae.ErrJSONParseFail, "Error parsing JSON for token response", err)
+			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
 		currentUser, err := deps.Store.GetUser(ctx, userID)
 		if err != nil {
 			logger.WithField("err", err.Error()).Error("Error while fetching User")
+			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
@@ -160,6 +287,6 @@ func jwtAuthMiddleware(next http.Handler, deps Dependencies) http.Handler {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
-		next.ServeHTTP(w, r.WithContext(nextContext))
+		next.ServeHTTP(w, withCurrentActor(r.WithContext(nextContext), currentUser))
 	})
 }
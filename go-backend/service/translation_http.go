@@ -0,0 +1,274 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// @Title listCoreValueTranslationsHandler
+// @Description list the translations of a core value, admins only
+// @Router /organisations/:organisation_id/core_values/:id/translations [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listCoreValueTranslationsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, coreValue, ok := coreValueForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		translations, err := deps.Store.ListCoreValueTranslations(req.Context(), coreValue.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while listing core value translations")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: translations})
+	})
+}
+
+// @Title saveCoreValueTranslationHandler
+// @Description add or replace the translation of a core value for a locale, admins only
+// @Router /organisations/:organisation_id/core_values/:id/translations/:locale [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func saveCoreValueTranslationHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, coreValue, ok := coreValueForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		var translation db.CoreValueTranslation
+		err := json.NewDecoder(req.Body).Decode(&translation)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		translation.CoreValueID = coreValue.ID
+		translation.Locale = mux.Vars(req)["locale"]
+
+		valid, errFields := translation.Validate()
+		if !valid {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-translation",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid translation data"},
+				},
+			})
+			return
+		}
+
+		savedTranslation, err := deps.Store.SaveCoreValueTranslation(req.Context(), translation)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while saving core value translation")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: savedTranslation})
+	})
+}
+
+// @Title deleteCoreValueTranslationHandler
+// @Description remove the translation of a core value for a locale, admins only
+// @Router /organisations/:organisation_id/core_values/:id/translations/:locale [delete]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func deleteCoreValueTranslationHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, coreValue, ok := coreValueForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		locale, ok := translationLocale(rw, req)
+		if !ok {
+			return
+		}
+
+		err := deps.Store.DeleteCoreValueTranslation(req.Context(), coreValue.ID, locale)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Translation not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while deleting core value translation")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, nil)
+	})
+}
+
+// @Title listBadgeTranslationsHandler
+// @Description list the translations of a badge, admins only
+// @Router /organizations/:organization_id/badges/:id/translations [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listBadgeTranslationsHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, badge, ok := badgeForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		translations, err := deps.Store.ListBadgeTranslations(req.Context(), badge.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while listing badge translations")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: translations})
+	})
+}
+
+// @Title saveBadgeTranslationHandler
+// @Description add or replace the translation of a badge for a locale, admins only
+// @Router /organizations/:organization_id/badges/:id/translations/:locale [put]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func saveBadgeTranslationHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, badge, ok := badgeForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		var translation db.BadgeTranslation
+		err := json.NewDecoder(req.Body).Decode(&translation)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while decoding request data")
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: messageObject{
+					Message: "Invalid json request body",
+				},
+			})
+			return
+		}
+		translation.BadgeID = badge.ID
+		translation.Locale = mux.Vars(req)["locale"]
+
+		valid, errFields := translation.Validate()
+		if !valid {
+			repsonse(rw, http.StatusBadRequest, errorResponse{
+				Error: errorObject{
+					Code:          "invalid-translation",
+					Fields:        errFields,
+					messageObject: messageObject{"Invalid translation data"},
+				},
+			})
+			return
+		}
+
+		savedTranslation, err := deps.Store.SaveBadgeTranslation(req.Context(), translation)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while saving badge translation")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: savedTranslation})
+	})
+}
+
+// @Title deleteBadgeTranslationHandler
+// @Description remove the translation of a badge for a locale, admins only
+// @Router /organizations/:organization_id/badges/:id/translations/:locale [delete]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func deleteBadgeTranslationHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, badge, ok := badgeForAdmin(rw, req, deps)
+		if !ok {
+			return
+		}
+
+		locale, ok := translationLocale(rw, req)
+		if !ok {
+			return
+		}
+
+		err := deps.Store.DeleteBadgeTranslation(req.Context(), badge.ID, locale)
+		if err == ae.ErrRecordNotFound {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "Translation not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while deleting badge translation")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, nil)
+	})
+}
+
+// translationLocale - the canonical form of the locale in the url, writing the error response
+// and returning false when it isn't a valid locale
+func translationLocale(rw http.ResponseWriter, req *http.Request) (locale string, ok bool) {
+	locale, ok = db.CanonicalLocale(mux.Vars(req)["locale"])
+	if !ok {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          "invalid-translation",
+				Fields:        map[string]string{"locale": "Please enter a valid locale, e.g. en or de-DE"},
+				messageObject: messageObject{"Invalid translation data"},
+			},
+		})
+	}
+	return
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"net/http"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type TranslationHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *TranslationHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+	suite.dbMock.On("GetRoleByID", mock.Anything, 2).Return(db.Role{ID: 2, Name: db.AdminRoleName}, nil)
+}
+
+func (suite *TranslationHandlerTestSuite) TestRequestLocales() {
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	assert.Empty(suite.T(), requestLocales(req))
+
+	req.Header.Set("Accept-Language", "en;q=0.5, de-AT, ja;q=0.8, fr;q=0")
+	assert.Equal(suite.T(), []string{"de-AT", "de", "ja", "en"}, requestLocales(req))
+
+	req.Header.Set("Accept-Language", "not a header;;")
+	assert.Empty(suite.T(), requestLocales(req))
+}
+
+func (suite *TranslationHandlerTestSuite) TestListCoreValuesInRequestedLocale() {
+	suite.dbMock.On("ListCoreValues", mock.Anything, int64(1), false).Return([]db.CoreValue{
+		{ID: 1, OrgID: 1, Text: "Teamwork", Description: "Working together"},
+		{ID: 2, OrgID: 1, Text: "Quality", Description: "Doing it right", Position: 1},
+	}, nil)
+	suite.dbMock.On("PreferredCoreValueTranslations", mock.Anything, int64(1), []string{"de-DE", "de"}).Return(
+		map[int64]db.CoreValueTranslation{1: {CoreValueID: 1, Locale: "de", Text: "Teamarbeit", Description: "Zusammenarbeiten"}}, nil)
+
+	recorder := makeHTTPCallWithHeaders(http.MethodGet,
+		"/organisations/{organisation_id:[0-9]+}/core_values",
+		"/organisations/1/core_values",
+		"",
+		map[string]string{"Accept-Language": "de-DE"},
+		listCoreValuesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":[{"id":1,"org_id":1,"text":"Teamarbeit","description":"Zusammenarbeiten","parent_id":null,"thumbnail_url":null,"position":0},{"id":2,"org_id":1,"text":"Quality","description":"Doing it right","parent_id":null,"thumbnail_url":null,"position":1}]}`, recorder.Body.String())
+	suite.dbMock.AssertCalled(suite.T(), "PreferredCoreValueTranslations", mock.Anything, int64(1), []string{"de-DE", "de"})
+}
+
+func (suite *TranslationHandlerTestSuite) TestShowBadgeInRequestedLocale() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("PreferredBadgeTranslations", mock.Anything, 1, []string{"ja"}).Return(
+		map[int]db.BadgeTranslation{2: {BadgeID: 2, Locale: "ja", Name: "ハッカソン優勝者"}}, nil)
+
+	recorder := makeHTTPCallWithHeaders(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}",
+		"/organizations/1/badges/2",
+		"",
+		map[string]string{"Accept-Language": "ja"},
+		showBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"id":2,"name":"ハッカソン優勝者","org_id":1,"hi5_count_required":100,"hi5_frequency":"","image":null}}`, recorder.Body.String())
+}
+
+func (suite *TranslationHandlerTestSuite) TestSaveCoreValueTranslationSuccess() {
+	suite.dbMock.On("GetCoreValue", mock.Anything, int64(1), int64(3)).Return(testThumbnailCoreValue, nil)
+	suite.dbMock.On("SaveCoreValueTranslation", mock.Anything, db.CoreValueTranslation{
+		CoreValueID: 3, Locale: "de-DE", Text: "Teamarbeit", Description: "Zusammenarbeiten",
+	}).Return(db.CoreValueTranslation{CoreValueID: 3, Locale: "de-DE", Text: "Teamarbeit", Description: "Zusammenarbeiten"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/translations/{locale}",
+		"/organisations/1/core_values/3/translations/de_de",
+		`{"text":"Teamarbeit","description":"Zusammenarbeiten"}`,
+		testAdmin,
+		saveCoreValueTranslationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	assert.Equal(suite.T(), `{"data":{"core_value_id":3,"locale":"de-DE","text":"Teamarbeit","description":"Zusammenarbeiten"}}`, recorder.Body.String())
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *TranslationHandlerTestSuite) TestSaveBadgeTranslationWithInvalidLocale() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPut,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/translations/{locale}",
+		"/organizations/1/badges/2/translations/klingon-galaxy",
+		`{"name":""}`,
+		testAdmin,
+		saveBadgeTranslationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-translation","message":"Invalid translation data","fields":{"locale":"Please enter a valid locale, e.g. en or de-DE","name":"Can't be blank"}}}`, recorder.Body.String())
+	suite.dbMock.AssertNotCalled(suite.T(), "SaveBadgeTranslation", mock.Anything, mock.Anything)
+}
+
+func (suite *TranslationHandlerTestSuite) TestDeleteBadgeTranslationNotFound() {
+	suite.dbMock.On("ShowBadge", mock.Anything, db.Badge{ID: 2, OrganizationID: 1}).Return(testGrantedBadge, nil)
+	suite.dbMock.On("DeleteBadgeTranslation", mock.Anything, 2, "ja").Return(ae.ErrRecordNotFound)
+
+	recorder := makeHTTPCallAsActor(http.MethodDelete,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/translations/{locale}",
+		"/organizations/1/badges/2/translations/ja",
+		"",
+		testAdmin,
+		deleteBadgeTranslationHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	assert.Equal(suite.T(), `{"error":{"message":"Translation not found"}}`, recorder.Body.String())
+}
+
+func (suite *TranslationHandlerTestSuite) TestListCoreValueTranslationsWhenNotAdmin() {
+	suite.dbMock.On("GetRoleByID", mock.Anything, 1).Return(db.Role{ID: 1, Name: "Employee"}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organisations/{organisation_id:[0-9]+}/core_values/{id:[0-9]+}/translations",
+		"/organisations/1/core_values/3/translations",
+		"",
+		db.User{ID: 5, OrgID: 1, RoleID: 1},
+		listCoreValueTranslationsHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListCoreValueTranslations", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	ae "joshsoftware/peerly/apperrors"
+	"joshsoftware/peerly/aws"
+	"joshsoftware/peerly/config"
+	"joshsoftware/peerly/util/thumbnail"
+
+	logger "github.com/sirupsen/logrus"
+)
+
+// uploadKind - what sets one kind of upload apart in the shared upload flow: where its files
+// are stored and how it is named in error responses, e.g. "invalid-badge-image" and "badge image"
+type uploadKind struct {
+	bucket    string
+	errorCode string
+	name      string
+}
+
+// pendingUpload - an upload slot being confirmed, with the store callbacks of its kind
+type pendingUpload struct {
+	uploadKind
+	objectKey string
+	// verify compares the object that landed in storage with what the slot was handed out for
+	verify func(size int64, contentType string) (valid bool, errFields map[string]string)
+	// discard deletes the slot once its upload is rejected
+	discard func(ctx context.Context) error
+}
+
+// resizedImages - the copies generated from an uploaded image, one per size
+type resizedImages struct {
+	format       string
+	maxDimension int
+	sizes        []int
+	objectKey    func(size int) string
+	contentType  string
+}
+
+// createUploadSlot - validates the requested upload, stores its slot with create and signs where to PUT
+// the file, writing the error response and returning false when the request can't go ahead
+func createUploadSlot(rw http.ResponseWriter, req *http.Request, deps Dependencies, kind uploadKind,
+	validate func() (valid bool, errFields map[string]string),
+	create func(ctx context.Context) (objectKey, contentType string, err error)) (uploadURL string, ok bool) {
+	valid, errFields := validate()
+	if !valid {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          kind.errorCode,
+				Fields:        errFields,
+				messageObject: messageObject{"Invalid " + kind.name + " data"},
+			},
+		})
+		return
+	}
+
+	objectKey, contentType, err := create(req.Context())
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while creating " + kind.name + " upload")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	signedURL, err := deps.AWSStore.GetAWSS3UploadURL(req.Context(), kind.bucket, objectKey, contentType)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while retrieving upload URL")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Error while retrieving URL",
+			},
+		})
+		return
+	}
+
+	return signedURL.S3SignedURL, true
+}
+
+// verifyUploadedObject - checks the file was uploaded and matches its slot, rejecting the upload when it doesn't
+func verifyUploadedObject(rw http.ResponseWriter, req *http.Request, deps Dependencies, upload pendingUpload) (objectInfo aws.S3ObjectInfo, ok bool) {
+	objectInfo, err := deps.AWSStore.GetAWSS3ObjectInfo(req.Context(), upload.bucket, upload.objectKey)
+	if err != nil {
+		repsonse(rw, http.StatusBadRequest, errorResponse{
+			Error: errorObject{
+				Code:          upload.errorCode,
+				Fields:        map[string]string{"file": "File has not been uploaded"},
+				messageObject: messageObject{"Invalid " + upload.name + " upload"},
+			},
+		})
+		return
+	}
+
+	valid, errFields := upload.verify(objectInfo.Size, objectInfo.ContentType)
+	if !valid {
+		rejectUpload(rw, req, deps, upload, errFields)
+		return
+	}
+
+	ok = true
+	return
+}
+
+// storeResizedImages - decodes the uploaded image and stores a resized copy of it for each size,
+// rejecting the upload when it isn't a usable image
+func storeResizedImages(rw http.ResponseWriter, req *http.Request, deps Dependencies, upload pendingUpload, resized resizedImages) (ok bool) {
+	data, err := deps.AWSStore.GetAWSS3Object(req.Context(), upload.bucket, upload.objectKey)
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while fetching " + upload.name)
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	img, err := thumbnail.Decode(data, resized.format, resized.maxDimension)
+	if err == ae.ErrImageTooLarge {
+		rejectUpload(rw, req, deps, upload, map[string]string{
+			"file": "Must be at most " + strconv.Itoa(resized.maxDimension) + " pixels wide and tall",
+		})
+		return
+	}
+	if err != nil {
+		rejectUpload(rw, req, deps, upload, map[string]string{"file": "Must be a valid image of its content type"})
+		return
+	}
+
+	for _, size := range resized.sizes {
+		var encoded []byte
+		encoded, err = thumbnail.EncodePNG(thumbnail.Fit(img, size))
+		if err == nil {
+			err = deps.AWSStore.PutAWSS3Object(req.Context(), upload.bucket, resized.objectKey(size), resized.contentType, encoded)
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while storing resized " + upload.name)
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+	}
+
+	ok = true
+	return
+}
+
+// rejectUpload - throws away an upload that doesn't match its slot or isn't usable rather than keep an
+// unverified file around. The slot is only discarded once its file is gone, so a failed rejection can be retried.
+func rejectUpload(rw http.ResponseWriter, req *http.Request, deps Dependencies, upload pendingUpload, errFields map[string]string) {
+	err := deps.AWSStore.DeleteAWSS3Object(req.Context(), upload.bucket, upload.objectKey)
+	if err == nil {
+		err = upload.discard(req.Context())
+	}
+	if err != nil {
+		logger.WithField("err", err.Error()).Error("Error while rejecting " + upload.name + " upload")
+		repsonse(rw, http.StatusInternalServerError, errorResponse{
+			Error: messageObject{
+				Message: "Internal server error",
+			},
+		})
+		return
+	}
+
+	repsonse(rw, http.StatusBadRequest, errorResponse{
+		Error: errorObject{
+			Code:          upload.errorCode,
+			Fields:        errFields,
+			messageObject: messageObject{"Invalid " + upload.name + " upload"},
+		},
+	})
+}
+
+// deleteStoredObjects - removes objects from the images bucket. Failures are only logged, an orphaned
+// object costs some storage but must not fail the request that stopped using it.
+func deleteStoredObjects(ctx context.Context, deps Dependencies, objectKeys []string) {
+	bucket := config.ImagesBucket()
+	for _, objectKey := range objectKeys {
+		err := deps.AWSStore.DeleteAWSS3Object(ctx, bucket, objectKey)
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"err": err.Error(),
+				"key": objectKey,
+			}).Error("Error while deleting stored object")
+		}
+	}
+}
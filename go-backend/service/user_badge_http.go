@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	logger "github.com/sirupsen/logrus"
+)
+
+// awardBadges - evaluates the organization's badges for the user. Awarding is best effort:
+// a failure is only logged since the nightly job awards anything missed here.
+func awardBadges(ctx context.Context, deps Dependencies, organizationID, userID int) {
+	awarded, err := deps.Store.AwardBadges(ctx, organizationID, userID)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"err":     err.Error(),
+			"user_id": userID,
+		}).Error("Error while awarding badges")
+		return
+	}
+
+	for _, userBadge := range awarded {
+		logger.WithFields(logger.Fields{
+			"user_id":  userBadge.UserID,
+			"badge_id": userBadge.BadgeID,
+		}).Info("Badge awarded")
+	}
+}
+
+// @Title listUserBadgesHandler
+// @Description list the badges awarded to a user of the current user's organization
+// @Router /users/:id/badges [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listUserBadgesHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		userID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		_, err = deps.Store.GetUserByOrganization(req.Context(), userID, actor.OrgID)
+		if err == sql.ErrNoRows {
+			repsonse(rw, http.StatusNotFound, errorResponse{
+				Error: messageObject{
+					Message: "User not found",
+				},
+			})
+			return
+		}
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching user")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		badges, err := deps.Store.ListUserBadges(req.Context(), userID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while listing user badges")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		names, err := localizedBadgeNames(req, deps, actor.OrgID)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		for i := range badges {
+			if name, ok := names[badges[i].BadgeID]; ok {
+				badges[i].Name = name
+			}
+			badges[i].Image, err = signBadgeImage(req, deps, badges[i].ImageKey)
+			if err != nil {
+				repsonse(rw, http.StatusInternalServerError, errorResponse{
+					Error: messageObject{
+						Message: "Error while retrieving URL",
+					},
+				})
+				return
+			}
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: badges})
+	})
+}
+
+// @Title listBadgeHoldersHandler
+// @Description list the users holding a badge of the organization
+// @Router /organizations/:organization_id/badges/:id/holders [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listBadgeHoldersHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		vars := mux.Vars(req)
+		organizationID, err := strconv.Atoi(vars["organization_id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error organization_id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		badgeID, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error id key is missing")
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		if actor.OrgID != organizationID {
+			repsonse(rw, http.StatusForbidden, errorResponse{
+				Error: messageObject{
+					Message: "User doesn't belong to given organization",
+				},
+			})
+			return
+		}
+
+		holders, err := deps.Store.ListBadgeHolders(req.Context(), organizationID, badgeID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while listing badge holders")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: holders})
+	})
+}
+
+// @Title listMyBadgeProgressHandler
+// @Description how close the current user is to each badge of their organization
+// @Router /me/badges/progress [get]
+// @Accept  json
+// @Success 200 {object}
+// @Failure 400 {object}
+func listMyBadgeProgressHandler(deps Dependencies) http.HandlerFunc {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		actor, err := getCurrentActor(req)
+		if err != nil {
+			currentActorErrorResponse(rw, err)
+			return
+		}
+
+		progress, err := deps.Store.ListBadgeProgress(req.Context(), actor.OrgID, actor.ID)
+		if err != nil {
+			logger.WithField("err", err.Error()).Error("Error while fetching badge progress")
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		names, err := localizedBadgeNames(req, deps, actor.OrgID)
+		if err != nil {
+			repsonse(rw, http.StatusInternalServerError, errorResponse{
+				Error: messageObject{
+					Message: "Internal server error",
+				},
+			})
+			return
+		}
+
+		for i := range progress {
+			if name, ok := names[progress[i].BadgeID]; ok {
+				progress[i].Name = name
+			}
+			progress[i].Image, err = signBadgeImage(req, deps, progress[i].ImageKey)
+			if err != nil {
+				repsonse(rw, http.StatusInternalServerError, errorResponse{
+					Error: messageObject{
+						Message: "Error while retrieving URL",
+					},
+				})
+				return
+			}
+		}
+
+		repsonse(rw, http.StatusOK, successResponse{Data: progress})
+	})
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"joshsoftware/peerly/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserBadgeHandlerTestSuite struct {
+	suite.Suite
+
+	dbMock *db.DBMockStore
+}
+
+func (suite *UserBadgeHandlerTestSuite) SetupTest() {
+	suite.dbMock = &db.DBMockStore{}
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestListUserBadgesSuccess() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{ID: 7, OrgID: 1}, nil)
+	suite.dbMock.On("ListUserBadges", mock.Anything, 7).Return([]db.UserBadge{
+		{ID: 10, UserID: 7, BadgeID: 2, Name: "Weekly star", Tier: db.SilverTier, AchievedCount: 3, AwardedAt: 1594339200, Source: db.AutomaticBadgeAward},
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/users/{id:[0-9]+}/badges",
+		"/users/7/badges",
+		"",
+		db.User{ID: 1, OrgID: 1},
+		listUserBadgesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":[{"id":10,"user_id":7,"badge_id":2,"name":"Weekly star","tier":"silver","achieved_count":3,"awarded_at":1594339200,"source":"automatic","image":null}]}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestListUserBadgesOfUserInAnotherOrganization() {
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, 7, 1).Return(db.User{}, sql.ErrNoRows)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/users/{id:[0-9]+}/badges",
+		"/users/7/badges",
+		"",
+		db.User{ID: 1, OrgID: 1},
+		listUserBadgesHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"message":"User not found"}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusNotFound, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListUserBadges", mock.Anything, mock.Anything)
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestListBadgeHoldersSuccess() {
+	suite.dbMock.On("ListBadgeHolders", mock.Anything, 1, 2).Return([]db.BadgeHolder{
+		{UserID: 7, Name: "Jane Doe", DisplayName: "jane", AchievedCount: 3, AwardedAt: 1594339200, Source: db.AutomaticBadgeAward},
+	}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/holders",
+		"/organizations/1/badges/2/holders",
+		"",
+		db.User{ID: 1, OrgID: 1},
+		listBadgeHoldersHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":[{"user_id":7,"full_name":"Jane Doe","display_name":"jane","profile_image_url":"","tier":"","achieved_count":3,"awarded_at":1594339200,"source":"automatic"}]}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestListBadgeHoldersOfAnotherOrganization() {
+	recorder := makeHTTPCallAsActor(http.MethodGet,
+		"/organizations/{organization_id:[0-9]+}/badges/{id:[0-9]+}/holders",
+		"/organizations/2/badges/2/holders",
+		"",
+		db.User{ID: 1, OrgID: 1},
+		listBadgeHoldersHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusForbidden, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "ListBadgeHolders", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestAwardBadgesFailureDoesNotFailHi5() {
+	suite.dbMock.On("CreateRecognitionHi5", mock.Anything, testRecognitionHi5, testRecognitionHi5.RecognitionID).Return(nil)
+	suite.dbMock.On("ShowRecognition", mock.Anything).Return(testPublishedRecognition, nil)
+	suite.dbMock.On("GetUserByOrganization", mock.Anything, mock.Anything, 1).Return(db.User{}, nil)
+	suite.dbMock.On("GetRecognitionRuleSettings", mock.Anything, 1).Return(db.DefaultRecognitionRuleSettings(1), nil)
+	suite.dbMock.On("GetContentFilterSettings", mock.Anything, 1).Return(db.DefaultContentFilterSettings(1), nil)
+	suite.dbMock.On("AwardBadges", mock.Anything, 1, testPublishedRecognition.GivenFor).Return([]db.UserBadge{}, errors.New("connection reset"))
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/recognitions/{recognition_id:[0-9]+}/hi5",
+		"/recognitions/1/hi5",
+		`{"id": 1, "recognition_id": 1, "comment": "Test Comment"}`,
+		testHi5Giver,
+		createRecognitionHi5Handler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), http.StatusCreated, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestCreateBadgeWithCoreValueOfAnotherOrganization() {
+	suite.dbMock.On("ListCoreValues", mock.Anything, int64(1), false).Return([]db.CoreValue{{ID: 4, OrgID: 1}}, nil)
+
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges",
+		"/organizations/1/badges",
+		`{"name":"Innovator","criteria":{"metric":"recognitions_received","core_value_ids":[4,9],"tiers":{"bronze":1,"silver":5,"gold":10}}}`,
+		db.User{ID: 1, OrgID: 1},
+		createBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-badge","message":"Please provide valid badge data","fields":{"criteria.core_value_ids":"Must be core values of the organization"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+	suite.dbMock.AssertNotCalled(suite.T(), "CreateBadge", mock.Anything, mock.Anything)
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestCreateBadgeWithInvalidCriteria() {
+	recorder := makeHTTPCallAsActor(http.MethodPost,
+		"/organizations/{organization_id:[0-9]+}/badges",
+		"/organizations/1/badges",
+		`{"name":"Regular","criteria":{"metric":"weekly_streak","window_days":7,"threshold":4}}`,
+		db.User{ID: 1, OrgID: 1},
+		createBadgeHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"error":{"code":"invalid-badge","message":"Please provide valid badge data","fields":{"criteria.window_days":"Can't be set for streaks"}}}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusBadRequest, recorder.Code)
+}
+
+func (suite *UserBadgeHandlerTestSuite) TestListMyBadgeProgress() {
+	expiresAt := int64(1594944000)
+	suite.dbMock.On("ListBadgeProgress", mock.Anything, 1, 1).Return([]db.BadgeProgress{{
+		BadgeID:      2,
+		Name:         "Team player",
+		Metric:       db.DistinctGiversMetric,
+		CoreValueIDs: []int64{},
+		Current:      5,
+		Target:       8,
+		NextTier:     db.GoldTier,
+		Levels:       []db.BadgeLevel{{Tier: db.BronzeTier, Threshold: 2}, {Tier: db.SilverTier, Threshold: 4}, {Tier: db.GoldTier, Threshold: 8}},
+		EarnedTiers:  []string{db.BronzeTier, db.SilverTier},
+		WindowDays:   7,
+		ExpiresAt:    &expiresAt,
+	}}, nil)
+
+	recorder := makeHTTPCallWithJWTMiddleware(http.MethodGet,
+		"/me/badges/progress",
+		"/me/badges/progress",
+		"",
+		listMyBadgeProgressHandler(Dependencies{Store: suite.dbMock}),
+	)
+
+	assert.Equal(suite.T(), `{"data":[{"badge_id":2,"name":"Team player","metric":"distinct_givers","core_value_ids":[],"current":5,"target":8,"next_tier":"gold","levels":[{"tier":"bronze","threshold":2},{"tier":"silver","threshold":4},{"tier":"gold","threshold":8}],"earned_tiers":["bronze","silver"],"completed":false,"window_days":7,"expires_at":1594944000,"image":null}]}`, recorder.Body.String())
+	assert.Equal(suite.T(), http.StatusOK, recorder.Code)
+	suite.dbMock.AssertExpectations(suite.T())
+}
@@ -13,8 +13,13 @@ import (
 // external scripts that would otherwise do the same thing.
 func Init(deps service.Dependencies) {
 	s1 := gocron.NewScheduler(time.UTC)
-	//To reset Hi5 quota balance user's of each organization
-	s1.Every(1).Day().At("00:00").Do(deps.Store.ResetHi5QuotaBalanceJob)
+	//To reset Hi5 quota balance user's of each organization. Runs every minute since each
+	//organization's schedule is evaluated in its own timezone and may be a cron expression
+	s1.Every(1).Minute().Do(deps.Store.ResetHi5QuotaBalanceJob)
 	s1.Every(1).Hours().Do(deps.Store.CleanBlacklistedTokens)
+	//To publish recognitions whose scheduled publish time has passed
+	s1.Every(1).Minute().Do(deps.Store.PublishScheduledRecognitionsJob)
+	//To award badges earned through Hi5s the request time evaluation missed
+	s1.Every(1).Day().At("02:00").Do(deps.Store.AwardBadgesJob)
 	s1.Start()
 }
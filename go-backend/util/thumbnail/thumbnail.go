@@ -0,0 +1,103 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	// registers the formats image.Decode accepts
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+
+	ae "joshsoftware/peerly/apperrors"
+)
+
+// Decode - decodes a "png", "jpeg" or "gif" image, checking that it really is in the given format
+// and that neither side is larger than maxDimension before the pixels are decoded
+func Decode(data []byte, format string, maxDimension int) (img image.Image, err error) {
+	config, decodedFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decodedFormat != format {
+		err = ae.ErrUnsupportedImage
+		return
+	}
+
+	if config.Width > maxDimension || config.Height > maxDimension {
+		err = ae.ErrImageTooLarge
+		return
+	}
+
+	img, _, err = image.Decode(bytes.NewReader(data))
+	if err != nil {
+		err = ae.ErrUnsupportedImage
+		return
+	}
+	return
+}
+
+// Fit - scales img down to fit within a size x size square, keeping its aspect ratio. Each pixel
+// of the thumbnail is the average of the pixels it covers; images that already fit are only copied.
+func Fit(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	width, height := srcWidth, srcHeight
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, srcHeight*size/srcWidth
+		} else {
+			width, height = srcWidth*size/srcHeight, size
+		}
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, srcHeight)
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, srcWidth)
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := img.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+
+			// RGBA() is premultiplied and 16 bit, the same as image.RGBA apart from the depth
+			offset := thumb.PixOffset(x, y)
+			thumb.Pix[offset] = uint8(r / count >> 8)
+			thumb.Pix[offset+1] = uint8(g / count >> 8)
+			thumb.Pix[offset+2] = uint8(b / count >> 8)
+			thumb.Pix[offset+3] = uint8(a / count >> 8)
+		}
+	}
+	return thumb
+}
+
+// span - the source pixels [from, to) covered by pixel i of a dimension scaled from srcLength to length
+func span(i, length, srcLength int) (from, to int) {
+	from = i * srcLength / length
+	to = (i + 1) * srcLength / length
+	if to <= from {
+		to = from + 1
+	}
+	return
+}
+
+// EncodePNG - thumbnails are always stored as PNG so transparency survives
+func EncodePNG(img image.Image) (data []byte, err error) {
+	var buf bytes.Buffer
+	err = png.Encode(&buf, img)
+	if err != nil {
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
@@ -0,0 +1,58 @@
+package thumbnail
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	ae "joshsoftware/peerly/apperrors"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodedPNG(width, height int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+	img, err := Decode(encodedPNG(40, 20), "png", 100)
+	assert.Nil(t, err)
+	assert.Equal(t, image.Rect(0, 0, 40, 20), img.Bounds())
+
+	_, err = Decode(encodedPNG(40, 20), "gif", 100)
+	assert.Equal(t, ae.ErrUnsupportedImage, err)
+
+	_, err = Decode(encodedPNG(40, 200), "png", 100)
+	assert.Equal(t, ae.ErrImageTooLarge, err)
+
+	_, err = Decode([]byte("not an image"), "gif", 100)
+	assert.Equal(t, ae.ErrUnsupportedImage, err)
+
+	var buf bytes.Buffer
+	gif.Encode(&buf, image.NewPaletted(image.Rect(0, 0, 8, 8), color.Palette{color.Black, color.White}), nil)
+	_, err = Decode(buf.Bytes(), "gif", 100)
+	assert.Nil(t, err)
+}
+
+func TestFitKeepsAspectRatio(t *testing.T) {
+	assert.Equal(t, image.Rect(0, 0, 64, 32), Fit(image.NewRGBA(image.Rect(0, 0, 512, 256)), 64).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 16, 64), Fit(image.NewRGBA(image.Rect(0, 0, 100, 400)), 64).Bounds())
+	assert.Equal(t, image.Rect(0, 0, 1, 64), Fit(image.NewRGBA(image.Rect(0, 0, 2, 1000)), 64).Bounds())
+	// smaller images are not scaled up
+	assert.Equal(t, image.Rect(0, 0, 30, 20), Fit(image.NewRGBA(image.Rect(0, 0, 30, 20)), 64).Bounds())
+}
+
+func TestFitAveragesPixels(t *testing.T) {
+	src := image.NewRGBA(image.Rect(10, 10, 12, 11))
+	src.Set(10, 10, color.RGBA{R: 200, A: 255})
+	src.Set(11, 10, color.RGBA{B: 100, A: 255})
+
+	thumb := Fit(src, 1)
+
+	assert.Equal(t, color.RGBA{R: 100, B: 50, A: 255}, thumb.At(0, 0))
+}